@@ -1,14 +1,26 @@
 package app_server
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
 	"strings"
 	_ "todo-app/docs"
 	"todo-app/internal/auth"
+	"todo-app/internal/calendar"
+	"todo-app/internal/deltasync"
+	"todo-app/internal/events"
+	"todo-app/internal/habits"
+	"todo-app/internal/importers"
+	"todo-app/internal/rules"
+	"todo-app/internal/stats"
+	"todo-app/internal/templates"
 	"todo-app/internal/todos"
+	"todo-app/internal/transfer"
 	"todo-app/internal/users"
+	"todo-app/internal/webhooks"
+	"todo-app/pkg/database"
 	"todo-app/pkg/email"
 
 	"github.com/go-playground/validator/v10"
@@ -46,22 +58,83 @@ func InitializeServer() {
 	todoRepository := todos.GetRepository(logger, db)
 	userRepository := users.GetRepository(logger, db)
 	authRepository := auth.GetRepository(logger, db)
+	templateRepository := templates.GetRepository(logger, db)
+	statsRepository := stats.GetRepository(logger, db)
+	habitRepository := habits.GetRepository(logger, db)
+	transferRepository := transfer.GetRepository(logger, db)
+	calendarRepository := calendar.GetRepository(logger, db)
+	importRepository := importers.GetRepository(logger, db)
+	syncRepository := deltasync.GetRepository(logger, db)
+	webhookRepository := webhooks.GetRepository(logger, db)
+	ruleRepository := rules.GetRepository(logger, db)
 
+	transactor := database.GetTransactor(db)
 	v := validator.New()
 
+	jwtKeys, err := auth.LoadKeys(logger)
+	if err != nil {
+		logger.Errorw("failed to load JWT signing keys", "error", err)
+		os.Exit(1)
+	}
+
 	//Initialize services
 	emailService := email.GetService(logger)
-	authService := auth.GetService(logger, userRepository, authRepository, v)
-	todoService := todos.GetService(logger, todoRepository, v)
+	authService := auth.GetService(logger, userRepository, authRepository, transactor, emailService, jwtKeys, v)
+	todoService := todos.GetService(logger, todoRepository, transactor, v)
 	userService := users.GetService(logger, userRepository, v, emailService)
+	templateService := templates.GetService(logger, templateRepository, todoService, transactor, v)
+	statsService := stats.GetService(logger, statsRepository)
+	habitService := habits.GetService(logger, habitRepository, v)
+	transferService := transfer.GetService(logger, transferRepository, todoService, v)
+	calendarService := calendar.GetService(logger, calendarRepository, userRepository, todoService, transferService, v)
+	importService := importers.GetService(logger, importRepository, todoService)
+	eventHub := events.GetHub(logger)
+	syncService := deltasync.GetService(logger, syncRepository, todoService, v)
+	webhookService := webhooks.GetService(logger, webhookRepository, v)
+	ruleService := rules.GetService(logger, ruleRepository, userRepository, todoService, emailService, v)
+
+	// Jobs that were running when the server stopped will not finish
+	if err := importService.FailInterrupted(context.Background()); err != nil {
+		logger.Errorw("failed to fail interrupted import jobs", "error", err)
+	}
+
+	// Delete what belongs to a todo item along with it
+	todoService.OnDelete(habitService.DeleteCheckIns)
+
+	// Subscribe to todo changes
+	todoService.Subscribe(statsService.HandleTodoEvent)
+	todoService.Subscribe(eventHub.Publish)
+	todoService.Subscribe(syncService.HandleTodoEvent)
+	todoService.Subscribe(webhookService.HandleTodoEvent)
+	todoService.Subscribe(ruleService.HandleTodoEvent)
+	userService.Subscribe(webhookService.HandleUserEvent)
+	userService.Subscribe(authService.HandleUserEvent)
+
+	// Send webhook deliveries in the background, including those pending from before a restart
+	go webhookService.Run(context.Background())
+	// Fire due_soon rules as items become due
+	go ruleService.Run(context.Background())
 
 	// Initialize handlers
 	todoEndpointHandler := todos.GetEndpointHandler(logger, todoService, e)
 	userEndpointHandler := users.GetEndpointHandler(logger, userService, e)
 	authEndpointHandler := auth.GetEndpointHandler(logger, authService, e)
+	templateEndpointHandler := templates.GetEndpointHandler(logger, templateService, e)
+	statsEndpointHandler := stats.GetEndpointHandler(logger, statsService, e)
+	habitEndpointHandler := habits.GetEndpointHandler(logger, habitService, todoService, e)
+	transferEndpointHandler := transfer.GetEndpointHandler(logger, transferService, e)
+	calendarEndpointHandler := calendar.GetEndpointHandler(logger, calendarService, e)
+	importEndpointHandler := importers.GetEndpointHandler(logger, importService, e)
+	eventEndpointHandler := events.GetEndpointHandler(logger, eventHub, authService, todoService, e)
+	syncEndpointHandler := deltasync.GetEndpointHandler(logger, syncService, e)
+	webhookEndpointHandler := webhooks.GetEndpointHandler(logger, webhookService, e)
+	ruleEndpointHandler := rules.GetEndpointHandler(logger, ruleService, e)
 
 	jwtMiddleware := auth.JWTMiddleware(authService, logger)
 
+	// Sessions record the device they were started from
+	e.Use(auth.ClientMiddleware())
+
 	// Apply JWT middleware to protected routes
 	if os.Getenv("APP_ENV") != "test" {
 		e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -72,8 +145,20 @@ func InitializeServer() {
 				// Public routes that do not require authentication
 				isPublicRoute := (method == http.MethodPost && path == "/user") ||
 					path == "/auth/login" ||
+					// the token of the login is in the body
+					path == "/auth/mfa/verify" ||
+					(method == http.MethodPost && strings.HasPrefix(path, "/auth/webauthn/login/")) ||
+					(method == http.MethodPost && strings.HasPrefix(path, "/auth/webauthn/mfa/")) ||
+					(method == http.MethodPost && strings.HasPrefix(path, "/auth/password/")) ||
 					strings.Contains(path, "/user/verify-email") ||
 					strings.HasPrefix(path, "/auth/google/") ||
+					(method == http.MethodGet && strings.HasPrefix(path, "/calendar/feed/")) ||
+					// CalDAV clients log in with basic auth and an app password
+					strings.HasPrefix(path, "/caldav/") ||
+					path == "/.well-known/caldav" ||
+					path == "/.well-known/jwks.json" ||
+					// the WebSocket checks the token itself, browsers cannot send it as a header
+					(method == http.MethodGet && path == "/ws") ||
 					strings.Contains(path, "/swagger")
 
 				if isPublicRoute {
@@ -89,6 +174,16 @@ func InitializeServer() {
 	todoEndpointHandler.AddEndpoints()
 	userEndpointHandler.AddEndpoints()
 	authEndpointHandler.AddEndpoints()
+	templateEndpointHandler.AddEndpoints()
+	statsEndpointHandler.AddEndpoints()
+	habitEndpointHandler.AddEndpoints()
+	transferEndpointHandler.AddEndpoints()
+	calendarEndpointHandler.AddEndpoints()
+	importEndpointHandler.AddEndpoints()
+	eventEndpointHandler.AddEndpoints()
+	syncEndpointHandler.AddEndpoints()
+	webhookEndpointHandler.AddEndpoints()
+	ruleEndpointHandler.AddEndpoints()
 
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
@@ -124,7 +219,7 @@ func initializeDb() error {
 }
 
 func migrateDb() error {
-	err := db.AutoMigrate(&todos.ToDoItem{})
+	err := db.AutoMigrate(&todos.ToDoItem{}, &todos.Tag{}, &todos.TimeEntry{})
 	if err != nil {
 		return err
 	}
@@ -134,10 +229,72 @@ func migrateDb() error {
 		return err
 	}
 
-	err = db.AutoMigrate(&auth.RefreshToken{})
+	err = migrateRefreshTokens()
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&auth.RefreshToken{}, &auth.TOTPCredential{}, &auth.RecoveryCode{}, &auth.WebAuthnCredential{}, &auth.PersonalAccessToken{}, &auth.RevokedToken{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&templates.Template{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&habits.CheckIn{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&calendar.FeedToken{}, &calendar.AppPassword{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&importers.Job{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&deltasync.Change{}, &deltasync.ClientItem{})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(&webhooks.Webhook{}, &webhooks.Delivery{})
 	if err != nil {
 		return err
 	}
 
+	err = db.AutoMigrate(&rules.Rule{}, &rules.Firing{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// migrateRefreshTokens : replaces the plain text tokens of older installs with their
+// hashes, every existing token becomes a family of its own
+func migrateRefreshTokens() error {
+	migrator := db.Migrator()
+	if !migrator.HasTable("refresh_tokens") || !migrator.HasColumn("refresh_tokens", "token") {
+		return nil
+	}
+
+	statements := []string{
+		"ALTER TABLE refresh_tokens ADD COLUMN token_hash char(64) NULL, ADD COLUMN family_id varchar(36) NULL",
+		"UPDATE refresh_tokens SET token_hash = SHA2(token, 256), family_id = UUID()",
+		"ALTER TABLE refresh_tokens DROP COLUMN token",
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
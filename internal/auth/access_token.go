@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"todo-app/pkg/locale"
+
+	"gorm.io/gorm"
+)
+
+// AccessTokenPrefix : tells personal access tokens apart from JWTs, and makes leaked
+// ones easy to find
+const AccessTokenPrefix = "tdp_"
+
+const (
+	accessTokenExpiration = 90 * 24 * time.Hour
+	// accessTokenTouchInterval : the last use is stored at most this often, scripts can
+	// send many requests
+	accessTokenTouchInterval = time.Minute
+)
+
+// scopeResources : the scope of the routes under each first path segment. Routes that
+// are not listed, such as those managing credentials, cannot be used with access tokens.
+var scopeResources = map[string]string{
+	"todos":        "todos",
+	"timer":        "todos",
+	"time-entries": "todos",
+	"reports":      "todos",
+	"stats":        "todos",
+	"sync":         "todos",
+	"imports":      "todos",
+	"events":       "todos",
+	"templates":    "templates",
+	"habits":       "habits",
+	"webhooks":     "webhooks",
+	"rules":        "rules",
+}
+
+// RequiredScope : the scope an access token needs for the request, reads need
+// <resource>:read and anything else <resource>:write. Empty when no scope allows it.
+func RequiredScope(method string, path string) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	resource, ok := scopeResources[segment]
+	if !ok {
+		return ""
+	}
+
+	if method == http.MethodGet || method == http.MethodHead {
+		return resource + ":read"
+	}
+
+	return resource + ":write"
+}
+
+func (s *service) CreateAccessToken(ctx context.Context, userID uint, input AccessTokenInput) (AccessTokenResponse, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return AccessTokenResponse{}, err
+	}
+
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		s.logger.Errorw("failed to generate access token", "error", err)
+		return AccessTokenResponse{}, errors.New(locale.ErrorInternalServer)
+	}
+	token := AccessTokenPrefix + base64.RawURLEncoding.EncodeToString(bytes)
+
+	expiration := accessTokenExpiration
+	if input.ExpiresInDays > 0 {
+		expiration = time.Duration(input.ExpiresInDays) * 24 * time.Hour
+	}
+
+	accessToken := PersonalAccessToken{
+		UserID:    userID,
+		Name:      input.Name,
+		TokenHash: hashToken(token),
+		Scopes:    input.Scopes,
+		ExpiresAt: time.Now().Add(expiration),
+	}
+	if err := s.authRepository.CreateAccessToken(ctx, &accessToken); err != nil {
+		return AccessTokenResponse{}, errors.New(locale.ErrorInternalServer)
+	}
+
+	s.logger.Infow("access token created", "user_id", userID, "id", accessToken.ID, "scopes", input.Scopes)
+	return AccessTokenResponse{PersonalAccessToken: accessToken, Token: token}, nil
+}
+
+func (s *service) GetAccessTokens(ctx context.Context, userID uint) ([]PersonalAccessToken, error) {
+	tokens, err := s.authRepository.GetAccessTokens(ctx, userID)
+	if err != nil {
+		return nil, errors.New(locale.ErrorInternalServer)
+	}
+
+	return tokens, nil
+}
+
+func (s *service) DeleteAccessToken(ctx context.Context, userID uint, id uint) error {
+	err := s.authRepository.DeleteAccessToken(ctx, userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New(locale.ErrorNotFoundRecord)
+	}
+	if err != nil {
+		return errors.New(locale.ErrorInternalServer)
+	}
+
+	s.logger.Infow("access token deleted", "user_id", userID, "id", id)
+	return nil
+}
+
+// ValidateAccessToken : the unexpired access token, its last use is recorded. Tokens
+// created before the user changed or reset their password, or logged out everywhere, are
+// rejected like access tokens issued before.
+func (s *service) ValidateAccessToken(ctx context.Context, token string) (PersonalAccessToken, error) {
+	if !strings.HasPrefix(token, AccessTokenPrefix) {
+		return PersonalAccessToken{}, errors.New(locale.ErrorInvalidToken)
+	}
+
+	accessToken, err := s.authRepository.GetAccessTokenByHash(ctx, hashToken(token))
+	if err != nil {
+		return PersonalAccessToken{}, errors.New(locale.ErrorInvalidToken)
+	}
+
+	now := time.Now()
+	if now.After(accessToken.ExpiresAt) {
+		return PersonalAccessToken{}, errors.New(locale.ErrorInvalidToken)
+	}
+
+	invalidBefore, err := s.tokensInvalidBefore(ctx, accessToken.UserID)
+	if err != nil {
+		return PersonalAccessToken{}, err
+	}
+	if accessToken.CreatedAt.Before(invalidBefore) {
+		return PersonalAccessToken{}, errors.New(locale.ErrorInvalidToken)
+	}
+
+	if accessToken.LastUsedAt == nil || now.Sub(*accessToken.LastUsedAt) > accessTokenTouchInterval {
+		// a failed update does not fail the request
+		if err := s.authRepository.TouchAccessToken(ctx, accessToken.ID, now); err == nil {
+			accessToken.LastUsedAt = &now
+		}
+	}
+
+	return accessToken, nil
+}
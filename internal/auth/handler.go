@@ -10,6 +10,7 @@ import (
 	"todo-app/pkg/handlers"
 	"todo-app/pkg/locale"
 
+	"github.com/go-webauthn/webauthn/protocol"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
 )
@@ -44,11 +45,106 @@ func (h *endpointHandler) AddEndpoints() {
 			Path:    "/auth/logout",
 			Handler: h.logout,
 		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/auth/sessions",
+			Handler: h.getSessions,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/auth/sessions/:id",
+			Handler: h.deleteSession,
+		},
 		{
 			Method:  http.MethodPost,
 			Path:    "/auth/refresh",
 			Handler: h.refresh,
 		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/auth/password/forgot",
+			Handler: h.forgotPassword,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/auth/password/reset",
+			Handler: h.resetPassword,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/auth/mfa/totp/enroll",
+			Handler: h.enrollTOTP,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/auth/mfa/totp/confirm",
+			Handler: h.confirmTOTP,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/auth/mfa/verify",
+			Handler: h.verifyMFA,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/auth/webauthn/register/begin",
+			Handler: h.beginPasskeyRegistration,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/auth/webauthn/register/finish",
+			Handler: h.finishPasskeyRegistration,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/auth/webauthn/login/begin",
+			Handler: h.beginPasskeyLogin,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/auth/webauthn/login/finish",
+			Handler: h.finishPasskeyLogin,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/auth/webauthn/mfa/begin",
+			Handler: h.beginPasskeyMFA,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/auth/webauthn/mfa/finish",
+			Handler: h.finishPasskeyMFA,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/auth/webauthn/credentials",
+			Handler: h.getPasskeys,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/auth/webauthn/credentials/:id",
+			Handler: h.deletePasskey,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/user/me/tokens",
+			Handler: h.createAccessToken,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/user/me/tokens",
+			Handler: h.getAccessTokens,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/user/me/tokens/:id",
+			Handler: h.deleteAccessToken,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/.well-known/jwks.json",
+			Handler: h.getJWKS,
+		},
 		{
 			Method:  http.MethodGet,
 			Path:    "/auth/google/login",
@@ -67,7 +163,7 @@ func (h *endpointHandler) AddEndpoints() {
 }
 
 // @Summary User login
-// @Description Authenticate user and return JWT token
+// @Description Authenticate user and return JWT token. Users with two-factor authentication get mfa_required and an mfa_token instead, to exchange with a code at /auth/mfa/verify or a passkey at /auth/webauthn/mfa/finish.
 // @Tags auth
 // @ID login
 // @Accept json
@@ -97,11 +193,12 @@ func (h *endpointHandler) login(ctx echo.Context) error {
 }
 
 // @Summary User logout
-// @Description Logout user and revoke refresh tokens
+// @Description Logout the session of the token, or every session of the user with all=true. The access tokens of the session stop working at once, with all=true every access token and personal access token of the user does.
 // @Tags auth
 // @ID logout
 // @Security BearerAuth
 // @Produce json
+// @Param all query bool false "Log out everywhere"
 // @Success 200 {string} string "Successfully logged out"
 // @Failure 400 {object} errors.ResponseError "Bad Request"
 // @Failure 401 {object} errors.ResponseError "Unauthorized"
@@ -120,7 +217,8 @@ func (h *endpointHandler) logout(ctx echo.Context) error {
 		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: locale.ErrorInvalidToken})
 	}
 
-	err := h.service.Logout(ctx.Request().Context(), tokenString)
+	everywhere := ctx.QueryParam("all") == "true"
+	err := h.service.Logout(ctx.Request().Context(), tokenString, everywhere)
 	if err != nil {
 		h.logger.Warnw("logout failed", "error", err.Error())
 		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: err.Error()})
@@ -130,8 +228,55 @@ func (h *endpointHandler) logout(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, map[string]string{"message": "Successfully logged out"})
 }
 
+// @Summary List sessions
+// @Description Lists the devices the logged in user is logged in on, the session of the request is marked as current
+// @Tags auth
+// @ID get-sessions
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} Session
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /auth/sessions [get]
+func (h *endpointHandler) getSessions(ctx echo.Context) error {
+	userId := GetUserIdFromContext(ctx)
+
+	sessions, err := h.service.GetSessions(ctx.Request().Context(), userId, GetSessionIdFromContext(ctx))
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, sessions)
+}
+
+// @Summary Revoke session
+// @Description Logs out a session of the logged in user, it cannot be refreshed anymore and its access tokens stop working at once
+// @Tags auth
+// @ID delete-session
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 204
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /auth/sessions/{id} [delete]
+func (h *endpointHandler) deleteSession(ctx echo.Context) error {
+	userId := GetUserIdFromContext(ctx)
+
+	err := h.service.RevokeSession(ctx.Request().Context(), userId, ctx.Param("id"))
+	if err != nil {
+		if err.Error() == locale.ErrorNotFoundRecord {
+			return ctx.JSON(http.StatusNotFound, e.ResponseError{Message: err.Error()})
+		}
+
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: err.Error()})
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
 // @Summary Refresh JWT token
-// @Description Refresh JWT token using refresh token
+// @Description Refresh JWT token using refresh token. The refresh token is rotated, the response holds the one to use next.
 // @Tags auth
 // @ID refresh
 // @Accept json
@@ -165,6 +310,463 @@ func (h *endpointHandler) refresh(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, response)
 }
 
+// @Summary Forgot password
+// @Description Mails a password reset link valid for one hour. Answers the same whether an account with the email exists or not.
+// @Tags auth
+// @ID forgot-password
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequest true "Email of the account"
+// @Success 202 {string} string "Reset email sent when the account exists"
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Router /auth/password/forgot [post]
+func (h *endpointHandler) forgotPassword(ctx echo.Context) error {
+	var req ForgotPasswordRequest
+	if err := ctx.Bind(&req); err != nil {
+		h.logger.Warnw("could not bind forgot password request", "error", err.Error())
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody})
+	}
+
+	if err := h.service.ForgotPassword(ctx.Request().Context(), req); err != nil {
+		if err.Error() != locale.ErrorInternalServer {
+			return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+		}
+		// failures are not told apart from unknown emails
+		h.logger.Errorw("forgot password failed", "error", err.Error())
+	}
+
+	return ctx.JSON(http.StatusAccepted, map[string]string{"message": "If the account exists, a reset email has been sent"})
+}
+
+// @Summary Reset password
+// @Description Sets a new password with the token of a reset email and logs out all sessions, personal access tokens stop working
+// @Tags auth
+// @ID reset-password
+// @Accept json
+// @Produce json
+// @Param request body ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {string} string "Password has been reset"
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /auth/password/reset [post]
+func (h *endpointHandler) resetPassword(ctx echo.Context) error {
+	var req ResetPasswordRequest
+	if err := ctx.Bind(&req); err != nil {
+		h.logger.Warnw("could not bind reset password request", "error", err.Error())
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody})
+	}
+
+	err := h.service.ResetPassword(ctx.Request().Context(), req)
+	if err != nil {
+		h.logger.Warnw("password reset failed", "error", err.Error())
+		switch err.Error() {
+		case locale.ErrorInvalidResetToken:
+			return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: err.Error()})
+		case locale.ErrorInternalServer:
+			return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: err.Error()})
+		}
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{"message": "Password has been reset"})
+}
+
+// @Summary Enroll TOTP
+// @Description Starts two-factor authentication for the logged in user with a new TOTP secret, shown as an otpauth URI and a QR code. It is turned on once confirmed with a code.
+// @Tags auth
+// @ID enroll-totp
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} TOTPEnrollment
+// @Failure 400 {object} errors.ResponseError "Already enabled"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /auth/mfa/totp/enroll [post]
+func (h *endpointHandler) enrollTOTP(ctx echo.Context) error {
+	userId := GetUserIdFromContext(ctx)
+
+	enrollment, err := h.service.EnrollTOTP(ctx.Request().Context(), userId)
+	if err != nil {
+		return h.mfaError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, enrollment)
+}
+
+// @Summary Confirm TOTP
+// @Description Turns two-factor authentication on with a code of the enrolled authenticator. The response holds the recovery codes, they are not shown again.
+// @Tags auth
+// @ID confirm-totp
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body MFACodeRequest true "Code of the authenticator"
+// @Success 200 {object} RecoveryCodesResponse
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Invalid code"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /auth/mfa/totp/confirm [post]
+func (h *endpointHandler) confirmTOTP(ctx echo.Context) error {
+	var req MFACodeRequest
+	if err := ctx.Bind(&req); err != nil {
+		h.logger.Warnw("could not bind totp confirm request", "error", err.Error())
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody})
+	}
+
+	userId := GetUserIdFromContext(ctx)
+	response, err := h.service.ConfirmTOTP(ctx.Request().Context(), userId, req)
+	if err != nil {
+		return h.mfaError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// @Summary Verify MFA
+// @Description Completes a login that answered mfa_required, with a TOTP code or a recovery code. Each recovery code works once.
+// @Tags auth
+// @ID verify-mfa
+// @Accept json
+// @Produce json
+// @Param request body MFAVerifyRequest true "MFA token of the login and code"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Invalid token or code"
+// @Failure 429 {object} errors.ResponseError "Too many attempts"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /auth/mfa/verify [post]
+func (h *endpointHandler) verifyMFA(ctx echo.Context) error {
+	var req MFAVerifyRequest
+	if err := ctx.Bind(&req); err != nil {
+		h.logger.Warnw("could not bind mfa verify request", "error", err.Error())
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody})
+	}
+
+	response, err := h.service.VerifyMFA(ctx.Request().Context(), req)
+	if err != nil {
+		return h.mfaError(ctx, err)
+	}
+
+	h.logger.Infow("user logged in successfully", "user_id", response.User.ID)
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// @Summary Begin passkey registration
+// @Description Returns the options for navigator.credentials.create to add a passkey to the logged in user. Once registered, the passkey logs in without a password and is asked for as the second factor of password logins.
+// @Tags auth
+// @ID begin-passkey-registration
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} protocol.PublicKeyCredentialCreationOptions
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /auth/webauthn/register/begin [post]
+func (h *endpointHandler) beginPasskeyRegistration(ctx echo.Context) error {
+	userId := GetUserIdFromContext(ctx)
+
+	options, err := h.service.BeginPasskeyRegistration(ctx.Request().Context(), userId)
+	if err != nil {
+		return h.mfaError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, options)
+}
+
+// @Summary Finish passkey registration
+// @Description Stores the passkey created with the options of /auth/webauthn/register/begin
+// @Tags auth
+// @ID finish-passkey-registration
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body PasskeyRegistrationRequest true "Name and the credential returned by the browser"
+// @Success 201 {object} WebAuthnCredential
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Invalid passkey"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /auth/webauthn/register/finish [post]
+func (h *endpointHandler) finishPasskeyRegistration(ctx echo.Context) error {
+	var req PasskeyRegistrationRequest
+	if err := ctx.Bind(&req); err != nil {
+		h.logger.Warnw("could not bind passkey registration request", "error", err.Error())
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody})
+	}
+
+	userId := GetUserIdFromContext(ctx)
+	passkey, err := h.service.FinishPasskeyRegistration(ctx.Request().Context(), userId, req)
+	if err != nil {
+		return h.mfaError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusCreated, passkey)
+}
+
+// @Summary Begin passkey login
+// @Description Returns the options for navigator.credentials.get to log in with a passkey, without a password
+// @Tags auth
+// @ID begin-passkey-login
+// @Produce json
+// @Success 200 {object} protocol.PublicKeyCredentialRequestOptions
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /auth/webauthn/login/begin [post]
+func (h *endpointHandler) beginPasskeyLogin(ctx echo.Context) error {
+	options, err := h.service.BeginPasskeyLogin(ctx.Request().Context())
+	if err != nil {
+		return h.mfaError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, options)
+}
+
+// @Summary Finish passkey login
+// @Description Logs in the owner of the passkey with the credential returned by the browser
+// @Tags auth
+// @ID finish-passkey-login
+// @Accept json
+// @Produce json
+// @Param request body protocol.CredentialAssertionResponse true "Credential returned by the browser"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Invalid passkey"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /auth/webauthn/login/finish [post]
+func (h *endpointHandler) finishPasskeyLogin(ctx echo.Context) error {
+	var req protocol.CredentialAssertionResponse
+	if err := ctx.Bind(&req); err != nil {
+		h.logger.Warnw("could not bind passkey login request", "error", err.Error())
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody})
+	}
+
+	response, err := h.service.FinishPasskeyLogin(ctx.Request().Context(), req)
+	if err != nil {
+		return h.mfaError(ctx, err)
+	}
+
+	h.logger.Infow("user logged in successfully", "user_id", response.User.ID)
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// @Summary Begin passkey second factor
+// @Description Returns the options for navigator.credentials.get to complete a login that answered mfa_required with a passkey
+// @Tags auth
+// @ID begin-passkey-mfa
+// @Accept json
+// @Produce json
+// @Param request body PasskeyMFARequest true "MFA token of the login"
+// @Success 200 {object} protocol.PublicKeyCredentialRequestOptions
+// @Failure 400 {object} errors.ResponseError "No passkey registered"
+// @Failure 401 {object} errors.ResponseError "Invalid token"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /auth/webauthn/mfa/begin [post]
+func (h *endpointHandler) beginPasskeyMFA(ctx echo.Context) error {
+	var req PasskeyMFARequest
+	if err := ctx.Bind(&req); err != nil {
+		h.logger.Warnw("could not bind passkey mfa request", "error", err.Error())
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody})
+	}
+
+	options, err := h.service.BeginPasskeyMFA(ctx.Request().Context(), req)
+	if err != nil {
+		return h.mfaError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, options)
+}
+
+// @Summary Finish passkey second factor
+// @Description Completes a login that answered mfa_required with the credential returned by the browser
+// @Tags auth
+// @ID finish-passkey-mfa
+// @Accept json
+// @Produce json
+// @Param request body PasskeyMFAVerifyRequest true "MFA token of the login and credential"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Invalid token or passkey"
+// @Failure 429 {object} errors.ResponseError "Too many attempts"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /auth/webauthn/mfa/finish [post]
+func (h *endpointHandler) finishPasskeyMFA(ctx echo.Context) error {
+	var req PasskeyMFAVerifyRequest
+	if err := ctx.Bind(&req); err != nil {
+		h.logger.Warnw("could not bind passkey mfa verify request", "error", err.Error())
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody})
+	}
+
+	response, err := h.service.FinishPasskeyMFA(ctx.Request().Context(), req)
+	if err != nil {
+		return h.mfaError(ctx, err)
+	}
+
+	h.logger.Infow("user logged in successfully", "user_id", response.User.ID)
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// @Summary List passkeys
+// @Description Lists the passkeys of the logged in user
+// @Tags auth
+// @ID get-passkeys
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} WebAuthnCredential
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /auth/webauthn/credentials [get]
+func (h *endpointHandler) getPasskeys(ctx echo.Context) error {
+	userId := GetUserIdFromContext(ctx)
+
+	passkeys, err := h.service.GetPasskeys(ctx.Request().Context(), userId)
+	if err != nil {
+		return h.mfaError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, passkeys)
+}
+
+// @Summary Delete passkey
+// @Description Deletes a passkey of the logged in user
+// @Tags auth
+// @ID delete-passkey
+// @Security BearerAuth
+// @Param id path int true "Passkey ID"
+// @Success 204
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /auth/webauthn/credentials/{id} [delete]
+func (h *endpointHandler) deletePasskey(ctx echo.Context) error {
+	id, err := handlers.GetUrlId(ctx, h.logger)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidID, Details: err.Error()})
+	}
+
+	userId := GetUserIdFromContext(ctx)
+	if err := h.service.DeletePasskey(ctx.Request().Context(), userId, id); err != nil {
+		return h.mfaError(ctx, err)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// @Summary Create personal access token
+// @Description Creates a token for scripts, sent as a Bearer token instead of a JWT. It can only use the routes its scopes allow and expires after expires_in_days, 90 by default. The token is only shown in this response.
+// @Tags auth
+// @ID create-access-token
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body AccessTokenInput true "Name, scopes and lifetime"
+// @Success 201 {object} AccessTokenResponse
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /user/me/tokens [post]
+func (h *endpointHandler) createAccessToken(ctx echo.Context) error {
+	var input AccessTokenInput
+	if err := ctx.Bind(&input); err != nil {
+		h.logger.Warnw("could not bind access token request", "error", err.Error())
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody})
+	}
+
+	userId := GetUserIdFromContext(ctx)
+	response, err := h.service.CreateAccessToken(ctx.Request().Context(), userId, input)
+	if err != nil {
+		if err.Error() == locale.ErrorInternalServer {
+			return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: err.Error()})
+		}
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusCreated, response)
+}
+
+// @Summary List personal access tokens
+// @Description Lists the personal access tokens of the logged in user, without the tokens
+// @Tags auth
+// @ID get-access-tokens
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} PersonalAccessToken
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /user/me/tokens [get]
+func (h *endpointHandler) getAccessTokens(ctx echo.Context) error {
+	userId := GetUserIdFromContext(ctx)
+
+	tokens, err := h.service.GetAccessTokens(ctx.Request().Context(), userId)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, tokens)
+}
+
+// @Summary Delete personal access token
+// @Description Deletes a personal access token of the logged in user, it stops working right away
+// @Tags auth
+// @ID delete-access-token
+// @Security BearerAuth
+// @Param id path int true "Token ID"
+// @Success 204
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /user/me/tokens/{id} [delete]
+func (h *endpointHandler) deleteAccessToken(ctx echo.Context) error {
+	id, err := handlers.GetUrlId(ctx, h.logger)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidID, Details: err.Error()})
+	}
+
+	userId := GetUserIdFromContext(ctx)
+	err = h.service.DeleteAccessToken(ctx.Request().Context(), userId, id)
+	if err != nil {
+		if err.Error() == locale.ErrorNotFoundRecord {
+			return ctx.JSON(http.StatusNotFound, e.ResponseError{Message: err.Error()})
+		}
+
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: err.Error()})
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// mfaError : the response for errors of the two-factor endpoints
+func (h *endpointHandler) mfaError(ctx echo.Context, err error) error {
+	h.logger.Warnw("two-factor request failed", "error", err.Error())
+
+	switch err.Error() {
+	case locale.ErrorInvalidMFACode, locale.ErrorInvalidPasskey, locale.ErrorInvalidToken, locale.ErrorUserNotFound, locale.ErrorEmailUnverified:
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: err.Error()})
+	case locale.ErrorTooManyMFAAttempts:
+		return ctx.JSON(http.StatusTooManyRequests, e.ResponseError{Message: err.Error()})
+	case locale.ErrorMFAAlreadyEnabled, locale.ErrorMFANotEnrolled:
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: err.Error()})
+	case locale.ErrorNotFoundRecord:
+		return ctx.JSON(http.StatusNotFound, e.ResponseError{Message: err.Error()})
+	case locale.ErrorInternalServer:
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+}
+
+// @Summary JSON Web Key Set
+// @Description Returns the public keys JWTs are signed with, by kid. Keys scheduled to sign later are listed ahead of time, replaced keys until their grace period is over.
+// @Tags auth
+// @ID jwks
+// @Produce json
+// @Success 200 {object} JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *endpointHandler) getJWKS(ctx echo.Context) error {
+	// verifiers refetch within a few minutes, before a scheduled key starts signing
+	ctx.Response().Header().Set("Cache-Control", "public, max-age=300")
+
+	return ctx.JSON(http.StatusOK, h.service.JWKS())
+}
+
 // @Summary Google OAuth login
 // @Description Redirects user to Google OAuth for authentication
 // @Tags auth
@@ -197,6 +799,11 @@ func (h *endpointHandler) googleCallback(ctx echo.Context) error {
 		return ctx.Redirect(http.StatusTemporaryRedirect, "/login?error=google-failed")
 	}
 
+	frontendURL := "http://local.todo.com" // This should be an env var in a real app
+	if response.MFARequired {
+		return ctx.Redirect(http.StatusTemporaryRedirect, fmt.Sprintf("%s/login/mfa?mfa_token=%s", frontendURL, response.MFAToken))
+	}
+
 	// On success, we need to send the tokens and user data to the frontend.
 	userJSON, err := json.Marshal(response.User)
 	if err != nil {
@@ -205,7 +812,6 @@ func (h *endpointHandler) googleCallback(ctx echo.Context) error {
 	}
 	userBase64 := base64.URLEncoding.EncodeToString(userJSON)
 
-	frontendURL := "http://local.todo.com" // This should be an env var in a real app
 	redirectURL := fmt.Sprintf(
 		"%s/login/success?token=%s&refresh=%s&user=%s",
 		frontendURL,
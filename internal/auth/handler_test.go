@@ -2,6 +2,7 @@ package auth
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -99,7 +100,7 @@ func TestHandler_Logout(t *testing.T) {
 
 		mockService.
 			EXPECT().
-			Logout(ctx.Request().Context(), "valid_token").
+			Logout(ctx.Request().Context(), "valid_token", false).
 			Return(nil).
 			Times(1)
 
@@ -214,3 +215,257 @@ func TestHandler_RefreshToken(t *testing.T) {
 		}
 	})
 }
+
+func TestHandler_ForgotPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+
+	t.Run("accepted even when the reset failed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/auth/password/forgot", strings.NewReader(`{"email": "go@go.com"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		logger := zap.NewNop().Sugar()
+		h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+		mockService.
+			EXPECT().
+			ForgotPassword(ctx.Request().Context(), ForgotPasswordRequest{Email: "go@go.com"}).
+			Return(errors.New(locale.ErrorInternalServer)).
+			Times(1)
+
+		if assert.NoError(t, h.forgotPassword(ctx)) {
+			assert.Equal(t, http.StatusAccepted, rec.Code)
+		}
+
+		ctrl.Finish()
+	})
+}
+
+func TestHandler_ResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+
+	t.Run("invalid reset token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/auth/password/reset", strings.NewReader(`{"token": "go", "password": "password"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		logger := zap.NewNop().Sugar()
+		h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+		mockService.
+			EXPECT().
+			ResetPassword(ctx.Request().Context(), ResetPasswordRequest{Token: "go", Password: "password"}).
+			Return(errors.New(locale.ErrorInvalidResetToken)).
+			Times(1)
+
+		if assert.NoError(t, h.resetPassword(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), locale.ErrorInvalidResetToken)
+		}
+
+		ctrl.Finish()
+	})
+}
+
+func TestHandler_VerifyMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"invalid code", errors.New(locale.ErrorInvalidMFACode), http.StatusUnauthorized},
+		{"invalid token", errors.New(locale.ErrorInvalidToken), http.StatusUnauthorized},
+		{"too many attempts", errors.New(locale.ErrorTooManyMFAAttempts), http.StatusTooManyRequests},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/auth/mfa/verify", strings.NewReader(`{"mfa_token": "go", "code": "123456"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(req, rec)
+
+			logger := zap.NewNop().Sugar()
+			h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+			mockService.
+				EXPECT().
+				VerifyMFA(ctx.Request().Context(), MFAVerifyRequest{MFAToken: "go", Code: "123456"}).
+				Return(LoginResponse{}, test.err).
+				Times(1)
+
+			if assert.NoError(t, h.verifyMFA(ctx)) {
+				assert.Equal(t, test.status, rec.Code)
+				assert.Contains(t, rec.Body.String(), test.err.Error())
+			}
+
+			ctrl.Finish()
+		})
+	}
+}
+
+func TestHandler_DeletePasskey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+
+	t.Run("passkey of another user", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetPath("/auth/webauthn/credentials/:id")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues("7")
+		ctx.Set("user_id", uint(1))
+
+		logger := zap.NewNop().Sugar()
+		h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+		mockService.
+			EXPECT().
+			DeletePasskey(ctx.Request().Context(), uint(1), uint(7)).
+			Return(errors.New(locale.ErrorNotFoundRecord)).
+			Times(1)
+
+		if assert.NoError(t, h.deletePasskey(ctx)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+		}
+
+		ctrl.Finish()
+	})
+}
+
+func TestHandler_JWKS(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	h := &endpointHandler{logger: zap.NewNop().Sugar(), service: mockService, e: e}
+
+	jwks := JWKS{Keys: []JWK{{KeyType: "OKP", KeyID: "2026-10", Use: "sig", Algorithm: "EdDSA", Curve: "Ed25519", X: "key"}}}
+	mockService.EXPECT().JWKS().Return(jwks).Times(1)
+
+	if assert.NoError(t, h.getJWKS(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "public, max-age=300", rec.Header().Get("Cache-Control"))
+
+		var body JWKS
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, jwks, body)
+	}
+}
+
+func TestJWTMiddleware_AccessToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	token := PersonalAccessToken{ID: 3, UserID: 1, Scopes: []string{"todos:read"}}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+	}{
+		{"read with read scope", http.MethodGet, "/todos", http.StatusOK},
+		{"write with read scope", http.MethodPost, "/todos", http.StatusForbidden},
+		{"other resource", http.MethodGet, "/habits", http.StatusForbidden},
+		{"credentials", http.MethodGet, "/user/me/tokens", http.StatusForbidden},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.path, nil)
+			req.Header.Set("Authorization", "Bearer tdp_token")
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(req, rec)
+
+			mockService.
+				EXPECT().
+				ValidateAccessToken(ctx.Request().Context(), "tdp_token").
+				Return(token, nil).
+				Times(1)
+
+			next := func(c echo.Context) error {
+				assert.Equal(t, uint(1), GetUserIdFromContext(c))
+				return c.NoContent(http.StatusOK)
+			}
+			if assert.NoError(t, JWTMiddleware(mockService, logger)(next)(ctx)) {
+				assert.Equal(t, test.status, rec.Code)
+			}
+
+			ctrl.Finish()
+		})
+	}
+
+	t.Run("invalid token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+		req.Header.Set("Authorization", "Bearer tdp_token")
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		mockService.
+			EXPECT().
+			ValidateAccessToken(ctx.Request().Context(), "tdp_token").
+			Return(PersonalAccessToken{}, errors.New(locale.ErrorInvalidToken)).
+			Times(1)
+
+		next := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+		if assert.NoError(t, JWTMiddleware(mockService, logger)(next)(ctx)) {
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		}
+
+		ctrl.Finish()
+	})
+}
+
+func TestJWTMiddleware_Revoked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	claims := &JWTClaims{UserID: 1}
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"valid", nil, http.StatusOK},
+		{"revoked", errors.New(locale.ErrorInvalidToken), http.StatusUnauthorized},
+		{"lookup failed", errors.New(locale.ErrorInternalServer), http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+			req.Header.Set("Authorization", "Bearer token")
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(req, rec)
+
+			mockService.EXPECT().ValidateToken("token").Return(claims, nil).Times(1)
+			mockService.EXPECT().CheckRevocation(ctx.Request().Context(), claims).Return(test.err).Times(1)
+
+			next := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+			if assert.NoError(t, JWTMiddleware(mockService, logger)(next)(ctx)) {
+				assert.Equal(t, test.status, rec.Code)
+			}
+
+			ctrl.Finish()
+		})
+	}
+}
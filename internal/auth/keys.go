@@ -0,0 +1,268 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// defaultKeyGracePeriod : how long a key still verifies tokens once the next one signs,
+// longer than the tokens it signed live
+const defaultKeyGracePeriod = time.Hour
+
+// minRSAKeyBits : smaller RSA keys are refused
+const minRSAKeyBits = 2048
+
+// signingKey : a private key of the manifest, it signs from NotBefore on until the
+// next key takes over
+type signingKey struct {
+	id        string
+	method    jwt.SigningMethod
+	private   crypto.Signer
+	notBefore time.Time
+}
+
+// KeySet : the keys JWTs are signed and verified with, identified by the kid header
+type KeySet struct {
+	// keys : ordered by notBefore
+	keys  []signingKey
+	grace time.Duration
+	now   func() time.Time
+}
+
+// keyManifest : the file JWT_KEYS_FILE points to. Key files are PEM encoded PKCS#8 (or
+// PKCS#1 for RSA) private keys, relative paths are read from the manifest's directory.
+// A rotation is scheduled by adding a key with a later not_before.
+type keyManifest struct {
+	Keys []struct {
+		ID        string    `json:"kid"`
+		File      string    `json:"file"`
+		NotBefore time.Time `json:"not_before"`
+	} `json:"keys"`
+}
+
+// LoadKeys : the keys of the manifest in JWT_KEYS_FILE, kept for JWT_KEY_GRACE_PERIOD
+// once replaced. Without a manifest only test mode starts, with a key that lives as long
+// as the process.
+func LoadKeys(logger *zap.SugaredLogger) (*KeySet, error) {
+	path := os.Getenv("JWT_KEYS_FILE")
+	if path == "" {
+		if os.Getenv("APP_ENV") != "test" {
+			return nil, errors.New("JWT_KEYS_FILE is not set")
+		}
+
+		logger.Warnw("JWT_KEYS_FILE is not set, signing with a generated key. Tokens will not survive a restart.")
+		return generateKeySet()
+	}
+
+	grace := defaultKeyGracePeriod
+	if value := os.Getenv("JWT_KEY_GRACE_PERIOD"); value != "" {
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration < 0 {
+			return nil, fmt.Errorf("invalid JWT_KEY_GRACE_PERIOD %q", value)
+		}
+		grace = duration
+	}
+
+	keys, err := readKeySet(path, grace)
+	if err != nil {
+		return nil, err
+	}
+
+	signing, _ := keys.signingKey()
+	logger.Infow("loaded JWT signing keys", "keys", len(keys.keys), "signing_kid", signing.id)
+	return keys, nil
+}
+
+func readKeySet(path string, grace time.Duration) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read key manifest: %w", err)
+	}
+
+	var manifest keyManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("could not parse key manifest: %w", err)
+	}
+
+	keys := &KeySet{grace: grace, now: time.Now}
+	for _, entry := range manifest.Keys {
+		if entry.ID == "" || slices.ContainsFunc(keys.keys, func(key signingKey) bool { return key.id == entry.ID }) {
+			return nil, fmt.Errorf("key manifest: missing or duplicate kid %q", entry.ID)
+		}
+
+		file := entry.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(filepath.Dir(path), file)
+		}
+		private, method, err := readPrivateKey(file)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", entry.ID, err)
+		}
+
+		keys.keys = append(keys.keys, signingKey{id: entry.ID, method: method, private: private, notBefore: entry.NotBefore})
+	}
+	slices.SortStableFunc(keys.keys, func(a, b signingKey) int { return a.notBefore.Compare(b.notBefore) })
+
+	if _, err := keys.signingKey(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// readPrivateKey : an RSA key signs with RS256, an Ed25519 key with EdDSA
+func readPrivateKey(file string) (crypto.Signer, jwt.SigningMethod, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, errors.New("no PEM data")
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return nil, nil, fmt.Errorf("RSA key of %d bits, at least %d are required", key.N.BitLen(), minRSAKeyBits)
+		}
+		return key, jwt.SigningMethodRS256, nil
+	case ed25519.PrivateKey:
+		return key, jwt.SigningMethodEdDSA, nil
+	}
+
+	return nil, nil, errors.New("only RSA and Ed25519 keys are supported")
+}
+
+// generateKeySet : a single Ed25519 key, for tests
+func generateKeySet() (*KeySet, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	key := signingKey{id: uuid.New().String(), method: jwt.SigningMethodEdDSA, private: private}
+	return &KeySet{keys: []signingKey{key}, grace: defaultKeyGracePeriod, now: time.Now}, nil
+}
+
+// signingKey : the latest key whose time has come
+func (k *KeySet) signingKey() (signingKey, error) {
+	now := k.now()
+	for i := len(k.keys) - 1; i >= 0; i-- {
+		if !k.keys[i].notBefore.After(now) {
+			return k.keys[i], nil
+		}
+	}
+
+	return signingKey{}, errors.New("no JWT signing key is active yet")
+}
+
+// verifies : whether tokens signed with the key are accepted, from its not_before until
+// the grace period after the next key took over
+func (k *KeySet) verifies(i int, now time.Time) bool {
+	if k.keys[i].notBefore.After(now) {
+		return false
+	}
+
+	return i == len(k.keys)-1 || now.Before(k.keys[i+1].notBefore.Add(k.grace))
+}
+
+// sign : the claims signed with the current key, its kid in the header
+func (k *KeySet) sign(claims jwt.Claims) (string, error) {
+	key, err := k.signingKey()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+
+	return token.SignedString(key.private)
+}
+
+// parse : the token verified with the key of its kid, when that key is still accepted
+func (k *KeySet) parse(tokenString string, claims jwt.Claims, options ...jwt.ParserOption) (*jwt.Token, error) {
+	options = append(options, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}))
+
+	return jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		now := k.now()
+		for i, key := range k.keys {
+			if key.id != kid {
+				continue
+			}
+			if !k.verifies(i, now) {
+				return nil, errors.New("retired signing key")
+			}
+			if token.Method.Alg() != key.method.Alg() {
+				return nil, errors.New("unexpected signing method")
+			}
+			return key.private.Public(), nil
+		}
+
+		return nil, errors.New("unknown signing key")
+	}, options...)
+}
+
+// JWKS : the public keys that verify tokens now, and those scheduled to sign later so
+// that verifiers have them before the first token
+func (k *KeySet) JWKS() JWKS {
+	now := k.now()
+	jwks := JWKS{Keys: []JWK{}}
+	for i, key := range k.keys {
+		if key.notBefore.After(now) || k.verifies(i, now) {
+			jwks.Keys = append(jwks.Keys, publicJWK(key))
+		}
+	}
+
+	return jwks
+}
+
+// publicJWK : the public key as a JWK (RFC 7517, RFC 8037 for Ed25519)
+func publicJWK(key signingKey) JWK {
+	jwk := JWK{KeyID: key.id, Use: "sig", Algorithm: key.method.Alg()}
+
+	switch public := key.private.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+
+	return jwk
+}
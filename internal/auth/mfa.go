@@ -0,0 +1,289 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"time"
+	"todo-app/internal/users"
+	"todo-app/pkg/locale"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+)
+
+const (
+	// mfaAudience : the audience of challenge tokens, which are only accepted by VerifyMFA
+	mfaAudience            = "mfa"
+	mfaChallengeExpiration = 5 * time.Minute
+	// maxMFAAttempts : wrong codes allowed per challenge, a new challenge needs the
+	// password again
+	maxMFAAttempts = 5
+
+	mfaMethodTOTP     = "totp"
+	mfaMethodWebAuthn = "webauthn"
+
+	// totpQRCodeSize : the width and height of the QR code image in pixels
+	totpQRCodeSize = 256
+)
+
+// mfaAttempts : the codes tried with a challenge, until it expires
+type mfaAttempts struct {
+	count     int
+	expiresAt time.Time
+}
+
+// mfaMethods : the second factors of the user, a login needs one of them when there are
+// any
+func (s *service) mfaMethods(ctx context.Context, userID uint) ([]string, error) {
+	var methods []string
+
+	credential, err := s.authRepository.GetTOTPCredential(ctx, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.Errorw("failed to get totp credential", "user_id", userID, "error", err)
+		return nil, errors.New(locale.ErrorInternalServer)
+	}
+	if err == nil && credential.ConfirmedAt != nil {
+		methods = append(methods, mfaMethodTOTP)
+	}
+
+	passkeys, err := s.authRepository.GetWebAuthnCredentials(ctx, userID)
+	if err != nil {
+		return nil, errors.New(locale.ErrorInternalServer)
+	}
+	if len(passkeys) > 0 {
+		methods = append(methods, mfaMethodWebAuthn)
+	}
+
+	return methods, nil
+}
+
+// mfaChallenge : the response of a login that still needs a second factor
+func (s *service) mfaChallenge(user users.User, methods []string) (LoginResponse, error) {
+	claims := JWTClaims{
+		UserID: user.ID,
+		Email:  user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Audience:  jwt.ClaimStrings{mfaAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(mfaChallengeExpiration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    "todo-app",
+		},
+	}
+
+	token, err := s.keys.sign(claims)
+	if err != nil {
+		s.logger.Errorw("failed to sign mfa token", "error", err)
+		return LoginResponse{}, errors.New(locale.ErrorInternalServer)
+	}
+
+	return LoginResponse{
+		MFARequired: true,
+		MFAToken:    token,
+		MFAMethods:  methods,
+		User: UserInfo{
+			ID:        user.ID,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Email:     user.Email,
+		},
+	}, nil
+}
+
+// EnrollTOTP : a new secret for the user, replacing one that was not confirmed yet
+func (s *service) EnrollTOTP(ctx context.Context, userID uint) (TOTPEnrollment, error) {
+	user, err := s.userRepository.GetById(ctx, userID)
+	if err != nil {
+		return TOTPEnrollment{}, errors.New(locale.ErrorUserNotFound)
+	}
+
+	credential, err := s.authRepository.GetTOTPCredential(ctx, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return TOTPEnrollment{}, errors.New(locale.ErrorInternalServer)
+	}
+	if credential.ConfirmedAt != nil {
+		return TOTPEnrollment{}, errors.New(locale.ErrorMFAAlreadyEnabled)
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		s.logger.Errorw("failed to generate totp secret", "error", err)
+		return TOTPEnrollment{}, errors.New(locale.ErrorInternalServer)
+	}
+
+	credential.UserID = userID
+	credential.Secret = secret
+	credential.LastStep = 0
+	if err := s.authRepository.SaveTOTPCredential(ctx, &credential); err != nil {
+		return TOTPEnrollment{}, errors.New(locale.ErrorInternalServer)
+	}
+
+	uri := totpURI(secret, user.Email)
+	png, err := qrcode.Encode(uri, qrcode.Medium, totpQRCodeSize)
+	if err != nil {
+		s.logger.Errorw("failed to draw totp qr code", "error", err)
+		return TOTPEnrollment{}, errors.New(locale.ErrorInternalServer)
+	}
+
+	return TOTPEnrollment{
+		Secret: secret,
+		URI:    uri,
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// ConfirmTOTP : turns MFA on once the user proves the authenticator works, the recovery
+// codes are only shown here
+func (s *service) ConfirmTOTP(ctx context.Context, userID uint, req MFACodeRequest) (RecoveryCodesResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return RecoveryCodesResponse{}, err
+	}
+
+	credential, err := s.authRepository.GetTOTPCredential(ctx, userID)
+	if err != nil {
+		return RecoveryCodesResponse{}, errors.New(locale.ErrorMFANotEnrolled)
+	}
+	if credential.ConfirmedAt != nil {
+		return RecoveryCodesResponse{}, errors.New(locale.ErrorMFAAlreadyEnabled)
+	}
+
+	now := time.Now()
+	step, ok := matchTOTP(credential.Secret, req.Code, now)
+	if !ok {
+		return RecoveryCodesResponse{}, errors.New(locale.ErrorInvalidMFACode)
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	records := make([]RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		codes[i], err = generateRecoveryCode()
+		if err != nil {
+			s.logger.Errorw("failed to generate recovery code", "error", err)
+			return RecoveryCodesResponse{}, errors.New(locale.ErrorInternalServer)
+		}
+		records[i] = RecoveryCode{UserID: userID, CodeHash: hashToken(normalizeRecoveryCode(codes[i]))}
+	}
+
+	credential.ConfirmedAt = &now
+	credential.LastStep = step
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.authRepository.SaveTOTPCredential(ctx, &credential); err != nil {
+			return err
+		}
+
+		return s.authRepository.ReplaceRecoveryCodes(ctx, userID, records)
+	})
+	if err != nil {
+		return RecoveryCodesResponse{}, errors.New(locale.ErrorInternalServer)
+	}
+
+	s.logger.Infow("totp enabled", "user_id", userID)
+	return RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// VerifyMFA : exchanges the challenge of a login and a TOTP or recovery code for the
+// tokens
+func (s *service) VerifyMFA(ctx context.Context, req MFAVerifyRequest) (LoginResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return LoginResponse{}, err
+	}
+
+	claims, err := s.parseMFAToken(req.MFAToken)
+	if err != nil {
+		return LoginResponse{}, errors.New(locale.ErrorInvalidToken)
+	}
+
+	if !s.countMFAAttempt(claims.ID, claims.ExpiresAt.Time) {
+		s.logger.Warnw("too many mfa attempts", "user_id", claims.UserID)
+		return LoginResponse{}, errors.New(locale.ErrorTooManyMFAAttempts)
+	}
+
+	credential, err := s.authRepository.GetTOTPCredential(ctx, claims.UserID)
+	if err != nil || credential.ConfirmedAt == nil {
+		return LoginResponse{}, errors.New(locale.ErrorInvalidToken)
+	}
+
+	valid, err := s.checkMFACode(ctx, credential, req.Code)
+	if err != nil {
+		return LoginResponse{}, errors.New(locale.ErrorInternalServer)
+	}
+	if !valid {
+		s.logger.Warnw("invalid mfa code", "user_id", claims.UserID)
+		return LoginResponse{}, errors.New(locale.ErrorInvalidMFACode)
+	}
+	s.finishMFAChallenge(claims.ID)
+
+	user, err := s.userRepository.GetById(ctx, claims.UserID)
+	if err != nil {
+		return LoginResponse{}, errors.New(locale.ErrorUserNotFound)
+	}
+
+	return s.issueTokens(ctx, user)
+}
+
+// checkMFACode : a TOTP code is accepted once per time step, a recovery code once
+func (s *service) checkMFACode(ctx context.Context, credential TOTPCredential, code string) (bool, error) {
+	if isTOTPCode(code) {
+		step, ok := matchTOTP(credential.Secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+
+		return s.authRepository.UseTOTPStep(ctx, credential.ID, step)
+	}
+
+	used, err := s.authRepository.UseRecoveryCode(ctx, credential.UserID, hashToken(normalizeRecoveryCode(code)), time.Now())
+	if used {
+		s.logger.Infow("recovery code used", "user_id", credential.UserID)
+	}
+
+	return used, err
+}
+
+func (s *service) parseMFAToken(tokenString string) (*JWTClaims, error) {
+	token, err := s.keys.parse(tokenString, &JWTClaims{}, jwt.WithAudience(mfaAudience), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*JWTClaims)
+	if !ok || !token.Valid || claims.ID == "" {
+		return nil, errors.New("invalid token")
+	}
+
+	return claims, nil
+}
+
+// countMFAAttempt : false when the challenge already had its attempts
+func (s *service) countMFAAttempt(id string, expiresAt time.Time) bool {
+	s.mfaMu.Lock()
+	defer s.mfaMu.Unlock()
+
+	now := time.Now()
+	for key, attempts := range s.mfaAttempts {
+		if now.After(attempts.expiresAt) {
+			delete(s.mfaAttempts, key)
+		}
+	}
+
+	attempts := s.mfaAttempts[id]
+	if attempts.count >= maxMFAAttempts {
+		return false
+	}
+	s.mfaAttempts[id] = mfaAttempts{count: attempts.count + 1, expiresAt: expiresAt}
+
+	return true
+}
+
+// finishMFAChallenge : a challenge is exchanged once
+func (s *service) finishMFAChallenge(id string) {
+	s.mfaMu.Lock()
+	defer s.mfaMu.Unlock()
+
+	attempts := s.mfaAttempts[id]
+	attempts.count = maxMFAAttempts
+	s.mfaAttempts[id] = attempts
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"net/http"
+	"slices"
 	"strings"
 	e "todo-app/pkg/errors"
 	"todo-app/pkg/locale"
@@ -10,7 +11,8 @@ import (
 	"go.uber.org/zap"
 )
 
-// JWTMiddleware creates a middleware function for JWT authentication
+// JWTMiddleware creates a middleware function for JWT authentication. Revoked JWTs are
+// rejected. Personal access tokens are accepted as well, for the routes their scopes allow.
 func JWTMiddleware(authService Service, logger *zap.SugaredLogger) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -27,21 +29,52 @@ func JWTMiddleware(authService Service, logger *zap.SugaredLogger) echo.Middlewa
 				return c.JSON(http.StatusUnauthorized, e.ResponseError{Message: locale.ErrorInvalidToken})
 			}
 
+			if strings.HasPrefix(tokenString, AccessTokenPrefix) {
+				return accessTokenAuth(c, next, authService, logger, tokenString)
+			}
+
 			claims, err := authService.ValidateToken(tokenString)
 			if err != nil {
 				logger.Warnw("invalid token", "error", err.Error())
 				return c.JSON(http.StatusUnauthorized, e.ResponseError{Message: locale.ErrorInvalidToken})
 			}
 
+			if err := authService.CheckRevocation(c.Request().Context(), claims); err != nil {
+				if err.Error() == locale.ErrorInternalServer {
+					return c.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorInternalServer})
+				}
+				logger.Warnw("revoked token", "user_id", claims.UserID, "jti", claims.ID)
+				return c.JSON(http.StatusUnauthorized, e.ResponseError{Message: locale.ErrorInvalidToken})
+			}
+
 			// Store user information in context
 			c.Set("user_id", claims.UserID)
 			c.Set("user_email", claims.Email)
+			c.Set("session_id", claims.SessionID)
 
 			return next(c)
 		}
 	}
 }
 
+func accessTokenAuth(c echo.Context, next echo.HandlerFunc, authService Service, logger *zap.SugaredLogger, tokenString string) error {
+	token, err := authService.ValidateAccessToken(c.Request().Context(), tokenString)
+	if err != nil {
+		logger.Warnw("invalid access token", "error", err.Error())
+		return c.JSON(http.StatusUnauthorized, e.ResponseError{Message: locale.ErrorInvalidToken})
+	}
+
+	scope := RequiredScope(c.Request().Method, c.Request().URL.Path)
+	if scope == "" || !slices.Contains(token.Scopes, scope) {
+		logger.Warnw("access token without scope", "token_id", token.ID, "scope", scope, "path", c.Request().URL.Path)
+		return c.JSON(http.StatusForbidden, e.ResponseError{Message: locale.ErrorInsufficientScope, Details: scope})
+	}
+
+	c.Set("user_id", token.UserID)
+
+	return next(c)
+}
+
 func GetUserIdFromContext(c echo.Context) uint {
 	userID, ok := c.Get("user_id").(uint)
 	if !ok {
@@ -50,6 +83,13 @@ func GetUserIdFromContext(c echo.Context) uint {
 	return userID
 }
 
+// GetSessionIdFromContext : the session of the JWT of the request, empty for access
+// tokens
+func GetSessionIdFromContext(c echo.Context) string {
+	sessionID, _ := c.Get("session_id").(string)
+	return sessionID
+}
+
 func GetUserEmailFromContext(c echo.Context) string {
 	email, ok := c.Get("user_email").(string)
 	if !ok {
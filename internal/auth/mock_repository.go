@@ -12,6 +12,7 @@ package auth
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -40,6 +41,48 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CreateAccessToken mocks base method.
+func (m *MockRepository) CreateAccessToken(ctx context.Context, token *PersonalAccessToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccessToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAccessToken indicates an expected call of CreateAccessToken.
+func (mr *MockRepositoryMockRecorder) CreateAccessToken(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccessToken", reflect.TypeOf((*MockRepository)(nil).CreateAccessToken), ctx, token)
+}
+
+// CreateWebAuthnCredential mocks base method.
+func (m *MockRepository) CreateWebAuthnCredential(ctx context.Context, credential *WebAuthnCredential) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebAuthnCredential", ctx, credential)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebAuthnCredential indicates an expected call of CreateWebAuthnCredential.
+func (mr *MockRepositoryMockRecorder) CreateWebAuthnCredential(ctx, credential any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebAuthnCredential", reflect.TypeOf((*MockRepository)(nil).CreateWebAuthnCredential), ctx, credential)
+}
+
+// DeleteAccessToken mocks base method.
+func (m *MockRepository) DeleteAccessToken(ctx context.Context, userID, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccessToken", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccessToken indicates an expected call of DeleteAccessToken.
+func (mr *MockRepositoryMockRecorder) DeleteAccessToken(ctx, userID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccessToken", reflect.TypeOf((*MockRepository)(nil).DeleteAccessToken), ctx, userID, id)
+}
+
 // DeleteExpiredTokens mocks base method.
 func (m *MockRepository) DeleteExpiredTokens(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -54,19 +97,181 @@ func (mr *MockRepositoryMockRecorder) DeleteExpiredTokens(ctx any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredTokens", reflect.TypeOf((*MockRepository)(nil).DeleteExpiredTokens), ctx)
 }
 
+// DeleteWebAuthnCredential mocks base method.
+func (m *MockRepository) DeleteWebAuthnCredential(ctx context.Context, userID, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebAuthnCredential", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebAuthnCredential indicates an expected call of DeleteWebAuthnCredential.
+func (mr *MockRepositoryMockRecorder) DeleteWebAuthnCredential(ctx, userID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebAuthnCredential", reflect.TypeOf((*MockRepository)(nil).DeleteWebAuthnCredential), ctx, userID, id)
+}
+
+// GetAccessTokenByHash mocks base method.
+func (m *MockRepository) GetAccessTokenByHash(ctx context.Context, hash string) (PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccessTokenByHash", ctx, hash)
+	ret0, _ := ret[0].(PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccessTokenByHash indicates an expected call of GetAccessTokenByHash.
+func (mr *MockRepositoryMockRecorder) GetAccessTokenByHash(ctx, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessTokenByHash", reflect.TypeOf((*MockRepository)(nil).GetAccessTokenByHash), ctx, hash)
+}
+
+// GetAccessTokens mocks base method.
+func (m *MockRepository) GetAccessTokens(ctx context.Context, userID uint) ([]PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccessTokens", ctx, userID)
+	ret0, _ := ret[0].([]PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccessTokens indicates an expected call of GetAccessTokens.
+func (mr *MockRepositoryMockRecorder) GetAccessTokens(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessTokens", reflect.TypeOf((*MockRepository)(nil).GetAccessTokens), ctx, userID)
+}
+
+// GetActiveRefreshTokens mocks base method.
+func (m *MockRepository) GetActiveRefreshTokens(ctx context.Context, userID uint) ([]RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveRefreshTokens", ctx, userID)
+	ret0, _ := ret[0].([]RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveRefreshTokens indicates an expected call of GetActiveRefreshTokens.
+func (mr *MockRepositoryMockRecorder) GetActiveRefreshTokens(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveRefreshTokens", reflect.TypeOf((*MockRepository)(nil).GetActiveRefreshTokens), ctx, userID)
+}
+
 // GetRefreshToken mocks base method.
-func (m *MockRepository) GetRefreshToken(ctx context.Context, token string) (RefreshToken, error) {
+func (m *MockRepository) GetRefreshToken(ctx context.Context, tokenHash string) (RefreshToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRefreshToken", ctx, token)
+	ret := m.ctrl.Call(m, "GetRefreshToken", ctx, tokenHash)
 	ret0, _ := ret[0].(RefreshToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetRefreshToken indicates an expected call of GetRefreshToken.
-func (mr *MockRepositoryMockRecorder) GetRefreshToken(ctx, token any) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetRefreshToken(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshToken", reflect.TypeOf((*MockRepository)(nil).GetRefreshToken), ctx, tokenHash)
+}
+
+// GetTOTPCredential mocks base method.
+func (m *MockRepository) GetTOTPCredential(ctx context.Context, userID uint) (TOTPCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTOTPCredential", ctx, userID)
+	ret0, _ := ret[0].(TOTPCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTOTPCredential indicates an expected call of GetTOTPCredential.
+func (mr *MockRepositoryMockRecorder) GetTOTPCredential(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTOTPCredential", reflect.TypeOf((*MockRepository)(nil).GetTOTPCredential), ctx, userID)
+}
+
+// GetWebAuthnCredential mocks base method.
+func (m *MockRepository) GetWebAuthnCredential(ctx context.Context, credentialID []byte) (WebAuthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebAuthnCredential", ctx, credentialID)
+	ret0, _ := ret[0].(WebAuthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebAuthnCredential indicates an expected call of GetWebAuthnCredential.
+func (mr *MockRepositoryMockRecorder) GetWebAuthnCredential(ctx, credentialID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebAuthnCredential", reflect.TypeOf((*MockRepository)(nil).GetWebAuthnCredential), ctx, credentialID)
+}
+
+// GetWebAuthnCredentials mocks base method.
+func (m *MockRepository) GetWebAuthnCredentials(ctx context.Context, userID uint) ([]WebAuthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebAuthnCredentials", ctx, userID)
+	ret0, _ := ret[0].([]WebAuthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebAuthnCredentials indicates an expected call of GetWebAuthnCredentials.
+func (mr *MockRepositoryMockRecorder) GetWebAuthnCredentials(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebAuthnCredentials", reflect.TypeOf((*MockRepository)(nil).GetWebAuthnCredentials), ctx, userID)
+}
+
+// IsTokenRevoked mocks base method.
+func (m *MockRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTokenRevoked", ctx, jti)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsTokenRevoked indicates an expected call of IsTokenRevoked.
+func (mr *MockRepositoryMockRecorder) IsTokenRevoked(ctx, jti any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTokenRevoked", reflect.TypeOf((*MockRepository)(nil).IsTokenRevoked), ctx, jti)
+}
+
+// ReplaceRecoveryCodes mocks base method.
+func (m *MockRepository) ReplaceRecoveryCodes(ctx context.Context, userID uint, codes []RecoveryCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodes", ctx, userID, codes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceRecoveryCodes indicates an expected call of ReplaceRecoveryCodes.
+func (mr *MockRepositoryMockRecorder) ReplaceRecoveryCodes(ctx, userID, codes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodes", reflect.TypeOf((*MockRepository)(nil).ReplaceRecoveryCodes), ctx, userID, codes)
+}
+
+// ReplaceRefreshToken mocks base method.
+func (m *MockRepository) ReplaceRefreshToken(ctx context.Context, id uint, now time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRefreshToken", ctx, id, now)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplaceRefreshToken indicates an expected call of ReplaceRefreshToken.
+func (mr *MockRepositoryMockRecorder) ReplaceRefreshToken(ctx, id, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRefreshToken", reflect.TypeOf((*MockRepository)(nil).ReplaceRefreshToken), ctx, id, now)
+}
+
+// RevokeRefreshTokenFamily mocks base method.
+func (m *MockRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshTokenFamily", ctx, familyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshTokenFamily indicates an expected call of RevokeRefreshTokenFamily.
+func (mr *MockRepositoryMockRecorder) RevokeRefreshTokenFamily(ctx, familyID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshToken", reflect.TypeOf((*MockRepository)(nil).GetRefreshToken), ctx, token)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshTokenFamily", reflect.TypeOf((*MockRepository)(nil).RevokeRefreshTokenFamily), ctx, familyID)
 }
 
 // RevokeRefreshTokensByUserID mocks base method.
@@ -83,6 +288,34 @@ func (mr *MockRepositoryMockRecorder) RevokeRefreshTokensByUserID(ctx, userID an
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshTokensByUserID", reflect.TypeOf((*MockRepository)(nil).RevokeRefreshTokensByUserID), ctx, userID)
 }
 
+// RevokeSession mocks base method.
+func (m *MockRepository) RevokeSession(ctx context.Context, userID uint, familyID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, userID, familyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockRepositoryMockRecorder) RevokeSession(ctx, userID, familyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockRepository)(nil).RevokeSession), ctx, userID, familyID)
+}
+
+// RevokeToken mocks base method.
+func (m *MockRepository) RevokeToken(ctx context.Context, token *RevokedToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeToken indicates an expected call of RevokeToken.
+func (mr *MockRepositoryMockRecorder) RevokeToken(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockRepository)(nil).RevokeToken), ctx, token)
+}
+
 // SaveRefreshToken mocks base method.
 func (m *MockRepository) SaveRefreshToken(ctx context.Context, token *RefreshToken) error {
 	m.ctrl.T.Helper()
@@ -96,3 +329,76 @@ func (mr *MockRepositoryMockRecorder) SaveRefreshToken(ctx, token any) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRefreshToken", reflect.TypeOf((*MockRepository)(nil).SaveRefreshToken), ctx, token)
 }
+
+// SaveTOTPCredential mocks base method.
+func (m *MockRepository) SaveTOTPCredential(ctx context.Context, credential *TOTPCredential) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTOTPCredential", ctx, credential)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTOTPCredential indicates an expected call of SaveTOTPCredential.
+func (mr *MockRepositoryMockRecorder) SaveTOTPCredential(ctx, credential any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTOTPCredential", reflect.TypeOf((*MockRepository)(nil).SaveTOTPCredential), ctx, credential)
+}
+
+// TouchAccessToken mocks base method.
+func (m *MockRepository) TouchAccessToken(ctx context.Context, id uint, usedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAccessToken", ctx, id, usedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAccessToken indicates an expected call of TouchAccessToken.
+func (mr *MockRepositoryMockRecorder) TouchAccessToken(ctx, id, usedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAccessToken", reflect.TypeOf((*MockRepository)(nil).TouchAccessToken), ctx, id, usedAt)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockRepository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string, now time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, userID, codeHash, now)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockRepositoryMockRecorder) UseRecoveryCode(ctx, userID, codeHash, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockRepository)(nil).UseRecoveryCode), ctx, userID, codeHash, now)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockRepository) UseTOTPStep(ctx context.Context, id uint, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", ctx, id, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockRepositoryMockRecorder) UseTOTPStep(ctx, id, step any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockRepository)(nil).UseTOTPStep), ctx, id, step)
+}
+
+// UseWebAuthnCredential mocks base method.
+func (m *MockRepository) UseWebAuthnCredential(ctx context.Context, id uint, oldCount, newCount uint32, now time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseWebAuthnCredential", ctx, id, oldCount, newCount, now)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseWebAuthnCredential indicates an expected call of UseWebAuthnCredential.
+func (mr *MockRepositoryMockRecorder) UseWebAuthnCredential(ctx, id, oldCount, newCount, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseWebAuthnCredential", reflect.TypeOf((*MockRepository)(nil).UseWebAuthnCredential), ctx, id, oldCount, newCount, now)
+}
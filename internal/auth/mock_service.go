@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: internal/auth/service.go
+// Source: ./internal/auth/service.go
 //
 // Generated by this command:
 //
-//	mockgen -source=internal/auth/service.go -destination=internal/auth/mock_service.go -package=auth
+//	mockgen -source=./internal/auth/service.go -destination=./internal/auth/mock_service.go -package=auth
 //
 
 // Package auth is a generated GoMock package.
@@ -12,7 +12,9 @@ package auth
 import (
 	context "context"
 	reflect "reflect"
+	users "todo-app/internal/users"
 
+	protocol "github.com/go-webauthn/webauthn/protocol"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,6 +42,242 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// BeginPasskeyLogin mocks base method.
+func (m *MockService) BeginPasskeyLogin(ctx context.Context) (protocol.PublicKeyCredentialRequestOptions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginPasskeyLogin", ctx)
+	ret0, _ := ret[0].(protocol.PublicKeyCredentialRequestOptions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginPasskeyLogin indicates an expected call of BeginPasskeyLogin.
+func (mr *MockServiceMockRecorder) BeginPasskeyLogin(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginPasskeyLogin", reflect.TypeOf((*MockService)(nil).BeginPasskeyLogin), ctx)
+}
+
+// BeginPasskeyMFA mocks base method.
+func (m *MockService) BeginPasskeyMFA(ctx context.Context, req PasskeyMFARequest) (protocol.PublicKeyCredentialRequestOptions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginPasskeyMFA", ctx, req)
+	ret0, _ := ret[0].(protocol.PublicKeyCredentialRequestOptions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginPasskeyMFA indicates an expected call of BeginPasskeyMFA.
+func (mr *MockServiceMockRecorder) BeginPasskeyMFA(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginPasskeyMFA", reflect.TypeOf((*MockService)(nil).BeginPasskeyMFA), ctx, req)
+}
+
+// BeginPasskeyRegistration mocks base method.
+func (m *MockService) BeginPasskeyRegistration(ctx context.Context, userID uint) (protocol.PublicKeyCredentialCreationOptions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginPasskeyRegistration", ctx, userID)
+	ret0, _ := ret[0].(protocol.PublicKeyCredentialCreationOptions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginPasskeyRegistration indicates an expected call of BeginPasskeyRegistration.
+func (mr *MockServiceMockRecorder) BeginPasskeyRegistration(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginPasskeyRegistration", reflect.TypeOf((*MockService)(nil).BeginPasskeyRegistration), ctx, userID)
+}
+
+// CheckRevocation mocks base method.
+func (m *MockService) CheckRevocation(ctx context.Context, claims *JWTClaims) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckRevocation", ctx, claims)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckRevocation indicates an expected call of CheckRevocation.
+func (mr *MockServiceMockRecorder) CheckRevocation(ctx, claims any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckRevocation", reflect.TypeOf((*MockService)(nil).CheckRevocation), ctx, claims)
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockService) ConfirmTOTP(ctx context.Context, userID uint, req MFACodeRequest) (RecoveryCodesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", ctx, userID, req)
+	ret0, _ := ret[0].(RecoveryCodesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockServiceMockRecorder) ConfirmTOTP(ctx, userID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockService)(nil).ConfirmTOTP), ctx, userID, req)
+}
+
+// CreateAccessToken mocks base method.
+func (m *MockService) CreateAccessToken(ctx context.Context, userID uint, input AccessTokenInput) (AccessTokenResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccessToken", ctx, userID, input)
+	ret0, _ := ret[0].(AccessTokenResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccessToken indicates an expected call of CreateAccessToken.
+func (mr *MockServiceMockRecorder) CreateAccessToken(ctx, userID, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccessToken", reflect.TypeOf((*MockService)(nil).CreateAccessToken), ctx, userID, input)
+}
+
+// DeleteAccessToken mocks base method.
+func (m *MockService) DeleteAccessToken(ctx context.Context, userID, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccessToken", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccessToken indicates an expected call of DeleteAccessToken.
+func (mr *MockServiceMockRecorder) DeleteAccessToken(ctx, userID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccessToken", reflect.TypeOf((*MockService)(nil).DeleteAccessToken), ctx, userID, id)
+}
+
+// DeletePasskey mocks base method.
+func (m *MockService) DeletePasskey(ctx context.Context, userID, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePasskey", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePasskey indicates an expected call of DeletePasskey.
+func (mr *MockServiceMockRecorder) DeletePasskey(ctx, userID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePasskey", reflect.TypeOf((*MockService)(nil).DeletePasskey), ctx, userID, id)
+}
+
+// EnrollTOTP mocks base method.
+func (m *MockService) EnrollTOTP(ctx context.Context, userID uint) (TOTPEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollTOTP", ctx, userID)
+	ret0, _ := ret[0].(TOTPEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTOTP indicates an expected call of EnrollTOTP.
+func (mr *MockServiceMockRecorder) EnrollTOTP(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTOTP", reflect.TypeOf((*MockService)(nil).EnrollTOTP), ctx, userID)
+}
+
+// FinishPasskeyLogin mocks base method.
+func (m *MockService) FinishPasskeyLogin(ctx context.Context, response protocol.CredentialAssertionResponse) (LoginResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishPasskeyLogin", ctx, response)
+	ret0, _ := ret[0].(LoginResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishPasskeyLogin indicates an expected call of FinishPasskeyLogin.
+func (mr *MockServiceMockRecorder) FinishPasskeyLogin(ctx, response any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishPasskeyLogin", reflect.TypeOf((*MockService)(nil).FinishPasskeyLogin), ctx, response)
+}
+
+// FinishPasskeyMFA mocks base method.
+func (m *MockService) FinishPasskeyMFA(ctx context.Context, req PasskeyMFAVerifyRequest) (LoginResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishPasskeyMFA", ctx, req)
+	ret0, _ := ret[0].(LoginResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishPasskeyMFA indicates an expected call of FinishPasskeyMFA.
+func (mr *MockServiceMockRecorder) FinishPasskeyMFA(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishPasskeyMFA", reflect.TypeOf((*MockService)(nil).FinishPasskeyMFA), ctx, req)
+}
+
+// FinishPasskeyRegistration mocks base method.
+func (m *MockService) FinishPasskeyRegistration(ctx context.Context, userID uint, req PasskeyRegistrationRequest) (WebAuthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishPasskeyRegistration", ctx, userID, req)
+	ret0, _ := ret[0].(WebAuthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishPasskeyRegistration indicates an expected call of FinishPasskeyRegistration.
+func (mr *MockServiceMockRecorder) FinishPasskeyRegistration(ctx, userID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishPasskeyRegistration", reflect.TypeOf((*MockService)(nil).FinishPasskeyRegistration), ctx, userID, req)
+}
+
+// ForgotPassword mocks base method.
+func (m *MockService) ForgotPassword(ctx context.Context, req ForgotPasswordRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForgotPassword", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForgotPassword indicates an expected call of ForgotPassword.
+func (mr *MockServiceMockRecorder) ForgotPassword(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForgotPassword", reflect.TypeOf((*MockService)(nil).ForgotPassword), ctx, req)
+}
+
+// GetAccessTokens mocks base method.
+func (m *MockService) GetAccessTokens(ctx context.Context, userID uint) ([]PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccessTokens", ctx, userID)
+	ret0, _ := ret[0].([]PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccessTokens indicates an expected call of GetAccessTokens.
+func (mr *MockServiceMockRecorder) GetAccessTokens(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessTokens", reflect.TypeOf((*MockService)(nil).GetAccessTokens), ctx, userID)
+}
+
+// GetPasskeys mocks base method.
+func (m *MockService) GetPasskeys(ctx context.Context, userID uint) ([]WebAuthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasskeys", ctx, userID)
+	ret0, _ := ret[0].([]WebAuthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasskeys indicates an expected call of GetPasskeys.
+func (mr *MockServiceMockRecorder) GetPasskeys(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasskeys", reflect.TypeOf((*MockService)(nil).GetPasskeys), ctx, userID)
+}
+
+// GetSessions mocks base method.
+func (m *MockService) GetSessions(ctx context.Context, userID uint, currentSessionID string) ([]Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessions", ctx, userID, currentSessionID)
+	ret0, _ := ret[0].([]Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessions indicates an expected call of GetSessions.
+func (mr *MockServiceMockRecorder) GetSessions(ctx, userID, currentSessionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessions", reflect.TypeOf((*MockService)(nil).GetSessions), ctx, userID, currentSessionID)
+}
+
 // GoogleCallback mocks base method.
 func (m *MockService) GoogleCallback(ctx context.Context, code string) (*LoginResponse, error) {
 	m.ctrl.T.Helper()
@@ -69,6 +307,32 @@ func (mr *MockServiceMockRecorder) GoogleLogin(ctx, state any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GoogleLogin", reflect.TypeOf((*MockService)(nil).GoogleLogin), ctx, state)
 }
 
+// HandleUserEvent mocks base method.
+func (m *MockService) HandleUserEvent(ctx context.Context, event users.Event) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "HandleUserEvent", ctx, event)
+}
+
+// HandleUserEvent indicates an expected call of HandleUserEvent.
+func (mr *MockServiceMockRecorder) HandleUserEvent(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleUserEvent", reflect.TypeOf((*MockService)(nil).HandleUserEvent), ctx, event)
+}
+
+// JWKS mocks base method.
+func (m *MockService) JWKS() JWKS {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKS")
+	ret0, _ := ret[0].(JWKS)
+	return ret0
+}
+
+// JWKS indicates an expected call of JWKS.
+func (mr *MockServiceMockRecorder) JWKS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKS", reflect.TypeOf((*MockService)(nil).JWKS))
+}
+
 // Login mocks base method.
 func (m *MockService) Login(ctx context.Context, req LoginRequest) (LoginResponse, error) {
 	m.ctrl.T.Helper()
@@ -85,17 +349,17 @@ func (mr *MockServiceMockRecorder) Login(ctx, req any) *gomock.Call {
 }
 
 // Logout mocks base method.
-func (m *MockService) Logout(ctx context.Context, token string) error {
+func (m *MockService) Logout(ctx context.Context, token string, everywhere bool) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Logout", ctx, token)
+	ret := m.ctrl.Call(m, "Logout", ctx, token, everywhere)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Logout indicates an expected call of Logout.
-func (mr *MockServiceMockRecorder) Logout(ctx, token any) *gomock.Call {
+func (mr *MockServiceMockRecorder) Logout(ctx, token, everywhere any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockService)(nil).Logout), ctx, token)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockService)(nil).Logout), ctx, token, everywhere)
 }
 
 // RefreshToken mocks base method.
@@ -113,6 +377,49 @@ func (mr *MockServiceMockRecorder) RefreshToken(ctx, refreshToken any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockService)(nil).RefreshToken), ctx, refreshToken)
 }
 
+// ResetPassword mocks base method.
+func (m *MockService) ResetPassword(ctx context.Context, req ResetPasswordRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockServiceMockRecorder) ResetPassword(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockService)(nil).ResetPassword), ctx, req)
+}
+
+// RevokeSession mocks base method.
+func (m *MockService) RevokeSession(ctx context.Context, userID uint, sessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, userID, sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockServiceMockRecorder) RevokeSession(ctx, userID, sessionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockService)(nil).RevokeSession), ctx, userID, sessionID)
+}
+
+// ValidateAccessToken mocks base method.
+func (m *MockService) ValidateAccessToken(ctx context.Context, token string) (PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateAccessToken", ctx, token)
+	ret0, _ := ret[0].(PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidateAccessToken indicates an expected call of ValidateAccessToken.
+func (mr *MockServiceMockRecorder) ValidateAccessToken(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateAccessToken", reflect.TypeOf((*MockService)(nil).ValidateAccessToken), ctx, token)
+}
+
 // ValidateToken mocks base method.
 func (m *MockService) ValidateToken(tokenString string) (*JWTClaims, error) {
 	m.ctrl.T.Helper()
@@ -127,3 +434,18 @@ func (mr *MockServiceMockRecorder) ValidateToken(tokenString any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateToken", reflect.TypeOf((*MockService)(nil).ValidateToken), tokenString)
 }
+
+// VerifyMFA mocks base method.
+func (m *MockService) VerifyMFA(ctx context.Context, req MFAVerifyRequest) (LoginResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyMFA", ctx, req)
+	ret0, _ := ret[0].(LoginResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyMFA indicates an expected call of VerifyMFA.
+func (mr *MockServiceMockRecorder) VerifyMFA(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyMFA", reflect.TypeOf((*MockService)(nil).VerifyMFA), ctx, req)
+}
@@ -3,6 +3,7 @@ package auth
 import (
 	"time"
 
+	"github.com/go-webauthn/webauthn/protocol"
 	"github.com/golang-jwt/jwt/v5"
 	"gorm.io/gorm"
 )
@@ -12,11 +13,26 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// LoginResponse : when MFARequired is set only MFAToken is filled, it is exchanged for
+// the tokens with a code at /auth/mfa/verify or a passkey at /auth/webauthn/mfa/finish
 type LoginResponse struct {
-	Token     string   `json:"token"`
-	Refresh   string   `json:"refresh_token"`
-	ExpiresAt int64    `json:"expires_at"`
-	User      UserInfo `json:"user"`
+	Token       string   `json:"token,omitempty"`
+	Refresh     string   `json:"refresh_token,omitempty"`
+	ExpiresAt   int64    `json:"expires_at,omitempty"`
+	User        UserInfo `json:"user"`
+	MFARequired bool     `json:"mfa_required,omitempty"`
+	MFAToken    string   `json:"mfa_token,omitempty"`
+	// MFAMethods : the second factors the user has, totp and webauthn
+	MFAMethods []string `json:"mfa_methods,omitempty"`
 }
 
 type UserInfo struct {
@@ -26,16 +42,169 @@ type UserInfo struct {
 	Email     string `json:"email"`
 }
 
+// JWTClaims : the jti (RegisteredClaims.ID) identifies the token for revocation
 type JWTClaims struct {
 	UserID uint   `json:"user_id"`
 	Email  string `json:"email"`
+	// SessionID : the refresh token family the token was issued with
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// RefreshToken : only the SHA-256 hash of the token is stored. Every refresh replaces
+// the token with a new one of the same family, a replaced token that is used again
+// revokes the family. A family is a session, its latest token tells the client it was
+// last used from.
 type RefreshToken struct {
 	gorm.Model
 	UserID    uint      `gorm:"not null"`
-	Token     string    `gorm:"type:varchar(255);uniqueIndex;not null"`
+	TokenHash string    `gorm:"type:char(64);uniqueIndex;not null"`
+	FamilyID  string    `gorm:"type:varchar(36);index;not null"`
 	ExpiresAt time.Time `gorm:"not null"`
 	IsRevoked bool      `gorm:"default:false"`
+	// ReplacedAt : when the token was exchanged for a new one
+	ReplacedAt *time.Time
+	// SessionStartedAt : when the family was created with a login
+	SessionStartedAt *time.Time
+	UserAgent        string `gorm:"type:varchar(255);not null;default:''"`
+	IP               string `gorm:"type:varchar(45);not null;default:''"`
+}
+
+// RevokedToken : an access token that was revoked before it expired, by the jti claim,
+// or a revoked session whose access tokens all are rejected, by the sid claim. It can be
+// deleted once it expired.
+type RevokedToken struct {
+	// JTI : the jti of the token, or the sid of the session
+	JTI       string    `gorm:"type:varchar(36);primaryKey"`
+	UserID    uint      `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// Session : a login of the user on a device, it lasts as long as its refresh tokens
+type Session struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	// Current : the session of the request
+	Current bool `json:"current"`
+}
+
+// TOTPCredential : the TOTP secret of a user, MFA is on once it is confirmed with a code
+type TOTPCredential struct {
+	gorm.Model
+	UserID      uint   `gorm:"uniqueIndex;not null"`
+	Secret      string `gorm:"type:varchar(64);not null"`
+	ConfirmedAt *time.Time
+	// LastStep : the time step of the last accepted code, a code is not accepted twice
+	LastStep int64
+}
+
+// RecoveryCode : single use code for when the authenticator is lost, only its SHA-256
+// hash is stored
+type RecoveryCode struct {
+	gorm.Model
+	UserID   uint   `gorm:"index;not null"`
+	CodeHash string `gorm:"type:char(64);uniqueIndex;not null"`
+	UsedAt   *time.Time
+}
+
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+	// QRCode : the URI as a PNG data URL
+	QRCode string `json:"qr_code"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	// Code : a TOTP code or a recovery code
+	Code string `json:"code" validate:"required"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// WebAuthnCredential : a passkey of a user. It logs in without a password, or is the
+// second factor after one.
+type WebAuthnCredential struct {
+	ID           uint   `gorm:"primaryKey" json:"id"`
+	UserID       uint   `gorm:"not null;index" json:"-"`
+	CredentialID []byte `gorm:"type:varbinary(1023);not null;uniqueIndex" json:"-"`
+	// PublicKey : the COSE_Key the authenticator signs with
+	PublicKey []byte `gorm:"type:blob;not null" json:"-"`
+	// SignCount : the counter of the last assertion, a lower one means a cloned
+	// authenticator
+	SignCount uint32 `gorm:"not null" json:"-"`
+	// BackupEligible : whether the passkey can be synced to other devices, it never
+	// changes for a passkey
+	BackupEligible bool       `gorm:"not null;default:false" json:"-"`
+	Name           string     `gorm:"type:varchar(100);not null" json:"name"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+type PasskeyRegistrationRequest struct {
+	Name       string                              `json:"name" validate:"required,max=100"`
+	Credential protocol.CredentialCreationResponse `json:"credential"`
+}
+
+type PasskeyMFARequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+}
+
+type PasskeyMFAVerifyRequest struct {
+	MFAToken   string                               `json:"mfa_token" validate:"required"`
+	Credential protocol.CredentialAssertionResponse `json:"credential"`
+}
+
+// PersonalAccessToken : a token scripts use instead of a JWT, limited to its scopes.
+// Only its SHA-256 hash is stored.
+type PersonalAccessToken struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"-"`
+	Name       string     `gorm:"type:varchar(100);not null" json:"name"`
+	TokenHash  string     `gorm:"type:char(64);not null;uniqueIndex" json:"-"`
+	Scopes     []string   `gorm:"type:json;serializer:json" json:"scopes"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+type AccessTokenInput struct {
+	Name   string   `json:"name" validate:"required,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=todos:read todos:write templates:read templates:write habits:read habits:write webhooks:read webhooks:write rules:read rules:write"`
+	// ExpiresInDays : 90 when not set
+	ExpiresInDays int `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
+
+// AccessTokenResponse : the token is only shown once, when it is created
+type AccessTokenResponse struct {
+	PersonalAccessToken
+	Token string `json:"token"`
+}
+
+// JWKS : the public keys JWTs are verified with, served at /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK : an RSA or Ed25519 public key, binary values are base64url encoded
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
 }
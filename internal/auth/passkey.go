@@ -0,0 +1,379 @@
+package auth
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"todo-app/pkg/locale"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"gorm.io/gorm"
+)
+
+// The WebAuthn ceremonies a challenge is issued for
+const (
+	ceremonyRegistration = "registration"
+	ceremonyLogin        = "login"
+	ceremonyMFA          = "mfa"
+)
+
+// webauthnChallengeExpiration : matches the timeout the browser is given
+const webauthnChallengeExpiration = 5 * time.Minute
+
+// webauthnChallenge : a ceremony waiting for the response of the browser, userID is 0
+// for passwordless logins, where the passkey tells who the user is
+type webauthnChallenge struct {
+	ceremony  string
+	userID    uint
+	session   webauthn.SessionData
+	expiresAt time.Time
+}
+
+// passkeyUser : a user as go-webauthn sees it
+type passkeyUser struct {
+	id          uint
+	name        string
+	displayName string
+	passkeys    []WebAuthnCredential
+}
+
+func (u passkeyUser) WebAuthnID() []byte {
+	return userHandle(u.id)
+}
+
+func (u passkeyUser) WebAuthnName() string {
+	return u.name
+}
+
+func (u passkeyUser) WebAuthnDisplayName() string {
+	return u.displayName
+}
+
+func (u passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, len(u.passkeys))
+	for i, passkey := range u.passkeys {
+		credentials[i] = webauthn.Credential{
+			ID:            passkey.CredentialID,
+			PublicKey:     passkey.PublicKey,
+			Flags:         webauthn.CredentialFlags{BackupEligible: passkey.BackupEligible},
+			Authenticator: webauthn.Authenticator{SignCount: passkey.SignCount},
+		}
+	}
+
+	return credentials
+}
+
+// newRelyingParty : the WebAuthn relying party of the app, credentials are bound to the
+// domain of the frontend
+func newRelyingParty(rpID string, origin string) (*webauthn.WebAuthn, error) {
+	timeout := webauthn.TimeoutConfig{Enforce: true, Timeout: webauthnChallengeExpiration, TimeoutUVD: webauthnChallengeExpiration}
+
+	return webauthn.New(&webauthn.Config{
+		RPID:                  rpID,
+		RPDisplayName:         "Todo App",
+		RPOrigins:             []string{origin},
+		AttestationPreference: protocol.PreferNoAttestation,
+		// passwordless logins find the passkey without knowing the user, and it
+		// replaces the password only when it checks who holds it
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey:        protocol.ResidentKeyRequirementRequired,
+			RequireResidentKey: protocol.ResidentKeyRequired(),
+			UserVerification:   protocol.VerificationRequired,
+		},
+		Timeouts: webauthn.TimeoutsConfig{Login: timeout, Registration: timeout},
+	})
+}
+
+// BeginPasskeyRegistration : the options to create a passkey of the user with
+func (s *service) BeginPasskeyRegistration(ctx context.Context, userID uint) (protocol.PublicKeyCredentialCreationOptions, error) {
+	user, err := s.userRepository.GetById(ctx, userID)
+	if err != nil {
+		return protocol.PublicKeyCredentialCreationOptions{}, errors.New(locale.ErrorUserNotFound)
+	}
+
+	passkeys, err := s.authRepository.GetWebAuthnCredentials(ctx, userID)
+	if err != nil {
+		return protocol.PublicKeyCredentialCreationOptions{}, errors.New(locale.ErrorInternalServer)
+	}
+
+	owner := passkeyUser{
+		id:          userID,
+		name:        user.Email,
+		displayName: strings.TrimSpace(fmt.Sprintf("%s %s", user.FirstName, user.LastName)),
+		passkeys:    passkeys,
+	}
+	exclusions := webauthn.Credentials(owner.WebAuthnCredentials()).CredentialDescriptors()
+	creation, session, err := s.webauthn.BeginRegistration(owner, webauthn.WithExclusions(exclusions))
+	if err != nil {
+		s.logger.Errorw("failed to begin passkey registration", "user_id", userID, "error", err)
+		return protocol.PublicKeyCredentialCreationOptions{}, errors.New(locale.ErrorInternalServer)
+	}
+	s.addWebAuthnChallenge(ceremonyRegistration, userID, *session)
+
+	return creation.Response, nil
+}
+
+// FinishPasskeyRegistration : stores the passkey created with the options of
+// BeginPasskeyRegistration
+func (s *service) FinishPasskeyRegistration(ctx context.Context, userID uint, req PasskeyRegistrationRequest) (WebAuthnCredential, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return WebAuthnCredential{}, err
+	}
+
+	parsed, err := req.Credential.Parse()
+	if err != nil {
+		s.logger.Warnw("invalid passkey registration", "user_id", userID, "error", err)
+		return WebAuthnCredential{}, errors.New(locale.ErrorInvalidPasskey)
+	}
+	session, ok := s.takeWebAuthnChallenge(parsed.Response.CollectedClientData.Challenge, ceremonyRegistration, userID)
+	if !ok {
+		return WebAuthnCredential{}, errors.New(locale.ErrorInvalidPasskey)
+	}
+
+	credential, err := s.webauthn.CreateCredential(passkeyUser{id: userID}, session, parsed)
+	if err != nil {
+		s.logger.Warnw("invalid passkey registration", "user_id", userID, "error", err)
+		return WebAuthnCredential{}, errors.New(locale.ErrorInvalidPasskey)
+	}
+
+	passkey := WebAuthnCredential{
+		UserID:         userID,
+		CredentialID:   credential.ID,
+		PublicKey:      credential.PublicKey,
+		SignCount:      credential.Authenticator.SignCount,
+		BackupEligible: credential.Flags.BackupEligible,
+		Name:           req.Name,
+	}
+	if err := s.authRepository.CreateWebAuthnCredential(ctx, &passkey); err != nil {
+		return WebAuthnCredential{}, errors.New(locale.ErrorInternalServer)
+	}
+
+	s.logger.Infow("passkey registered", "user_id", userID, "id", passkey.ID)
+	return passkey, nil
+}
+
+// BeginPasskeyLogin : the options of a passwordless login, the browser offers the
+// passkeys it has for the app
+func (s *service) BeginPasskeyLogin(ctx context.Context) (protocol.PublicKeyCredentialRequestOptions, error) {
+	assertion, session, err := s.webauthn.BeginDiscoverableLogin()
+	if err != nil {
+		s.logger.Errorw("failed to begin passkey login", "error", err)
+		return protocol.PublicKeyCredentialRequestOptions{}, errors.New(locale.ErrorInternalServer)
+	}
+	s.addWebAuthnChallenge(ceremonyLogin, 0, *session)
+
+	return assertion.Response, nil
+}
+
+// FinishPasskeyLogin : logs in the owner of the passkey. The passkey checked who holds
+// it, so no second factor is asked for.
+func (s *service) FinishPasskeyLogin(ctx context.Context, response protocol.CredentialAssertionResponse) (LoginResponse, error) {
+	parsed, err := response.Parse()
+	if err != nil {
+		s.logger.Warnw("invalid passkey assertion", "error", err)
+		return LoginResponse{}, errors.New(locale.ErrorInvalidPasskey)
+	}
+	session, ok := s.takeWebAuthnChallenge(parsed.Response.CollectedClientData.Challenge, ceremonyLogin, 0)
+	if !ok {
+		return LoginResponse{}, errors.New(locale.ErrorInvalidPasskey)
+	}
+
+	// the passkey is looked up by its id, the library checks the user handle against
+	// its owner
+	var passkey WebAuthnCredential
+	owner := func(rawID, _ []byte) (webauthn.User, error) {
+		passkey, err = s.authRepository.GetWebAuthnCredential(ctx, rawID)
+		if err != nil {
+			return nil, err
+		}
+
+		return passkeyUser{id: passkey.UserID, passkeys: []WebAuthnCredential{passkey}}, nil
+	}
+	_, credential, err := s.webauthn.ValidatePasskeyLogin(owner, session, parsed)
+	if err != nil {
+		s.logger.Warnw("invalid passkey assertion", "user_id", passkey.UserID, "error", err)
+		return LoginResponse{}, errors.New(locale.ErrorInvalidPasskey)
+	}
+	if err := s.usePasskey(ctx, passkey, credential); err != nil {
+		return LoginResponse{}, err
+	}
+
+	user, err := s.userRepository.GetById(ctx, passkey.UserID)
+	if err != nil {
+		return LoginResponse{}, errors.New(locale.ErrorInvalidPasskey)
+	}
+	if !user.IsEmailVerified {
+		return LoginResponse{}, errors.New(locale.ErrorEmailUnverified)
+	}
+
+	return s.issueTokens(ctx, user)
+}
+
+// BeginPasskeyMFA : the options to use a passkey of the user as the second factor of a
+// login
+func (s *service) BeginPasskeyMFA(ctx context.Context, req PasskeyMFARequest) (protocol.PublicKeyCredentialRequestOptions, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return protocol.PublicKeyCredentialRequestOptions{}, err
+	}
+
+	claims, err := s.parseMFAToken(req.MFAToken)
+	if err != nil {
+		return protocol.PublicKeyCredentialRequestOptions{}, errors.New(locale.ErrorInvalidToken)
+	}
+
+	passkeys, err := s.authRepository.GetWebAuthnCredentials(ctx, claims.UserID)
+	if err != nil {
+		return protocol.PublicKeyCredentialRequestOptions{}, errors.New(locale.ErrorInternalServer)
+	}
+	if len(passkeys) == 0 {
+		return protocol.PublicKeyCredentialRequestOptions{}, errors.New(locale.ErrorMFANotEnrolled)
+	}
+
+	// the password already told who the user is
+	owner := passkeyUser{id: claims.UserID, passkeys: passkeys}
+	assertion, session, err := s.webauthn.BeginLogin(owner, webauthn.WithUserVerification(protocol.VerificationDiscouraged))
+	if err != nil {
+		s.logger.Errorw("failed to begin passkey mfa", "user_id", claims.UserID, "error", err)
+		return protocol.PublicKeyCredentialRequestOptions{}, errors.New(locale.ErrorInternalServer)
+	}
+	s.addWebAuthnChallenge(ceremonyMFA, claims.UserID, *session)
+
+	return assertion.Response, nil
+}
+
+// FinishPasskeyMFA : exchanges the challenge of a login and a passkey of the user for
+// the tokens
+func (s *service) FinishPasskeyMFA(ctx context.Context, req PasskeyMFAVerifyRequest) (LoginResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return LoginResponse{}, err
+	}
+
+	claims, err := s.parseMFAToken(req.MFAToken)
+	if err != nil {
+		return LoginResponse{}, errors.New(locale.ErrorInvalidToken)
+	}
+
+	if !s.countMFAAttempt(claims.ID, claims.ExpiresAt.Time) {
+		s.logger.Warnw("too many mfa attempts", "user_id", claims.UserID)
+		return LoginResponse{}, errors.New(locale.ErrorTooManyMFAAttempts)
+	}
+
+	parsed, err := req.Credential.Parse()
+	if err != nil {
+		s.logger.Warnw("invalid passkey assertion", "user_id", claims.UserID, "error", err)
+		return LoginResponse{}, errors.New(locale.ErrorInvalidPasskey)
+	}
+	session, ok := s.takeWebAuthnChallenge(parsed.Response.CollectedClientData.Challenge, ceremonyMFA, claims.UserID)
+	if !ok {
+		return LoginResponse{}, errors.New(locale.ErrorInvalidPasskey)
+	}
+
+	passkey, err := s.authRepository.GetWebAuthnCredential(ctx, parsed.RawID)
+	if err != nil || passkey.UserID != claims.UserID {
+		return LoginResponse{}, errors.New(locale.ErrorInvalidPasskey)
+	}
+	credential, err := s.webauthn.ValidateLogin(passkeyUser{id: claims.UserID, passkeys: []WebAuthnCredential{passkey}}, session, parsed)
+	if err != nil {
+		s.logger.Warnw("invalid passkey assertion", "user_id", claims.UserID, "error", err)
+		return LoginResponse{}, errors.New(locale.ErrorInvalidPasskey)
+	}
+	if err := s.usePasskey(ctx, passkey, credential); err != nil {
+		return LoginResponse{}, err
+	}
+	s.finishMFAChallenge(claims.ID)
+
+	user, err := s.userRepository.GetById(ctx, claims.UserID)
+	if err != nil {
+		return LoginResponse{}, errors.New(locale.ErrorUserNotFound)
+	}
+
+	return s.issueTokens(ctx, user)
+}
+
+func (s *service) GetPasskeys(ctx context.Context, userID uint) ([]WebAuthnCredential, error) {
+	passkeys, err := s.authRepository.GetWebAuthnCredentials(ctx, userID)
+	if err != nil {
+		return nil, errors.New(locale.ErrorInternalServer)
+	}
+
+	return passkeys, nil
+}
+
+func (s *service) DeletePasskey(ctx context.Context, userID uint, id uint) error {
+	err := s.authRepository.DeleteWebAuthnCredential(ctx, userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New(locale.ErrorNotFoundRecord)
+	}
+	if err != nil {
+		return errors.New(locale.ErrorInternalServer)
+	}
+
+	s.logger.Infow("passkey deleted", "user_id", userID, "id", id)
+	return nil
+}
+
+// usePasskey : moves the counter of the passkey on to the one of the verified assertion
+func (s *service) usePasskey(ctx context.Context, passkey WebAuthnCredential, credential *webauthn.Credential) error {
+	// a counter that did not move on means a cloned authenticator, authenticators
+	// without a counter always send 0
+	if credential.Authenticator.CloneWarning {
+		s.logger.Warnw("security_event passkey_counter_reused", "user_id", passkey.UserID, "id", passkey.ID)
+		return errors.New(locale.ErrorInvalidPasskey)
+	}
+	used, err := s.authRepository.UseWebAuthnCredential(ctx, passkey.ID, passkey.SignCount, credential.Authenticator.SignCount, time.Now())
+	if err != nil {
+		return errors.New(locale.ErrorInternalServer)
+	}
+	if !used {
+		return errors.New(locale.ErrorInvalidPasskey)
+	}
+
+	return nil
+}
+
+// addWebAuthnChallenge : keeps the session of the ceremony until the browser answers it
+func (s *service) addWebAuthnChallenge(ceremony string, userID uint, session webauthn.SessionData) {
+	s.webauthnMu.Lock()
+	defer s.webauthnMu.Unlock()
+
+	now := time.Now()
+	for key, pending := range s.webauthnChallenges {
+		if now.After(pending.expiresAt) {
+			delete(s.webauthnChallenges, key)
+		}
+	}
+	s.webauthnChallenges[session.Challenge] = webauthnChallenge{
+		ceremony:  ceremony,
+		userID:    userID,
+		session:   session,
+		expiresAt: now.Add(webauthnChallengeExpiration),
+	}
+}
+
+// takeWebAuthnChallenge : the session of the challenge, false when it was not issued for
+// the ceremony and user or expired. A challenge is answered once.
+func (s *service) takeWebAuthnChallenge(challenge string, ceremony string, userID uint) (webauthn.SessionData, bool) {
+	s.webauthnMu.Lock()
+	defer s.webauthnMu.Unlock()
+
+	pending, ok := s.webauthnChallenges[challenge]
+	if !ok {
+		return webauthn.SessionData{}, false
+	}
+	delete(s.webauthnChallenges, challenge)
+
+	if pending.ceremony != ceremony || pending.userID != userID || time.Now().After(pending.expiresAt) {
+		return webauthn.SessionData{}, false
+	}
+
+	return pending.session, true
+}
+
+// userHandle : the WebAuthn user ID, it holds no personal information
+func userHandle(userID uint) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(userID))
+}
@@ -2,16 +2,40 @@ package auth
 
 import (
 	"context"
+	"time"
+	"todo-app/pkg/database"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository interface {
 	SaveRefreshToken(ctx context.Context, token *RefreshToken) error
-	GetRefreshToken(ctx context.Context, token string) (RefreshToken, error)
+	GetRefreshToken(ctx context.Context, tokenHash string) (RefreshToken, error)
+	ReplaceRefreshToken(ctx context.Context, id uint, now time.Time) (bool, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
 	RevokeRefreshTokensByUserID(ctx context.Context, userID uint) error
+	GetActiveRefreshTokens(ctx context.Context, userID uint) ([]RefreshToken, error)
+	RevokeSession(ctx context.Context, userID uint, familyID string) error
 	DeleteExpiredTokens(ctx context.Context) error
+	RevokeToken(ctx context.Context, token *RevokedToken) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	GetTOTPCredential(ctx context.Context, userID uint) (TOTPCredential, error)
+	SaveTOTPCredential(ctx context.Context, credential *TOTPCredential) error
+	UseTOTPStep(ctx context.Context, id uint, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID uint, codes []RecoveryCode) error
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string, now time.Time) (bool, error)
+	CreateWebAuthnCredential(ctx context.Context, credential *WebAuthnCredential) error
+	GetWebAuthnCredential(ctx context.Context, credentialID []byte) (WebAuthnCredential, error)
+	GetWebAuthnCredentials(ctx context.Context, userID uint) ([]WebAuthnCredential, error)
+	UseWebAuthnCredential(ctx context.Context, id uint, oldCount uint32, newCount uint32, now time.Time) (bool, error)
+	DeleteWebAuthnCredential(ctx context.Context, userID uint, id uint) error
+	CreateAccessToken(ctx context.Context, token *PersonalAccessToken) error
+	GetAccessTokens(ctx context.Context, userID uint) ([]PersonalAccessToken, error)
+	GetAccessTokenByHash(ctx context.Context, hash string) (PersonalAccessToken, error)
+	TouchAccessToken(ctx context.Context, id uint, usedAt time.Time) error
+	DeleteAccessToken(ctx context.Context, userID uint, id uint) error
 }
 
 type repository struct {
@@ -27,7 +51,7 @@ func GetRepository(logger *zap.SugaredLogger, db *gorm.DB) Repository {
 }
 
 func (r *repository) SaveRefreshToken(ctx context.Context, token *RefreshToken) error {
-	result := r.db.WithContext(ctx).Create(token)
+	result := database.Conn(ctx, r.db).Create(token)
 	if result.Error != nil {
 		r.logger.Errorw("failed to create refresh token", "error", result.Error)
 
@@ -37,9 +61,10 @@ func (r *repository) SaveRefreshToken(ctx context.Context, token *RefreshToken)
 	return nil
 }
 
-func (r *repository) GetRefreshToken(ctx context.Context, token string) (RefreshToken, error) {
+// GetRefreshToken : revoked tokens included, so that reuse can be detected
+func (r *repository) GetRefreshToken(ctx context.Context, tokenHash string) (RefreshToken, error) {
 	var refreshToken RefreshToken
-	result := r.db.WithContext(ctx).Where("token = ? AND is_revoked = ?", token, false).First(&refreshToken)
+	result := database.Conn(ctx, r.db).Where("token_hash = ?", tokenHash).First(&refreshToken)
 	if result.Error != nil {
 		r.logger.Errorw("failed to find refresh token", "error", result.Error)
 
@@ -49,6 +74,33 @@ func (r *repository) GetRefreshToken(ctx context.Context, token string) (Refresh
 	return refreshToken, nil
 }
 
+// ReplaceRefreshToken : revokes the token as replaced, false when it was already revoked,
+// for example by a refresh running at the same time
+func (r *repository) ReplaceRefreshToken(ctx context.Context, id uint, now time.Time) (bool, error) {
+	result := database.Conn(ctx, r.db).
+		Model(&RefreshToken{}).
+		Where("id = ? AND is_revoked = ?", id, false).
+		Updates(map[string]interface{}{"is_revoked": true, "replaced_at": now})
+	if result.Error != nil {
+		r.logger.Errorw("failed to replace refresh token", "id", id, "error", result.Error)
+
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *repository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	result := r.db.WithContext(ctx).Model(&RefreshToken{}).Where("family_id = ?", familyID).Update("is_revoked", true)
+	if result.Error != nil {
+		r.logger.Errorw("failed to revoke refresh token family", "family_id", familyID, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
 func (r *repository) RevokeRefreshTokensByUserID(ctx context.Context, userID uint) error {
 	result := r.db.WithContext(ctx).Model(&RefreshToken{}).Where("user_id = ?", userID).Update("is_revoked", true)
 	if result.Error != nil {
@@ -60,6 +112,43 @@ func (r *repository) RevokeRefreshTokensByUserID(ctx context.Context, userID uin
 	return nil
 }
 
+// GetActiveRefreshTokens : the latest token of each session of the user that can still
+// be refreshed
+func (r *repository) GetActiveRefreshTokens(ctx context.Context, userID uint) ([]RefreshToken, error) {
+	var tokens []RefreshToken
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND is_revoked = ? AND replaced_at IS NULL AND expires_at > ?", userID, false, time.Now()).
+		Order("created_at DESC").
+		Find(&tokens)
+	if result.Error != nil {
+		r.logger.Errorw("failed to get active refresh tokens", "user_id", userID, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return tokens, nil
+}
+
+// RevokeSession : revokes the family of the user, gorm.ErrRecordNotFound when the user
+// has no such session left
+func (r *repository) RevokeSession(ctx context.Context, userID uint, familyID string) error {
+	result := r.db.WithContext(ctx).
+		Model(&RefreshToken{}).
+		Where("user_id = ? AND family_id = ? AND is_revoked = ?", userID, familyID, false).
+		Update("is_revoked", true)
+	if result.Error != nil {
+		r.logger.Errorw("failed to revoke session", "family_id", familyID, "error", result.Error)
+
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// DeleteExpiredTokens : refresh tokens and revoked access tokens that expired
 func (r *repository) DeleteExpiredTokens(ctx context.Context) error {
 	result := r.db.WithContext(ctx).Where("expires_at < NOW()").Delete(&RefreshToken{})
 	if result.Error != nil {
@@ -68,5 +157,228 @@ func (r *repository) DeleteExpiredTokens(ctx context.Context) error {
 		return result.Error
 	}
 
+	result = r.db.WithContext(ctx).Where("expires_at < NOW()").Delete(&RevokedToken{})
+	if result.Error != nil {
+		r.logger.Errorw("failed to delete expired revoked tokens", "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+// RevokeToken : revoking a token twice is not an error
+func (r *repository) RevokeToken(ctx context.Context, token *RevokedToken) error {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(token)
+	if result.Error != nil {
+		r.logger.Errorw("failed to revoke token", "user_id", token.UserID, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var count int64
+	result := r.db.WithContext(ctx).Model(&RevokedToken{}).Where("jti = ?", jti).Count(&count)
+	if result.Error != nil {
+		r.logger.Errorw("failed to check revoked token", "error", result.Error)
+
+		return false, result.Error
+	}
+
+	return count > 0, nil
+}
+
+func (r *repository) GetTOTPCredential(ctx context.Context, userID uint) (TOTPCredential, error) {
+	var credential TOTPCredential
+	result := database.Conn(ctx, r.db).Where("user_id = ?", userID).First(&credential)
+	if result.Error != nil {
+		return TOTPCredential{}, result.Error
+	}
+
+	return credential, nil
+}
+
+func (r *repository) SaveTOTPCredential(ctx context.Context, credential *TOTPCredential) error {
+	result := database.Conn(ctx, r.db).Save(credential)
+	if result.Error != nil {
+		r.logger.Errorw("failed to save totp credential", "user_id", credential.UserID, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+// UseTOTPStep : marks the time step as used, false when it or a later one already was
+func (r *repository) UseTOTPStep(ctx context.Context, id uint, step int64) (bool, error) {
+	result := database.Conn(ctx, r.db).
+		Model(&TOTPCredential{}).
+		Where("id = ? AND last_step < ?", id, step).
+		Update("last_step", step)
+	if result.Error != nil {
+		r.logger.Errorw("failed to use totp step", "id", id, "error", result.Error)
+
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// ReplaceRecoveryCodes : the codes replace all earlier ones of the user
+func (r *repository) ReplaceRecoveryCodes(ctx context.Context, userID uint, codes []RecoveryCode) error {
+	conn := database.Conn(ctx, r.db)
+	result := conn.Unscoped().Where("user_id = ?", userID).Delete(&RecoveryCode{})
+	if result.Error != nil {
+		r.logger.Errorw("failed to delete recovery codes", "user_id", userID, "error", result.Error)
+
+		return result.Error
+	}
+
+	result = conn.Create(&codes)
+	if result.Error != nil {
+		r.logger.Errorw("failed to create recovery codes", "user_id", userID, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+// UseRecoveryCode : marks the code as used, false when it does not exist or was used
+func (r *repository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", now)
+	if result.Error != nil {
+		r.logger.Errorw("failed to use recovery code", "user_id", userID, "error", result.Error)
+
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *repository) CreateWebAuthnCredential(ctx context.Context, credential *WebAuthnCredential) error {
+	result := r.db.WithContext(ctx).Create(credential)
+	if result.Error != nil {
+		r.logger.Errorw("failed to create webauthn credential", "user_id", credential.UserID, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) GetWebAuthnCredential(ctx context.Context, credentialID []byte) (WebAuthnCredential, error) {
+	var credential WebAuthnCredential
+	result := r.db.WithContext(ctx).Where("credential_id = ?", credentialID).First(&credential)
+	if result.Error != nil {
+		return WebAuthnCredential{}, result.Error
+	}
+
+	return credential, nil
+}
+
+func (r *repository) GetWebAuthnCredentials(ctx context.Context, userID uint) ([]WebAuthnCredential, error) {
+	var credentials []WebAuthnCredential
+	result := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&credentials)
+	if result.Error != nil {
+		r.logger.Errorw("failed to get webauthn credentials", "user_id", userID, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return credentials, nil
+}
+
+// UseWebAuthnCredential : stores the counter of an assertion, false when another one
+// changed it first
+func (r *repository) UseWebAuthnCredential(ctx context.Context, id uint, oldCount uint32, newCount uint32, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&WebAuthnCredential{}).
+		Where("id = ? AND sign_count = ?", id, oldCount).
+		Updates(map[string]interface{}{"sign_count": newCount, "last_used_at": now})
+	if result.Error != nil {
+		r.logger.Errorw("failed to update webauthn credential", "id", id, "error", result.Error)
+
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *repository) DeleteWebAuthnCredential(ctx context.Context, userID uint, id uint) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&WebAuthnCredential{})
+	if result.Error != nil {
+		r.logger.Errorw("failed to delete webauthn credential", "id", id, "error", result.Error)
+
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) CreateAccessToken(ctx context.Context, token *PersonalAccessToken) error {
+	result := r.db.WithContext(ctx).Create(token)
+	if result.Error != nil {
+		r.logger.Errorw("failed to create access token", "user_id", token.UserID, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) GetAccessTokens(ctx context.Context, userID uint) ([]PersonalAccessToken, error) {
+	var tokens []PersonalAccessToken
+	result := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&tokens)
+	if result.Error != nil {
+		r.logger.Errorw("failed to get access tokens", "user_id", userID, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return tokens, nil
+}
+
+func (r *repository) GetAccessTokenByHash(ctx context.Context, hash string) (PersonalAccessToken, error) {
+	var token PersonalAccessToken
+	result := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&token)
+	if result.Error != nil {
+		return PersonalAccessToken{}, result.Error
+	}
+
+	return token, nil
+}
+
+func (r *repository) TouchAccessToken(ctx context.Context, id uint, usedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&PersonalAccessToken{}).Where("id = ?", id).Update("last_used_at", usedAt)
+	if result.Error != nil {
+		r.logger.Errorw("failed to touch access token", "id", id, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) DeleteAccessToken(ctx context.Context, userID uint, id uint) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&PersonalAccessToken{})
+	if result.Error != nil {
+		r.logger.Errorw("failed to delete access token", "id", id, "error", result.Error)
+
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
 	return nil
 }
@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+	"todo-app/internal/users"
+	"todo-app/pkg/locale"
+)
+
+// revocationCacheSize : the tokens and users whose revocation state is kept in memory,
+// the others are looked up in the database
+const revocationCacheSize = 10000
+
+// revocationRecheckInterval : how long a token that was not revoked, and the time the
+// tokens of a user are invalid before, are cached. Revocations handled by other instances
+// take effect within it.
+const revocationRecheckInterval = 5 * time.Second
+
+// CheckRevocation : rejects an access token that was revoked, of a session that was
+// revoked, or issued before the user changed their password or logged out everywhere.
+// A revocation is final and cached for as long as a token lives, the state that may
+// still change is read again after revocationRecheckInterval. Changes made by this
+// instance update the cache at once.
+func (s *service) CheckRevocation(ctx context.Context, claims *JWTClaims) error {
+	for _, id := range []string{claims.ID, claims.SessionID} {
+		if id == "" {
+			continue
+		}
+		revoked, err := s.isTokenRevoked(ctx, id)
+		if err != nil {
+			return errors.New(locale.ErrorInternalServer)
+		}
+		if revoked {
+			return errors.New(locale.ErrorInvalidToken)
+		}
+	}
+
+	invalidBefore, err := s.tokensInvalidBefore(ctx, claims.UserID)
+	if err != nil {
+		return err
+	}
+	// iat has a precision of seconds, tokens issued in the second of the change pass
+	if claims.IssuedAt == nil || claims.IssuedAt.Time.Before(invalidBefore.Truncate(time.Second)) {
+		return errors.New(locale.ErrorInvalidToken)
+	}
+
+	return nil
+}
+
+// isTokenRevoked : whether the jti of a token, or the sid of its session, was revoked
+func (s *service) isTokenRevoked(ctx context.Context, id string) (bool, error) {
+	if revoked, ok := s.revokedTokens.Get(id); ok {
+		return revoked, nil
+	}
+
+	revoked, err := s.authRepository.IsTokenRevoked(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	ttl := s.revocationRecheck
+	if revoked {
+		ttl = s.tokenExpiration
+	}
+	s.revokedTokens.Add(id, revoked, ttl)
+
+	return revoked, nil
+}
+
+// tokensInvalidBefore : the zero time when every unexpired token of the user is valid
+func (s *service) tokensInvalidBefore(ctx context.Context, userID uint) (time.Time, error) {
+	if invalidBefore, ok := s.invalidBefore.Get(userID); ok {
+		return invalidBefore, nil
+	}
+
+	user, err := s.userRepository.GetById(ctx, userID)
+	if err != nil {
+		return time.Time{}, errors.New(locale.ErrorInvalidToken)
+	}
+
+	var invalidBefore time.Time
+	if user.TokensInvalidBefore != nil {
+		invalidBefore = *user.TokensInvalidBefore
+	}
+	s.invalidBefore.Add(userID, invalidBefore, s.revocationRecheck)
+
+	return invalidBefore, nil
+}
+
+// revokeToken : the access token is rejected until it expires
+func (s *service) revokeToken(ctx context.Context, claims *JWTClaims) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+
+	token := RevokedToken{JTI: claims.ID, UserID: claims.UserID, ExpiresAt: claims.ExpiresAt.Time}
+	if err := s.authRepository.RevokeToken(ctx, &token); err != nil {
+		return errors.New(locale.ErrorInternalServer)
+	}
+	s.revokedTokens.Add(claims.ID, true, time.Until(token.ExpiresAt))
+
+	return nil
+}
+
+// revokeSessionTokens : the access tokens of the session are rejected until the last one
+// issued expires, the session's refresh tokens have to be revoked so that no new ones are
+func (s *service) revokeSessionTokens(ctx context.Context, userID uint, sessionID string) error {
+	token := RevokedToken{JTI: sessionID, UserID: userID, ExpiresAt: time.Now().Add(s.tokenExpiration)}
+	if err := s.authRepository.RevokeToken(ctx, &token); err != nil {
+		return errors.New(locale.ErrorInternalServer)
+	}
+	s.revokedTokens.Add(sessionID, true, s.tokenExpiration)
+
+	return nil
+}
+
+// invalidateTokens : rejects every access token of the user issued until now
+func (s *service) invalidateTokens(ctx context.Context, userID uint) error {
+	now := time.Now()
+	if err := s.userRepository.Update(ctx, userID, map[string]interface{}{"tokens_invalid_before": now}); err != nil {
+		return errors.New(locale.ErrorInternalServer)
+	}
+	s.invalidBefore.Add(userID, now, s.revocationRecheck)
+
+	return nil
+}
+
+// HandleUserEvent : logs out every session of a user who changed their password. The
+// refresh tokens are revoked, so that no new access token is issued past the timestamp,
+// and the cached state is dropped to be read again with it.
+func (s *service) HandleUserEvent(ctx context.Context, event users.Event) {
+	if event.Type != users.EventPasswordChanged {
+		return
+	}
+
+	s.invalidBefore.Remove(event.User.ID)
+	if err := s.authRepository.RevokeRefreshTokensByUserID(ctx, event.User.ID); err != nil {
+		s.logger.Errorw("failed to revoke refresh tokens after password change", "error", err, "user_id", event.User.ID)
+	}
+}
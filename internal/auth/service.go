@@ -3,57 +3,113 @@ package auth
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 	"todo-app/internal/users"
+	"todo-app/pkg/database"
+	"todo-app/pkg/email"
 	"todo-app/pkg/locale"
+	"todo-app/pkg/lru"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	googleOauth2 "google.golang.org/api/oauth2/v2"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
+// refreshTokenExpiration : how long a refresh token can be exchanged, every refresh
+// issues a new token with a new expiry
+const refreshTokenExpiration = 7 * 24 * time.Hour
+
+// passwordResetExpiration : how long a password reset link can be used
+const passwordResetExpiration = time.Hour
+
 type Service interface {
 	Login(ctx context.Context, req LoginRequest) (LoginResponse, error)
-	Logout(ctx context.Context, token string) error
+	Logout(ctx context.Context, token string, everywhere bool) error
 	ValidateToken(tokenString string) (*JWTClaims, error)
+	JWKS() JWKS
+	CheckRevocation(ctx context.Context, claims *JWTClaims) error
+	HandleUserEvent(ctx context.Context, event users.Event)
 	RefreshToken(ctx context.Context, refreshToken string) (LoginResponse, error)
 	GoogleLogin(ctx context.Context, state string) string
 	GoogleCallback(ctx context.Context, code string) (*LoginResponse, error)
+	ForgotPassword(ctx context.Context, req ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req ResetPasswordRequest) error
+	EnrollTOTP(ctx context.Context, userID uint) (TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID uint, req MFACodeRequest) (RecoveryCodesResponse, error)
+	VerifyMFA(ctx context.Context, req MFAVerifyRequest) (LoginResponse, error)
+	BeginPasskeyRegistration(ctx context.Context, userID uint) (protocol.PublicKeyCredentialCreationOptions, error)
+	FinishPasskeyRegistration(ctx context.Context, userID uint, req PasskeyRegistrationRequest) (WebAuthnCredential, error)
+	BeginPasskeyLogin(ctx context.Context) (protocol.PublicKeyCredentialRequestOptions, error)
+	FinishPasskeyLogin(ctx context.Context, response protocol.CredentialAssertionResponse) (LoginResponse, error)
+	BeginPasskeyMFA(ctx context.Context, req PasskeyMFARequest) (protocol.PublicKeyCredentialRequestOptions, error)
+	FinishPasskeyMFA(ctx context.Context, req PasskeyMFAVerifyRequest) (LoginResponse, error)
+	GetPasskeys(ctx context.Context, userID uint) ([]WebAuthnCredential, error)
+	DeletePasskey(ctx context.Context, userID uint, id uint) error
+	CreateAccessToken(ctx context.Context, userID uint, input AccessTokenInput) (AccessTokenResponse, error)
+	GetAccessTokens(ctx context.Context, userID uint) ([]PersonalAccessToken, error)
+	DeleteAccessToken(ctx context.Context, userID uint, id uint) error
+	ValidateAccessToken(ctx context.Context, token string) (PersonalAccessToken, error)
+	GetSessions(ctx context.Context, userID uint, currentSessionID string) ([]Session, error)
+	RevokeSession(ctx context.Context, userID uint, sessionID string) error
 }
 
 type service struct {
 	logger          *zap.SugaredLogger
 	userRepository  users.Repository
 	authRepository  Repository
+	transactor      database.Transactor
+	emailService    email.Service
 	validator       *validator.Validate
-	jwtSecret       []byte
+	keys            *KeySet
 	tokenExpiration time.Duration
 	googleOauth     *oauth2.Config
+	// async : sends emails in the background, so that the response time does not tell
+	// whether an account exists
+	async       func(f func())
+	mfaMu       sync.Mutex
+	mfaAttempts map[string]mfaAttempts
+	webauthn    *webauthn.WebAuthn
+	// webauthnChallenges : the pending ceremonies by challenge
+	webauthnMu         sync.Mutex
+	webauthnChallenges map[string]webauthnChallenge
+	// revokedTokens : whether each recently seen jti or sid is revoked
+	revokedTokens *lru.Cache[string, bool]
+	// invalidBefore : users.User.TokensInvalidBefore of recently seen users
+	invalidBefore *lru.Cache[uint, time.Time]
+	// revocationRecheck : how long state that may still change is cached
+	revocationRecheck time.Duration
 }
 
 func GetService(
 	logger *zap.SugaredLogger,
 	userRepo users.Repository,
 	authRepo Repository,
+	transactor database.Transactor,
+	emailService email.Service,
+	keys *KeySet,
 	validator *validator.Validate,
 ) Service {
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "test"
-	}
-
 	// Diagnostic logging to ensure Google credentials are loaded
 	googleClientID := os.Getenv("GOOGLE_CLIENT_ID")
 	googleClientSecret := os.Getenv("GOOGLE_CLIENT_SECRET")
@@ -75,14 +131,36 @@ func GetService(
 		Endpoint: google.Endpoint,
 	}
 
+	rpID := os.Getenv("WEBAUTHN_RP_ID")
+	if rpID == "" {
+		rpID = "local.todo.com"
+	}
+	origin := os.Getenv("WEBAUTHN_ORIGIN")
+	if origin == "" {
+		origin = "http://local.todo.com"
+	}
+	relyingParty, err := newRelyingParty(rpID, origin)
+	if err != nil {
+		logger.Fatalw("invalid webauthn configuration", "error", err)
+	}
+
 	return &service{
-		logger:          logger,
-		userRepository:  userRepo,
-		authRepository:  authRepo,
-		validator:       validator,
-		jwtSecret:       []byte(jwtSecret),
-		tokenExpiration: 20 * time.Minute,
-		googleOauth:     googleOauth,
+		logger:             logger,
+		userRepository:     userRepo,
+		authRepository:     authRepo,
+		transactor:         transactor,
+		emailService:       emailService,
+		validator:          validator,
+		keys:               keys,
+		tokenExpiration:    20 * time.Minute,
+		googleOauth:        googleOauth,
+		async:              func(f func()) { go f() },
+		mfaAttempts:        map[string]mfaAttempts{},
+		webauthn:           relyingParty,
+		webauthnChallenges: map[string]webauthnChallenge{},
+		revokedTokens:      lru.New[string, bool](revocationCacheSize),
+		invalidBefore:      lru.New[uint, time.Time](revocationCacheSize),
+		revocationRecheck:  revocationRecheckInterval,
 	}
 }
 
@@ -107,129 +185,232 @@ func (s *service) Login(ctx context.Context, req LoginRequest) (LoginResponse, e
 		return LoginResponse{}, errors.New(locale.ErrorEmailUnverified)
 	}
 
-	expiresAt := time.Now().Add(s.tokenExpiration)
-	claims := JWTClaims{
-		UserID: user.ID,
-		Email:  user.Email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expiresAt),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "todo-app",
-		},
+	return s.completeLogin(ctx, user)
+}
+
+// Logout : ends the session of the token, or every session of the user. Tokens issued
+// before sessions were recorded end every session. The access tokens of the session are
+// revoked, logging out everywhere rejects every access token of the user issued until now.
+func (s *service) Logout(ctx context.Context, token string, everywhere bool) error {
+	claims, err := s.ValidateToken(token)
+	if err != nil {
+		return err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(s.jwtSecret)
+	if err := s.revokeToken(ctx, claims); err != nil {
+		return err
+	}
+
+	if everywhere || claims.SessionID == "" {
+		if everywhere {
+			if err := s.invalidateTokens(ctx, claims.UserID); err != nil {
+				return err
+			}
+		}
+		return s.authRepository.RevokeRefreshTokensByUserID(ctx, claims.UserID)
+	}
+
+	err = s.authRepository.RevokeSession(ctx, claims.UserID, claims.SessionID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	// the other access tokens of the session, issued before it was last refreshed
+	return s.revokeSessionTokens(ctx, claims.UserID, claims.SessionID)
+}
+
+func (s *service) ValidateToken(tokenString string) (*JWTClaims, error) {
+	token, err := s.keys.parse(tokenString, &JWTClaims{})
 	if err != nil {
-		s.logger.Errorw("failed to sign token", "error", err)
-		return LoginResponse{}, errors.New(locale.ErrorInternalServer)
+		return nil, err
+	}
+
+	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
+		// challenge tokens of logins waiting for a code give no access
+		if slices.Contains(claims.Audience, mfaAudience) {
+			return nil, errors.New("invalid token")
+		}
+		return claims, nil
 	}
 
+	return nil, errors.New("invalid token")
+}
+
+// JWKS : the public keys of the tokens, for services that verify them
+func (s *service) JWKS() JWKS {
+	return s.keys.JWKS()
+}
+
+// RefreshToken : exchanges the refresh token for a new one of the same family. A token
+// that was already exchanged being presented again means it leaked, the whole family is
+// revoked so that neither the attacker nor the user can keep refreshing with it.
+func (s *service) RefreshToken(ctx context.Context, refreshToken string) (LoginResponse, error) {
+	var response LoginResponse
+	var reused *RefreshToken
+	err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		tokenRecord, err := s.authRepository.GetRefreshToken(ctx, hashToken(refreshToken))
+		if err != nil {
+			return errors.New(locale.ErrorInvalidToken)
+		}
+
+		if tokenRecord.ReplacedAt != nil {
+			reused = &tokenRecord
+			return errors.New(locale.ErrorInvalidToken)
+		}
+
+		if tokenRecord.IsRevoked || time.Now().After(tokenRecord.ExpiresAt) {
+			return errors.New(locale.ErrorInvalidToken)
+		}
+
+		replaced, err := s.authRepository.ReplaceRefreshToken(ctx, tokenRecord.ID, time.Now())
+		if err != nil {
+			return errors.New(locale.ErrorInternalServer)
+		}
+		if !replaced {
+			// another refresh with the same token won the race
+			reused = &tokenRecord
+			return errors.New(locale.ErrorInvalidToken)
+		}
+
+		user, err := s.userRepository.GetById(ctx, tokenRecord.UserID)
+		if err != nil {
+			return errors.New(locale.ErrorUserNotFound)
+		}
+
+		startedAt := tokenRecord.CreatedAt
+		if tokenRecord.SessionStartedAt != nil {
+			startedAt = *tokenRecord.SessionStartedAt
+		}
+		response, err = s.sessionTokens(ctx, user, tokenRecord.FamilyID, startedAt)
+
+		return err
+	})
+
+	// revoked outside the transaction, which is rolled back
+	if reused != nil {
+		s.revokeFamily(ctx, *reused)
+	}
+	if err != nil {
+		return LoginResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (s *service) revokeFamily(ctx context.Context, tokenRecord RefreshToken) {
+	s.logger.Warnw(
+		"refresh token reused, revoking its family",
+		"security_event", "refresh_token_reuse",
+		"user_id", tokenRecord.UserID,
+		"family_id", tokenRecord.FamilyID,
+	)
+
+	if err := s.authRepository.RevokeRefreshTokenFamily(ctx, tokenRecord.FamilyID); err != nil {
+		s.logger.Errorw("failed to revoke reused refresh token family", "family_id", tokenRecord.FamilyID, "error", err)
+	}
+}
+
+// issueRefreshToken : stores the hash of a new refresh token of the family and returns
+// the token, with the client it is issued to
+func (s *service) issueRefreshToken(ctx context.Context, userID uint, familyID string, startedAt time.Time) (string, error) {
 	refreshToken, err := s.generateRefreshToken()
 	if err != nil {
 		s.logger.Errorw("failed to generate refresh token", "error", err)
-		return LoginResponse{}, errors.New(locale.ErrorInternalServer)
+		return "", errors.New(locale.ErrorInternalServer)
 	}
 
+	client := clientFromContext(ctx)
 	refreshTokenRecord := RefreshToken{
-		UserID:    user.ID,
-		Token:     refreshToken,
-		ExpiresAt: time.Now().Add(7 * 24 * time.Hour), // 7 days
-		IsRevoked: false,
+		UserID:           userID,
+		TokenHash:        hashToken(refreshToken),
+		FamilyID:         familyID,
+		ExpiresAt:        time.Now().Add(refreshTokenExpiration),
+		IsRevoked:        false,
+		SessionStartedAt: &startedAt,
+		UserAgent:        truncate(client.UserAgent, 255),
+		IP:               truncate(client.IP, 45),
 	}
 
 	if err := s.authRepository.SaveRefreshToken(ctx, &refreshTokenRecord); err != nil {
 		s.logger.Errorw("failed to store refresh token", "error", err)
-		return LoginResponse{}, errors.New(locale.ErrorInternalServer)
+		return "", errors.New(locale.ErrorInternalServer)
 	}
 
-	return LoginResponse{
-		Token:     tokenString,
-		Refresh:   refreshToken,
-		ExpiresAt: expiresAt.Unix(),
-		User: UserInfo{
-			ID:        user.ID,
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			Email:     user.Email,
-		},
-	}, nil
+	return refreshToken, nil
 }
 
-func (s *service) Logout(ctx context.Context, token string) error {
-	claims, err := s.ValidateToken(token)
-	if err != nil {
+// ForgotPassword : mails a reset link when an account with the email exists. Nothing
+// tells the caller whether it does.
+func (s *service) ForgotPassword(ctx context.Context, req ForgotPasswordRequest) error {
+	if err := s.validator.Struct(req); err != nil {
 		return err
 	}
 
-	return s.authRepository.RevokeRefreshTokensByUserID(ctx, claims.UserID)
-}
-
-func (s *service) ValidateToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return s.jwtSecret, nil
-	})
+	user, err := s.userRepository.GetByEmail(ctx, strings.ToLower(req.Email))
+	if err != nil {
+		s.logger.Infow("password reset asked for unknown email", "email", req.Email)
+		return nil
+	}
 
+	resetToken, err := s.generateRefreshToken()
 	if err != nil {
-		return nil, err
+		s.logger.Errorw("failed to generate password reset token", "error", err)
+		return errors.New(locale.ErrorInternalServer)
 	}
 
-	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-		return claims, nil
+	expiresAt := time.Now().Add(passwordResetExpiration)
+	updates := map[string]interface{}{
+		"password_reset_token_hash": hashToken(resetToken),
+		"password_reset_expiry":     &expiresAt,
+	}
+	if err := s.userRepository.Update(ctx, user.ID, updates); err != nil {
+		return errors.New(locale.ErrorInternalServer)
 	}
 
-	return nil, errors.New("invalid token")
+	s.async(func() {
+		if err := s.emailService.SendPasswordResetEmail(user.Email, user.FirstName, resetToken); err != nil {
+			s.logger.Errorw("failed to send password reset email", "error", err, "user_id", user.ID)
+		}
+	})
+
+	return nil
 }
 
-func (s *service) RefreshToken(ctx context.Context, refreshToken string) (LoginResponse, error) {
-	// Validate refresh token
-	tokenRecord, err := s.authRepository.GetRefreshToken(ctx, refreshToken)
+// ResetPassword : sets the new password with a reset token, which can be used once.
+// All refresh tokens of the user are revoked and the access tokens issued until now are
+// rejected, so that other sessions have to log in again.
+func (s *service) ResetPassword(ctx context.Context, req ResetPasswordRequest) error {
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+
+	tokenHash := hashToken(req.Token)
+	user, err := s.userRepository.GetByPasswordResetToken(ctx, tokenHash)
 	if err != nil {
-		return LoginResponse{}, errors.New(locale.ErrorInvalidToken)
+		return errors.New(locale.ErrorInvalidResetToken)
 	}
 
-	if tokenRecord.IsRevoked || time.Now().After(tokenRecord.ExpiresAt) {
-		return LoginResponse{}, errors.New(locale.ErrorInvalidToken)
+	if user.PasswordResetExpiry == nil || time.Now().After(*user.PasswordResetExpiry) {
+		return errors.New(locale.ErrorInvalidResetToken)
 	}
 
-	user, err := s.userRepository.GetById(ctx, tokenRecord.UserID)
+	reset, err := s.userRepository.ResetPassword(ctx, user.ID, tokenHash, req.Password)
 	if err != nil {
-		return LoginResponse{}, errors.New(locale.ErrorUserNotFound)
+		return errors.New(locale.ErrorInternalServer)
 	}
-
-	// Generate new JWT token
-	expiresAt := time.Now().Add(s.tokenExpiration)
-	claims := JWTClaims{
-		UserID: user.ID,
-		Email:  user.Email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expiresAt),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "todo-app",
-		},
+	if !reset {
+		return errors.New(locale.ErrorInvalidResetToken)
 	}
+	// the repository moved TokensInvalidBefore
+	s.invalidBefore.Remove(user.ID)
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(s.jwtSecret)
-	if err != nil {
-		s.logger.Errorw("failed to sign token", "error", err)
-		return LoginResponse{}, errors.New(locale.ErrorInternalServer)
+	if err := s.authRepository.RevokeRefreshTokensByUserID(ctx, user.ID); err != nil {
+		s.logger.Errorw("failed to revoke refresh tokens after password reset", "error", err, "user_id", user.ID)
+		return errors.New(locale.ErrorInternalServer)
 	}
 
-	return LoginResponse{
-		Token:     tokenString,
-		Refresh:   refreshToken,
-		ExpiresAt: expiresAt.Unix(),
-		User: UserInfo{
-			ID:        user.ID,
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			Email:     user.Email,
-		},
-	}, nil
+	s.logger.Infow("password reset", "user_id", user.ID)
+	return nil
 }
 
 func (s *service) generateRefreshToken() (string, error) {
@@ -240,6 +421,12 @@ func (s *service) generateRefreshToken() (string, error) {
 	return base64.URLEncoding.EncodeToString(bytes), nil
 }
 
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
 func (s *service) GoogleLogin(ctx context.Context, state string) string {
 	// Generate a random state string for CSRF protection
 	b := make([]byte, 16)
@@ -291,44 +478,60 @@ func (s *service) GoogleCallback(ctx context.Context, code string) (*LoginRespon
 		user = newUser // Assign the newly created user
 	}
 
-	// Generate JWT for the user
+	loginResponse, err := s.completeLogin(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &loginResponse, nil
+}
+
+// completeLogin : the response once the user proved who they are, a challenge when
+// the user has MFA on
+func (s *service) completeLogin(ctx context.Context, user users.User) (LoginResponse, error) {
+	methods, err := s.mfaMethods(ctx, user.ID)
+	if err != nil {
+		return LoginResponse{}, err
+	}
+	if len(methods) > 0 {
+		return s.mfaChallenge(user, methods)
+	}
+
+	return s.issueTokens(ctx, user)
+}
+
+// issueTokens : an access token and a refresh token of a new session
+func (s *service) issueTokens(ctx context.Context, user users.User) (LoginResponse, error) {
+	return s.sessionTokens(ctx, user, uuid.New().String(), time.Now())
+}
+
+// sessionTokens : an access token of the session and its next refresh token
+func (s *service) sessionTokens(ctx context.Context, user users.User, sessionID string, startedAt time.Time) (LoginResponse, error) {
 	expiresAt := time.Now().Add(s.tokenExpiration)
 	claims := JWTClaims{
-		UserID: user.ID,
-		Email:  user.Email,
+		UserID:    user.ID,
+		Email:     user.Email,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "todo-app",
 		},
 	}
 
-	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := jwtToken.SignedString(s.jwtSecret)
+	tokenString, err := s.keys.sign(claims)
 	if err != nil {
 		s.logger.Errorw("failed to sign token", "error", err)
-		return nil, errors.New(locale.ErrorInternalServer)
+		return LoginResponse{}, errors.New(locale.ErrorInternalServer)
 	}
 
-	refreshToken, err := s.generateRefreshToken()
+	refreshToken, err := s.issueRefreshToken(ctx, user.ID, sessionID, startedAt)
 	if err != nil {
-		s.logger.Errorw("failed to generate refresh token", "error", err)
-		return nil, errors.New(locale.ErrorInternalServer)
-	}
-
-	refreshTokenRecord := RefreshToken{
-		UserID:    user.ID,
-		Token:     refreshToken,
-		ExpiresAt: time.Now().Add(7 * 24 * time.Hour), // 7 days
-		IsRevoked: false,
-	}
-
-	if err := s.authRepository.SaveRefreshToken(ctx, &refreshTokenRecord); err != nil {
-		s.logger.Errorw("failed to store refresh token", "error", err)
-		return nil, errors.New(locale.ErrorInternalServer)
+		return LoginResponse{}, err
 	}
 
-	return &LoginResponse{
+	return LoginResponse{
 		Token:     tokenString,
 		Refresh:   refreshToken,
 		ExpiresAt: expiresAt.Unix(),
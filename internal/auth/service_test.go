@@ -1,18 +1,41 @@
 package auth
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
 	"errors"
+	"fmt"
 	"github.com/go-playground/validator/v10"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 	"todo-app/internal/users"
+	"todo-app/pkg/database"
+	"todo-app/pkg/email"
 	"todo-app/pkg/locale"
 )
 
@@ -22,7 +45,7 @@ func TestService_Login(t *testing.T) {
 	mockUserRepo := users.NewMockRepository(ctrl)
 	v := validator.New()
 	logger := zap.NewNop().Sugar()
-	service := GetService(logger, mockUserRepo, mockAuthRepo, v)
+	service := GetService(logger, mockUserRepo, mockAuthRepo, database.NewMockTransactor(ctrl), email.NewMockService(ctrl), testKeys(t), v)
 	ctx := context.Background()
 
 	password := "test"
@@ -84,7 +107,8 @@ func TestService_Logout(t *testing.T) {
 	mockUserRepo := users.NewMockRepository(ctrl)
 	v := validator.New()
 	logger := zap.NewNop().Sugar()
-	service := GetService(logger, mockUserRepo, mockAuthRepo, v)
+	keys := testKeys(t)
+	service := GetService(logger, mockUserRepo, mockAuthRepo, database.NewMockTransactor(ctrl), email.NewMockService(ctrl), keys, v)
 	ctx := context.Background()
 
 	password := "test"
@@ -110,8 +134,7 @@ func TestService_Logout(t *testing.T) {
 			},
 		}
 
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		tokenString, err := token.SignedString([]byte("test"))
+		tokenString, err := keys.sign(claims)
 
 		mockAuthRepo.
 			EXPECT().
@@ -119,15 +142,57 @@ func TestService_Logout(t *testing.T) {
 			Return(nil).
 			Times(1)
 
-		err = service.Logout(ctx, tokenString)
+		err = service.Logout(ctx, tokenString, false)
 
 		assert.NoError(t, err)
 
 		ctrl.Finish()
 	})
 
+	t.Run("logout of the session", func(t *testing.T) {
+		claims := JWTClaims{
+			UserID:    user.ID,
+			Email:     user.Email,
+			SessionID: "session",
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+				IssuedAt:  jwt.NewNumericDate(time.Now()),
+				Issuer:    "todo-app",
+			},
+		}
+		tokenString, err := keys.sign(claims)
+		assert.NoError(t, err)
+
+		mockAuthRepo.
+			EXPECT().
+			RevokeSession(ctx, user.ID, "session").
+			Return(nil).
+			Times(1)
+		mockAuthRepo.
+			EXPECT().
+			RevokeToken(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, token *RevokedToken) error {
+				assert.Equal(t, "session", token.JTI)
+				return nil
+			}).
+			Times(1)
+
+		assert.NoError(t, service.Logout(ctx, tokenString, false))
+
+		mockUserRepo.EXPECT().Update(ctx, user.ID, gomock.Any()).Return(nil).Times(1)
+		mockAuthRepo.
+			EXPECT().
+			RevokeRefreshTokensByUserID(ctx, user.ID).
+			Return(nil).
+			Times(1)
+
+		assert.NoError(t, service.Logout(ctx, tokenString, true))
+
+		ctrl.Finish()
+	})
+
 	t.Run("failed logout", func(t *testing.T) {
-		err := service.Logout(ctx, "invalid_token")
+		err := service.Logout(ctx, "invalid_token", false)
 
 		assert.Error(t, err)
 
@@ -139,15 +204,26 @@ func TestService_RefreshToken(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockAuthRepo := NewMockRepository(ctrl)
 	mockUserRepo := users.NewMockRepository(ctrl)
+	mockTransactor := database.NewMockTransactor(ctrl)
 	v := validator.New()
 	logger := zap.NewNop().Sugar()
-	service := GetService(logger, mockUserRepo, mockAuthRepo, v)
+	service := GetService(logger, mockUserRepo, mockAuthRepo, mockTransactor, email.NewMockService(ctrl), testKeys(t), v)
 	ctx := context.Background()
 
+	mockTransactor.
+		EXPECT().
+		WithTransaction(ctx, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		}).
+		AnyTimes()
+
 	t.Run("successful refresh token", func(t *testing.T) {
 		token := "valid_token"
 		tokenRecord := RefreshToken{
+			Model:     gorm.Model{ID: 3},
 			UserID:    1,
+			FamilyID:  "family",
 			IsRevoked: false,
 			ExpiresAt: time.Now().Add(time.Hour * 24),
 		}
@@ -163,22 +239,42 @@ func TestService_RefreshToken(t *testing.T) {
 
 		mockAuthRepo.
 			EXPECT().
-			GetRefreshToken(ctx, token).
+			GetRefreshToken(ctx, hashToken(token)).
 			Return(tokenRecord, nil).
 			Times(1)
 
+		mockAuthRepo.
+			EXPECT().
+			ReplaceRefreshToken(ctx, tokenRecord.ID, gomock.Any()).
+			Return(true, nil).
+			Times(1)
+
 		mockUserRepo.
 			EXPECT().
 			GetById(ctx, user.ID).
 			Return(user, nil).
 			Times(1)
 
+		var saved RefreshToken
+		mockAuthRepo.
+			EXPECT().
+			SaveRefreshToken(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, refreshToken *RefreshToken) error {
+				saved = *refreshToken
+				return nil
+			}).
+			Times(1)
+
 		response, err := service.RefreshToken(ctx, token)
 
 		assert.NoError(t, err)
 		assert.Equal(t, user.FirstName, response.User.FirstName)
 		assert.Equal(t, user.LastName, response.User.LastName)
 		assert.Equal(t, user.Email, response.User.Email)
+		assert.NotEqual(t, token, response.Refresh)
+		assert.Equal(t, hashToken(response.Refresh), saved.TokenHash)
+		assert.Equal(t, tokenRecord.FamilyID, saved.FamilyID)
+		assert.Equal(t, user.ID, saved.UserID)
 
 		ctrl.Finish()
 	})
@@ -193,7 +289,7 @@ func TestService_RefreshToken(t *testing.T) {
 
 		mockAuthRepo.
 			EXPECT().
-			GetRefreshToken(ctx, token).
+			GetRefreshToken(ctx, hashToken(token)).
 			Return(tokenRecord, nil).
 			Times(1)
 
@@ -216,10 +312,78 @@ func TestService_RefreshToken(t *testing.T) {
 
 		mockAuthRepo.
 			EXPECT().
-			GetRefreshToken(ctx, token).
+			GetRefreshToken(ctx, hashToken(token)).
+			Return(tokenRecord, nil).
+			Times(1)
+
+		response, err := service.RefreshToken(ctx, token)
+
+		assert.Error(t, err)
+		assert.Equal(t, response, LoginResponse{})
+		assert.Equal(t, err, errors.New(locale.ErrorInvalidToken))
+
+		ctrl.Finish()
+	})
+
+	t.Run("reused refresh token revokes its family", func(t *testing.T) {
+		token := "replaced_token"
+		replacedAt := time.Now().Add(-time.Minute)
+		tokenRecord := RefreshToken{
+			UserID:     1,
+			FamilyID:   "family",
+			IsRevoked:  true,
+			ExpiresAt:  time.Now().Add(time.Hour * 24),
+			ReplacedAt: &replacedAt,
+		}
+
+		mockAuthRepo.
+			EXPECT().
+			GetRefreshToken(ctx, hashToken(token)).
+			Return(tokenRecord, nil).
+			Times(1)
+
+		mockAuthRepo.
+			EXPECT().
+			RevokeRefreshTokenFamily(ctx, "family").
+			Return(nil).
+			Times(1)
+
+		response, err := service.RefreshToken(ctx, token)
+
+		assert.Error(t, err)
+		assert.Equal(t, response, LoginResponse{})
+		assert.Equal(t, err, errors.New(locale.ErrorInvalidToken))
+
+		ctrl.Finish()
+	})
+
+	t.Run("token replaced by a concurrent refresh", func(t *testing.T) {
+		token := "raced_token"
+		tokenRecord := RefreshToken{
+			Model:     gorm.Model{ID: 4},
+			UserID:    1,
+			FamilyID:  "other family",
+			ExpiresAt: time.Now().Add(time.Hour * 24),
+		}
+
+		mockAuthRepo.
+			EXPECT().
+			GetRefreshToken(ctx, hashToken(token)).
 			Return(tokenRecord, nil).
 			Times(1)
 
+		mockAuthRepo.
+			EXPECT().
+			ReplaceRefreshToken(ctx, tokenRecord.ID, gomock.Any()).
+			Return(false, nil).
+			Times(1)
+
+		mockAuthRepo.
+			EXPECT().
+			RevokeRefreshTokenFamily(ctx, "other family").
+			Return(nil).
+			Times(1)
+
 		response, err := service.RefreshToken(ctx, token)
 
 		assert.Error(t, err)
@@ -229,3 +393,1128 @@ func TestService_RefreshToken(t *testing.T) {
 		ctrl.Finish()
 	})
 }
+
+func TestService_ForgotPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAuthRepo := NewMockRepository(ctrl)
+	mockUserRepo := users.NewMockRepository(ctrl)
+	mockEmailService := email.NewMockService(ctrl)
+	v := validator.New()
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockUserRepo, mockAuthRepo, database.NewMockTransactor(ctrl), mockEmailService, testKeys(t), v).(*service)
+	service.async = func(f func()) { f() }
+	ctx := context.Background()
+
+	t.Run("mails a reset token", func(t *testing.T) {
+		user := users.User{Model: gorm.Model{ID: 1}, Email: "test@test.com", FirstName: "test"}
+
+		mockUserRepo.
+			EXPECT().
+			GetByEmail(ctx, user.Email).
+			Return(user, nil).
+			Times(1)
+
+		var tokenHash string
+		mockUserRepo.
+			EXPECT().
+			Update(ctx, user.ID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uint, updates map[string]interface{}) error {
+				tokenHash = updates["password_reset_token_hash"].(string)
+				expiry := updates["password_reset_expiry"].(*time.Time)
+				assert.WithinDuration(t, time.Now().Add(time.Hour), *expiry, time.Minute)
+				return nil
+			}).
+			Times(1)
+
+		mockEmailService.
+			EXPECT().
+			SendPasswordResetEmail(user.Email, user.FirstName, gomock.Any()).
+			DoAndReturn(func(_ string, _ string, resetToken string) error {
+				assert.Equal(t, hashToken(resetToken), tokenHash)
+				return nil
+			}).
+			Times(1)
+
+		err := service.ForgotPassword(ctx, ForgotPasswordRequest{Email: "Test@test.com"})
+		assert.NoError(t, err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("unknown email", func(t *testing.T) {
+		mockUserRepo.
+			EXPECT().
+			GetByEmail(ctx, "nobody@test.com").
+			Return(users.User{}, gorm.ErrRecordNotFound).
+			Times(1)
+
+		err := service.ForgotPassword(ctx, ForgotPasswordRequest{Email: "nobody@test.com"})
+		assert.NoError(t, err)
+
+		ctrl.Finish()
+	})
+}
+
+func TestService_ResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAuthRepo := NewMockRepository(ctrl)
+	mockUserRepo := users.NewMockRepository(ctrl)
+	v := validator.New()
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockUserRepo, mockAuthRepo, database.NewMockTransactor(ctrl), email.NewMockService(ctrl), testKeys(t), v)
+	ctx := context.Background()
+
+	token := "reset_token"
+	expiry := time.Now().Add(time.Minute * 30)
+	user := users.User{Model: gorm.Model{ID: 1}, PasswordResetTokenHash: hashToken(token), PasswordResetExpiry: &expiry}
+
+	t.Run("successful reset", func(t *testing.T) {
+		mockUserRepo.
+			EXPECT().
+			GetByPasswordResetToken(ctx, hashToken(token)).
+			Return(user, nil).
+			Times(1)
+
+		mockUserRepo.
+			EXPECT().
+			ResetPassword(ctx, user.ID, hashToken(token), "new password").
+			Return(true, nil).
+			Times(1)
+
+		mockAuthRepo.
+			EXPECT().
+			RevokeRefreshTokensByUserID(ctx, user.ID).
+			Return(nil).
+			Times(1)
+
+		err := service.ResetPassword(ctx, ResetPasswordRequest{Token: token, Password: "new password"})
+		assert.NoError(t, err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("token used at the same time", func(t *testing.T) {
+		mockUserRepo.
+			EXPECT().
+			GetByPasswordResetToken(ctx, hashToken(token)).
+			Return(user, nil).
+			Times(1)
+
+		mockUserRepo.
+			EXPECT().
+			ResetPassword(ctx, user.ID, hashToken(token), "new password").
+			Return(false, nil).
+			Times(1)
+
+		err := service.ResetPassword(ctx, ResetPasswordRequest{Token: token, Password: "new password"})
+		assert.Equal(t, errors.New(locale.ErrorInvalidResetToken), err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		expired := time.Now().Add(-time.Minute)
+		expiredUser := user
+		expiredUser.PasswordResetExpiry = &expired
+
+		mockUserRepo.
+			EXPECT().
+			GetByPasswordResetToken(ctx, hashToken(token)).
+			Return(expiredUser, nil).
+			Times(1)
+
+		err := service.ResetPassword(ctx, ResetPasswordRequest{Token: token, Password: "new password"})
+		assert.Equal(t, errors.New(locale.ErrorInvalidResetToken), err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("short password", func(t *testing.T) {
+		err := service.ResetPassword(ctx, ResetPasswordRequest{Token: token, Password: "short"})
+		assert.Error(t, err)
+
+		ctrl.Finish()
+	})
+}
+
+func TestTOTPCode(t *testing.T) {
+	// the SHA1 vector of RFC 6238, cut to six digits
+	secret := base32NoPadding.EncodeToString([]byte("12345678901234567890"))
+
+	code, err := totpCode(secret, totpStep(time.Unix(59, 0)))
+	assert.NoError(t, err)
+	assert.Equal(t, "287082", code)
+
+	step, ok := matchTOTP(secret, "287082", time.Unix(59+totpPeriod, 0))
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), step)
+
+	_, ok = matchTOTP(secret, "287082", time.Unix(59+3*totpPeriod, 0))
+	assert.False(t, ok)
+}
+
+func TestService_MFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAuthRepo := NewMockRepository(ctrl)
+	mockUserRepo := users.NewMockRepository(ctrl)
+	mockTransactor := database.NewMockTransactor(ctrl)
+	v := validator.New()
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockUserRepo, mockAuthRepo, mockTransactor, email.NewMockService(ctrl), testKeys(t), v).(*service)
+	ctx := context.Background()
+
+	password := "test"
+	userPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	user := users.User{
+		Model:           gorm.Model{ID: 1},
+		Email:           "test@test.com",
+		Password:        string(userPassword),
+		IsEmailVerified: true,
+	}
+	secret, _ := generateTOTPSecret()
+	confirmedAt := time.Now().Add(-time.Hour)
+	credential := TOTPCredential{Model: gorm.Model{ID: 3}, UserID: user.ID, Secret: secret, ConfirmedAt: &confirmedAt}
+
+	login := func(t *testing.T) string {
+		mockUserRepo.EXPECT().GetByEmail(ctx, user.Email).Return(user, nil).Times(1)
+		mockAuthRepo.EXPECT().GetTOTPCredential(ctx, user.ID).Return(credential, nil).Times(1)
+		mockAuthRepo.EXPECT().GetWebAuthnCredentials(ctx, user.ID).Return(nil, nil).Times(1)
+
+		response, err := service.Login(ctx, LoginRequest{Email: user.Email, Password: password})
+		assert.NoError(t, err)
+		assert.True(t, response.MFARequired)
+		assert.Equal(t, []string{mfaMethodTOTP}, response.MFAMethods)
+		assert.Empty(t, response.Token)
+		assert.Empty(t, response.Refresh)
+
+		return response.MFAToken
+	}
+
+	t.Run("challenge token is not an access token", func(t *testing.T) {
+		mfaToken := login(t)
+
+		_, err := service.ValidateToken(mfaToken)
+		assert.Error(t, err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("verify with totp code", func(t *testing.T) {
+		mfaToken := login(t)
+		code, _ := totpCode(secret, totpStep(time.Now()))
+
+		mockAuthRepo.EXPECT().GetTOTPCredential(ctx, user.ID).Return(credential, nil).Times(1)
+		mockAuthRepo.EXPECT().UseTOTPStep(ctx, credential.ID, totpStep(time.Now())).Return(true, nil).Times(1)
+		mockUserRepo.EXPECT().GetById(ctx, user.ID).Return(user, nil).Times(1)
+		mockAuthRepo.EXPECT().SaveRefreshToken(ctx, gomock.Any()).Return(nil).Times(1)
+
+		response, err := service.VerifyMFA(ctx, MFAVerifyRequest{MFAToken: mfaToken, Code: code})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response.Token)
+		assert.NotEmpty(t, response.Refresh)
+
+		// the challenge is exchanged once
+		_, err = service.VerifyMFA(ctx, MFAVerifyRequest{MFAToken: mfaToken, Code: code})
+		assert.Equal(t, errors.New(locale.ErrorTooManyMFAAttempts), err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("replayed totp code", func(t *testing.T) {
+		mfaToken := login(t)
+		code, _ := totpCode(secret, totpStep(time.Now()))
+
+		mockAuthRepo.EXPECT().GetTOTPCredential(ctx, user.ID).Return(credential, nil).Times(1)
+		mockAuthRepo.EXPECT().UseTOTPStep(ctx, credential.ID, totpStep(time.Now())).Return(false, nil).Times(1)
+
+		_, err := service.VerifyMFA(ctx, MFAVerifyRequest{MFAToken: mfaToken, Code: code})
+		assert.Equal(t, errors.New(locale.ErrorInvalidMFACode), err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("verify with recovery code", func(t *testing.T) {
+		mfaToken := login(t)
+
+		mockAuthRepo.EXPECT().GetTOTPCredential(ctx, user.ID).Return(credential, nil).Times(1)
+		mockAuthRepo.EXPECT().UseRecoveryCode(ctx, user.ID, hashToken("abcde12345"), gomock.Any()).Return(true, nil).Times(1)
+		mockUserRepo.EXPECT().GetById(ctx, user.ID).Return(user, nil).Times(1)
+		mockAuthRepo.EXPECT().SaveRefreshToken(ctx, gomock.Any()).Return(nil).Times(1)
+
+		response, err := service.VerifyMFA(ctx, MFAVerifyRequest{MFAToken: mfaToken, Code: "ABCDE-12345"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response.Token)
+
+		ctrl.Finish()
+	})
+
+	t.Run("too many attempts", func(t *testing.T) {
+		mfaToken := login(t)
+
+		mockAuthRepo.EXPECT().GetTOTPCredential(ctx, user.ID).Return(credential, nil).Times(maxMFAAttempts)
+		mockAuthRepo.EXPECT().UseRecoveryCode(ctx, user.ID, gomock.Any(), gomock.Any()).Return(false, nil).Times(maxMFAAttempts)
+
+		for i := 0; i < maxMFAAttempts; i++ {
+			_, err := service.VerifyMFA(ctx, MFAVerifyRequest{MFAToken: mfaToken, Code: "wrong-code"})
+			assert.Equal(t, errors.New(locale.ErrorInvalidMFACode), err)
+		}
+
+		_, err := service.VerifyMFA(ctx, MFAVerifyRequest{MFAToken: mfaToken, Code: "wrong-code"})
+		assert.Equal(t, errors.New(locale.ErrorTooManyMFAAttempts), err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("access token is not a challenge", func(t *testing.T) {
+		mockAuthRepo.EXPECT().SaveRefreshToken(ctx, gomock.Any()).Return(nil).Times(1)
+		tokens, err := service.issueTokens(ctx, user)
+		assert.NoError(t, err)
+
+		_, err = service.VerifyMFA(ctx, MFAVerifyRequest{MFAToken: tokens.Token, Code: "123456"})
+		assert.Equal(t, errors.New(locale.ErrorInvalidToken), err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("confirm enrollment", func(t *testing.T) {
+		enrolled := TOTPCredential{Model: gorm.Model{ID: 3}, UserID: user.ID, Secret: secret}
+		code, _ := totpCode(secret, totpStep(time.Now()))
+
+		mockAuthRepo.EXPECT().GetTOTPCredential(ctx, user.ID).Return(enrolled, nil).Times(1)
+		mockTransactor.
+			EXPECT().
+			WithTransaction(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+				return fn(ctx)
+			}).
+			Times(1)
+		mockAuthRepo.
+			EXPECT().
+			SaveTOTPCredential(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, saved *TOTPCredential) error {
+				assert.NotNil(t, saved.ConfirmedAt)
+				assert.Equal(t, totpStep(time.Now()), saved.LastStep)
+				return nil
+			}).
+			Times(1)
+		var stored []RecoveryCode
+		mockAuthRepo.
+			EXPECT().
+			ReplaceRecoveryCodes(ctx, user.ID, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, userID uint, codes []RecoveryCode) error {
+				stored = codes
+				return nil
+			}).
+			Times(1)
+
+		response, err := service.ConfirmTOTP(ctx, user.ID, MFACodeRequest{Code: code})
+		assert.NoError(t, err)
+		assert.Len(t, response.RecoveryCodes, recoveryCodeCount)
+		assert.Len(t, stored, recoveryCodeCount)
+		assert.Equal(t, hashToken(normalizeRecoveryCode(response.RecoveryCodes[0])), stored[0].CodeHash)
+
+		ctrl.Finish()
+	})
+
+	t.Run("confirm with wrong code", func(t *testing.T) {
+		enrolled := TOTPCredential{Model: gorm.Model{ID: 3}, UserID: user.ID, Secret: secret}
+		code, _ := totpCode(secret, totpStep(time.Now())+10)
+
+		mockAuthRepo.EXPECT().GetTOTPCredential(ctx, user.ID).Return(enrolled, nil).Times(1)
+
+		_, err := service.ConfirmTOTP(ctx, user.ID, MFACodeRequest{Code: code})
+		assert.Equal(t, errors.New(locale.ErrorInvalidMFACode), err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("enroll returns the uri as a qr code", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetById(ctx, user.ID).Return(user, nil).Times(1)
+		mockAuthRepo.EXPECT().GetTOTPCredential(ctx, user.ID).Return(TOTPCredential{}, gorm.ErrRecordNotFound).Times(1)
+		mockAuthRepo.EXPECT().SaveTOTPCredential(ctx, gomock.Any()).Return(nil).Times(1)
+
+		enrollment, err := service.EnrollTOTP(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(enrollment.URI, "otpauth://totp/"))
+
+		data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(enrollment.QRCode, "data:image/png;base64,"))
+		assert.NoError(t, err)
+		config, err := png.DecodeConfig(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, totpQRCodeSize, config.Width)
+
+		ctrl.Finish()
+	})
+
+	t.Run("enroll when already enabled", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetById(ctx, user.ID).Return(user, nil).Times(1)
+		mockAuthRepo.EXPECT().GetTOTPCredential(ctx, user.ID).Return(credential, nil).Times(1)
+
+		_, err := service.EnrollTOTP(ctx, user.ID)
+		assert.Equal(t, errors.New(locale.ErrorMFAAlreadyEnabled), err)
+
+		ctrl.Finish()
+	})
+}
+
+// passkeyClientData : the client data the browser signs for the challenge
+func passkeyClientData(service *service, ceremony string, challenge protocol.URLEncodedBase64) ([]byte, [32]byte) {
+	clientDataJSON, _ := json.Marshal(map[string]string{"type": ceremony, "challenge": challenge.String(), "origin": service.webauthn.Config.RPOrigins[0]})
+	return clientDataJSON, sha256.Sum256(clientDataJSON)
+}
+
+// passkeyAssertion : signs an assertion for the challenge with an ES256 key, the way an
+// authenticator does
+func passkeyAssertion(t *testing.T, service *service, key *ecdsa.PrivateKey, credentialID []byte, userID uint, challenge protocol.URLEncodedBase64, signCount uint32) protocol.CredentialAssertionResponse {
+	rpIDHash := sha256.Sum256([]byte(service.webauthn.Config.RPID))
+	// user present and verified
+	authData := binary.BigEndian.AppendUint32(append(rpIDHash[:], 0x05), signCount)
+	clientDataJSON, clientDataHash := passkeyClientData(service, "webauthn.get", challenge)
+	digest := sha256.Sum256(append(authData, clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	assert.NoError(t, err)
+
+	var response protocol.CredentialAssertionResponse
+	response.ID = base64.RawURLEncoding.EncodeToString(credentialID)
+	response.RawID = credentialID
+	response.Type = "public-key"
+	response.AssertionResponse.ClientDataJSON = clientDataJSON
+	response.AssertionResponse.AuthenticatorData = authData
+	response.AssertionResponse.Signature = signature
+	response.AssertionResponse.UserHandle = userHandle(userID)
+
+	return response
+}
+
+// passkeyAttestation : creates the ES256 passkey key for the challenge. signer signs a
+// "packed" self attestation, without one the attestation is "none".
+func passkeyAttestation(t *testing.T, service *service, key *ecdsa.PrivateKey, signer *ecdsa.PrivateKey, credentialID []byte, challenge protocol.URLEncodedBase64) protocol.CredentialCreationResponse {
+	rpIDHash := sha256.Sum256([]byte(service.webauthn.Config.RPID))
+	// user present and verified, with the attested credential data
+	authData := binary.BigEndian.AppendUint32(append(rpIDHash[:], 0x45), 0)
+	authData = append(authData, make([]byte, 16)...)
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(credentialID)))
+	authData = append(append(authData, credentialID...), coseKey(&key.PublicKey)...)
+	clientDataJSON, clientDataHash := passkeyClientData(service, "webauthn.create", challenge)
+
+	attestation := map[string]any{"fmt": "none", "attStmt": map[string]any{}, "authData": authData}
+	if signer != nil {
+		digest := sha256.Sum256(append(authData, clientDataHash[:]...))
+		signature, err := ecdsa.SignASN1(rand.Reader, signer, digest[:])
+		assert.NoError(t, err)
+		attestation["fmt"] = "packed"
+		attestation["attStmt"] = map[string]any{"alg": -7, "sig": signature}
+	}
+	attestationObject, err := webauthncbor.Marshal(attestation)
+	assert.NoError(t, err)
+
+	var response protocol.CredentialCreationResponse
+	response.ID = base64.RawURLEncoding.EncodeToString(credentialID)
+	response.RawID = credentialID
+	response.Type = "public-key"
+	response.AttestationResponse.ClientDataJSON = clientDataJSON
+	response.AttestationResponse.AttestationObject = attestationObject
+
+	return response
+}
+
+// coseKey : the COSE_Key of an ES256 public key
+func coseKey(key *ecdsa.PublicKey) []byte {
+	out := []byte{0xa5, 0x01, 0x02, 0x03, 0x26, 0x20, 0x01, 0x21, 0x58, 0x20}
+	out = append(out, key.X.FillBytes(make([]byte, 32))...)
+	out = append(out, 0x22, 0x58, 0x20)
+	return append(out, key.Y.FillBytes(make([]byte, 32))...)
+}
+
+func TestService_Passkey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAuthRepo := NewMockRepository(ctrl)
+	mockUserRepo := users.NewMockRepository(ctrl)
+	v := validator.New()
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockUserRepo, mockAuthRepo, database.NewMockTransactor(ctrl), email.NewMockService(ctrl), testKeys(t), v).(*service)
+	ctx := context.Background()
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	user := users.User{Model: gorm.Model{ID: 1}, Email: "test@test.com", IsEmailVerified: true}
+	passkey := WebAuthnCredential{ID: 7, UserID: user.ID, CredentialID: []byte("credential"), PublicKey: coseKey(&key.PublicKey), SignCount: 4}
+
+	t.Run("passwordless login", func(t *testing.T) {
+		options, err := service.BeginPasskeyLogin(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, protocol.VerificationRequired, options.UserVerification)
+
+		mockAuthRepo.EXPECT().GetWebAuthnCredential(ctx, passkey.CredentialID).Return(passkey, nil).Times(1)
+		mockAuthRepo.EXPECT().UseWebAuthnCredential(ctx, passkey.ID, uint32(4), uint32(5), gomock.Any()).Return(true, nil).Times(1)
+		mockUserRepo.EXPECT().GetById(ctx, user.ID).Return(user, nil).Times(1)
+		mockAuthRepo.EXPECT().SaveRefreshToken(ctx, gomock.Any()).Return(nil).Times(1)
+
+		assertion := passkeyAssertion(t, service, key, passkey.CredentialID, user.ID, options.Challenge, 5)
+		response, err := service.FinishPasskeyLogin(ctx, assertion)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response.Token)
+		assert.False(t, response.MFARequired)
+
+		// the challenge is answered once
+		_, err = service.FinishPasskeyLogin(ctx, assertion)
+		assert.Equal(t, errors.New(locale.ErrorInvalidPasskey), err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("counter did not move on", func(t *testing.T) {
+		options, _ := service.BeginPasskeyLogin(ctx)
+
+		mockAuthRepo.EXPECT().GetWebAuthnCredential(ctx, passkey.CredentialID).Return(passkey, nil).Times(1)
+
+		_, err := service.FinishPasskeyLogin(ctx, passkeyAssertion(t, service, key, passkey.CredentialID, user.ID, options.Challenge, 4))
+		assert.Equal(t, errors.New(locale.ErrorInvalidPasskey), err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("signed by another key", func(t *testing.T) {
+		options, _ := service.BeginPasskeyLogin(ctx)
+
+		mockAuthRepo.EXPECT().GetWebAuthnCredential(ctx, passkey.CredentialID).Return(passkey, nil).Times(1)
+
+		_, err := service.FinishPasskeyLogin(ctx, passkeyAssertion(t, service, otherKey, passkey.CredentialID, user.ID, options.Challenge, 5))
+		assert.Equal(t, errors.New(locale.ErrorInvalidPasskey), err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("second factor", func(t *testing.T) {
+		mockAuthRepo.EXPECT().GetTOTPCredential(ctx, user.ID).Return(TOTPCredential{}, gorm.ErrRecordNotFound).Times(1)
+		mockAuthRepo.EXPECT().GetWebAuthnCredentials(ctx, user.ID).Return([]WebAuthnCredential{passkey}, nil).Times(2)
+		challenge, err := service.completeLogin(ctx, user)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{mfaMethodWebAuthn}, challenge.MFAMethods)
+
+		options, err := service.BeginPasskeyMFA(ctx, PasskeyMFARequest{MFAToken: challenge.MFAToken})
+		assert.NoError(t, err)
+		assert.Equal(t, protocol.URLEncodedBase64(passkey.CredentialID), options.AllowedCredentials[0].CredentialID)
+
+		mockAuthRepo.EXPECT().GetWebAuthnCredential(ctx, passkey.CredentialID).Return(passkey, nil).Times(1)
+		mockAuthRepo.EXPECT().UseWebAuthnCredential(ctx, passkey.ID, uint32(4), uint32(5), gomock.Any()).Return(true, nil).Times(1)
+		mockUserRepo.EXPECT().GetById(ctx, user.ID).Return(user, nil).Times(1)
+		mockAuthRepo.EXPECT().SaveRefreshToken(ctx, gomock.Any()).Return(nil).Times(1)
+
+		response, err := service.FinishPasskeyMFA(ctx, PasskeyMFAVerifyRequest{
+			MFAToken:   challenge.MFAToken,
+			Credential: passkeyAssertion(t, service, key, passkey.CredentialID, user.ID, options.Challenge, 5),
+		})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response.Token)
+
+		ctrl.Finish()
+	})
+
+	t.Run("second factor with the passkey of another user", func(t *testing.T) {
+		otherUser := users.User{Model: gorm.Model{ID: 2}, Email: "other@test.com"}
+		challenge, _ := service.mfaChallenge(otherUser, []string{mfaMethodWebAuthn})
+
+		mockAuthRepo.EXPECT().GetWebAuthnCredentials(ctx, otherUser.ID).Return([]WebAuthnCredential{{ID: 8, UserID: otherUser.ID}}, nil).Times(1)
+		options, err := service.BeginPasskeyMFA(ctx, PasskeyMFARequest{MFAToken: challenge.MFAToken})
+		assert.NoError(t, err)
+
+		mockAuthRepo.EXPECT().GetWebAuthnCredential(ctx, passkey.CredentialID).Return(passkey, nil).Times(1)
+
+		_, err = service.FinishPasskeyMFA(ctx, PasskeyMFAVerifyRequest{
+			MFAToken:   challenge.MFAToken,
+			Credential: passkeyAssertion(t, service, key, passkey.CredentialID, user.ID, options.Challenge, 5),
+		})
+		assert.Equal(t, errors.New(locale.ErrorInvalidPasskey), err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("challenge of another ceremony", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetById(ctx, user.ID).Return(user, nil).Times(1)
+		mockAuthRepo.EXPECT().GetWebAuthnCredentials(ctx, user.ID).Return(nil, nil).Times(1)
+		options, err := service.BeginPasskeyRegistration(ctx, user.ID)
+		assert.NoError(t, err)
+
+		_, err = service.FinishPasskeyLogin(ctx, passkeyAssertion(t, service, key, passkey.CredentialID, user.ID, options.Challenge, 5))
+		assert.Equal(t, errors.New(locale.ErrorInvalidPasskey), err)
+
+		ctrl.Finish()
+	})
+
+	registrations := []struct {
+		name   string
+		signer *ecdsa.PrivateKey
+		err    error
+	}{
+		{"registration without attestation", nil, nil},
+		{"registration with packed self attestation", key, nil},
+		{"registration with packed attestation signed by another key", otherKey, errors.New(locale.ErrorInvalidPasskey)},
+	}
+	for _, test := range registrations {
+		t.Run(test.name, func(t *testing.T) {
+			mockUserRepo.EXPECT().GetById(ctx, user.ID).Return(user, nil).Times(1)
+			mockAuthRepo.EXPECT().GetWebAuthnCredentials(ctx, user.ID).Return([]WebAuthnCredential{passkey}, nil).Times(1)
+			options, err := service.BeginPasskeyRegistration(ctx, user.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, protocol.URLEncodedBase64(passkey.CredentialID), options.CredentialExcludeList[0].CredentialID)
+			assert.Equal(t, protocol.ResidentKeyRequirementRequired, options.AuthenticatorSelection.ResidentKey)
+
+			if test.err == nil {
+				mockAuthRepo.EXPECT().CreateWebAuthnCredential(ctx, gomock.Any()).Return(nil).Times(1)
+			}
+
+			created, err := service.FinishPasskeyRegistration(ctx, user.ID, PasskeyRegistrationRequest{
+				Name:       "laptop",
+				Credential: passkeyAttestation(t, service, key, test.signer, []byte("new credential"), options.Challenge),
+			})
+			assert.Equal(t, test.err, err)
+			if test.err == nil {
+				assert.Equal(t, []byte("new credential"), created.CredentialID)
+				assert.Equal(t, coseKey(&key.PublicKey), created.PublicKey)
+			}
+
+			ctrl.Finish()
+		})
+	}
+}
+
+func TestService_AccessToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAuthRepo := NewMockRepository(ctrl)
+	mockUserRepo := users.NewMockRepository(ctrl)
+	v := validator.New()
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockUserRepo, mockAuthRepo, database.NewMockTransactor(ctrl), email.NewMockService(ctrl), testKeys(t), v)
+	ctx := context.Background()
+
+	t.Run("create stores the hash", func(t *testing.T) {
+		var stored PersonalAccessToken
+		mockAuthRepo.
+			EXPECT().
+			CreateAccessToken(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, token *PersonalAccessToken) error {
+				stored = *token
+				return nil
+			}).
+			Times(1)
+
+		response, err := service.CreateAccessToken(ctx, 1, AccessTokenInput{Name: "ci", Scopes: []string{"todos:read"}, ExpiresInDays: 7})
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(response.Token, AccessTokenPrefix))
+		assert.Equal(t, hashToken(response.Token), stored.TokenHash)
+		assert.WithinDuration(t, time.Now().Add(7*24*time.Hour), stored.ExpiresAt, time.Minute)
+
+		ctrl.Finish()
+	})
+
+	t.Run("unknown scope", func(t *testing.T) {
+		_, err := service.CreateAccessToken(ctx, 1, AccessTokenInput{Name: "ci", Scopes: []string{"user:write"}})
+		assert.Error(t, err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("validate records the last use", func(t *testing.T) {
+		token := PersonalAccessToken{ID: 3, UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}
+		mockAuthRepo.EXPECT().GetAccessTokenByHash(ctx, hashToken("tdp_token")).Return(token, nil).Times(1)
+		mockUserRepo.EXPECT().GetById(ctx, uint(1)).Return(users.User{Model: gorm.Model{ID: 1}}, nil).Times(1)
+		mockAuthRepo.EXPECT().TouchAccessToken(ctx, token.ID, gomock.Any()).Return(nil).Times(1)
+
+		validated, err := service.ValidateAccessToken(ctx, "tdp_token")
+		assert.NoError(t, err)
+		assert.NotNil(t, validated.LastUsedAt)
+
+		// not again right after
+		mockAuthRepo.EXPECT().GetAccessTokenByHash(ctx, hashToken("tdp_token")).Return(validated, nil).Times(1)
+		_, err = service.ValidateAccessToken(ctx, "tdp_token")
+		assert.NoError(t, err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		token := PersonalAccessToken{ID: 3, UserID: 1, ExpiresAt: time.Now().Add(-time.Hour)}
+		mockAuthRepo.EXPECT().GetAccessTokenByHash(ctx, hashToken("tdp_token")).Return(token, nil).Times(1)
+
+		_, err := service.ValidateAccessToken(ctx, "tdp_token")
+		assert.Equal(t, errors.New(locale.ErrorInvalidToken), err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("token created before a password change", func(t *testing.T) {
+		changedAt := time.Now().Add(-time.Minute)
+		token := PersonalAccessToken{ID: 4, UserID: 2, ExpiresAt: time.Now().Add(time.Hour), CreatedAt: changedAt.Add(-time.Hour)}
+		mockAuthRepo.EXPECT().GetAccessTokenByHash(ctx, hashToken("tdp_token")).Return(token, nil).Times(1)
+		mockUserRepo.EXPECT().GetById(ctx, uint(2)).Return(users.User{Model: gorm.Model{ID: 2}, TokensInvalidBefore: &changedAt}, nil).Times(1)
+
+		_, err := service.ValidateAccessToken(ctx, "tdp_token")
+		assert.Equal(t, errors.New(locale.ErrorInvalidToken), err)
+
+		// tokens created since keep working
+		token.CreatedAt = time.Now()
+		mockAuthRepo.EXPECT().GetAccessTokenByHash(ctx, hashToken("tdp_token")).Return(token, nil).Times(1)
+		mockAuthRepo.EXPECT().TouchAccessToken(ctx, token.ID, gomock.Any()).Return(nil).Times(1)
+
+		_, err = service.ValidateAccessToken(ctx, "tdp_token")
+		assert.NoError(t, err)
+
+		ctrl.Finish()
+	})
+}
+
+func TestRequiredScope(t *testing.T) {
+	assert.Equal(t, "todos:read", RequiredScope(http.MethodGet, "/todos/5"))
+	assert.Equal(t, "todos:write", RequiredScope(http.MethodPatch, "/time-entries/5"))
+	assert.Equal(t, "rules:write", RequiredScope(http.MethodDelete, "/rules/1"))
+	assert.Equal(t, "", RequiredScope(http.MethodGet, "/user/me/tokens"))
+	assert.Equal(t, "", RequiredScope(http.MethodPost, "/auth/logout"))
+}
+
+func TestService_Sessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAuthRepo := NewMockRepository(ctrl)
+	mockUserRepo := users.NewMockRepository(ctrl)
+	mockTransactor := database.NewMockTransactor(ctrl)
+	v := validator.New()
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockUserRepo, mockAuthRepo, mockTransactor, email.NewMockService(ctrl), testKeys(t), v)
+	ctx := WithClient(context.Background(), Client{UserAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0", IP: "203.0.113.7"})
+
+	startedAt := time.Now().Add(-48 * time.Hour)
+	user := users.User{Model: gorm.Model{ID: 1}, Email: "test@test.com"}
+
+	t.Run("refresh keeps the session", func(t *testing.T) {
+		record := RefreshToken{Model: gorm.Model{ID: 5}, UserID: user.ID, FamilyID: "session", ExpiresAt: time.Now().Add(time.Hour), SessionStartedAt: &startedAt}
+
+		mockTransactor.
+			EXPECT().
+			WithTransaction(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+				return fn(ctx)
+			}).
+			Times(1)
+		mockAuthRepo.EXPECT().GetRefreshToken(ctx, hashToken("refresh")).Return(record, nil).Times(1)
+		mockAuthRepo.EXPECT().ReplaceRefreshToken(ctx, record.ID, gomock.Any()).Return(true, nil).Times(1)
+		mockUserRepo.EXPECT().GetById(ctx, user.ID).Return(user, nil).Times(1)
+		mockAuthRepo.
+			EXPECT().
+			SaveRefreshToken(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, token *RefreshToken) error {
+				assert.Equal(t, "session", token.FamilyID)
+				assert.Equal(t, startedAt, *token.SessionStartedAt)
+				assert.Equal(t, "203.0.113.7", token.IP)
+				return nil
+			}).
+			Times(1)
+
+		response, err := service.RefreshToken(ctx, "refresh")
+		assert.NoError(t, err)
+
+		claims, err := service.ValidateToken(response.Token)
+		assert.NoError(t, err)
+		assert.Equal(t, "session", claims.SessionID)
+
+		ctrl.Finish()
+	})
+
+	t.Run("list sessions", func(t *testing.T) {
+		lastUsed := time.Now().Add(-time.Hour)
+		tokens := []RefreshToken{
+			{Model: gorm.Model{CreatedAt: lastUsed}, FamilyID: "current", SessionStartedAt: &startedAt, UserAgent: clientFromContext(ctx).UserAgent},
+			{Model: gorm.Model{CreatedAt: lastUsed}, FamilyID: "other", UserAgent: "curl/8.5.0"},
+		}
+		mockAuthRepo.EXPECT().GetActiveRefreshTokens(ctx, user.ID).Return(tokens, nil).Times(1)
+
+		sessions, err := service.GetSessions(ctx, user.ID, "current")
+		assert.NoError(t, err)
+		assert.Equal(t, []Session{
+			{ID: "current", Device: "Firefox on Linux", UserAgent: tokens[0].UserAgent, CreatedAt: startedAt, LastUsedAt: lastUsed, Current: true},
+			{ID: "other", Device: "curl", UserAgent: "curl/8.5.0", CreatedAt: lastUsed, LastUsedAt: lastUsed},
+		}, sessions)
+
+		ctrl.Finish()
+	})
+
+	t.Run("revoke the session of another user", func(t *testing.T) {
+		mockAuthRepo.EXPECT().RevokeSession(ctx, user.ID, "other").Return(gorm.ErrRecordNotFound).Times(1)
+
+		err := service.RevokeSession(ctx, user.ID, "other")
+		assert.Equal(t, errors.New(locale.ErrorNotFoundRecord), err)
+
+		ctrl.Finish()
+	})
+}
+
+func TestService_Revocation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAuthRepo := NewMockRepository(ctrl)
+	mockUserRepo := users.NewMockRepository(ctrl)
+	v := validator.New()
+	logger := zap.NewNop().Sugar()
+	mockTransactor := database.NewMockTransactor(ctrl)
+	s := GetService(logger, mockUserRepo, mockAuthRepo, mockTransactor, email.NewMockService(ctrl), testKeys(t), v).(*service)
+	ctx := context.Background()
+
+	user := users.User{Model: gorm.Model{ID: 1}, Email: "test@test.com"}
+	newClaims := func(issuedAt time.Time) JWTClaims {
+		return JWTClaims{
+			UserID:    user.ID,
+			Email:     user.Email,
+			SessionID: uuid.New().String(),
+			RegisteredClaims: jwt.RegisteredClaims{
+				ID:        uuid.New().String(),
+				ExpiresAt: jwt.NewNumericDate(issuedAt.Add(s.tokenExpiration)),
+				IssuedAt:  jwt.NewNumericDate(issuedAt),
+			},
+		}
+	}
+	sign := func(claims JWTClaims) string {
+		token, err := s.keys.sign(claims)
+		assert.NoError(t, err)
+		return token
+	}
+
+	t.Run("logout revokes the access tokens of the session", func(t *testing.T) {
+		claims := newClaims(time.Now())
+		earlier := newClaims(time.Now().Add(-time.Minute))
+		earlier.SessionID = claims.SessionID
+		mockAuthRepo.
+			EXPECT().
+			RevokeToken(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, token *RevokedToken) error {
+				assert.Equal(t, claims.ID, token.JTI)
+				assert.Equal(t, claims.ExpiresAt.Time, token.ExpiresAt)
+				return nil
+			}).
+			Times(1)
+		mockAuthRepo.EXPECT().RevokeSession(ctx, user.ID, claims.SessionID).Return(nil).Times(1)
+		mockAuthRepo.
+			EXPECT().
+			RevokeToken(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, token *RevokedToken) error {
+				assert.Equal(t, claims.SessionID, token.JTI)
+				assert.WithinDuration(t, time.Now().Add(s.tokenExpiration), token.ExpiresAt, time.Second)
+				return nil
+			}).
+			Times(1)
+
+		assert.NoError(t, s.Logout(ctx, sign(claims), false))
+
+		// known from the cache, the database is not asked
+		err := s.CheckRevocation(ctx, &claims)
+		assert.Equal(t, errors.New(locale.ErrorInvalidToken), err)
+
+		// issued to the session before it was refreshed
+		mockAuthRepo.EXPECT().IsTokenRevoked(ctx, earlier.ID).Return(false, nil).Times(1)
+		err = s.CheckRevocation(ctx, &earlier)
+		assert.Equal(t, errors.New(locale.ErrorInvalidToken), err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("a revoked session rejects its access tokens", func(t *testing.T) {
+		claims := newClaims(time.Now())
+		mockAuthRepo.EXPECT().RevokeSession(ctx, user.ID, claims.SessionID).Return(nil).Times(1)
+		mockAuthRepo.EXPECT().RevokeToken(ctx, gomock.Any()).Return(nil).Times(1)
+
+		assert.NoError(t, s.RevokeSession(ctx, user.ID, claims.SessionID))
+
+		mockAuthRepo.EXPECT().IsTokenRevoked(ctx, claims.ID).Return(false, nil).Times(1)
+		err := s.CheckRevocation(ctx, &claims)
+		assert.Equal(t, errors.New(locale.ErrorInvalidToken), err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("a valid token is looked up once", func(t *testing.T) {
+		claims := newClaims(time.Now())
+		mockAuthRepo.EXPECT().IsTokenRevoked(ctx, claims.ID).Return(false, nil).Times(1)
+		mockAuthRepo.EXPECT().IsTokenRevoked(ctx, claims.SessionID).Return(false, nil).Times(1)
+		mockUserRepo.EXPECT().GetById(ctx, user.ID).Return(user, nil).Times(1)
+
+		assert.NoError(t, s.CheckRevocation(ctx, &claims))
+		assert.NoError(t, s.CheckRevocation(ctx, &claims))
+
+		ctrl.Finish()
+	})
+
+	t.Run("revocations of other instances are seen once rechecked", func(t *testing.T) {
+		s.revocationRecheck = 0
+		defer func() { s.revocationRecheck = revocationRecheckInterval }()
+		s.invalidBefore.Remove(user.ID)
+
+		claims := newClaims(time.Now())
+		mockAuthRepo.EXPECT().IsTokenRevoked(ctx, claims.ID).Return(false, nil).Times(2)
+		mockAuthRepo.EXPECT().IsTokenRevoked(ctx, claims.SessionID).Return(false, nil).Times(1)
+		mockUserRepo.EXPECT().GetById(ctx, user.ID).Return(user, nil).Times(1)
+
+		assert.NoError(t, s.CheckRevocation(ctx, &claims))
+
+		// the session was revoked on another instance
+		mockAuthRepo.EXPECT().IsTokenRevoked(ctx, claims.SessionID).Return(true, nil).Times(1)
+
+		err := s.CheckRevocation(ctx, &claims)
+		assert.Equal(t, errors.New(locale.ErrorInvalidToken), err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("logging out everywhere rejects the tokens issued before", func(t *testing.T) {
+		earlier := newClaims(time.Now().Add(-time.Minute))
+		current := newClaims(time.Now().Add(-time.Minute))
+		mockAuthRepo.EXPECT().RevokeToken(ctx, gomock.Any()).Return(nil).Times(1)
+		mockUserRepo.
+			EXPECT().
+			Update(ctx, user.ID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uint, updates map[string]interface{}) error {
+				assert.WithinDuration(t, time.Now(), updates["tokens_invalid_before"].(time.Time), time.Second)
+				return nil
+			}).
+			Times(1)
+		mockAuthRepo.EXPECT().RevokeRefreshTokensByUserID(ctx, user.ID).Return(nil).Times(1)
+
+		assert.NoError(t, s.Logout(ctx, sign(current), true))
+
+		mockAuthRepo.EXPECT().IsTokenRevoked(ctx, earlier.ID).Return(false, nil).Times(1)
+		mockAuthRepo.EXPECT().IsTokenRevoked(ctx, earlier.SessionID).Return(false, nil).Times(1)
+		err := s.CheckRevocation(ctx, &earlier)
+		assert.Equal(t, errors.New(locale.ErrorInvalidToken), err)
+
+		later := newClaims(time.Now().Add(time.Second))
+		mockAuthRepo.EXPECT().IsTokenRevoked(ctx, later.ID).Return(false, nil).Times(1)
+		mockAuthRepo.EXPECT().IsTokenRevoked(ctx, later.SessionID).Return(false, nil).Times(1)
+		assert.NoError(t, s.CheckRevocation(ctx, &later))
+
+		ctrl.Finish()
+	})
+
+	t.Run("a password change is read again", func(t *testing.T) {
+		changedAt := time.Now().Add(time.Minute)
+		changed := user
+		changed.TokensInvalidBefore = &changedAt
+		mockAuthRepo.EXPECT().RevokeRefreshTokensByUserID(ctx, user.ID).Return(nil).Times(1)
+		s.HandleUserEvent(ctx, users.Event{Type: users.EventPasswordChanged, User: changed})
+
+		claims := newClaims(time.Now())
+		mockAuthRepo.EXPECT().IsTokenRevoked(ctx, claims.ID).Return(false, nil).Times(1)
+		mockAuthRepo.EXPECT().IsTokenRevoked(ctx, claims.SessionID).Return(false, nil).Times(1)
+		mockUserRepo.EXPECT().GetById(ctx, user.ID).Return(changed, nil).Times(1)
+
+		err := s.CheckRevocation(ctx, &claims)
+		assert.Equal(t, errors.New(locale.ErrorInvalidToken), err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("a password change revokes the refresh tokens", func(t *testing.T) {
+		record := RefreshToken{Model: gorm.Model{ID: 5}, UserID: user.ID, FamilyID: "session", ExpiresAt: time.Now().Add(time.Hour)}
+		mockAuthRepo.
+			EXPECT().
+			RevokeRefreshTokensByUserID(ctx, user.ID).
+			DoAndReturn(func(context.Context, uint) error {
+				record.IsRevoked = true
+				return nil
+			}).
+			Times(1)
+
+		s.HandleUserEvent(ctx, users.Event{Type: users.EventPasswordChanged, User: user})
+
+		mockTransactor.
+			EXPECT().
+			WithTransaction(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+				return fn(ctx)
+			}).
+			Times(1)
+		mockAuthRepo.EXPECT().GetRefreshToken(ctx, hashToken("refresh")).DoAndReturn(func(context.Context, string) (RefreshToken, error) {
+			return record, nil
+		}).Times(1)
+
+		_, err := s.RefreshToken(ctx, "refresh")
+		assert.Equal(t, errors.New(locale.ErrorInvalidToken), err)
+
+		ctrl.Finish()
+	})
+}
+
+func TestDescribeDevice(t *testing.T) {
+	assert.Equal(t, "Chrome on macOS", describeDevice("Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36"))
+	assert.Equal(t, "Safari on iOS", describeDevice("Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1"))
+	assert.Equal(t, "Edge on Windows", describeDevice("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36 Edg/126.0.0.0"))
+	assert.Equal(t, "Unknown device", describeDevice(""))
+	assert.Equal(t, "h\u00e9", truncate("h\u00e9llo", 3))
+	assert.Equal(t, "h", truncate("h\u00e9llo", 2))
+}
+
+// testKeys : a key set for the service under test
+func testKeys(t *testing.T) *KeySet {
+	keys, err := generateKeySet()
+	assert.NoError(t, err)
+	return keys
+}
+
+// writeKey : stores the key as PKCS#8 PEM in the directory
+func writeKey(t *testing.T, dir string, name string, key interface{}) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0600))
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	_, current, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	_, next, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	writeKey(t, dir, "old.pem", rsaKey)
+	writeKey(t, dir, "current.pem", current)
+	writeKey(t, dir, "next.pem", next)
+
+	now := time.Now().Truncate(time.Second)
+	manifest := fmt.Sprintf(`{"keys": [
+		{"kid": "next", "file": "next.pem", "not_before": %q},
+		{"kid": "old", "file": "old.pem", "not_before": %q},
+		{"kid": "current", "file": "current.pem", "not_before": %q}
+	]}`, now.Add(time.Hour).Format(time.RFC3339), now.Add(-48*time.Hour).Format(time.RFC3339), now.Add(-30*time.Minute).Format(time.RFC3339))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "keys.json"), []byte(manifest), 0600))
+
+	keys, err := readKeySet(filepath.Join(dir, "keys.json"), time.Hour)
+	if !assert.NoError(t, err) {
+		return
+	}
+	claims := jwt.RegisteredClaims{Subject: "1"}
+	kid := func(token string) string {
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, &jwt.RegisteredClaims{})
+		assert.NoError(t, err)
+		return parsed.Header["kid"].(string)
+	}
+
+	t.Run("the latest active key signs", func(t *testing.T) {
+		token, err := keys.sign(claims)
+		assert.NoError(t, err)
+		assert.Equal(t, "current", kid(token))
+
+		_, err = keys.parse(token, &jwt.RegisteredClaims{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("the replaced key verifies during the grace period", func(t *testing.T) {
+		keys.now = func() time.Time { return now.Add(-time.Hour) }
+		token, err := keys.sign(claims)
+		assert.NoError(t, err)
+		assert.Equal(t, "old", kid(token))
+
+		keys.now = time.Now
+		_, err = keys.parse(token, &jwt.RegisteredClaims{})
+		assert.NoError(t, err)
+
+		// the current key took over three and a half hours before, past the grace period
+		keys.now = func() time.Time { return now.Add(3 * time.Hour) }
+		_, err = keys.parse(token, &jwt.RegisteredClaims{})
+		assert.Error(t, err)
+		keys.now = time.Now
+	})
+
+	t.Run("the JWKS lists the accepted and the scheduled keys", func(t *testing.T) {
+		jwks := keys.JWKS()
+		if assert.Len(t, jwks.Keys, 3) {
+			assert.Equal(t, JWK{
+				KeyType:   "RSA",
+				KeyID:     "old",
+				Use:       "sig",
+				Algorithm: "RS256",
+				N:         base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+				E:         "AQAB",
+			}, jwks.Keys[0])
+			assert.Equal(t, JWK{
+				KeyType:   "OKP",
+				KeyID:     "current",
+				Use:       "sig",
+				Algorithm: "EdDSA",
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(current.Public().(ed25519.PublicKey)),
+			}, jwks.Keys[1])
+			assert.Equal(t, "next", jwks.Keys[2].KeyID)
+		}
+
+		keys.now = func() time.Time { return now.Add(3 * time.Hour) }
+		jwks = keys.JWKS()
+		keys.now = time.Now
+		if assert.Len(t, jwks.Keys, 1) {
+			assert.Equal(t, "next", jwks.Keys[0].KeyID)
+		}
+	})
+
+	t.Run("other tokens are rejected", func(t *testing.T) {
+		hmac, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test"))
+		assert.NoError(t, err)
+		_, err = keys.parse(hmac, &jwt.RegisteredClaims{})
+		assert.Error(t, err)
+
+		unknown := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+		unknown.Header["kid"] = "unknown"
+		token, err := unknown.SignedString(current)
+		assert.NoError(t, err)
+		_, err = keys.parse(token, &jwt.RegisteredClaims{})
+		assert.Error(t, err)
+
+		// signed by another key under the kid of the current one
+		forged := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+		forged.Header["kid"] = "current"
+		token, err = forged.SignedString(next)
+		assert.NoError(t, err)
+		_, err = keys.parse(token, &jwt.RegisteredClaims{})
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadKeys(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+
+	t.Run("refuses to start without keys", func(t *testing.T) {
+		t.Setenv("APP_ENV", "")
+		t.Setenv("JWT_KEYS_FILE", "")
+
+		_, err := LoadKeys(logger)
+		assert.Error(t, err)
+	})
+
+	t.Run("generates a key in test mode", func(t *testing.T) {
+		t.Setenv("APP_ENV", "test")
+		t.Setenv("JWT_KEYS_FILE", "")
+
+		keys, err := LoadKeys(logger)
+		assert.NoError(t, err)
+		assert.Len(t, keys.JWKS().Keys, 1)
+	})
+
+	t.Run("refuses weak and missing keys", func(t *testing.T) {
+		dir := t.TempDir()
+		weak, err := rsa.GenerateKey(rand.Reader, 1024)
+		assert.NoError(t, err)
+		writeKey(t, dir, "weak.pem", weak)
+		_, future, err := ed25519.GenerateKey(rand.Reader)
+		assert.NoError(t, err)
+		writeKey(t, dir, "future.pem", future)
+
+		manifests := []string{
+			`{"keys": [{"kid": "weak", "file": "weak.pem", "not_before": "2026-01-01T00:00:00Z"}]}`,
+			`{"keys": [{"kid": "missing", "file": "missing.pem", "not_before": "2026-01-01T00:00:00Z"}]}`,
+			// nothing can sign yet
+			`{"keys": [{"kid": "future", "file": "future.pem", "not_before": "2999-01-01T00:00:00Z"}]}`,
+		}
+		for _, manifest := range manifests {
+			path := filepath.Join(dir, "keys.json")
+			assert.NoError(t, os.WriteFile(path, []byte(manifest), 0600))
+			t.Setenv("JWT_KEYS_FILE", path)
+
+			_, err := LoadKeys(logger)
+			assert.Error(t, err, manifest)
+		}
+	})
+}
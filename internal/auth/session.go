@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"todo-app/pkg/locale"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// Client : the device a request comes from, recorded with the refresh tokens issued to
+// it
+type Client struct {
+	UserAgent string
+	IP        string
+}
+
+type clientKey struct{}
+
+// WithClient : a context for requests of the client
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+func clientFromContext(ctx context.Context) Client {
+	client, _ := ctx.Value(clientKey{}).(Client)
+	return client
+}
+
+// ClientMiddleware : puts the client of each request in its context
+func ClientMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			request := c.Request()
+			client := Client{UserAgent: request.UserAgent(), IP: c.RealIP()}
+			c.SetRequest(request.WithContext(WithClient(request.Context(), client)))
+
+			return next(c)
+		}
+	}
+}
+
+// GetSessions : the sessions of the user that can still be refreshed, the latest used
+// first
+func (s *service) GetSessions(ctx context.Context, userID uint, currentSessionID string) ([]Session, error) {
+	tokens, err := s.authRepository.GetActiveRefreshTokens(ctx, userID)
+	if err != nil {
+		return nil, errors.New(locale.ErrorInternalServer)
+	}
+
+	sessions := make([]Session, 0, len(tokens))
+	for _, token := range tokens {
+		createdAt := token.CreatedAt
+		if token.SessionStartedAt != nil {
+			createdAt = *token.SessionStartedAt
+		}
+
+		sessions = append(sessions, Session{
+			ID:         token.FamilyID,
+			Device:     describeDevice(token.UserAgent),
+			UserAgent:  token.UserAgent,
+			IP:         token.IP,
+			CreatedAt:  createdAt,
+			LastUsedAt: token.CreatedAt,
+			Current:    token.FamilyID == currentSessionID,
+		})
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession : logs the session out, it cannot be refreshed anymore and its access
+// tokens are rejected
+func (s *service) RevokeSession(ctx context.Context, userID uint, sessionID string) error {
+	// the refresh tokens are revoked first, it fails for sessions of other users
+	err := s.authRepository.RevokeSession(ctx, userID, sessionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New(locale.ErrorNotFoundRecord)
+	}
+	if err != nil {
+		return errors.New(locale.ErrorInternalServer)
+	}
+
+	if err := s.revokeSessionTokens(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
+	s.logger.Infow("session revoked", "user_id", userID, "session_id", sessionID)
+	return nil
+}
+
+// browsers and systems as they appear in user agents, checked in order since browsers
+// name the ones they are built on
+var (
+	browsers = [][2]string{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+	}
+	systems = [][2]string{
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	}
+)
+
+// describeDevice : a name for the device of the user agent, like "Firefox on Linux"
+func describeDevice(userAgent string) string {
+	find := func(names [][2]string) string {
+		for _, name := range names {
+			if strings.Contains(userAgent, name[0]) {
+				return name[1]
+			}
+		}
+		return ""
+	}
+
+	browser, system := find(browsers), find(systems)
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	}
+
+	return "Unknown device"
+}
+
+// truncate : the first max bytes of the string, without splitting a character
+func truncate(value string, max int) string {
+	if len(value) <= max {
+		return value
+	}
+
+	for max > 0 && !utf8.RuneStart(value[max]) {
+		max--
+	}
+
+	return value[:max]
+}
@@ -0,0 +1,404 @@
+package calendar
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"todo-app/internal/auth"
+	e "todo-app/pkg/errors"
+	"todo-app/pkg/handlers"
+	"todo-app/pkg/ical"
+	"todo-app/pkg/locale"
+
+	"github.com/labstack/echo/v4"
+)
+
+// The CalDAV tree has one principal, the authenticated user, whose calendar home holds
+// a single calendar with the tasks
+const (
+	davRoot        = "/caldav/"
+	principalPath  = "/caldav/principal/"
+	homePath       = "/caldav/calendars/"
+	collectionPath = "/caldav/calendars/todos/"
+)
+
+const (
+	contentTypeXML      = "application/xml; charset=utf-8"
+	contentTypeResource = "text/calendar; charset=utf-8; component=VTODO"
+)
+
+func (h *endpointHandler) davEndpoints() []handlers.Endpoint {
+	return []handlers.Endpoint{
+		{
+			Method:  http.MethodGet,
+			Path:    "/.well-known/caldav",
+			Handler: h.wellKnown,
+		},
+		{
+			Method:  echo.PROPFIND,
+			Path:    "/.well-known/caldav",
+			Handler: h.wellKnown,
+		},
+		{
+			Method:  http.MethodOptions,
+			Path:    "/caldav/*",
+			Handler: h.davOptions,
+		},
+		{
+			Method:  echo.PROPFIND,
+			Path:    "/caldav/*",
+			Handler: h.basicAuth(h.propfind),
+		},
+		{
+			Method:  echo.REPORT,
+			Path:    "/caldav/*",
+			Handler: h.basicAuth(h.report),
+		},
+		{
+			Method:  http.MethodOptions,
+			Path:    "/caldav/calendars/todos/:name",
+			Handler: h.davOptions,
+		},
+		{
+			Method:  echo.PROPFIND,
+			Path:    "/caldav/calendars/todos/:name",
+			Handler: h.basicAuth(h.propfind),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/caldav/calendars/todos/:name",
+			Handler: h.basicAuth(h.getResource),
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/caldav/calendars/todos/:name",
+			Handler: h.basicAuth(h.putResource),
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/caldav/calendars/todos/:name",
+			Handler: h.basicAuth(h.deleteResource),
+		},
+	}
+}
+
+// basicAuth : CalDAV clients cannot send a JWT, they log in with the account email
+// and an app password instead
+func (h *endpointHandler) basicAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		email, password, ok := ctx.Request().BasicAuth()
+		if ok {
+			userId, err := h.service.Authenticate(ctx.Request().Context(), email, password)
+			if err == nil {
+				ctx.Set("user_id", userId)
+				ctx.Set("user_email", email)
+
+				return next(ctx)
+			}
+			h.logger.Warnw("caldav login failed", "email", email)
+		}
+
+		ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, `Basic realm="todo-app"`)
+
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: locale.ErrorInvalidCredentials})
+	}
+}
+
+// wellKnown : lets clients find the CalDAV root from the host name alone (RFC 6764)
+func (h *endpointHandler) wellKnown(ctx echo.Context) error {
+	return ctx.Redirect(http.StatusMovedPermanently, davRoot)
+}
+
+func (h *endpointHandler) davOptions(ctx echo.Context) error {
+	ctx.Response().Header().Set("DAV", "1, 3, calendar-access")
+	ctx.Response().Header().Set(echo.HeaderAllow, "OPTIONS, GET, PUT, DELETE, PROPFIND, REPORT")
+
+	return ctx.NoContent(http.StatusOK)
+}
+
+func (h *endpointHandler) propfind(ctx echo.Context) error {
+	userId := auth.GetUserIdFromContext(ctx)
+	request, err := parseDAVRequest(ctx.Request().Body)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidDAVRequest, Details: err.Error()})
+	}
+	// Depth infinity is treated like 1, the tree is only two levels deep
+	withChildren := ctx.Request().Header.Get("Depth") != "0"
+
+	m := newMultistatus()
+	switch relative := strings.Trim(strings.TrimPrefix(ctx.Request().URL.Path, "/caldav"), "/"); relative {
+	case "":
+		m.response(davRoot, h.rootProps(), request.Props)
+	case "principal":
+		m.response(principalPath, h.principalProps(auth.GetUserEmailFromContext(ctx)), request.Props)
+	case "calendars":
+		m.response(homePath, h.homeProps(), request.Props)
+		if withChildren {
+			token, err := h.service.GetSyncToken(ctx.Request().Context(), userId)
+			if err != nil {
+				return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorInternalServer, Details: err.Error()})
+			}
+			m.response(collectionPath, h.collectionProps(token), request.Props)
+		}
+	case "calendars/todos":
+		token, err := h.service.GetSyncToken(ctx.Request().Context(), userId)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorInternalServer, Details: err.Error()})
+		}
+		m.response(collectionPath, h.collectionProps(token), request.Props)
+
+		if withChildren {
+			resources, err := h.service.GetResources(ctx.Request().Context(), userId)
+			if err != nil {
+				return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorInternalServer, Details: err.Error()})
+			}
+			for _, resource := range resources {
+				m.response(resourceHref(resource.Name), resourceProps(resource, wantsData(request.Props)), request.Props)
+			}
+		}
+	default:
+		name, ok := strings.CutPrefix(relative, "calendars/todos/")
+		if !ok || strings.Contains(name, "/") {
+			return ctx.JSON(http.StatusNotFound, e.ResponseError{Message: locale.ErrorNotFoundRecord})
+		}
+		name, _ = url.PathUnescape(name)
+
+		resource, err := h.service.GetResource(ctx.Request().Context(), userId, name)
+		if err != nil {
+			return h.resourceError(ctx, err)
+		}
+		m.response(resourceHref(resource.Name), resourceProps(resource, wantsData(request.Props)), request.Props)
+	}
+
+	return ctx.Blob(http.StatusMultiStatus, contentTypeXML, []byte(m.String()))
+}
+
+// report : answers calendar-query, calendar-multiget and sync-collection reports on
+// the task collection. Of the calendar-query filters only the component is applied,
+// other filters return a superset, which clients filter again.
+func (h *endpointHandler) report(ctx echo.Context) error {
+	userId := auth.GetUserIdFromContext(ctx)
+	if strings.Trim(ctx.Request().URL.Path, "/") != strings.Trim(collectionPath, "/") {
+		return ctx.JSON(http.StatusNotFound, e.ResponseError{Message: locale.ErrorNotFoundRecord})
+	}
+
+	request, err := parseDAVRequest(ctx.Request().Body)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidDAVRequest, Details: err.Error()})
+	}
+
+	m := newMultistatus()
+	switch request.Root {
+	case xml.Name{Space: nsCalDAV, Local: "calendar-query"}:
+		for _, component := range request.Components {
+			if component != "VCALENDAR" && component != "VTODO" {
+				return ctx.Blob(http.StatusMultiStatus, contentTypeXML, []byte(m.String()))
+			}
+		}
+
+		resources, err := h.service.GetResources(ctx.Request().Context(), userId)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorInternalServer, Details: err.Error()})
+		}
+		for _, resource := range resources {
+			m.response(resourceHref(resource.Name), resourceProps(resource, wantsData(request.Props)), request.Props)
+		}
+	case xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}:
+		for _, href := range request.Hrefs {
+			name, err := url.PathUnescape(path.Base(href))
+			if err != nil {
+				m.status(href, http.StatusNotFound)
+				continue
+			}
+
+			resource, err := h.service.GetResource(ctx.Request().Context(), userId, name)
+			if err != nil {
+				m.status(href, http.StatusNotFound)
+				continue
+			}
+			m.response(href, resourceProps(resource, wantsData(request.Props)), request.Props)
+		}
+	case xml.Name{Space: nsDAV, Local: "sync-collection"}:
+		changes, err := h.service.GetChanges(ctx.Request().Context(), userId, request.SyncToken)
+		if err != nil {
+			if err.Error() == locale.ErrorInvalidSyncToken {
+				return ctx.Blob(http.StatusForbidden, contentTypeXML, []byte(davError("valid-sync-token")))
+			}
+
+			return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorInternalServer, Details: err.Error()})
+		}
+		for _, resource := range changes.Resources {
+			m.response(resourceHref(resource.Name), resourceProps(resource, wantsData(request.Props)), request.Props)
+		}
+		for _, name := range changes.Deleted {
+			m.status(resourceHref(name), http.StatusNotFound)
+		}
+		m.syncToken(changes.SyncToken)
+	default:
+		return ctx.Blob(http.StatusForbidden, contentTypeXML, []byte(davError("supported-report")))
+	}
+
+	return ctx.Blob(http.StatusMultiStatus, contentTypeXML, []byte(m.String()))
+}
+
+func (h *endpointHandler) getResource(ctx echo.Context) error {
+	userId := auth.GetUserIdFromContext(ctx)
+	name, _ := url.PathUnescape(ctx.Param("name"))
+
+	resource, err := h.service.GetResource(ctx.Request().Context(), userId, name)
+	if err != nil {
+		return h.resourceError(ctx, err)
+	}
+
+	data, err := resourceData(resource)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorInternalServer, Details: err.Error()})
+	}
+
+	ctx.Response().Header().Set("ETag", resource.ETag)
+	ctx.Response().Header().Set(echo.HeaderLastModified, resource.LastModified.UTC().Format(http.TimeFormat))
+
+	return ctx.Blob(http.StatusOK, contentTypeResource, []byte(data))
+}
+
+func (h *endpointHandler) putResource(ctx echo.Context) error {
+	userId := auth.GetUserIdFromContext(ctx)
+	name, _ := url.PathUnescape(ctx.Param("name"))
+
+	resource, created, err := h.service.PutResource(ctx.Request().Context(), userId, name, ctx.Request().Body, preconditions(ctx))
+	if err != nil {
+		return h.resourceError(ctx, err)
+	}
+
+	ctx.Response().Header().Set("ETag", resource.ETag)
+	if created {
+		return ctx.NoContent(http.StatusCreated)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (h *endpointHandler) deleteResource(ctx echo.Context) error {
+	userId := auth.GetUserIdFromContext(ctx)
+	name, _ := url.PathUnescape(ctx.Param("name"))
+
+	err := h.service.DeleteResource(ctx.Request().Context(), userId, name, preconditions(ctx))
+	if err != nil {
+		return h.resourceError(ctx, err)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (h *endpointHandler) resourceError(ctx echo.Context, err error) error {
+	switch err.Error() {
+	case locale.ErrorNotFoundRecord:
+		return ctx.JSON(http.StatusNotFound, e.ResponseError{Message: locale.ErrorNotFoundRecord})
+	case locale.ErrorInvalidICal:
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidICal})
+	case locale.ErrorPreconditionFailed:
+		return ctx.JSON(http.StatusPreconditionFailed, e.ResponseError{Message: locale.ErrorPreconditionFailed})
+	case locale.ErrorNotATask:
+		return ctx.JSON(http.StatusConflict, e.ResponseError{Message: locale.ErrorNotATask})
+	}
+
+	h.logger.Errorw("caldav request failed", "error", err)
+
+	return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorInternalServer, Details: err.Error()})
+}
+
+func (h *endpointHandler) rootProps() map[xml.Name]string {
+	return map[xml.Name]string{
+		{Space: nsDAV, Local: "resourcetype"}:           "<d:collection/>",
+		{Space: nsDAV, Local: "current-user-principal"}: hrefElement(principalPath),
+	}
+}
+
+func (h *endpointHandler) principalProps(email string) map[xml.Name]string {
+	return map[xml.Name]string{
+		{Space: nsDAV, Local: "resourcetype"}:                 "<d:collection/><d:principal/>",
+		{Space: nsDAV, Local: "displayname"}:                  escapeXML(email),
+		{Space: nsDAV, Local: "current-user-principal"}:       hrefElement(principalPath),
+		{Space: nsDAV, Local: "principal-URL"}:                hrefElement(principalPath),
+		{Space: nsCalDAV, Local: "calendar-home-set"}:         hrefElement(homePath),
+		{Space: nsCalDAV, Local: "calendar-user-address-set"}: hrefElement("mailto:" + email),
+	}
+}
+
+func (h *endpointHandler) homeProps() map[xml.Name]string {
+	return map[xml.Name]string{
+		{Space: nsDAV, Local: "resourcetype"}:           "<d:collection/>",
+		{Space: nsDAV, Local: "current-user-principal"}: hrefElement(principalPath),
+	}
+}
+
+func (h *endpointHandler) collectionProps(syncToken string) map[xml.Name]string {
+	return map[xml.Name]string{
+		{Space: nsDAV, Local: "resourcetype"}:           "<d:collection/><c:calendar/>",
+		{Space: nsDAV, Local: "displayname"}:            "Todos",
+		{Space: nsDAV, Local: "current-user-principal"}: hrefElement(principalPath),
+		{Space: nsDAV, Local: "current-user-privilege-set"}: "<d:privilege><d:read/></d:privilege>" +
+			"<d:privilege><d:write/></d:privilege>",
+		{Space: nsDAV, Local: "supported-report-set"}: "<d:supported-report><d:report><c:calendar-query/></d:report></d:supported-report>" +
+			"<d:supported-report><d:report><c:calendar-multiget/></d:report></d:supported-report>" +
+			"<d:supported-report><d:report><d:sync-collection/></d:report></d:supported-report>",
+		{Space: nsDAV, Local: "sync-token"}:                          escapeXML(syncToken),
+		{Space: nsCalendarServer, Local: "getctag"}:                  escapeXML(syncToken),
+		{Space: nsCalDAV, Local: "supported-calendar-component-set"}: `<c:comp name="VTODO"/>`,
+	}
+}
+
+// resourceProps : the properties of a task resource, the calendar data is only
+// rendered when it is asked for
+func resourceProps(resource Resource, withData bool) map[xml.Name]string {
+	props := map[xml.Name]string{
+		{Space: nsDAV, Local: "resourcetype"}:    "",
+		propGetETag:                              escapeXML(resource.ETag),
+		{Space: nsDAV, Local: "getcontenttype"}:  contentTypeResource,
+		{Space: nsDAV, Local: "getlastmodified"}: resource.LastModified.UTC().Format(http.TimeFormat),
+	}
+	if withData {
+		if data, err := resourceData(resource); err == nil {
+			props[propCalendarData] = escapeXML(data)
+		}
+	}
+
+	return props
+}
+
+func wantsData(props []xml.Name) bool {
+	for _, name := range props {
+		if name == propCalendarData {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resourceData : the resource as an iCalendar object holding its VTODO
+func resourceData(resource Resource) (string, error) {
+	var data bytes.Buffer
+	err := ical.Encode(&data, ical.Calendar{ProdID: prodID, Todos: []ical.Todo{resource.Todo}})
+
+	return data.String(), err
+}
+
+func resourceHref(name string) string {
+	return collectionPath + url.PathEscape(name)
+}
+
+func preconditions(ctx echo.Context) Preconditions {
+	return Preconditions{
+		IfMatch:     ctx.Request().Header.Get("If-Match"),
+		IfNoneMatch: ctx.Request().Header.Get("If-None-Match"),
+	}
+}
+
+// davError : the body of a response failing a WebDAV precondition
+func davError(condition string) string {
+	return xml.Header + `<d:error xmlns:d="DAV:"><d:` + condition + `/></d:error>`
+}
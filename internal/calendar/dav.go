@@ -0,0 +1,189 @@
+package calendar
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	nsDAV            = "DAV:"
+	nsCalDAV         = "urn:ietf:params:xml:ns:caldav"
+	nsCalendarServer = "http://calendarserver.org/ns/"
+)
+
+// prefixes : namespace prefixes declared on every multistatus response
+var prefixes = map[string]string{
+	nsDAV:            "d",
+	nsCalDAV:         "c",
+	nsCalendarServer: "cs",
+}
+
+var (
+	propCalendarData = xml.Name{Space: nsCalDAV, Local: "calendar-data"}
+	propGetETag      = xml.Name{Space: nsDAV, Local: "getetag"}
+)
+
+// davRequest : the body of a PROPFIND or REPORT request
+type davRequest struct {
+	// Root : propfind, calendar-query, calendar-multiget or sync-collection
+	Root xml.Name
+	// Props : the requested properties, nil when all properties are requested
+	Props []xml.Name
+	// Hrefs : the resources of a calendar-multiget
+	Hrefs []string
+	// SyncToken : the token of a sync-collection, empty for the initial sync
+	SyncToken string
+	// Components : names of the comp-filters of a calendar-query
+	Components []string
+}
+
+// parseDAVRequest : reads the parts of a PROPFIND or REPORT body the server uses, an
+// empty body is a PROPFIND for all properties
+func parseDAVRequest(r io.Reader) (davRequest, error) {
+	var request davRequest
+	decoder := xml.NewDecoder(r)
+	var stack []xml.Name
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return davRequest{}, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch {
+			case len(stack) == 0:
+				request.Root = t.Name
+			case len(stack) == 1 && t.Name == (xml.Name{Space: nsDAV, Local: "prop"}):
+				request.Props = []xml.Name{}
+			case len(stack) == 2 && stack[1] == (xml.Name{Space: nsDAV, Local: "prop"}):
+				request.Props = append(request.Props, t.Name)
+			case t.Name == (xml.Name{Space: nsCalDAV, Local: "comp-filter"}):
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "name" {
+						request.Components = append(request.Components, strings.ToUpper(attr.Value))
+					}
+				}
+			}
+			stack = append(stack, t.Name)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) != 2 {
+				continue
+			}
+			switch stack[1] {
+			case xml.Name{Space: nsDAV, Local: "href"}:
+				request.Hrefs = append(request.Hrefs, strings.TrimSpace(string(t)))
+			case xml.Name{Space: nsDAV, Local: "sync-token"}:
+				request.SyncToken = strings.TrimSpace(string(t))
+			}
+		}
+	}
+
+	return request, nil
+}
+
+// multistatus : builds a 207 Multi-Status body
+type multistatus struct {
+	b strings.Builder
+}
+
+func newMultistatus() *multistatus {
+	m := &multistatus{}
+	m.b.WriteString(xml.Header)
+	m.b.WriteString(`<d:multistatus xmlns:d="DAV:" xmlns:c="` + nsCalDAV + `" xmlns:cs="` + nsCalendarServer + `">`)
+
+	return m
+}
+
+// response : adds href with the requested properties found in available, the others
+// are reported as not found. A nil requested list adds all properties but the
+// calendar data, like an allprop PROPFIND.
+func (m *multistatus) response(href string, available map[xml.Name]string, requested []xml.Name) {
+	if requested == nil {
+		for name := range available {
+			if name != propCalendarData {
+				requested = append(requested, name)
+			}
+		}
+		sort.Slice(requested, func(i, j int) bool {
+			return requested[i].Space+requested[i].Local < requested[j].Space+requested[j].Local
+		})
+	}
+
+	var found, missing strings.Builder
+	for _, name := range requested {
+		if value, ok := available[name]; ok {
+			found.WriteString(element(name, value))
+		} else {
+			missing.WriteString(element(name, ""))
+		}
+	}
+
+	m.b.WriteString("<d:response><d:href>" + escapeXML(href) + "</d:href>")
+	if found.Len() > 0 {
+		m.propstat(found.String(), http.StatusOK)
+	}
+	if missing.Len() > 0 {
+		m.propstat(missing.String(), http.StatusNotFound)
+	}
+	m.b.WriteString("</d:response>")
+}
+
+// status : adds href with a status instead of properties, like a deleted resource in
+// a sync-collection report
+func (m *multistatus) status(href string, code int) {
+	m.b.WriteString("<d:response><d:href>" + escapeXML(href) + "</d:href>" + statusLine(code) + "</d:response>")
+}
+
+func (m *multistatus) syncToken(token string) {
+	m.b.WriteString("<d:sync-token>" + escapeXML(token) + "</d:sync-token>")
+}
+
+func (m *multistatus) propstat(props string, code int) {
+	m.b.WriteString("<d:propstat><d:prop>" + props + "</d:prop>" + statusLine(code) + "</d:propstat>")
+}
+
+func (m *multistatus) String() string {
+	return m.b.String() + "</d:multistatus>"
+}
+
+// element : name with inner XML, using the declared prefix of its namespace
+func element(name xml.Name, inner string) string {
+	prefix, ok := prefixes[name.Space]
+	declaration := ""
+	if !ok {
+		prefix = "x"
+		declaration = ` xmlns:x="` + escapeXML(name.Space) + `"`
+	}
+	tag := prefix + ":" + name.Local
+	if inner == "" {
+		return "<" + tag + declaration + "/>"
+	}
+
+	return "<" + tag + declaration + ">" + inner + "</" + tag + ">"
+}
+
+func hrefElement(href string) string {
+	return "<d:href>" + escapeXML(href) + "</d:href>"
+}
+
+func statusLine(code int) string {
+	return "<d:status>HTTP/1.1 " + strconv.Itoa(code) + " " + http.StatusText(code) + "</d:status>"
+}
+
+func escapeXML(value string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(value))
+
+	return b.String()
+}
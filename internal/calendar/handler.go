@@ -0,0 +1,297 @@
+package calendar
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"todo-app/internal/auth"
+	e "todo-app/pkg/errors"
+	"todo-app/pkg/handlers"
+	"todo-app/pkg/locale"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type endpointHandler struct {
+	logger  *zap.SugaredLogger
+	service Service
+	e       *echo.Echo
+}
+
+func GetEndpointHandler(
+	logger *zap.SugaredLogger,
+	service Service,
+	e *echo.Echo,
+) handlers.EndpointHandler {
+	return &endpointHandler{
+		logger:  logger,
+		service: service,
+		e:       e,
+	}
+}
+
+func (h *endpointHandler) AddEndpoints() {
+	var endpoints = []handlers.Endpoint{
+		{
+			Method:  http.MethodPost,
+			Path:    "/calendar/feed-token",
+			Handler: h.createFeedToken,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/calendar/feed-token",
+			Handler: h.revokeFeedToken,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/calendar/feed/:token",
+			Handler: h.feed,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/todos/import/ical",
+			Handler: h.importICal,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/calendar/app-passwords",
+			Handler: h.getAppPasswords,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/calendar/app-passwords",
+			Handler: h.createAppPassword,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/calendar/app-passwords/:id",
+			Handler: h.deleteAppPassword,
+		},
+	}
+	endpoints = append(endpoints, h.davEndpoints()...)
+
+	for _, endpoint := range endpoints {
+		handlers.Method(h.e, endpoint.Method, endpoint.Path, endpoint.Handler)
+	}
+}
+
+// @Summary Create iCal feed token
+// @Description This endpoint creates the secret token of the current user's iCal feed and returns the feed URL.
+// @Description Creating a new token replaces the previous one.
+// @Tags calendar
+// @ID createFeedToken
+// @Security BearerAuth
+// @Produce json
+// @Success 201 {object} FeedTokenResponse
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /calendar/feed-token [post]
+func (h *endpointHandler) createFeedToken(ctx echo.Context) error {
+	h.logger.Infow("creating feed token...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	token, err := h.service.CreateFeedToken(ctx.Request().Context(), userId)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorCouldNotSaveFeedToken, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusCreated, FeedTokenResponse{
+		Token: token,
+		URL:   ctx.Scheme() + "://" + ctx.Request().Host + "/calendar/feed/" + token,
+	})
+}
+
+// @Summary Revoke iCal feed token
+// @Description This endpoint deletes the feed token of the current user, the feed URL stops working
+// @Tags calendar
+// @ID revokeFeedToken
+// @Security BearerAuth
+// @Success 204
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /calendar/feed-token [delete]
+func (h *endpointHandler) revokeFeedToken(ctx echo.Context) error {
+	h.logger.Infow("revoking feed token...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	err := h.service.RevokeFeedToken(ctx.Request().Context(), userId)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorCouldNotSaveFeedToken, Details: err.Error()})
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// @Summary iCal feed
+// @Description This endpoint returns the todo items of the token's owner as iCalendar VTODO components.
+// @Description It needs no login so that calendar clients can subscribe to it, the token is the secret.
+// @Tags calendar
+// @ID feed
+// @Produce text/calendar
+// @Param token path string true "Feed token"
+// @Success 200 {string} string "iCalendar object"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /calendar/feed/{token} [get]
+func (h *endpointHandler) feed(ctx echo.Context) error {
+	h.logger.Infow("writing ical feed...")
+
+	// the whole feed is rendered first so that an unknown token can still get a 404
+	var body bytes.Buffer
+	err := h.service.WriteFeed(ctx.Request().Context(), ctx.Param("token"), &body)
+	if err != nil {
+		if err.Error() == locale.ErrorInvalidFeedToken {
+			return ctx.JSON(http.StatusNotFound, e.ResponseError{Message: locale.ErrorInvalidFeedToken})
+		}
+
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorInternalServer, Details: err.Error()})
+	}
+
+	ctx.Response().Header().Set(echo.HeaderCacheControl, "private, max-age=300")
+
+	return ctx.Blob(http.StatusOK, "text/calendar; charset=utf-8", body.Bytes())
+}
+
+// @Summary Import iCal todos
+// @Description This endpoint imports the VTODO components of an .ics file. Components are matched by their UID,
+// @Description so importing the same file twice updates the items instead of duplicating them.
+// @Tags calendar
+// @ID importICal
+// @Security BearerAuth
+// @Accept text/calendar
+// @Produce json
+// @Param dry_run query bool false "Only validate and report"
+// @Success 200 {object} transfer.ImportResult
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Router /todos/import/ical [post]
+func (h *endpointHandler) importICal(ctx echo.Context) error {
+	h.logger.Infow("importing ical todos...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	dryRun := false
+	if value := ctx.QueryParam("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+		}
+		dryRun = parsed
+	}
+
+	result, err := h.service.ImportICal(ctx.Request().Context(), userId, ctx.Request().Body, dryRun)
+	if err != nil {
+		h.logger.Warn("could not import ical todos", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidICal, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// @Summary Get app passwords
+// @Description This endpoint lists the app passwords of the current user, without the passwords themselves
+// @Tags calendar
+// @ID getAppPasswords
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} AppPassword
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /calendar/app-passwords [get]
+func (h *endpointHandler) getAppPasswords(ctx echo.Context) error {
+	h.logger.Infow("getting app passwords...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	passwords, err := h.service.GetAppPasswords(ctx.Request().Context(), userId)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorInternalServer, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, passwords)
+}
+
+// @Summary Create app password
+// @Description This endpoint creates a password for CalDAV clients, which log in with the account email and this password.
+// @Description The password is only returned once.
+// @Tags calendar
+// @ID createAppPassword
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body AppPasswordInput true "Name of the client"
+// @Success 201 {object} AppPasswordResponse
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Router /calendar/app-passwords [post]
+func (h *endpointHandler) createAppPassword(ctx echo.Context) error {
+	h.logger.Infow("creating app password...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	var input AppPasswordInput
+	if err := ctx.Bind(&input); err != nil {
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+	}
+
+	response, err := h.service.CreateAppPassword(ctx.Request().Context(), userId, input)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidAppPassword, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusCreated, response)
+}
+
+// @Summary Delete app password
+// @Description This endpoint deletes an app password of the current user, clients using it are logged out
+// @Tags calendar
+// @ID deleteAppPassword
+// @Security BearerAuth
+// @Param id path int true "App password ID"
+// @Success 204
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /calendar/app-passwords/{id} [delete]
+func (h *endpointHandler) deleteAppPassword(ctx echo.Context) error {
+	h.logger.Infow("deleting app password...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	id, err := handlers.GetUrlId(ctx, h.logger)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidID, Details: err.Error()})
+	}
+
+	err = h.service.DeleteAppPassword(ctx.Request().Context(), userId, id)
+	if err != nil {
+		if err.Error() == locale.ErrorNotFoundRecord {
+			return ctx.JSON(http.StatusNotFound, e.ResponseError{Message: locale.ErrorNotFoundRecord})
+		}
+
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorInternalServer, Details: err.Error()})
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
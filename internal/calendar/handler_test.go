@@ -0,0 +1,357 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"todo-app/internal/transfer"
+	"todo-app/pkg/locale"
+
+	localErr "todo-app/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestHandler_CreateFeedToken(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	req := httptest.NewRequest(http.MethodPost, "/calendar/feed-token", nil)
+	req.Host = "local.todo.com"
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.Set("user_id", uint(1))
+
+	mockService.
+		EXPECT().
+		CreateFeedToken(ctx.Request().Context(), uint(1)).
+		Return("abc", nil).
+		Times(1)
+
+	if assert.NoError(t, h.createFeedToken(ctx)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var response FeedTokenResponse
+		err := json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NoError(t, err)
+
+		assert.Equal(t, FeedTokenResponse{Token: "abc", URL: "http://local.todo.com/calendar/feed/abc"}, response)
+	}
+
+	ctrl.Finish()
+}
+
+func TestHandler_Feed(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	t.Run("feed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/calendar/feed/abc", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("token")
+		ctx.SetParamValues("abc")
+
+		mockService.
+			EXPECT().
+			WriteFeed(ctx.Request().Context(), "abc", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, w io.Writer) error {
+				_, err := io.WriteString(w, "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n")
+				return err
+			}).
+			Times(1)
+
+		if assert.NoError(t, h.feed(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "text/calendar; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+			assert.Equal(t, "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n", rec.Body.String())
+		}
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/calendar/feed/nope", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("token")
+		ctx.SetParamValues("nope")
+
+		mockService.
+			EXPECT().
+			WriteFeed(ctx.Request().Context(), "nope", gomock.Any()).
+			Return(errors.New(locale.ErrorInvalidFeedToken)).
+			Times(1)
+
+		if assert.NoError(t, h.feed(ctx)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+
+			var responseError localErr.ResponseError
+			err := json.Unmarshal(rec.Body.Bytes(), &responseError)
+			assert.NoError(t, err)
+
+			assert.Equal(t, locale.ErrorInvalidFeedToken, responseError.Message)
+		}
+	})
+
+	ctrl.Finish()
+}
+
+func TestHandler_ImportICal(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	t.Run("import", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/todos/import/ical?dry_run=true", strings.NewReader("BEGIN:VCALENDAR\r\n"))
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+
+		mockService.
+			EXPECT().
+			ImportICal(ctx.Request().Context(), uint(1), gomock.Any(), true).
+			Return(transfer.ImportResult{Created: 1}, nil).
+			Times(1)
+
+		if assert.NoError(t, h.importICal(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/todos/import/ical", strings.NewReader("nope"))
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+
+		mockService.
+			EXPECT().
+			ImportICal(ctx.Request().Context(), uint(1), gomock.Any(), false).
+			Return(transfer.ImportResult{}, errors.New("not an iCalendar object")).
+			Times(1)
+
+		if assert.NoError(t, h.importICal(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var responseError localErr.ResponseError
+			err := json.Unmarshal(rec.Body.Bytes(), &responseError)
+			assert.NoError(t, err)
+
+			assert.Equal(t, locale.ErrorInvalidICal, responseError.Message)
+		}
+	})
+
+	ctrl.Finish()
+}
+
+func TestHandler_Propfind(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	body := `<?xml version="1.0"?>
+<d:propfind xmlns:d="DAV:" xmlns:cs="http://calendarserver.org/ns/">
+  <d:prop><d:resourcetype/><d:getetag/><cs:getctag/></d:prop>
+</d:propfind>`
+	req := httptest.NewRequest(echo.PROPFIND, "/caldav/calendars/todos/", strings.NewReader(body))
+	req.Header.Set("Depth", "1")
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.Set("user_id", uint(1))
+
+	mockService.
+		EXPECT().
+		GetSyncToken(ctx.Request().Context(), uint(1)).
+		Return("urn:todo-app:sync:42", nil).
+		Times(1)
+
+	mockService.
+		EXPECT().
+		GetResources(ctx.Request().Context(), uint(1)).
+		Return([]Resource{{Name: "todo-1@todo-app.ics", ETag: `"1-42"`}}, nil).
+		Times(1)
+
+	if assert.NoError(t, h.propfind(ctx)) {
+		assert.Equal(t, http.StatusMultiStatus, rec.Code)
+		assert.Contains(t, rec.Body.String(), "<d:href>/caldav/calendars/todos/</d:href><d:propstat><d:prop><d:resourcetype><d:collection/><c:calendar/></d:resourcetype><cs:getctag>urn:todo-app:sync:42</cs:getctag></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>")
+		assert.Contains(t, rec.Body.String(), "<d:href>/caldav/calendars/todos/todo-1@todo-app.ics</d:href><d:propstat><d:prop><d:resourcetype/><d:getetag>&#34;1-42&#34;</d:getetag></d:prop>")
+	}
+
+	ctrl.Finish()
+}
+
+func TestHandler_Report(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	syncCollection := func(token string) string {
+		return `<d:sync-collection xmlns:d="DAV:"><d:sync-token>` + token + `</d:sync-token><d:sync-level>1</d:sync-level><d:prop><d:getetag/></d:prop></d:sync-collection>`
+	}
+
+	t.Run("sync collection", func(t *testing.T) {
+		req := httptest.NewRequest(echo.REPORT, "/caldav/calendars/todos/", strings.NewReader(syncCollection("urn:todo-app:sync:1")))
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+
+		mockService.
+			EXPECT().
+			GetChanges(ctx.Request().Context(), uint(1), "urn:todo-app:sync:1").
+			Return(Changes{
+				Resources: []Resource{{Name: "a.ics", ETag: `"1-2"`}},
+				Deleted:   []string{"b.ics"},
+				SyncToken: "urn:todo-app:sync:2",
+			}, nil).
+			Times(1)
+
+		if assert.NoError(t, h.report(ctx)) {
+			assert.Equal(t, http.StatusMultiStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), "<d:href>/caldav/calendars/todos/a.ics</d:href><d:propstat><d:prop><d:getetag>&#34;1-2&#34;</d:getetag></d:prop>")
+			assert.Contains(t, rec.Body.String(), "<d:response><d:href>/caldav/calendars/todos/b.ics</d:href><d:status>HTTP/1.1 404 Not Found</d:status></d:response>")
+			assert.Contains(t, rec.Body.String(), "<d:sync-token>urn:todo-app:sync:2</d:sync-token></d:multistatus>")
+		}
+	})
+
+	t.Run("invalid sync token", func(t *testing.T) {
+		req := httptest.NewRequest(echo.REPORT, "/caldav/calendars/todos/", strings.NewReader(syncCollection("nope")))
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+
+		mockService.
+			EXPECT().
+			GetChanges(ctx.Request().Context(), uint(1), "nope").
+			Return(Changes{}, errors.New(locale.ErrorInvalidSyncToken)).
+			Times(1)
+
+		if assert.NoError(t, h.report(ctx)) {
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+			assert.Contains(t, rec.Body.String(), "<d:valid-sync-token/>")
+		}
+	})
+
+	ctrl.Finish()
+}
+
+func TestHandler_PutResource(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	t.Run("created", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/caldav/calendars/todos/a.ics", strings.NewReader("BEGIN:VCALENDAR\r\n"))
+		req.Header.Set("If-None-Match", "*")
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+		ctx.SetParamNames("name")
+		ctx.SetParamValues("a.ics")
+
+		mockService.
+			EXPECT().
+			PutResource(ctx.Request().Context(), uint(1), "a.ics", gomock.Any(), Preconditions{IfNoneMatch: "*"}).
+			Return(Resource{Name: "a.ics", ETag: `"3-4"`}, true, nil).
+			Times(1)
+
+		if assert.NoError(t, h.putResource(ctx)) {
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Equal(t, `"3-4"`, rec.Header().Get("ETag"))
+		}
+	})
+
+	t.Run("precondition failed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/caldav/calendars/todos/a.ics", strings.NewReader("BEGIN:VCALENDAR\r\n"))
+		req.Header.Set("If-Match", `"3-1"`)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+		ctx.SetParamNames("name")
+		ctx.SetParamValues("a.ics")
+
+		mockService.
+			EXPECT().
+			PutResource(ctx.Request().Context(), uint(1), "a.ics", gomock.Any(), Preconditions{IfMatch: `"3-1"`}).
+			Return(Resource{}, false, errors.New(locale.ErrorPreconditionFailed)).
+			Times(1)
+
+		if assert.NoError(t, h.putResource(ctx)) {
+			assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+		}
+	})
+
+	ctrl.Finish()
+}
+
+func TestHandler_BasicAuth(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	next := func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, fmt.Sprint(ctx.Get("user_id")))
+	}
+
+	t.Run("app password", func(t *testing.T) {
+		req := httptest.NewRequest(echo.PROPFIND, "/caldav/", nil)
+		req.SetBasicAuth("ann@example.com", "secret")
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		mockService.
+			EXPECT().
+			Authenticate(ctx.Request().Context(), "ann@example.com", "secret").
+			Return(uint(5), nil).
+			Times(1)
+
+		if assert.NoError(t, h.basicAuth(next)(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "5", rec.Body.String())
+		}
+	})
+
+	t.Run("missing credentials", func(t *testing.T) {
+		req := httptest.NewRequest(echo.PROPFIND, "/caldav/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		if assert.NoError(t, h.basicAuth(next)(ctx)) {
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.Equal(t, `Basic realm="todo-app"`, rec.Header().Get(echo.HeaderWWWAuthenticate))
+		}
+	})
+
+	ctrl.Finish()
+}
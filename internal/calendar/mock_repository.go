@@ -0,0 +1,203 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/calendar/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/calendar/repository.go -destination=internal/calendar/mock_repository.go -package=calendar
+//
+
+// Package calendar is a generated GoMock package.
+package calendar
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+	todos "todo-app/internal/todos"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateAppPassword mocks base method.
+func (m *MockRepository) CreateAppPassword(ctx context.Context, password *AppPassword) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAppPassword", ctx, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAppPassword indicates an expected call of CreateAppPassword.
+func (mr *MockRepositoryMockRecorder) CreateAppPassword(ctx, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAppPassword", reflect.TypeOf((*MockRepository)(nil).CreateAppPassword), ctx, password)
+}
+
+// DeleteAppPassword mocks base method.
+func (m *MockRepository) DeleteAppPassword(ctx context.Context, userId, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAppPassword", ctx, userId, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAppPassword indicates an expected call of DeleteAppPassword.
+func (mr *MockRepositoryMockRecorder) DeleteAppPassword(ctx, userId, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAppPassword", reflect.TypeOf((*MockRepository)(nil).DeleteAppPassword), ctx, userId, id)
+}
+
+// DeleteFeedToken mocks base method.
+func (m *MockRepository) DeleteFeedToken(ctx context.Context, userId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFeedToken", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFeedToken indicates an expected call of DeleteFeedToken.
+func (mr *MockRepositoryMockRecorder) DeleteFeedToken(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFeedToken", reflect.TypeOf((*MockRepository)(nil).DeleteFeedToken), ctx, userId)
+}
+
+// GetAppPasswordByHash mocks base method.
+func (m *MockRepository) GetAppPasswordByHash(ctx context.Context, hash string) (AppPassword, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAppPasswordByHash", ctx, hash)
+	ret0, _ := ret[0].(AppPassword)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppPasswordByHash indicates an expected call of GetAppPasswordByHash.
+func (mr *MockRepositoryMockRecorder) GetAppPasswordByHash(ctx, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppPasswordByHash", reflect.TypeOf((*MockRepository)(nil).GetAppPasswordByHash), ctx, hash)
+}
+
+// GetAppPasswords mocks base method.
+func (m *MockRepository) GetAppPasswords(ctx context.Context, userId uint) ([]AppPassword, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAppPasswords", ctx, userId)
+	ret0, _ := ret[0].([]AppPassword)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppPasswords indicates an expected call of GetAppPasswords.
+func (mr *MockRepositoryMockRecorder) GetAppPasswords(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppPasswords", reflect.TypeOf((*MockRepository)(nil).GetAppPasswords), ctx, userId)
+}
+
+// GetChangedItems mocks base method.
+func (m *MockRepository) GetChangedItems(ctx context.Context, userId uint, since time.Time) ([]todos.ToDoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChangedItems", ctx, userId, since)
+	ret0, _ := ret[0].([]todos.ToDoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChangedItems indicates an expected call of GetChangedItems.
+func (mr *MockRepositoryMockRecorder) GetChangedItems(ctx, userId, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChangedItems", reflect.TypeOf((*MockRepository)(nil).GetChangedItems), ctx, userId, since)
+}
+
+// GetFeedTokenByHash mocks base method.
+func (m *MockRepository) GetFeedTokenByHash(ctx context.Context, hash string) (FeedToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeedTokenByHash", ctx, hash)
+	ret0, _ := ret[0].(FeedToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeedTokenByHash indicates an expected call of GetFeedTokenByHash.
+func (mr *MockRepositoryMockRecorder) GetFeedTokenByHash(ctx, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeedTokenByHash", reflect.TypeOf((*MockRepository)(nil).GetFeedTokenByHash), ctx, hash)
+}
+
+// GetItemByExternalId mocks base method.
+func (m *MockRepository) GetItemByExternalId(ctx context.Context, userId uint, externalId string) (todos.ToDoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItemByExternalId", ctx, userId, externalId)
+	ret0, _ := ret[0].(todos.ToDoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItemByExternalId indicates an expected call of GetItemByExternalId.
+func (mr *MockRepositoryMockRecorder) GetItemByExternalId(ctx, userId, externalId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemByExternalId", reflect.TypeOf((*MockRepository)(nil).GetItemByExternalId), ctx, userId, externalId)
+}
+
+// GetLastChange mocks base method.
+func (m *MockRepository) GetLastChange(ctx context.Context, userId uint) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastChange", ctx, userId)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastChange indicates an expected call of GetLastChange.
+func (mr *MockRepositoryMockRecorder) GetLastChange(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastChange", reflect.TypeOf((*MockRepository)(nil).GetLastChange), ctx, userId)
+}
+
+// SaveFeedToken mocks base method.
+func (m *MockRepository) SaveFeedToken(ctx context.Context, token *FeedToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveFeedToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveFeedToken indicates an expected call of SaveFeedToken.
+func (mr *MockRepositoryMockRecorder) SaveFeedToken(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFeedToken", reflect.TypeOf((*MockRepository)(nil).SaveFeedToken), ctx, token)
+}
+
+// TouchAppPassword mocks base method.
+func (m *MockRepository) TouchAppPassword(ctx context.Context, id uint, usedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAppPassword", ctx, id, usedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAppPassword indicates an expected call of TouchAppPassword.
+func (mr *MockRepositoryMockRecorder) TouchAppPassword(ctx, id, usedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAppPassword", reflect.TypeOf((*MockRepository)(nil).TouchAppPassword), ctx, id, usedAt)
+}
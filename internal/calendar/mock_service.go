@@ -0,0 +1,250 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/calendar/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/calendar/service.go -destination=internal/calendar/mock_service.go -package=calendar
+//
+
+// Package calendar is a generated GoMock package.
+package calendar
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+	transfer "todo-app/internal/transfer"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockService) Authenticate(ctx context.Context, email, password string) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, email, password)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockServiceMockRecorder) Authenticate(ctx, email, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockService)(nil).Authenticate), ctx, email, password)
+}
+
+// CreateAppPassword mocks base method.
+func (m *MockService) CreateAppPassword(ctx context.Context, userId uint, input AppPasswordInput) (AppPasswordResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAppPassword", ctx, userId, input)
+	ret0, _ := ret[0].(AppPasswordResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAppPassword indicates an expected call of CreateAppPassword.
+func (mr *MockServiceMockRecorder) CreateAppPassword(ctx, userId, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAppPassword", reflect.TypeOf((*MockService)(nil).CreateAppPassword), ctx, userId, input)
+}
+
+// CreateFeedToken mocks base method.
+func (m *MockService) CreateFeedToken(ctx context.Context, userId uint) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFeedToken", ctx, userId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFeedToken indicates an expected call of CreateFeedToken.
+func (mr *MockServiceMockRecorder) CreateFeedToken(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFeedToken", reflect.TypeOf((*MockService)(nil).CreateFeedToken), ctx, userId)
+}
+
+// DeleteAppPassword mocks base method.
+func (m *MockService) DeleteAppPassword(ctx context.Context, userId, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAppPassword", ctx, userId, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAppPassword indicates an expected call of DeleteAppPassword.
+func (mr *MockServiceMockRecorder) DeleteAppPassword(ctx, userId, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAppPassword", reflect.TypeOf((*MockService)(nil).DeleteAppPassword), ctx, userId, id)
+}
+
+// DeleteResource mocks base method.
+func (m *MockService) DeleteResource(ctx context.Context, userId uint, name string, conditions Preconditions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteResource", ctx, userId, name, conditions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteResource indicates an expected call of DeleteResource.
+func (mr *MockServiceMockRecorder) DeleteResource(ctx, userId, name, conditions any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteResource", reflect.TypeOf((*MockService)(nil).DeleteResource), ctx, userId, name, conditions)
+}
+
+// GetAppPasswords mocks base method.
+func (m *MockService) GetAppPasswords(ctx context.Context, userId uint) ([]AppPassword, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAppPasswords", ctx, userId)
+	ret0, _ := ret[0].([]AppPassword)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppPasswords indicates an expected call of GetAppPasswords.
+func (mr *MockServiceMockRecorder) GetAppPasswords(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppPasswords", reflect.TypeOf((*MockService)(nil).GetAppPasswords), ctx, userId)
+}
+
+// GetChanges mocks base method.
+func (m *MockService) GetChanges(ctx context.Context, userId uint, syncToken string) (Changes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChanges", ctx, userId, syncToken)
+	ret0, _ := ret[0].(Changes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChanges indicates an expected call of GetChanges.
+func (mr *MockServiceMockRecorder) GetChanges(ctx, userId, syncToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChanges", reflect.TypeOf((*MockService)(nil).GetChanges), ctx, userId, syncToken)
+}
+
+// GetResource mocks base method.
+func (m *MockService) GetResource(ctx context.Context, userId uint, name string) (Resource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetResource", ctx, userId, name)
+	ret0, _ := ret[0].(Resource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetResource indicates an expected call of GetResource.
+func (mr *MockServiceMockRecorder) GetResource(ctx, userId, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResource", reflect.TypeOf((*MockService)(nil).GetResource), ctx, userId, name)
+}
+
+// GetResources mocks base method.
+func (m *MockService) GetResources(ctx context.Context, userId uint) ([]Resource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetResources", ctx, userId)
+	ret0, _ := ret[0].([]Resource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetResources indicates an expected call of GetResources.
+func (mr *MockServiceMockRecorder) GetResources(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResources", reflect.TypeOf((*MockService)(nil).GetResources), ctx, userId)
+}
+
+// GetSyncToken mocks base method.
+func (m *MockService) GetSyncToken(ctx context.Context, userId uint) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSyncToken", ctx, userId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSyncToken indicates an expected call of GetSyncToken.
+func (mr *MockServiceMockRecorder) GetSyncToken(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSyncToken", reflect.TypeOf((*MockService)(nil).GetSyncToken), ctx, userId)
+}
+
+// ImportICal mocks base method.
+func (m *MockService) ImportICal(ctx context.Context, userId uint, r io.Reader, dryRun bool) (transfer.ImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportICal", ctx, userId, r, dryRun)
+	ret0, _ := ret[0].(transfer.ImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportICal indicates an expected call of ImportICal.
+func (mr *MockServiceMockRecorder) ImportICal(ctx, userId, r, dryRun any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportICal", reflect.TypeOf((*MockService)(nil).ImportICal), ctx, userId, r, dryRun)
+}
+
+// PutResource mocks base method.
+func (m *MockService) PutResource(ctx context.Context, userId uint, name string, r io.Reader, conditions Preconditions) (Resource, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutResource", ctx, userId, name, r, conditions)
+	ret0, _ := ret[0].(Resource)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// PutResource indicates an expected call of PutResource.
+func (mr *MockServiceMockRecorder) PutResource(ctx, userId, name, r, conditions any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutResource", reflect.TypeOf((*MockService)(nil).PutResource), ctx, userId, name, r, conditions)
+}
+
+// RevokeFeedToken mocks base method.
+func (m *MockService) RevokeFeedToken(ctx context.Context, userId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeFeedToken", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeFeedToken indicates an expected call of RevokeFeedToken.
+func (mr *MockServiceMockRecorder) RevokeFeedToken(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeFeedToken", reflect.TypeOf((*MockService)(nil).RevokeFeedToken), ctx, userId)
+}
+
+// WriteFeed mocks base method.
+func (m *MockService) WriteFeed(ctx context.Context, token string, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteFeed", ctx, token, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteFeed indicates an expected call of WriteFeed.
+func (mr *MockServiceMockRecorder) WriteFeed(ctx, token, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteFeed", reflect.TypeOf((*MockService)(nil).WriteFeed), ctx, token, w)
+}
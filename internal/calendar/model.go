@@ -0,0 +1,63 @@
+package calendar
+
+import (
+	"time"
+	"todo-app/pkg/ical"
+)
+
+// FeedToken : secret that gives read access to a user's iCal feed. Only its SHA-256
+// hash is stored, a user has at most one token.
+type FeedToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserId    uint      `gorm:"not null;uniqueIndex"`
+	TokenHash string    `gorm:"type:char(64);not null;uniqueIndex"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// FeedTokenResponse : the token is only shown once, when it is created
+type FeedTokenResponse struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// AppPassword : password for clients that cannot log in with a JWT, like CalDAV
+// clients. Only its SHA-256 hash is stored.
+type AppPassword struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserId       uint       `gorm:"not null;index" json:"-"`
+	Name         string     `gorm:"type:varchar(100);not null" json:"name"`
+	PasswordHash string     `gorm:"type:char(64);not null;uniqueIndex" json:"-"`
+	LastUsedAt   *time.Time `json:"last_used_at"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+type AppPasswordInput struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// AppPasswordResponse : the password is only shown once, when it is created
+type AppPasswordResponse struct {
+	AppPassword
+	Password string `json:"password"`
+}
+
+// Resource : a task as a CalDAV calendar object resource, named after its UID
+type Resource struct {
+	Name         string
+	ETag         string
+	LastModified time.Time
+	Todo         ical.Todo
+}
+
+// Changes : the resources changed and the names of those deleted since a sync token
+type Changes struct {
+	Resources []Resource
+	Deleted   []string
+	SyncToken string
+}
+
+// Preconditions : the If-Match and If-None-Match headers of a request
+type Preconditions struct {
+	IfMatch     string
+	IfNoneMatch string
+}
@@ -0,0 +1,184 @@
+package calendar
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"todo-app/internal/todos"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	SaveFeedToken(ctx context.Context, token *FeedToken) error
+	GetFeedTokenByHash(ctx context.Context, hash string) (FeedToken, error)
+	DeleteFeedToken(ctx context.Context, userId uint) error
+	CreateAppPassword(ctx context.Context, password *AppPassword) error
+	GetAppPasswords(ctx context.Context, userId uint) ([]AppPassword, error)
+	GetAppPasswordByHash(ctx context.Context, hash string) (AppPassword, error)
+	TouchAppPassword(ctx context.Context, id uint, usedAt time.Time) error
+	DeleteAppPassword(ctx context.Context, userId uint, id uint) error
+	GetItemByExternalId(ctx context.Context, userId uint, externalId string) (todos.ToDoItem, error)
+	GetChangedItems(ctx context.Context, userId uint, since time.Time) ([]todos.ToDoItem, error)
+	GetLastChange(ctx context.Context, userId uint) (time.Time, error)
+}
+
+type repository struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func GetRepository(logger *zap.SugaredLogger, db *gorm.DB) Repository {
+	return &repository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// SaveFeedToken : stores the token, replacing the user's previous one
+func (r *repository) SaveFeedToken(ctx context.Context, token *FeedToken) error {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"token_hash", "created_at"}),
+		}).
+		Create(token)
+	if result.Error != nil {
+		r.logger.Errorw("failed to save feed token", "user_id", token.UserId, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) GetFeedTokenByHash(ctx context.Context, hash string) (FeedToken, error) {
+	var token FeedToken
+	result := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&token)
+	if result.Error != nil {
+		return FeedToken{}, result.Error
+	}
+
+	return token, nil
+}
+
+func (r *repository) DeleteFeedToken(ctx context.Context, userId uint) error {
+	result := r.db.WithContext(ctx).Where("user_id = ?", userId).Delete(&FeedToken{})
+	if result.Error != nil {
+		r.logger.Errorw("failed to delete feed token", "user_id", userId, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) CreateAppPassword(ctx context.Context, password *AppPassword) error {
+	result := r.db.WithContext(ctx).Create(password)
+	if result.Error != nil {
+		r.logger.Errorw("failed to create app password", "user_id", password.UserId, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) GetAppPasswords(ctx context.Context, userId uint) ([]AppPassword, error) {
+	var passwords []AppPassword
+	result := r.db.WithContext(ctx).Where("user_id = ?", userId).Order("id").Find(&passwords)
+	if result.Error != nil {
+		r.logger.Errorw("failed to get app passwords", "user_id", userId, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return passwords, nil
+}
+
+func (r *repository) GetAppPasswordByHash(ctx context.Context, hash string) (AppPassword, error) {
+	var password AppPassword
+	result := r.db.WithContext(ctx).Where("password_hash = ?", hash).First(&password)
+	if result.Error != nil {
+		return AppPassword{}, result.Error
+	}
+
+	return password, nil
+}
+
+func (r *repository) TouchAppPassword(ctx context.Context, id uint, usedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&AppPassword{}).Where("id = ?", id).Update("last_used_at", usedAt)
+	if result.Error != nil {
+		r.logger.Errorw("failed to update app password", "id", id, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) DeleteAppPassword(ctx context.Context, userId uint, id uint) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userId).Delete(&AppPassword{})
+	if result.Error != nil {
+		r.logger.Errorw("failed to delete app password", "id", id, "error", result.Error)
+
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) GetItemByExternalId(ctx context.Context, userId uint, externalId string) (todos.ToDoItem, error) {
+	var item todos.ToDoItem
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND external_id = ?", userId, externalId).
+		Preload("Tags").
+		First(&item)
+	if result.Error != nil {
+		return todos.ToDoItem{}, result.Error
+	}
+
+	return item, nil
+}
+
+// GetChangedItems : tasks updated or deleted after since, deleted ones included
+func (r *repository) GetChangedItems(ctx context.Context, userId uint, since time.Time) ([]todos.ToDoItem, error) {
+	var items []todos.ToDoItem
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Where("user_id = ? AND type <> ?", userId, todos.TypeHabit).
+		Where("updated_at > ? OR deleted_at > ?", since, since).
+		Preload("Tags").
+		Find(&items)
+	if result.Error != nil {
+		r.logger.Errorw("failed to get changed todo items", "user_id", userId, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return items, nil
+}
+
+// GetLastChange : when a task of the user was last updated or deleted, zero without tasks
+func (r *repository) GetLastChange(ctx context.Context, userId uint) (time.Time, error) {
+	var lastChange sql.NullTime
+	err := r.db.WithContext(ctx).
+		Unscoped().
+		Model(&todos.ToDoItem{}).
+		Select("MAX(GREATEST(updated_at, COALESCE(deleted_at, updated_at)))").
+		Where("user_id = ? AND type <> ?", userId, todos.TypeHabit).
+		Row().
+		Scan(&lastChange)
+	if err != nil {
+		r.logger.Errorw("failed to get last todo change", "user_id", userId, "error", err)
+
+		return time.Time{}, err
+	}
+
+	return lastChange.Time, nil
+}
@@ -0,0 +1,518 @@
+package calendar
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/todos"
+	"todo-app/internal/transfer"
+	"todo-app/internal/users"
+	"todo-app/pkg/authz"
+	"todo-app/pkg/ical"
+	"todo-app/pkg/locale"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	prodID = "-//todo-app//todos//EN"
+	// syncTokenPrefix : sync tokens are URIs, the part after the prefix is the time of
+	// the last change in unix milliseconds
+	syncTokenPrefix = "urn:todo-app:sync:"
+	// resourceSuffix : CalDAV resources are named after the UID of their todo
+	resourceSuffix = ".ics"
+)
+
+type Service interface {
+	CreateFeedToken(ctx context.Context, userId uint) (string, error)
+	RevokeFeedToken(ctx context.Context, userId uint) error
+	WriteFeed(ctx context.Context, token string, w io.Writer) error
+	ImportICal(ctx context.Context, userId uint, r io.Reader, dryRun bool) (transfer.ImportResult, error)
+	CreateAppPassword(ctx context.Context, userId uint, input AppPasswordInput) (AppPasswordResponse, error)
+	GetAppPasswords(ctx context.Context, userId uint) ([]AppPassword, error)
+	DeleteAppPassword(ctx context.Context, userId uint, id uint) error
+	Authenticate(ctx context.Context, email string, password string) (uint, error)
+	GetSyncToken(ctx context.Context, userId uint) (string, error)
+	GetResources(ctx context.Context, userId uint) ([]Resource, error)
+	GetResource(ctx context.Context, userId uint, name string) (Resource, error)
+	PutResource(ctx context.Context, userId uint, name string, r io.Reader, conditions Preconditions) (Resource, bool, error)
+	DeleteResource(ctx context.Context, userId uint, name string, conditions Preconditions) error
+	GetChanges(ctx context.Context, userId uint, syncToken string) (Changes, error)
+}
+
+type service struct {
+	logger          *zap.SugaredLogger
+	repository      Repository
+	userRepository  users.Repository
+	todoService     todos.Service
+	transferService transfer.Service
+	validator       *validator.Validate
+	now             func() time.Time
+}
+
+func GetService(
+	logger *zap.SugaredLogger,
+	repo Repository,
+	userRepo users.Repository,
+	todoService todos.Service,
+	transferService transfer.Service,
+	validator *validator.Validate,
+) Service {
+	return &service{
+		logger:          logger,
+		repository:      repo,
+		userRepository:  userRepo,
+		todoService:     todoService,
+		transferService: transferService,
+		validator:       validator,
+		now:             time.Now,
+	}
+}
+
+// CreateFeedToken : creates a new feed token for the user, the previous one stops working
+func (s *service) CreateFeedToken(ctx context.Context, userId uint) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(secret)
+
+	err := s.repository.SaveFeedToken(ctx, &FeedToken{UserId: userId, TokenHash: hashToken(token), CreatedAt: time.Now()})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (s *service) RevokeFeedToken(ctx context.Context, userId uint) error {
+	return s.repository.DeleteFeedToken(ctx, userId)
+}
+
+// WriteFeed : writes the tasks of the token's owner as VTODO components, habits are
+// left out since they have no single due date or completion
+func (s *service) WriteFeed(ctx context.Context, token string, w io.Writer) error {
+	feedToken, err := s.repository.GetFeedTokenByHash(ctx, hashToken(token))
+	if err != nil {
+		return errors.New(locale.ErrorInvalidFeedToken)
+	}
+
+	items, _, err := s.todoService.GetAllForUser(ctx, feedToken.UserId, todos.PaginationDetails{})
+	if err != nil {
+		return err
+	}
+
+	cal := ical.Calendar{ProdID: prodID, Name: "Todos"}
+	for _, item := range items {
+		if item.Type == todos.TypeHabit {
+			continue
+		}
+		cal.Todos = append(cal.Todos, todoFromItem(item))
+	}
+
+	return ical.Encode(w, cal)
+}
+
+// ImportICal : imports the VTODO components of an .ics file, matched to existing items
+// by their UID so that importing a file again updates instead of duplicating
+func (s *service) ImportICal(ctx context.Context, userId uint, r io.Reader, dryRun bool) (transfer.ImportResult, error) {
+	cal, err := ical.Decode(r)
+	if err != nil {
+		return transfer.ImportResult{}, err
+	}
+
+	records := make([]transfer.Record, 0, len(cal.Todos))
+	for _, todo := range cal.Todos {
+		records = append(records, recordFromTodo(todo))
+	}
+
+	return s.transferService.ImportRecords(ctx, userId, records, transfer.ImportOptions{Mode: transfer.ModeUpsert, DryRun: dryRun})
+}
+
+func (s *service) CreateAppPassword(ctx context.Context, userId uint, input AppPasswordInput) (AppPasswordResponse, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return AppPasswordResponse{}, err
+	}
+
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		return AppPasswordResponse{}, err
+	}
+	password := hex.EncodeToString(secret)
+
+	appPassword := AppPassword{UserId: userId, Name: input.Name, PasswordHash: hashToken(password)}
+	if err := s.repository.CreateAppPassword(ctx, &appPassword); err != nil {
+		return AppPasswordResponse{}, err
+	}
+
+	return AppPasswordResponse{AppPassword: appPassword, Password: password}, nil
+}
+
+func (s *service) GetAppPasswords(ctx context.Context, userId uint) ([]AppPassword, error) {
+	return s.repository.GetAppPasswords(ctx, userId)
+}
+
+func (s *service) DeleteAppPassword(ctx context.Context, userId uint, id uint) error {
+	err := s.repository.DeleteAppPassword(ctx, userId, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New(locale.ErrorNotFoundRecord)
+	}
+
+	return err
+}
+
+// Authenticate : the id of the user with the email, if password is one of their app
+// passwords. Account passwords are never accepted here.
+func (s *service) Authenticate(ctx context.Context, email string, password string) (uint, error) {
+	user, err := s.userRepository.GetByEmail(ctx, strings.ToLower(email))
+	if err != nil {
+		return 0, errors.New(locale.ErrorInvalidCredentials)
+	}
+
+	appPassword, err := s.repository.GetAppPasswordByHash(ctx, hashToken(password))
+	if err != nil || appPassword.UserId != user.ID {
+		return 0, errors.New(locale.ErrorInvalidCredentials)
+	}
+
+	if err := s.repository.TouchAppPassword(ctx, appPassword.ID, s.now()); err != nil {
+		s.logger.Warnw("could not update app password", "id", appPassword.ID, "error", err)
+	}
+
+	return user.ID, nil
+}
+
+// GetSyncToken : changes whenever a task of the user is updated or deleted, used as
+// the collection's sync token and ctag
+func (s *service) GetSyncToken(ctx context.Context, userId uint) (string, error) {
+	lastChange, err := s.repository.GetLastChange(ctx, userId)
+	if err != nil {
+		return "", err
+	}
+
+	return syncToken(lastChange), nil
+}
+
+func (s *service) GetResources(ctx context.Context, userId uint) ([]Resource, error) {
+	items, _, err := s.todoService.GetAllForUser(ctx, userId, todos.PaginationDetails{})
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(items))
+	for _, item := range items {
+		if item.Type == todos.TypeHabit {
+			continue
+		}
+		resources = append(resources, resourceFromItem(item))
+	}
+
+	return resources, nil
+}
+
+func (s *service) GetResource(ctx context.Context, userId uint, name string) (Resource, error) {
+	item, err := s.findItem(ctx, userId, name, authz.ActionRead)
+	if err != nil {
+		return Resource{}, err
+	}
+	if item.Type == todos.TypeHabit {
+		return Resource{}, errors.New(locale.ErrorNotFoundRecord)
+	}
+
+	return resourceFromItem(item), nil
+}
+
+// PutResource : creates or replaces the task stored under name, reporting whether it
+// was created. The VTODO's UID has to match the name.
+func (s *service) PutResource(ctx context.Context, userId uint, name string, r io.Reader, conditions Preconditions) (Resource, bool, error) {
+	cal, err := ical.Decode(r)
+	if err != nil {
+		s.logger.Warnw("could not decode resource", "name", name, "error", err)
+
+		return Resource{}, false, errors.New(locale.ErrorInvalidICal)
+	}
+	if len(cal.Todos) != 1 || cal.Todos[0].UID+resourceSuffix != name {
+		s.logger.Warnw("resource needs one VTODO with the UID of its name", "name", name, "todos", len(cal.Todos))
+
+		return Resource{}, false, errors.New(locale.ErrorInvalidICal)
+	}
+
+	existing, err := s.findItem(ctx, userId, name, authz.ActionUpdate)
+	found := err == nil
+	if err != nil && err.Error() != locale.ErrorNotFoundRecord {
+		return Resource{}, false, err
+	}
+	if found && existing.Type == todos.TypeHabit {
+		return Resource{}, false, errors.New(locale.ErrorNotATask)
+	}
+
+	etag := ""
+	if found {
+		etag = resourceFromItem(existing).ETag
+	}
+	if !conditions.met(etag) {
+		return Resource{}, false, errors.New(locale.ErrorPreconditionFailed)
+	}
+
+	record := recordFromTodo(cal.Todos[0])
+	if found {
+		updated, err := s.todoService.UpdateById(ctx, existing.ID, record.UpdateInput())
+		if err != nil {
+			s.logger.Warnw("could not update resource", "name", name, "error", err)
+
+			return Resource{}, false, errors.New(locale.ErrorInvalidICal)
+		}
+
+		return resourceFromItem(updated), false, nil
+	}
+
+	// new resources go through the upsert import, which also brings back a deleted
+	// task with the same UID
+	result, err := s.transferService.ImportRecords(ctx, userId, []transfer.Record{record}, transfer.ImportOptions{Mode: transfer.ModeUpsert})
+	if err != nil {
+		return Resource{}, false, err
+	}
+	if len(result.Errors) > 0 {
+		s.logger.Warnw("could not create resource", "name", name, "error", result.Errors[0].Error)
+
+		return Resource{}, false, errors.New(locale.ErrorInvalidICal)
+	}
+
+	created, err := s.GetResource(ctx, userId, name)
+	if err != nil {
+		return Resource{}, false, err
+	}
+
+	return created, true, nil
+}
+
+func (s *service) DeleteResource(ctx context.Context, userId uint, name string, conditions Preconditions) error {
+	item, err := s.findItem(ctx, userId, name, authz.ActionDelete)
+	if err != nil {
+		return err
+	}
+	if item.Type == todos.TypeHabit {
+		return errors.New(locale.ErrorNotFoundRecord)
+	}
+	if !conditions.met(resourceFromItem(item).ETag) {
+		return errors.New(locale.ErrorPreconditionFailed)
+	}
+
+	return s.todoService.DeleteById(ctx, item.ID)
+}
+
+// GetChanges : the tasks changed and deleted since the sync token, all tasks for an
+// empty token
+func (s *service) GetChanges(ctx context.Context, userId uint, token string) (Changes, error) {
+	// the new token is read first, so that changes made while listing are sent again
+	// rather than missed
+	newToken, err := s.GetSyncToken(ctx, userId)
+	if err != nil {
+		return Changes{}, err
+	}
+
+	if token == "" {
+		resources, err := s.GetResources(ctx, userId)
+		if err != nil {
+			return Changes{}, err
+		}
+
+		return Changes{Resources: resources, Deleted: []string{}, SyncToken: newToken}, nil
+	}
+
+	since, err := parseSyncToken(token)
+	if err != nil {
+		return Changes{}, errors.New(locale.ErrorInvalidSyncToken)
+	}
+
+	items, err := s.repository.GetChangedItems(ctx, userId, since)
+	if err != nil {
+		return Changes{}, err
+	}
+
+	changes := Changes{Resources: []Resource{}, Deleted: []string{}, SyncToken: newToken}
+	for _, item := range items {
+		resource := resourceFromItem(item)
+		if item.DeletedAt.Valid {
+			changes.Deleted = append(changes.Deleted, resource.Name)
+			continue
+		}
+		changes.Resources = append(changes.Resources, resource)
+	}
+
+	return changes, nil
+}
+
+// findItem : the item stored under a resource name, the name is either an external id
+// or the UID generated for items without one
+func (s *service) findItem(ctx context.Context, userId uint, name string, action authz.Action) (todos.ToDoItem, error) {
+	uid, ok := strings.CutSuffix(name, resourceSuffix)
+	if !ok {
+		return todos.ToDoItem{}, errors.New(locale.ErrorNotFoundRecord)
+	}
+
+	item, err := s.repository.GetItemByExternalId(ctx, userId, uid)
+	if err == nil {
+		return item, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return todos.ToDoItem{}, err
+	}
+
+	var id uint
+	if _, err := fmt.Sscanf(uid, "todo-%d@todo-app", &id); err != nil || generatedUID(id) != uid {
+		return todos.ToDoItem{}, errors.New(locale.ErrorNotFoundRecord)
+	}
+	item, err = s.todoService.GetById(ctx, id)
+	if err != nil || item.ExternalId != nil {
+		return todos.ToDoItem{}, errors.New(locale.ErrorNotFoundRecord)
+	}
+	if err := authz.Authorize(todos.ItemPolicy, userId, action, item); err != nil {
+		return todos.ToDoItem{}, err
+	}
+
+	return item, nil
+}
+
+// met : whether the If-Match and If-None-Match conditions hold for a resource with
+// etag, an empty etag meaning that the resource does not exist
+func (p Preconditions) met(etag string) bool {
+	if p.IfMatch != "" && (etag == "" || (p.IfMatch != "*" && p.IfMatch != etag)) {
+		return false
+	}
+	if p.IfNoneMatch != "" && etag != "" && (p.IfNoneMatch == "*" || p.IfNoneMatch == etag) {
+		return false
+	}
+
+	return true
+}
+
+func resourceFromItem(item todos.ToDoItem) Resource {
+	todo := todoFromItem(item)
+
+	return Resource{
+		Name:         todo.UID + resourceSuffix,
+		ETag:         fmt.Sprintf(`"%d-%d"`, item.ID, item.UpdatedAt.UnixMilli()),
+		LastModified: item.UpdatedAt,
+		Todo:         todo,
+	}
+}
+
+func syncToken(lastChange time.Time) string {
+	millis := int64(0)
+	if !lastChange.IsZero() {
+		millis = lastChange.UnixMilli()
+	}
+
+	return syncTokenPrefix + strconv.FormatInt(millis, 10)
+}
+
+func parseSyncToken(token string) (time.Time, error) {
+	value, ok := strings.CutPrefix(token, syncTokenPrefix)
+	if !ok {
+		return time.Time{}, errors.New("unknown sync token")
+	}
+	millis, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.UnixMilli(millis).UTC(), nil
+}
+
+func generatedUID(id uint) string {
+	return fmt.Sprintf("todo-%d@todo-app", id)
+}
+
+func todoFromItem(item todos.ToDoItem) ical.Todo {
+	created := item.CreatedAt
+	modified := item.UpdatedAt
+	todo := ical.Todo{
+		UID:          generatedUID(item.ID),
+		Summary:      item.Text,
+		Status:       ical.StatusNeedsAction,
+		Priority:     icalPriority(item.Priority),
+		Due:          item.DueAt,
+		Created:      &created,
+		LastModified: &modified,
+		Stamp:        modified,
+		RRule:        item.Recurrence,
+	}
+	if item.ExternalId != nil {
+		todo.UID = *item.ExternalId
+	}
+	if item.Done {
+		todo.Status = ical.StatusCompleted
+		todo.Completed = item.CompletedAt
+	}
+	for _, tag := range item.Tags {
+		todo.Categories = append(todo.Categories, tag.Name)
+	}
+
+	return todo
+}
+
+func recordFromTodo(todo ical.Todo) transfer.Record {
+	record := transfer.Record{
+		ExternalId: todo.UID,
+		Text:       todo.Summary,
+		Done:       todo.Status == ical.StatusCompleted,
+		Priority:   itemPriority(todo.Priority),
+		Recurrence: todo.RRule,
+		Tags:       todo.Categories,
+	}
+	if record.Done {
+		record.CompletedAt = todo.Completed
+	}
+	if todo.Due != nil {
+		due := *todo.Due
+		if todo.DueAllDay {
+			// a date without a time is due at the end of that day, like in quick-add
+			due = due.Add(24*time.Hour - time.Second)
+		}
+		record.DueAt = &due
+	}
+
+	return record
+}
+
+// icalPriority : maps priorities to the RFC 5545 scale, where 1 is the highest
+func icalPriority(priority string) int {
+	switch priority {
+	case todos.PriorityHigh:
+		return 1
+	case todos.PriorityMedium:
+		return 5
+	case todos.PriorityLow:
+		return 9
+	}
+
+	return 0
+}
+
+func itemPriority(priority int) string {
+	switch {
+	case priority >= 1 && priority <= 4:
+		return todos.PriorityHigh
+	case priority == 5:
+		return todos.PriorityMedium
+	case priority >= 6 && priority <= 9:
+		return todos.PriorityLow
+	}
+
+	return ""
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
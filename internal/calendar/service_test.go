@@ -0,0 +1,411 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/todos"
+	"todo-app/internal/transfer"
+	"todo-app/internal/users"
+	"todo-app/pkg/ical"
+	"todo-app/pkg/locale"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestService_CreateFeedToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockRepo, users.NewMockRepository(ctrl), todos.NewMockService(ctrl), transfer.NewMockService(ctrl), validator.New())
+	ctx := context.Background()
+
+	t.Run("only the hash is stored", func(t *testing.T) {
+		var saved *FeedToken
+		mockRepo.
+			EXPECT().
+			SaveFeedToken(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, token *FeedToken) error {
+				saved = token
+				return nil
+			}).
+			Times(1)
+
+		token, err := service.CreateFeedToken(ctx, 4)
+		assert.NoError(t, err)
+		assert.Len(t, token, 64)
+		assert.Equal(t, uint(4), saved.UserId)
+		assert.Equal(t, hashToken(token), saved.TokenHash)
+		assert.NotEqual(t, token, saved.TokenHash)
+	})
+
+	t.Run("save fails", func(t *testing.T) {
+		mockRepo.
+			EXPECT().
+			SaveFeedToken(ctx, gomock.Any()).
+			Return(errors.New("db down")).
+			Times(1)
+
+		_, err := service.CreateFeedToken(ctx, 4)
+		assert.Error(t, err)
+	})
+
+	ctrl.Finish()
+}
+
+func TestService_WriteFeed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	mockTodoService := todos.NewMockService(ctrl)
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockRepo, users.NewMockRepository(ctrl), mockTodoService, transfer.NewMockService(ctrl), validator.New())
+	ctx := context.Background()
+
+	created := time.Date(2025, time.March, 1, 9, 0, 0, 0, time.UTC)
+	updated := time.Date(2025, time.March, 2, 10, 30, 0, 0, time.UTC)
+	due := time.Date(2025, time.March, 5, 17, 0, 0, 0, time.UTC)
+	externalId := "abc-123"
+
+	t.Run("feed round trip", func(t *testing.T) {
+		mockRepo.
+			EXPECT().
+			GetFeedTokenByHash(ctx, hashToken("secret")).
+			Return(FeedToken{UserId: 2}, nil).
+			Times(1)
+
+		mockTodoService.
+			EXPECT().
+			GetAllForUser(ctx, uint(2), todos.PaginationDetails{}).
+			Return([]todos.ToDoItem{
+				{
+					Model:      gorm.Model{ID: 1, CreatedAt: created, UpdatedAt: updated},
+					Text:       "Pay rent, on time",
+					Priority:   todos.PriorityHigh,
+					DueAt:      &due,
+					Recurrence: "FREQ=MONTHLY;BYMONTHDAY=5",
+					Tags:       []todos.Tag{{Name: "home"}},
+				},
+				{
+					Model:       gorm.Model{ID: 2, CreatedAt: created, UpdatedAt: updated},
+					Text:        "Imported",
+					Done:        true,
+					CompletedAt: &updated,
+					Priority:    todos.PriorityLow,
+					ExternalId:  &externalId,
+				},
+				{
+					Model: gorm.Model{ID: 3, CreatedAt: created, UpdatedAt: updated},
+					Text:  "Stretch",
+					Type:  todos.TypeHabit,
+				},
+			}, todos.PaginationMetadata{ResultCount: 3, TotalCount: 3}, nil).
+			Times(1)
+
+		var out bytes.Buffer
+		err := service.WriteFeed(ctx, "secret", &out)
+		assert.NoError(t, err)
+
+		cal, err := ical.Decode(&out)
+		assert.NoError(t, err)
+		assert.Equal(t, prodID, cal.ProdID)
+		assert.Len(t, cal.Todos, 2)
+
+		assert.Equal(t, ical.Todo{
+			UID:          "todo-1@todo-app",
+			Summary:      "Pay rent, on time",
+			Status:       ical.StatusNeedsAction,
+			Priority:     1,
+			Due:          &due,
+			Created:      &created,
+			LastModified: &updated,
+			Stamp:        updated,
+			RRule:        "FREQ=MONTHLY;BYMONTHDAY=5",
+			Categories:   []string{"home"},
+		}, cal.Todos[0])
+
+		assert.Equal(t, "abc-123", cal.Todos[1].UID)
+		assert.Equal(t, ical.StatusCompleted, cal.Todos[1].Status)
+		assert.Equal(t, updated, *cal.Todos[1].Completed)
+		assert.Equal(t, 9, cal.Todos[1].Priority)
+
+		// the generated feed imports back into the same records
+		record := recordFromTodo(cal.Todos[0])
+		assert.Equal(t, "Pay rent, on time", record.Text)
+		assert.Equal(t, todos.PriorityHigh, record.Priority)
+		assert.Equal(t, due, *record.DueAt)
+		assert.Equal(t, "FREQ=MONTHLY;BYMONTHDAY=5", record.Recurrence)
+		assert.Equal(t, []string{"home"}, record.Tags)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		mockRepo.
+			EXPECT().
+			GetFeedTokenByHash(ctx, hashToken("wrong")).
+			Return(FeedToken{}, gorm.ErrRecordNotFound).
+			Times(1)
+
+		var out bytes.Buffer
+		err := service.WriteFeed(ctx, "wrong", &out)
+		assert.EqualError(t, err, locale.ErrorInvalidFeedToken)
+		assert.Empty(t, out.String())
+	})
+
+	ctrl.Finish()
+}
+
+func TestService_ImportICal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockTransferService := transfer.NewMockService(ctrl)
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, NewMockRepository(ctrl), users.NewMockRepository(ctrl), todos.NewMockService(ctrl), mockTransferService, validator.New())
+	ctx := context.Background()
+
+	t.Run("todos are upserted by uid", func(t *testing.T) {
+		input := "BEGIN:VCALENDAR\r\n" +
+			"VERSION:2.0\r\n" +
+			"BEGIN:VTODO\r\n" +
+			"UID:a-1\r\n" +
+			"SUMMARY:File taxes\r\n" +
+			"DUE;VALUE=DATE:20250430\r\n" +
+			"PRIORITY:2\r\n" +
+			"END:VTODO\r\n" +
+			"BEGIN:VTODO\r\n" +
+			"UID:a-2\r\n" +
+			"SUMMARY:Water plants\r\n" +
+			"STATUS:COMPLETED\r\n" +
+			"COMPLETED:20250401T080000Z\r\n" +
+			"PRIORITY:5\r\n" +
+			"END:VTODO\r\n" +
+			"END:VCALENDAR\r\n"
+
+		completed := time.Date(2025, time.April, 1, 8, 0, 0, 0, time.UTC)
+		due := time.Date(2025, time.April, 30, 23, 59, 59, 0, time.UTC)
+		mockTransferService.
+			EXPECT().
+			ImportRecords(ctx, uint(6), []transfer.Record{
+				{ExternalId: "a-1", Text: "File taxes", Priority: todos.PriorityHigh, DueAt: &due},
+				{ExternalId: "a-2", Text: "Water plants", Done: true, CompletedAt: &completed, Priority: todos.PriorityMedium},
+			}, transfer.ImportOptions{Mode: transfer.ModeUpsert, DryRun: true}).
+			Return(transfer.ImportResult{DryRun: true, Created: 2}, nil).
+			Times(1)
+
+		result, err := service.ImportICal(ctx, 6, strings.NewReader(input), true)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.Created)
+	})
+
+	t.Run("not an ics file", func(t *testing.T) {
+		_, err := service.ImportICal(ctx, 6, strings.NewReader("text,done\n"), false)
+		assert.Error(t, err)
+	})
+
+	ctrl.Finish()
+}
+
+func TestService_Authenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	mockUserRepo := users.NewMockRepository(ctrl)
+	logger := zap.NewNop().Sugar()
+	now := time.Date(2025, time.May, 1, 12, 0, 0, 0, time.UTC)
+	s := &service{logger: logger, repository: mockRepo, userRepository: mockUserRepo, now: func() time.Time { return now }}
+	ctx := context.Background()
+
+	t.Run("app password of the user", func(t *testing.T) {
+		mockUserRepo.
+			EXPECT().
+			GetByEmail(ctx, "ann@example.com").
+			Return(users.User{Model: gorm.Model{ID: 5}}, nil).
+			Times(1)
+
+		mockRepo.
+			EXPECT().
+			GetAppPasswordByHash(ctx, hashToken("secret")).
+			Return(AppPassword{ID: 2, UserId: 5}, nil).
+			Times(1)
+
+		mockRepo.
+			EXPECT().
+			TouchAppPassword(ctx, uint(2), now).
+			Return(nil).
+			Times(1)
+
+		userId, err := s.Authenticate(ctx, "Ann@example.com", "secret")
+		assert.NoError(t, err)
+		assert.Equal(t, uint(5), userId)
+	})
+
+	t.Run("app password of another user", func(t *testing.T) {
+		mockUserRepo.
+			EXPECT().
+			GetByEmail(ctx, "ann@example.com").
+			Return(users.User{Model: gorm.Model{ID: 5}}, nil).
+			Times(1)
+
+		mockRepo.
+			EXPECT().
+			GetAppPasswordByHash(ctx, hashToken("secret")).
+			Return(AppPassword{ID: 3, UserId: 6}, nil).
+			Times(1)
+
+		_, err := s.Authenticate(ctx, "ann@example.com", "secret")
+		assert.EqualError(t, err, locale.ErrorInvalidCredentials)
+	})
+
+	ctrl.Finish()
+}
+
+func TestService_PutResource(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	mockTodoService := todos.NewMockService(ctrl)
+	mockTransferService := transfer.NewMockService(ctrl)
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockRepo, users.NewMockRepository(ctrl), mockTodoService, mockTransferService, validator.New())
+	ctx := context.Background()
+
+	updated := time.Date(2025, time.May, 1, 12, 0, 0, 0, time.UTC)
+	body := func(uid string) *strings.Reader {
+		return strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nUID:" + uid + "\r\nSUMMARY:Call mom\r\nPRIORITY:1\r\nEND:VTODO\r\nEND:VCALENDAR\r\n")
+	}
+
+	t.Run("new resource", func(t *testing.T) {
+		externalId := "new-1"
+		mockRepo.
+			EXPECT().
+			GetItemByExternalId(ctx, uint(1), "new-1").
+			Return(todos.ToDoItem{}, gorm.ErrRecordNotFound).
+			Times(1)
+
+		mockTransferService.
+			EXPECT().
+			ImportRecords(ctx, uint(1), []transfer.Record{{ExternalId: "new-1", Text: "Call mom", Priority: todos.PriorityHigh}}, transfer.ImportOptions{Mode: transfer.ModeUpsert}).
+			Return(transfer.ImportResult{Created: 1}, nil).
+			Times(1)
+
+		mockRepo.
+			EXPECT().
+			GetItemByExternalId(ctx, uint(1), "new-1").
+			Return(todos.ToDoItem{Model: gorm.Model{ID: 9, UpdatedAt: updated}, Text: "Call mom", ExternalId: &externalId}, nil).
+			Times(1)
+
+		resource, created, err := service.PutResource(ctx, 1, "new-1.ics", body("new-1"), Preconditions{IfNoneMatch: "*"})
+		assert.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, "new-1.ics", resource.Name)
+		assert.Equal(t, `"9-1746100800000"`, resource.ETag)
+	})
+
+	t.Run("existing resource without external id", func(t *testing.T) {
+		mockRepo.
+			EXPECT().
+			GetItemByExternalId(ctx, uint(1), "todo-4@todo-app").
+			Return(todos.ToDoItem{}, gorm.ErrRecordNotFound).
+			Times(1)
+
+		mockTodoService.
+			EXPECT().
+			GetById(ctx, uint(4)).
+			Return(todos.ToDoItem{Model: gorm.Model{ID: 4, UpdatedAt: updated}, UserId: 1}, nil).
+			Times(1)
+
+		mockTodoService.
+			EXPECT().
+			UpdateById(ctx, uint(4), gomock.Any()).
+			Return(todos.ToDoItem{Model: gorm.Model{ID: 4, UpdatedAt: updated.Add(time.Second)}, UserId: 1, Text: "Call mom"}, nil).
+			Times(1)
+
+		resource, created, err := service.PutResource(ctx, 1, "todo-4@todo-app.ics", body("todo-4@todo-app"), Preconditions{IfMatch: `"4-1746100800000"`})
+		assert.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, `"4-1746100801000"`, resource.ETag)
+	})
+
+	t.Run("stale etag", func(t *testing.T) {
+		mockRepo.
+			EXPECT().
+			GetItemByExternalId(ctx, uint(1), "todo-4@todo-app").
+			Return(todos.ToDoItem{}, gorm.ErrRecordNotFound).
+			Times(1)
+
+		mockTodoService.
+			EXPECT().
+			GetById(ctx, uint(4)).
+			Return(todos.ToDoItem{Model: gorm.Model{ID: 4, UpdatedAt: updated}, UserId: 1}, nil).
+			Times(1)
+
+		_, _, err := service.PutResource(ctx, 1, "todo-4@todo-app.ics", body("todo-4@todo-app"), Preconditions{IfMatch: `"4-1"`})
+		assert.EqualError(t, err, locale.ErrorPreconditionFailed)
+	})
+
+	t.Run("uid does not match the name", func(t *testing.T) {
+		_, _, err := service.PutResource(ctx, 1, "other.ics", body("new-1"), Preconditions{})
+		assert.EqualError(t, err, locale.ErrorInvalidICal)
+	})
+
+	ctrl.Finish()
+}
+
+func TestService_GetChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockRepo, users.NewMockRepository(ctrl), todos.NewMockService(ctrl), transfer.NewMockService(ctrl), validator.New())
+	ctx := context.Background()
+
+	since := time.Date(2025, time.May, 1, 12, 0, 0, 0, time.UTC)
+	lastChange := since.Add(time.Hour)
+
+	t.Run("changed and deleted since the token", func(t *testing.T) {
+		mockRepo.
+			EXPECT().
+			GetLastChange(ctx, uint(1)).
+			Return(lastChange, nil).
+			Times(1)
+
+		mockRepo.
+			EXPECT().
+			GetChangedItems(ctx, uint(1), since).
+			Return([]todos.ToDoItem{
+				{Model: gorm.Model{ID: 1, UpdatedAt: lastChange}, Text: "Changed"},
+				{Model: gorm.Model{ID: 2, DeletedAt: gorm.DeletedAt{Time: lastChange, Valid: true}}, Text: "Deleted"},
+			}, nil).
+			Times(1)
+
+		changes, err := service.GetChanges(ctx, 1, syncToken(since))
+		assert.NoError(t, err)
+		assert.Len(t, changes.Resources, 1)
+		assert.Equal(t, "todo-1@todo-app.ics", changes.Resources[0].Name)
+		assert.Equal(t, []string{"todo-2@todo-app.ics"}, changes.Deleted)
+		assert.Equal(t, "urn:todo-app:sync:1746104400000", changes.SyncToken)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		mockRepo.
+			EXPECT().
+			GetLastChange(ctx, uint(1)).
+			Return(lastChange, nil).
+			Times(1)
+
+		_, err := service.GetChanges(ctx, 1, "http://other/sync/1")
+		assert.EqualError(t, err, locale.ErrorInvalidSyncToken)
+	})
+
+	ctrl.Finish()
+}
+
+func TestPreconditions_Met(t *testing.T) {
+	assert.True(t, Preconditions{}.met(""))
+	assert.True(t, Preconditions{IfNoneMatch: "*"}.met(""))
+	assert.False(t, Preconditions{IfNoneMatch: "*"}.met(`"1-2"`))
+	assert.True(t, Preconditions{IfMatch: `"1-2"`}.met(`"1-2"`))
+	assert.False(t, Preconditions{IfMatch: `"1-2"`}.met(`"1-3"`))
+	assert.False(t, Preconditions{IfMatch: "*"}.met(""))
+}
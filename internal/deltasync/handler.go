@@ -0,0 +1,122 @@
+package deltasync
+
+import (
+	"net/http"
+	"todo-app/internal/auth"
+	e "todo-app/pkg/errors"
+	"todo-app/pkg/handlers"
+	"todo-app/pkg/locale"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type endpointHandler struct {
+	logger  *zap.SugaredLogger
+	service Service
+	e       *echo.Echo
+}
+
+func GetEndpointHandler(
+	logger *zap.SugaredLogger,
+	service Service,
+	e *echo.Echo,
+) handlers.EndpointHandler {
+	return &endpointHandler{
+		logger:  logger,
+		service: service,
+		e:       e,
+	}
+}
+
+func (h *endpointHandler) AddEndpoints() {
+	var endpoints = []handlers.Endpoint{
+		{
+			Method:  http.MethodGet,
+			Path:    "/sync",
+			Handler: h.pull,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/sync",
+			Handler: h.push,
+		},
+	}
+
+	for _, endpoint := range endpoints {
+		handlers.Method(h.e, endpoint.Method, endpoint.Path, endpoint.Handler)
+	}
+}
+
+// @Summary Pull changes
+// @Description This endpoint returns the todo items created or updated since the token and tombstones for the deleted ones, with the token to pass next time.
+// @Description Without a token all items are returned. When has_more is set the client should pull again right away.
+// @Tags sync
+// @ID pullChanges
+// @Security BearerAuth
+// @Produce json
+// @Param since query string false "Token of the last pull"
+// @Success 200 {object} Delta
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /sync [get]
+func (h *endpointHandler) pull(ctx echo.Context) error {
+	h.logger.Infow("pulling changes...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	delta, err := h.service.GetDelta(ctx.Request().Context(), userId, ctx.QueryParam("since"))
+	if err != nil {
+		if err.Error() == locale.ErrorInvalidSyncToken {
+			return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: err.Error()})
+		}
+		h.logger.Error("could not get changes", "error", err.Error())
+
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorInternalServer, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, delta)
+}
+
+// @Summary Push changes
+// @Description This endpoint applies the changes an offline client made, in order, and returns a result for each of them.
+// @Description Updates and deletes of items changed on the server in the meantime are reported as conflicts, resolved by the last writer or, with the merge strategy, field by field.
+// @Tags sync
+// @ID pushChanges
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param changes body PushInput true "Mutations"
+// @Success 200 {object} PushResult
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Router /sync [post]
+func (h *endpointHandler) push(ctx echo.Context) error {
+	h.logger.Infow("pushing changes...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	var input PushInput
+	err := ctx.Bind(&input)
+	if err != nil {
+		h.logger.Warn("could not bind body to push struct", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+	}
+
+	result, err := h.service.Push(ctx.Request().Context(), userId, input)
+	if err != nil {
+		h.logger.Warn("could not push changes", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
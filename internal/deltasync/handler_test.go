@@ -0,0 +1,147 @@
+package deltasync
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"todo-app/pkg/locale"
+
+	localErr "todo-app/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestHandler_Pull(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	h := &endpointHandler{logger: zap.NewNop().Sugar(), service: mockService, e: e}
+
+	newContext := func(query string, userId uint) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/sync"+query, nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", userId)
+
+		return ctx, rec
+	}
+
+	t.Run("returns the delta", func(t *testing.T) {
+		ctx, rec := newContext("?since=40", 1)
+
+		mockService.
+			EXPECT().
+			GetDelta(ctx.Request().Context(), uint(1), "40").
+			Return(Delta{Token: "43", Deleted: []Tombstone{{ID: 3}}}, nil).
+			Times(1)
+
+		if assert.NoError(t, h.pull(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var response Delta
+			err := json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, "43", response.Token)
+			assert.Equal(t, uint(3), response.Deleted[0].ID)
+		}
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		ctx, rec := newContext("?since=abc", 1)
+
+		mockService.EXPECT().GetDelta(ctx.Request().Context(), uint(1), "abc").Return(Delta{}, errors.New(locale.ErrorInvalidSyncToken)).Times(1)
+
+		if assert.NoError(t, h.pull(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var response localErr.ResponseError
+			err := json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, locale.ErrorInvalidSyncToken, response.Message)
+		}
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		ctx, rec := newContext("", 0)
+
+		if assert.NoError(t, h.pull(ctx)) {
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		}
+	})
+
+	ctrl.Finish()
+}
+
+func TestHandler_Push(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	h := &endpointHandler{logger: zap.NewNop().Sugar(), service: mockService, e: e}
+
+	newContext := func(body string, userId uint) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/sync", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", userId)
+
+		return ctx, rec
+	}
+
+	t.Run("returns the results", func(t *testing.T) {
+		ctx, rec := newContext(`{"strategy": "merge", "mutations": [{"op": "delete", "id": 4, "updated_at": "2025-03-01T08:00:00Z"}]}`, 1)
+
+		mockService.
+			EXPECT().
+			Push(ctx.Request().Context(), uint(1), gomock.Any()).
+			DoAndReturn(func(_ interface{}, _ uint, input PushInput) (PushResult, error) {
+				assert.Equal(t, StrategyMerge, input.Strategy)
+				assert.Equal(t, uint(4), input.Mutations[0].Id)
+				return PushResult{Strategy: StrategyMerge, Results: []MutationResult{{Id: 4, Status: StatusApplied}}}, nil
+			}).
+			Times(1)
+
+		if assert.NoError(t, h.push(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var response PushResult
+			err := json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, StatusApplied, response.Results[0].Status)
+		}
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		ctx, rec := newContext(`{"mutations": 3}`, 1)
+
+		if assert.NoError(t, h.push(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var response localErr.ResponseError
+			err := json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, locale.ErrorInvalidBody, response.Message)
+		}
+	})
+
+	t.Run("invalid mutations", func(t *testing.T) {
+		ctx, rec := newContext(`{"mutations": [{"op": "move"}]}`, 1)
+
+		mockService.EXPECT().Push(ctx.Request().Context(), uint(1), gomock.Any()).Return(PushResult{}, errors.New("Key: 'PushInput.Mutations[0].Op' Error")).Times(1)
+
+		if assert.NoError(t, h.push(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	ctrl.Finish()
+}
@@ -0,0 +1,103 @@
+package deltasync
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"todo-app/internal/todos"
+)
+
+// merge : the changes the client may apply given the server item. A field the server
+// still has at its base value takes the client value. A field changed on both sides is
+// a conflict, won by the client only when its change is newer.
+func merge(item todos.ToDoItem, changes todos.ToDoItemUpdateInput, base *todos.ToDoItemUpdateInput, serverNewer bool) (todos.ToDoItemUpdateInput, []string) {
+	server := fieldValues(current(item))
+	client := fieldValues(changes)
+	started := map[string]json.RawMessage{}
+	if base != nil {
+		started = fieldValues(*base)
+	}
+
+	applied := map[string]json.RawMessage{}
+	var conflicts []string
+	for field, value := range client {
+		if bytes.Equal(value, server[field]) {
+			continue
+		}
+		if from, ok := started[field]; ok && bytes.Equal(from, server[field]) {
+			applied[field] = value
+			continue
+		}
+
+		conflicts = append(conflicts, field)
+		if !serverNewer {
+			applied[field] = value
+		}
+	}
+	slices.Sort(conflicts)
+
+	var merged todos.ToDoItemUpdateInput
+	data, _ := json.Marshal(applied)
+	_ = json.Unmarshal(data, &merged)
+
+	return merged, conflicts
+}
+
+// current : the item as the update input that would set its values
+func current(item todos.ToDoItem) todos.ToDoItemUpdateInput {
+	tags := make([]string, 0, len(item.Tags))
+	for _, tag := range item.Tags {
+		tags = append(tags, tag.Name)
+	}
+	// a nil target is sent as 0 by clients clearing it
+	targetPerWeek := 0
+	if item.TargetPerWeek != nil {
+		targetPerWeek = *item.TargetPerWeek
+	}
+
+	return todos.ToDoItemUpdateInput{
+		Text:          &item.Text,
+		Done:          &item.Done,
+		DueAt:         item.DueAt,
+		Priority:      &item.Priority,
+		Recurrence:    &item.Recurrence,
+		Tags:          &tags,
+		Estimate:      item.Estimate,
+		Type:          &item.Type,
+		TargetPerWeek: &targetPerWeek,
+		Timezone:      &item.Timezone,
+	}
+}
+
+// fieldValues : the set fields of the input by json name, normalized so that equal
+// values compare equal
+func fieldValues(input todos.ToDoItemUpdateInput) map[string]json.RawMessage {
+	if input.DueAt != nil {
+		dueAt := input.DueAt.UTC()
+		input.DueAt = &dueAt
+	}
+	if input.Tags != nil {
+		tags := make([]string, 0, len(*input.Tags))
+		for _, tag := range *input.Tags {
+			tags = append(tags, strings.ToLower(strings.TrimLeft(strings.TrimSpace(tag), "#")))
+		}
+		slices.Sort(tags)
+		input.Tags = &tags
+	}
+
+	var fields map[string]json.RawMessage
+	data, _ := json.Marshal(input)
+	_ = json.Unmarshal(data, &fields)
+	for field, value := range fields {
+		if string(value) == "null" {
+			delete(fields, field)
+		}
+	}
+
+	return fields
+}
+
+func hasChanges(input todos.ToDoItemUpdateInput) bool {
+	return len(fieldValues(input)) > 0
+}
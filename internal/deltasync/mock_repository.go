@@ -0,0 +1,161 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/deltasync/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/deltasync/repository.go -destination=internal/deltasync/mock_repository.go -package=deltasync
+//
+
+// Package deltasync is a generated GoMock package.
+package deltasync
+
+import (
+	context "context"
+	reflect "reflect"
+	todos "todo-app/internal/todos"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateClientItem mocks base method.
+func (m *MockRepository) CreateClientItem(ctx context.Context, clientItem *ClientItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateClientItem", ctx, clientItem)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateClientItem indicates an expected call of CreateClientItem.
+func (mr *MockRepositoryMockRecorder) CreateClientItem(ctx, clientItem any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateClientItem", reflect.TypeOf((*MockRepository)(nil).CreateClientItem), ctx, clientItem)
+}
+
+// GetChangedItems mocks base method.
+func (m *MockRepository) GetChangedItems(ctx context.Context, userId uint, since uint64, limit int) ([]ChangedItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChangedItems", ctx, userId, since, limit)
+	ret0, _ := ret[0].([]ChangedItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChangedItems indicates an expected call of GetChangedItems.
+func (mr *MockRepositoryMockRecorder) GetChangedItems(ctx, userId, since, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChangedItems", reflect.TypeOf((*MockRepository)(nil).GetChangedItems), ctx, userId, since, limit)
+}
+
+// GetClientItem mocks base method.
+func (m *MockRepository) GetClientItem(ctx context.Context, userId uint, clientId string) (ClientItem, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClientItem", ctx, userId, clientId)
+	ret0, _ := ret[0].(ClientItem)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetClientItem indicates an expected call of GetClientItem.
+func (mr *MockRepositoryMockRecorder) GetClientItem(ctx, userId, clientId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClientItem", reflect.TypeOf((*MockRepository)(nil).GetClientItem), ctx, userId, clientId)
+}
+
+// GetItem mocks base method.
+func (m *MockRepository) GetItem(ctx context.Context, id uint) (todos.ToDoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItem", ctx, id)
+	ret0, _ := ret[0].(todos.ToDoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItem indicates an expected call of GetItem.
+func (mr *MockRepositoryMockRecorder) GetItem(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItem", reflect.TypeOf((*MockRepository)(nil).GetItem), ctx, id)
+}
+
+// GetItemsByIds mocks base method.
+func (m *MockRepository) GetItemsByIds(ctx context.Context, ids []uint) ([]todos.ToDoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItemsByIds", ctx, ids)
+	ret0, _ := ret[0].([]todos.ToDoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItemsByIds indicates an expected call of GetItemsByIds.
+func (mr *MockRepositoryMockRecorder) GetItemsByIds(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemsByIds", reflect.TypeOf((*MockRepository)(nil).GetItemsByIds), ctx, ids)
+}
+
+// GetItemsForUser mocks base method.
+func (m *MockRepository) GetItemsForUser(ctx context.Context, userId uint) ([]todos.ToDoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItemsForUser", ctx, userId)
+	ret0, _ := ret[0].([]todos.ToDoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItemsForUser indicates an expected call of GetItemsForUser.
+func (mr *MockRepositoryMockRecorder) GetItemsForUser(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemsForUser", reflect.TypeOf((*MockRepository)(nil).GetItemsForUser), ctx, userId)
+}
+
+// GetLastChangeId mocks base method.
+func (m *MockRepository) GetLastChangeId(ctx context.Context, userId uint) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastChangeId", ctx, userId)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastChangeId indicates an expected call of GetLastChangeId.
+func (mr *MockRepositoryMockRecorder) GetLastChangeId(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastChangeId", reflect.TypeOf((*MockRepository)(nil).GetLastChangeId), ctx, userId)
+}
+
+// RecordChange mocks base method.
+func (m *MockRepository) RecordChange(ctx context.Context, change *Change) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordChange", ctx, change)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordChange indicates an expected call of RecordChange.
+func (mr *MockRepositoryMockRecorder) RecordChange(ctx, change any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordChange", reflect.TypeOf((*MockRepository)(nil).RecordChange), ctx, change)
+}
@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/deltasync/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/deltasync/service.go -destination=internal/deltasync/mock_service.go -package=deltasync
+//
+
+// Package deltasync is a generated GoMock package.
+package deltasync
+
+import (
+	context "context"
+	reflect "reflect"
+	todos "todo-app/internal/todos"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// GetDelta mocks base method.
+func (m *MockService) GetDelta(ctx context.Context, userId uint, since string) (Delta, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDelta", ctx, userId, since)
+	ret0, _ := ret[0].(Delta)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDelta indicates an expected call of GetDelta.
+func (mr *MockServiceMockRecorder) GetDelta(ctx, userId, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDelta", reflect.TypeOf((*MockService)(nil).GetDelta), ctx, userId, since)
+}
+
+// HandleTodoEvent mocks base method.
+func (m *MockService) HandleTodoEvent(ctx context.Context, event todos.Event) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "HandleTodoEvent", ctx, event)
+}
+
+// HandleTodoEvent indicates an expected call of HandleTodoEvent.
+func (mr *MockServiceMockRecorder) HandleTodoEvent(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleTodoEvent", reflect.TypeOf((*MockService)(nil).HandleTodoEvent), ctx, event)
+}
+
+// Push mocks base method.
+func (m *MockService) Push(ctx context.Context, userId uint, input PushInput) (PushResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Push", ctx, userId, input)
+	ret0, _ := ret[0].(PushResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Push indicates an expected call of Push.
+func (mr *MockServiceMockRecorder) Push(ctx, userId, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockService)(nil).Push), ctx, userId, input)
+}
@@ -0,0 +1,127 @@
+package deltasync
+
+import (
+	"time"
+	"todo-app/internal/todos"
+)
+
+const (
+	StrategyLastWriterWins = "last_writer_wins"
+	StrategyMerge          = "merge"
+)
+
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+const (
+	// StatusApplied : the mutation was applied as sent
+	StatusApplied = "applied"
+	// StatusMerged : only the fields without a conflict lost by the client were applied
+	StatusMerged = "merged"
+	// StatusConflict : the server version won, nothing was applied
+	StatusConflict = "conflict"
+	// StatusFailed : the mutation is invalid or the item does not exist
+	StatusFailed = "failed"
+)
+
+const (
+	// ConflictServerNewer : the item changed on the server after the client changed it
+	ConflictServerNewer = "server_newer"
+	// ConflictDeleted : the item was deleted on the server
+	ConflictDeleted = "deleted"
+	// ConflictFields : both sides changed the same fields
+	ConflictFields = "fields"
+)
+
+const (
+	ResolutionServer = "server"
+	ResolutionClient = "client"
+)
+
+// Change : a write to a todo item of the user. The auto-increment ID orders all changes
+// and is the sync token.
+type Change struct {
+	ID        uint64 `gorm:"primaryKey"`
+	UserId    uint   `gorm:"not null;index"`
+	ItemId    uint   `gorm:"not null"`
+	Type      string `gorm:"type:varchar(16);not null"`
+	CreatedAt time.Time
+}
+
+// ClientItem : the item created for an id chosen by an offline client, so that sending
+// the same create twice does not create two items
+type ClientItem struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserId    uint   `gorm:"not null;uniqueIndex:idx_client_item"`
+	ClientId  string `gorm:"type:varchar(64);not null;uniqueIndex:idx_client_item"`
+	ItemId    uint   `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+// ChangedItem : an item and its latest change
+type ChangedItem struct {
+	ItemId   uint
+	ChangeId uint64
+}
+
+// Delta : the changes since a token. Without a token Full is set and Items holds all
+// items of the user.
+type Delta struct {
+	Token   string           `json:"token"`
+	Full    bool             `json:"full"`
+	Items   []todos.ToDoItem `json:"items"`
+	Deleted []Tombstone      `json:"deleted"`
+	HasMore bool             `json:"has_more"`
+}
+
+type Tombstone struct {
+	ID        uint      `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// Mutation : a change made by the client while offline. UpdatedAt is when it was made,
+// Base holds the values of the changed fields the client started from, for merging.
+type Mutation struct {
+	Op             string                     `json:"op" validate:"required,oneof=create update delete"`
+	ClientId       string                     `json:"client_id" validate:"required_if=Op create,max=64"`
+	ParentClientId string                     `json:"parent_client_id" validate:"max=64"`
+	Id             uint                       `json:"id" validate:"required_unless=Op create"`
+	Item           *todos.ToDoItem            `json:"item" validate:"required_if=Op create"`
+	Changes        *todos.ToDoItemUpdateInput `json:"changes" validate:"required_if=Op update"`
+	Base           *todos.ToDoItemUpdateInput `json:"base"`
+	UpdatedAt      time.Time                  `json:"updated_at" validate:"required"`
+}
+
+type PushInput struct {
+	Strategy  string     `json:"strategy" validate:"omitempty,oneof=last_writer_wins merge"`
+	Mutations []Mutation `json:"mutations" validate:"required,max=500,dive"`
+}
+
+type MutationResult struct {
+	Index    int             `json:"index"`
+	ClientId string          `json:"client_id,omitempty"`
+	Id       uint            `json:"id,omitempty"`
+	Status   string          `json:"status"`
+	Item     *todos.ToDoItem `json:"item,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// Conflict : a mutation that met a newer server version. Resolution tells which side
+// won, Server is the item as it is now.
+type Conflict struct {
+	Index      int             `json:"index"`
+	Id         uint            `json:"id"`
+	Reason     string          `json:"reason"`
+	Fields     []string        `json:"fields,omitempty"`
+	Resolution string          `json:"resolution"`
+	Server     *todos.ToDoItem `json:"server"`
+}
+
+type PushResult struct {
+	Strategy  string           `json:"strategy"`
+	Results   []MutationResult `json:"results"`
+	Conflicts []Conflict       `json:"conflicts"`
+}
@@ -0,0 +1,149 @@
+package deltasync
+
+import (
+	"context"
+	"errors"
+	"todo-app/internal/todos"
+	"todo-app/pkg/database"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	RecordChange(ctx context.Context, change *Change) error
+	GetLastChangeId(ctx context.Context, userId uint) (uint64, error)
+	GetChangedItems(ctx context.Context, userId uint, since uint64, limit int) ([]ChangedItem, error)
+	GetItemsForUser(ctx context.Context, userId uint) ([]todos.ToDoItem, error)
+	GetItemsByIds(ctx context.Context, ids []uint) ([]todos.ToDoItem, error)
+	GetItem(ctx context.Context, id uint) (todos.ToDoItem, error)
+	GetClientItem(ctx context.Context, userId uint, clientId string) (ClientItem, bool, error)
+	CreateClientItem(ctx context.Context, clientItem *ClientItem) error
+}
+
+type repository struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func GetRepository(logger *zap.SugaredLogger, db *gorm.DB) Repository {
+	return &repository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// RecordChange : joins the transaction of the write, if there is one
+func (r *repository) RecordChange(ctx context.Context, change *Change) error {
+	result := database.Conn(ctx, r.db).Create(change)
+	if result.Error != nil {
+		r.logger.Errorw("failed to record todo change", "item_id", change.ItemId, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) GetLastChangeId(ctx context.Context, userId uint) (uint64, error) {
+	var last uint64
+	result := r.db.WithContext(ctx).
+		Model(&Change{}).
+		Select("COALESCE(MAX(id), 0)").
+		Where("user_id = ?", userId).
+		Scan(&last)
+	if result.Error != nil {
+		r.logger.Errorw("failed to get last todo change", "user_id", userId, "error", result.Error)
+
+		return 0, result.Error
+	}
+
+	return last, nil
+}
+
+// GetChangedItems : the items changed after since with their latest change, ordered by
+// it, so that the last one of a page is the token for the next page
+func (r *repository) GetChangedItems(ctx context.Context, userId uint, since uint64, limit int) ([]ChangedItem, error) {
+	var changed []ChangedItem
+	result := r.db.WithContext(ctx).
+		Model(&Change{}).
+		Select("item_id, MAX(id) AS change_id").
+		Where("user_id = ? AND id > ?", userId, since).
+		Group("item_id").
+		Order("change_id").
+		Limit(limit).
+		Scan(&changed)
+	if result.Error != nil {
+		r.logger.Errorw("failed to get changed todo items", "user_id", userId, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return changed, nil
+}
+
+func (r *repository) GetItemsForUser(ctx context.Context, userId uint) ([]todos.ToDoItem, error) {
+	var items []todos.ToDoItem
+	result := r.db.WithContext(ctx).Where("user_id = ?", userId).Preload("Tags").Order("id").Find(&items)
+	if result.Error != nil {
+		r.logger.Errorw("failed to get todo items", "user_id", userId, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return items, nil
+}
+
+// GetItemsByIds : deleted items included
+func (r *repository) GetItemsByIds(ctx context.Context, ids []uint) ([]todos.ToDoItem, error) {
+	var items []todos.ToDoItem
+	if len(ids) == 0 {
+		return items, nil
+	}
+
+	result := r.db.WithContext(ctx).Unscoped().Where("id IN ?", ids).Preload("Tags").Find(&items)
+	if result.Error != nil {
+		r.logger.Errorw("failed to get todo items by id", "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return items, nil
+}
+
+// GetItem : deleted items included
+func (r *repository) GetItem(ctx context.Context, id uint) (todos.ToDoItem, error) {
+	var item todos.ToDoItem
+	result := r.db.WithContext(ctx).Unscoped().Preload("Tags").First(&item, id)
+	if result.Error != nil {
+		return todos.ToDoItem{}, result.Error
+	}
+
+	return item, nil
+}
+
+func (r *repository) GetClientItem(ctx context.Context, userId uint, clientId string) (ClientItem, bool, error) {
+	var clientItem ClientItem
+	result := r.db.WithContext(ctx).Where("user_id = ? AND client_id = ?", userId, clientId).First(&clientItem)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return ClientItem{}, false, nil
+	}
+	if result.Error != nil {
+		r.logger.Errorw("failed to get client item", "user_id", userId, "error", result.Error)
+
+		return ClientItem{}, false, result.Error
+	}
+
+	return clientItem, true, nil
+}
+
+func (r *repository) CreateClientItem(ctx context.Context, clientItem *ClientItem) error {
+	result := r.db.WithContext(ctx).Create(clientItem)
+	if result.Error != nil {
+		r.logger.Errorw("failed to create client item", "user_id", clientItem.UserId, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
@@ -0,0 +1,288 @@
+package deltasync
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"todo-app/internal/todos"
+	"todo-app/pkg/authz"
+	"todo-app/pkg/locale"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// pageSize : the most changed items returned at once
+const pageSize = 500
+
+type Service interface {
+	GetDelta(ctx context.Context, userId uint, since string) (Delta, error)
+	Push(ctx context.Context, userId uint, input PushInput) (PushResult, error)
+	HandleTodoEvent(ctx context.Context, event todos.Event)
+}
+
+type service struct {
+	logger      *zap.SugaredLogger
+	repository  Repository
+	todoService todos.Service
+	validator   *validator.Validate
+}
+
+func GetService(logger *zap.SugaredLogger, repo Repository, todoService todos.Service, validator *validator.Validate) Service {
+	return &service{
+		logger:      logger,
+		repository:  repo,
+		todoService: todoService,
+		validator:   validator,
+	}
+}
+
+// HandleTodoEvent : records the change, the todo service calls it after every write
+func (s *service) HandleTodoEvent(ctx context.Context, event todos.Event) {
+	change := &Change{UserId: event.UserId, ItemId: event.Item.ID, Type: event.Type}
+	if err := s.repository.RecordChange(ctx, change); err != nil {
+		s.logger.Errorw("could not record todo change, clients will miss it until they resync", "item_id", event.Item.ID, "error", err)
+	}
+}
+
+// GetDelta : the items changed since the token and the tombstones of deleted ones. An
+// empty token returns all items instead.
+func (s *service) GetDelta(ctx context.Context, userId uint, since string) (Delta, error) {
+	if since == "" {
+		return s.snapshot(ctx, userId)
+	}
+
+	token, err := strconv.ParseUint(since, 10, 64)
+	if err != nil {
+		return Delta{}, errors.New(locale.ErrorInvalidSyncToken)
+	}
+
+	changed, err := s.repository.GetChangedItems(ctx, userId, token, pageSize+1)
+	if err != nil {
+		return Delta{}, err
+	}
+	delta := Delta{Token: since, Items: []todos.ToDoItem{}, Deleted: []Tombstone{}}
+	if len(changed) > pageSize {
+		changed = changed[:pageSize]
+		delta.HasMore = true
+	}
+	if len(changed) == 0 {
+		return delta, nil
+	}
+	delta.Token = strconv.FormatUint(changed[len(changed)-1].ChangeId, 10)
+
+	ids := make([]uint, 0, len(changed))
+	for _, item := range changed {
+		ids = append(ids, item.ItemId)
+	}
+	items, err := s.repository.GetItemsByIds(ctx, ids)
+	if err != nil {
+		return Delta{}, err
+	}
+	byId := make(map[uint]todos.ToDoItem, len(items))
+	for _, item := range items {
+		byId[item.ID] = item
+	}
+
+	for _, id := range ids {
+		item, ok := byId[id]
+		if !ok {
+			continue
+		}
+		if item.DeletedAt.Valid {
+			delta.Deleted = append(delta.Deleted, Tombstone{ID: item.ID, DeletedAt: item.DeletedAt.Time})
+			continue
+		}
+		delta.Items = append(delta.Items, item)
+	}
+
+	return delta, nil
+}
+
+// snapshot : the token is read before the items, so changes made meanwhile are sent again
+// with the next delta rather than lost
+func (s *service) snapshot(ctx context.Context, userId uint) (Delta, error) {
+	last, err := s.repository.GetLastChangeId(ctx, userId)
+	if err != nil {
+		return Delta{}, err
+	}
+
+	items, err := s.repository.GetItemsForUser(ctx, userId)
+	if err != nil {
+		return Delta{}, err
+	}
+	if items == nil {
+		items = []todos.ToDoItem{}
+	}
+
+	return Delta{Token: strconv.FormatUint(last, 10), Full: true, Items: items, Deleted: []Tombstone{}}, nil
+}
+
+// Push : applies the mutations in order. Mutations do not fail each other, every one
+// gets a result and conflicts are reported next to them.
+func (s *service) Push(ctx context.Context, userId uint, input PushInput) (PushResult, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return PushResult{}, err
+	}
+	if input.Strategy == "" {
+		input.Strategy = StrategyLastWriterWins
+	}
+
+	result := PushResult{Strategy: input.Strategy, Results: []MutationResult{}, Conflicts: []Conflict{}}
+	for i, mutation := range input.Mutations {
+		var mutationResult MutationResult
+		var conflict *Conflict
+		switch mutation.Op {
+		case OpCreate:
+			mutationResult = s.create(ctx, userId, mutation)
+		case OpUpdate:
+			mutationResult, conflict = s.update(ctx, userId, mutation, input.Strategy)
+		case OpDelete:
+			mutationResult, conflict = s.delete(ctx, userId, mutation)
+		}
+
+		mutationResult.Index = i
+		result.Results = append(result.Results, mutationResult)
+		if conflict != nil {
+			conflict.Index = i
+			result.Conflicts = append(result.Conflicts, *conflict)
+		}
+	}
+
+	return result, nil
+}
+
+func failed(mutation Mutation, err error) MutationResult {
+	return MutationResult{ClientId: mutation.ClientId, Id: mutation.Id, Status: StatusFailed, Error: err.Error()}
+}
+
+// create : items are created once per client id, sending a create again returns the
+// item created the first time
+func (s *service) create(ctx context.Context, userId uint, mutation Mutation) MutationResult {
+	existing, found, err := s.repository.GetClientItem(ctx, userId, mutation.ClientId)
+	if err != nil {
+		return failed(mutation, err)
+	}
+	if found {
+		item, err := s.repository.GetItem(ctx, existing.ItemId)
+		if err != nil {
+			return failed(mutation, err)
+		}
+		return MutationResult{ClientId: mutation.ClientId, Id: item.ID, Status: StatusApplied, Item: &item}
+	}
+
+	item := *mutation.Item
+	item.Model = gorm.Model{}
+	item.UserId = userId
+	if mutation.ParentClientId != "" {
+		parent, found, err := s.repository.GetClientItem(ctx, userId, mutation.ParentClientId)
+		if err != nil {
+			return failed(mutation, err)
+		}
+		if !found {
+			return failed(mutation, errors.New(locale.ErrorInvalidParent))
+		}
+		item.ParentId = &parent.ItemId
+	}
+
+	if err := s.todoService.Create(ctx, &item); err != nil {
+		return failed(mutation, err)
+	}
+	if err := s.repository.CreateClientItem(ctx, &ClientItem{UserId: userId, ClientId: mutation.ClientId, ItemId: item.ID}); err != nil {
+		s.logger.Warnw("could not remember client id of created item", "item_id", item.ID, "error", err)
+	}
+
+	return MutationResult{ClientId: mutation.ClientId, Id: item.ID, Status: StatusApplied, Item: &item}
+}
+
+// ownItem : the item of the mutation if the user may perform the action on it, deleted
+// ones included
+func (s *service) ownItem(ctx context.Context, userId uint, id uint, action authz.Action) (todos.ToDoItem, error) {
+	item, err := s.repository.GetItem(ctx, id)
+	if err != nil {
+		return todos.ToDoItem{}, errors.New(locale.ErrorNotFoundRecord)
+	}
+	if err := authz.Authorize(todos.ItemPolicy, userId, action, item); err != nil {
+		return todos.ToDoItem{}, err
+	}
+
+	return item, nil
+}
+
+func (s *service) update(ctx context.Context, userId uint, mutation Mutation, strategy string) (MutationResult, *Conflict) {
+	item, err := s.ownItem(ctx, userId, mutation.Id, authz.ActionUpdate)
+	if err != nil {
+		return failed(mutation, err), nil
+	}
+	if item.DeletedAt.Valid {
+		return MutationResult{Id: item.ID, Status: StatusConflict},
+			&Conflict{Id: item.ID, Reason: ConflictDeleted, Resolution: ResolutionServer, Server: &item}
+	}
+
+	serverNewer := item.UpdatedAt.After(mutation.UpdatedAt)
+	if strategy == StrategyLastWriterWins && serverNewer {
+		return MutationResult{Id: item.ID, Status: StatusConflict},
+			&Conflict{Id: item.ID, Reason: ConflictServerNewer, Resolution: ResolutionServer, Server: &item}
+	}
+
+	changes := *mutation.Changes
+	var conflict *Conflict
+	if strategy == StrategyMerge {
+		var fields []string
+		changes, fields = merge(item, *mutation.Changes, mutation.Base, serverNewer)
+		if len(fields) > 0 {
+			resolution := ResolutionClient
+			if serverNewer {
+				resolution = ResolutionServer
+			}
+			conflict = &Conflict{Id: item.ID, Reason: ConflictFields, Fields: fields, Resolution: resolution}
+		}
+	}
+
+	status := StatusApplied
+	if conflict != nil && conflict.Resolution == ResolutionServer {
+		status = StatusMerged
+		if !hasChanges(changes) {
+			status = StatusConflict
+		}
+	}
+	if !hasChanges(changes) {
+		if conflict != nil {
+			conflict.Server = &item
+		}
+		return MutationResult{Id: item.ID, Status: status, Item: &item}, conflict
+	}
+
+	updated, err := s.todoService.UpdateById(ctx, item.ID, changes)
+	if err != nil {
+		return failed(mutation, err), nil
+	}
+	if conflict != nil {
+		conflict.Server = &updated
+	}
+
+	return MutationResult{Id: updated.ID, Status: status, Item: &updated}, conflict
+}
+
+// delete : deleting a deleted item succeeds, deleting one changed on the server after
+// the client deleted it does not
+func (s *service) delete(ctx context.Context, userId uint, mutation Mutation) (MutationResult, *Conflict) {
+	item, err := s.ownItem(ctx, userId, mutation.Id, authz.ActionDelete)
+	if err != nil {
+		return failed(mutation, err), nil
+	}
+	if item.DeletedAt.Valid {
+		return MutationResult{Id: item.ID, Status: StatusApplied}, nil
+	}
+	if item.UpdatedAt.After(mutation.UpdatedAt) {
+		return MutationResult{Id: item.ID, Status: StatusConflict},
+			&Conflict{Id: item.ID, Reason: ConflictServerNewer, Resolution: ResolutionServer, Server: &item}
+	}
+
+	if err := s.todoService.DeleteById(ctx, item.ID); err != nil {
+		return failed(mutation, err), nil
+	}
+
+	return MutationResult{Id: item.ID, Status: StatusApplied}, nil
+}
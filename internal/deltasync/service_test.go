@@ -0,0 +1,245 @@
+package deltasync
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-app/internal/todos"
+	"todo-app/pkg/locale"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	before = time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	after  = time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+)
+
+func TestService_GetDelta(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	s := GetService(zap.NewNop().Sugar(), mockRepo, todos.NewMockService(ctrl), validator.New())
+	ctx := context.Background()
+
+	t.Run("without token", func(t *testing.T) {
+		mockRepo.EXPECT().GetLastChangeId(ctx, uint(1)).Return(uint64(40), nil).Times(1)
+		mockRepo.EXPECT().GetItemsForUser(ctx, uint(1)).Return([]todos.ToDoItem{{Model: gorm.Model{ID: 3}}}, nil).Times(1)
+
+		delta, err := s.GetDelta(ctx, 1, "")
+		assert.NoError(t, err)
+		assert.True(t, delta.Full)
+		assert.Equal(t, "40", delta.Token)
+		assert.Len(t, delta.Items, 1)
+	})
+
+	t.Run("changes with tombstones", func(t *testing.T) {
+		mockRepo.
+			EXPECT().
+			GetChangedItems(ctx, uint(1), uint64(40), pageSize+1).
+			Return([]ChangedItem{{ItemId: 5, ChangeId: 41}, {ItemId: 3, ChangeId: 43}}, nil).
+			Times(1)
+		mockRepo.
+			EXPECT().
+			GetItemsByIds(ctx, []uint{5, 3}).
+			Return([]todos.ToDoItem{
+				{Model: gorm.Model{ID: 3, DeletedAt: gorm.DeletedAt{Time: after, Valid: true}}},
+				{Model: gorm.Model{ID: 5}},
+			}, nil).
+			Times(1)
+
+		delta, err := s.GetDelta(ctx, 1, "40")
+		assert.NoError(t, err)
+		assert.False(t, delta.Full)
+		assert.False(t, delta.HasMore)
+		assert.Equal(t, "43", delta.Token)
+		assert.Equal(t, uint(5), delta.Items[0].ID)
+		assert.Equal(t, []Tombstone{{ID: 3, DeletedAt: after}}, delta.Deleted)
+	})
+
+	t.Run("nothing changed keeps the token", func(t *testing.T) {
+		mockRepo.EXPECT().GetChangedItems(ctx, uint(1), uint64(43), pageSize+1).Return(nil, nil).Times(1)
+
+		delta, err := s.GetDelta(ctx, 1, "43")
+		assert.NoError(t, err)
+		assert.Equal(t, "43", delta.Token)
+		assert.Empty(t, delta.Items)
+		assert.NotNil(t, delta.Deleted)
+	})
+
+	t.Run("full page", func(t *testing.T) {
+		changed := make([]ChangedItem, 0, pageSize+1)
+		for i := 1; i <= pageSize+1; i++ {
+			changed = append(changed, ChangedItem{ItemId: uint(i), ChangeId: uint64(100 + i)})
+		}
+		mockRepo.EXPECT().GetChangedItems(ctx, uint(1), uint64(100), pageSize+1).Return(changed, nil).Times(1)
+		mockRepo.EXPECT().GetItemsByIds(ctx, gomock.Len(pageSize)).Return(nil, nil).Times(1)
+
+		delta, err := s.GetDelta(ctx, 1, "100")
+		assert.NoError(t, err)
+		assert.True(t, delta.HasMore)
+		assert.Equal(t, "600", delta.Token)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		_, err := s.GetDelta(ctx, 1, "abc")
+		assert.EqualError(t, err, locale.ErrorInvalidSyncToken)
+	})
+
+	ctrl.Finish()
+}
+
+func TestService_Push(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	mockTodoService := todos.NewMockService(ctrl)
+	s := GetService(zap.NewNop().Sugar(), mockRepo, mockTodoService, validator.New())
+	ctx := context.Background()
+
+	t.Run("create resolves parents and is applied once", func(t *testing.T) {
+		mockRepo.EXPECT().GetClientItem(ctx, uint(1), "a1").Return(ClientItem{}, false, nil).Times(1)
+		mockRepo.EXPECT().GetClientItem(ctx, uint(1), "a0").Return(ClientItem{ItemId: 4}, true, nil).Times(1)
+		mockTodoService.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, item *todos.ToDoItem) error {
+				assert.Equal(t, uint(0), item.ID)
+				assert.Equal(t, uint(1), item.UserId)
+				assert.Equal(t, uint(4), *item.ParentId)
+				item.ID = 9
+				return nil
+			}).
+			Times(1)
+		mockRepo.EXPECT().CreateClientItem(ctx, &ClientItem{UserId: 1, ClientId: "a1", ItemId: 9}).Return(nil).Times(1)
+
+		mockRepo.EXPECT().GetClientItem(ctx, uint(1), "a2").Return(ClientItem{ItemId: 8}, true, nil).Times(1)
+		mockRepo.EXPECT().GetItem(ctx, uint(8)).Return(todos.ToDoItem{Model: gorm.Model{ID: 8}, UserId: 1}, nil).Times(1)
+
+		result, err := s.Push(ctx, 1, PushInput{Mutations: []Mutation{
+			{Op: OpCreate, ClientId: "a1", ParentClientId: "a0", Item: &todos.ToDoItem{Model: gorm.Model{ID: 77}, UserId: 2, Text: "Draft"}, UpdatedAt: before},
+			{Op: OpCreate, ClientId: "a2", Item: &todos.ToDoItem{Text: "Sent twice"}, UpdatedAt: before},
+		}})
+		assert.NoError(t, err)
+		assert.Equal(t, StrategyLastWriterWins, result.Strategy)
+		assert.Equal(t, MutationResult{Index: 0, ClientId: "a1", Id: 9, Status: StatusApplied, Item: result.Results[0].Item}, result.Results[0])
+		assert.Equal(t, uint(8), result.Results[1].Id)
+		assert.Equal(t, 1, result.Results[1].Index)
+		assert.Empty(t, result.Conflicts)
+	})
+
+	t.Run("last writer wins", func(t *testing.T) {
+		done := true
+		mockRepo.EXPECT().GetItem(ctx, uint(3)).Return(todos.ToDoItem{Model: gorm.Model{ID: 3, UpdatedAt: after}, UserId: 1}, nil).Times(1)
+		mockRepo.EXPECT().GetItem(ctx, uint(4)).Return(todos.ToDoItem{Model: gorm.Model{ID: 4, UpdatedAt: before}, UserId: 1}, nil).Times(1)
+		mockTodoService.
+			EXPECT().
+			UpdateById(ctx, uint(4), todos.ToDoItemUpdateInput{Done: &done}).
+			Return(todos.ToDoItem{Model: gorm.Model{ID: 4}, Done: true}, nil).
+			Times(1)
+		mockRepo.EXPECT().GetItem(ctx, uint(5)).Return(todos.ToDoItem{Model: gorm.Model{ID: 5}, UserId: 2}, nil).Times(1)
+
+		changes := &todos.ToDoItemUpdateInput{Done: &done}
+		result, err := s.Push(ctx, 1, PushInput{Mutations: []Mutation{
+			{Op: OpUpdate, Id: 3, Changes: changes, UpdatedAt: before.Add(time.Hour)},
+			{Op: OpUpdate, Id: 4, Changes: changes, UpdatedAt: before.Add(time.Hour)},
+			{Op: OpUpdate, Id: 5, Changes: changes, UpdatedAt: after},
+		}})
+		assert.NoError(t, err)
+		assert.Equal(t, StatusConflict, result.Results[0].Status)
+		assert.Equal(t, StatusApplied, result.Results[1].Status)
+		assert.Equal(t, StatusFailed, result.Results[2].Status)
+		assert.Equal(t, locale.ErrorNotFoundRecord, result.Results[2].Error)
+		assert.Len(t, result.Conflicts, 1)
+		assert.Equal(t, Conflict{Index: 0, Id: 3, Reason: ConflictServerNewer, Resolution: ResolutionServer, Server: result.Conflicts[0].Server}, result.Conflicts[0])
+	})
+
+	t.Run("merge applies the fields only the client changed", func(t *testing.T) {
+		server := todos.ToDoItem{Model: gorm.Model{ID: 6, UpdatedAt: after}, UserId: 1, Text: "Renamed on the web", Priority: "low", Tags: []todos.Tag{{Name: "work"}}}
+		mockRepo.EXPECT().GetItem(ctx, uint(6)).Return(server, nil).Times(1)
+
+		high := "high"
+		tags := []string{"#Work"}
+		text, oldText := "Renamed offline", "Report"
+		low := "low"
+		mockTodoService.
+			EXPECT().
+			UpdateById(ctx, uint(6), todos.ToDoItemUpdateInput{Priority: &high}).
+			Return(todos.ToDoItem{Model: gorm.Model{ID: 6}, Text: "Renamed on the web", Priority: "high"}, nil).
+			Times(1)
+
+		result, err := s.Push(ctx, 1, PushInput{Strategy: StrategyMerge, Mutations: []Mutation{{
+			Op:        OpUpdate,
+			Id:        6,
+			Changes:   &todos.ToDoItemUpdateInput{Text: &text, Priority: &high, Tags: &tags},
+			Base:      &todos.ToDoItemUpdateInput{Text: &oldText, Priority: &low},
+			UpdatedAt: before,
+		}}})
+		assert.NoError(t, err)
+		assert.Equal(t, StatusMerged, result.Results[0].Status)
+		assert.Equal(t, []string{"text"}, result.Conflicts[0].Fields)
+		assert.Equal(t, ResolutionServer, result.Conflicts[0].Resolution)
+		assert.Equal(t, "high", result.Conflicts[0].Server.Priority)
+	})
+
+	t.Run("merge lets the newer client win conflicting fields", func(t *testing.T) {
+		mockRepo.EXPECT().GetItem(ctx, uint(7)).Return(todos.ToDoItem{Model: gorm.Model{ID: 7, UpdatedAt: before}, UserId: 1, Text: "Web"}, nil).Times(1)
+
+		text := "Phone"
+		mockTodoService.
+			EXPECT().
+			UpdateById(ctx, uint(7), todos.ToDoItemUpdateInput{Text: &text}).
+			Return(todos.ToDoItem{Model: gorm.Model{ID: 7}, Text: "Phone"}, nil).
+			Times(1)
+
+		result, err := s.Push(ctx, 1, PushInput{Strategy: StrategyMerge, Mutations: []Mutation{
+			{Op: OpUpdate, Id: 7, Changes: &todos.ToDoItemUpdateInput{Text: &text}, UpdatedAt: after},
+		}})
+		assert.NoError(t, err)
+		assert.Equal(t, StatusApplied, result.Results[0].Status)
+		assert.Equal(t, ResolutionClient, result.Conflicts[0].Resolution)
+	})
+
+	t.Run("deleted items", func(t *testing.T) {
+		deleted := todos.ToDoItem{Model: gorm.Model{ID: 8, DeletedAt: gorm.DeletedAt{Time: before, Valid: true}}, UserId: 1}
+		mockRepo.EXPECT().GetItem(ctx, uint(8)).Return(deleted, nil).Times(2)
+		mockRepo.EXPECT().GetItem(ctx, uint(9)).Return(todos.ToDoItem{Model: gorm.Model{ID: 9, UpdatedAt: before}, UserId: 1}, nil).Times(1)
+		mockTodoService.EXPECT().DeleteById(ctx, uint(9)).Return(nil).Times(1)
+
+		done := true
+		result, err := s.Push(ctx, 1, PushInput{Mutations: []Mutation{
+			{Op: OpUpdate, Id: 8, Changes: &todos.ToDoItemUpdateInput{Done: &done}, UpdatedAt: after},
+			{Op: OpDelete, Id: 8, UpdatedAt: after},
+			{Op: OpDelete, Id: 9, UpdatedAt: after},
+		}})
+		assert.NoError(t, err)
+		assert.Equal(t, StatusConflict, result.Results[0].Status)
+		assert.Equal(t, ConflictDeleted, result.Conflicts[0].Reason)
+		assert.Equal(t, StatusApplied, result.Results[1].Status)
+		assert.Equal(t, StatusApplied, result.Results[2].Status)
+	})
+
+	t.Run("invalid mutations", func(t *testing.T) {
+		_, err := s.Push(ctx, 1, PushInput{Mutations: []Mutation{{Op: OpCreate, UpdatedAt: after}}})
+		assert.Error(t, err)
+
+		_, err = s.Push(ctx, 1, PushInput{Strategy: "newest", Mutations: []Mutation{}})
+		assert.Error(t, err)
+	})
+
+	ctrl.Finish()
+}
+
+func TestService_HandleTodoEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	s := GetService(zap.NewNop().Sugar(), mockRepo, todos.NewMockService(ctrl), validator.New())
+
+	mockRepo.EXPECT().RecordChange(gomock.Any(), &Change{UserId: 1, ItemId: 3, Type: todos.EventDeleted}).Return(nil).Times(1)
+
+	s.HandleTodoEvent(context.Background(), todos.Event{Type: todos.EventDeleted, UserId: 1, Item: todos.ToDoItem{Model: gorm.Model{ID: 3}}})
+
+	ctrl.Finish()
+}
@@ -0,0 +1,158 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"todo-app/internal/auth"
+	"todo-app/internal/todos"
+	e "todo-app/pkg/errors"
+	"todo-app/pkg/handlers"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	// heartbeatInterval : how often an idle stream gets a comment, so that proxies keep
+	// the connection open
+	heartbeatInterval = 15 * time.Second
+	// retryMillis : how long clients wait before reconnecting
+	retryMillis = 3000
+)
+
+// EventReset : tells a resuming client that events were missed and it has to reload
+const EventReset = "reset"
+
+type endpointHandler struct {
+	logger      *zap.SugaredLogger
+	hub         Hub
+	authService auth.Service
+	todoService todos.Service
+	e           *echo.Echo
+	heartbeat   time.Duration
+	upgrader    websocket.Upgrader
+}
+
+func GetEndpointHandler(
+	logger *zap.SugaredLogger,
+	hub Hub,
+	authService auth.Service,
+	todoService todos.Service,
+	e *echo.Echo,
+) handlers.EndpointHandler {
+	return &endpointHandler{
+		logger:      logger,
+		hub:         hub,
+		authService: authService,
+		todoService: todoService,
+		e:           e,
+		heartbeat:   heartbeatInterval,
+	}
+}
+
+func (h *endpointHandler) AddEndpoints() {
+	var endpoints = []handlers.Endpoint{
+		{
+			Method:  http.MethodGet,
+			Path:    "/events",
+			Handler: h.stream,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/ws",
+			Handler: h.connect,
+		},
+	}
+
+	for _, endpoint := range endpoints {
+		handlers.Method(h.e, endpoint.Method, endpoint.Path, endpoint.Handler)
+	}
+}
+
+// @Summary Event stream
+// @Description This endpoint streams the created, updated and deleted events of the current user's todo items as Server-Sent Events.
+// @Description Every event has an id; a client reconnecting with the Last-Event-ID header first gets the events it missed.
+// @Description When those are no longer buffered it gets a reset event and should reload its items.
+// @Tags events
+// @ID streamEvents
+// @Security BearerAuth
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "ID of the last event the client received"
+// @Success 200 {string} string "Event stream"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Router /events [get]
+func (h *endpointHandler) stream(ctx echo.Context) error {
+	h.logger.Infow("streaming events...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	lastEventId := ctx.Request().Header.Get("Last-Event-ID")
+	if lastEventId == "" {
+		// EventSource cannot set headers on the first connection
+		lastEventId = ctx.QueryParam("last_event_id")
+	}
+	subscription, missed, resumed := h.hub.Subscribe(userId, lastEventId)
+	defer h.hub.Unsubscribe(subscription)
+
+	response := ctx.Response()
+	response.Header().Set(echo.HeaderContentType, "text/event-stream")
+	response.Header().Set(echo.HeaderCacheControl, "no-cache")
+	response.Header().Set(echo.HeaderConnection, "keep-alive")
+	// stops nginx from buffering the stream
+	response.Header().Set("X-Accel-Buffering", "no")
+	response.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(response, "retry: %d\n\n", retryMillis); err != nil {
+		return nil
+	}
+	if !resumed {
+		if _, err := fmt.Fprintf(response, "event: %s\ndata: {}\n\n", EventReset); err != nil {
+			return nil
+		}
+	}
+	for _, message := range missed {
+		if err := writeMessage(response, message); err != nil {
+			return nil
+		}
+	}
+	response.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Request().Context().Done():
+			return nil
+		case message, ok := <-subscription.Messages:
+			if !ok {
+				// dropped for falling behind, the client reconnects and resumes
+				return nil
+			}
+			if err := writeMessage(response, message); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(response, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+		}
+		response.Flush()
+	}
+}
+
+func writeMessage(response *echo.Response, message Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(response, "id: %d\nevent: %s\ndata: %s\n\n", message.ID, message.Type, data)
+
+	return err
+}
@@ -0,0 +1,103 @@
+package events
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/todos"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func subscriptionCount(h *hub, userId uint) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.subscriptions[userId])
+}
+
+func TestHandler_Stream(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+
+	newContext := func(userId uint, lastEventId string) (echo.Context, *httptest.ResponseRecorder, context.CancelFunc) {
+		requestCtx, cancel := context.WithCancel(context.Background())
+		req := httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(requestCtx)
+		if lastEventId != "" {
+			req.Header.Set("Last-Event-ID", lastEventId)
+		}
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", userId)
+
+		return ctx, rec, cancel
+	}
+
+	t.Run("streams the user's events", func(t *testing.T) {
+		eventHub := GetHub(logger).(*hub)
+		h := &endpointHandler{logger: logger, hub: eventHub, e: e, heartbeat: 10 * time.Millisecond}
+		eventHub.Publish(context.Background(), event(todos.EventCreated, 1, 10))
+		eventHub.Publish(context.Background(), event(todos.EventCreated, 2, 20))
+
+		ctx, rec, cancel := newContext(1, "0")
+		done := make(chan error)
+		go func() { done <- h.stream(ctx) }()
+
+		// wait for the subscription before publishing
+		assert.Eventually(t, func() bool {
+			return subscriptionCount(eventHub, 1) == 1
+		}, time.Second, time.Millisecond)
+		eventHub.Publish(context.Background(), event(todos.EventDeleted, 1, 11))
+		eventHub.Publish(context.Background(), event(todos.EventDeleted, 2, 21))
+		time.Sleep(30 * time.Millisecond)
+		cancel()
+		assert.NoError(t, <-done)
+
+		body := rec.Body.String()
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/event-stream", rec.Header().Get(echo.HeaderContentType))
+		assert.True(t, strings.HasPrefix(body, "retry: 3000\n\n"))
+		assert.Contains(t, body, "id: 1\nevent: created\ndata: {\"id\":1,\"type\":\"created\"")
+		assert.Contains(t, body, "id: 3\nevent: deleted\n")
+		assert.NotContains(t, body, "id: 2\n")
+		assert.NotContains(t, body, "id: 4\n")
+		assert.NotContains(t, body, "event: reset")
+		assert.Contains(t, body, ": heartbeat\n\n")
+		assert.Equal(t, 0, subscriptionCount(eventHub, 1))
+	})
+
+	t.Run("unknown last event id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockHub := NewMockHub(ctrl)
+		h := &endpointHandler{logger: logger, hub: mockHub, e: e, heartbeat: time.Minute}
+
+		ctx, rec, cancel := newContext(1, "42")
+		subscription := &Subscription{UserId: 1, Messages: make(chan Message)}
+		mockHub.EXPECT().Subscribe(uint(1), "42").Return(subscription, nil, false).Times(1)
+		mockHub.EXPECT().Unsubscribe(subscription).Times(1)
+		cancel()
+
+		assert.NoError(t, h.stream(ctx))
+		assert.Contains(t, rec.Body.String(), "event: reset\ndata: {}\n\n")
+
+		ctrl.Finish()
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		h := &endpointHandler{logger: logger, hub: GetHub(logger), e: e, heartbeat: time.Minute}
+		ctx, rec, cancel := newContext(0, "")
+		defer cancel()
+
+		if assert.NoError(t, h.stream(ctx)) {
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package events
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+	"todo-app/internal/todos"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// replaySize : the number of recent events kept for clients that resume
+	replaySize = 1000
+	// subscriptionBuffer : the events a client may fall behind before it is dropped
+	subscriptionBuffer = 64
+)
+
+type Hub interface {
+	Publish(ctx context.Context, event todos.Event)
+	Subscribe(userId uint, lastEventId string) (*Subscription, []Message, bool)
+	Unsubscribe(subscription *Subscription)
+}
+
+type originKey struct{}
+
+// WithOrigin : a context for writes made by a WebSocket session, their events are not
+// sent back to the session
+func WithOrigin(ctx context.Context, sessionId string) context.Context {
+	return context.WithValue(ctx, originKey{}, sessionId)
+}
+
+type hub struct {
+	logger        *zap.SugaredLogger
+	mu            sync.Mutex
+	lastId        uint64
+	replay        []Message
+	subscriptions map[uint]map[*Subscription]bool
+	now           func() time.Time
+}
+
+func GetHub(logger *zap.SugaredLogger) Hub {
+	return &hub{
+		logger:        logger,
+		replay:        make([]Message, 0, replaySize),
+		subscriptions: map[uint]map[*Subscription]bool{},
+		now:           time.Now,
+	}
+}
+
+// Publish : sends a todo event to the subscriptions of its user. It is a todos.Listener
+// and never blocks, subscriptions whose buffer is full are dropped.
+func (h *hub) Publish(ctx context.Context, event todos.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastId++
+	message := Message{
+		ID:     h.lastId,
+		UserId: event.UserId,
+		Type:   event.Type,
+		Item:   event.Item,
+		At:     h.now().UTC(),
+	}
+	message.Origin, _ = ctx.Value(originKey{}).(string)
+	if len(h.replay) == replaySize {
+		copy(h.replay, h.replay[1:])
+		h.replay = h.replay[:replaySize-1]
+	}
+	h.replay = append(h.replay, message)
+
+	for subscription := range h.subscriptions[event.UserId] {
+		select {
+		case subscription.Messages <- message:
+		default:
+			h.logger.Warnw("dropping slow event subscription", "user_id", event.UserId)
+			h.remove(subscription)
+		}
+	}
+}
+
+// Subscribe : a subscription for the user's events and, when lastEventId is set, the
+// events after it. The bool is false when events after lastEventId are no longer
+// buffered or the id is unknown, the client then has to reload instead of resuming.
+func (h *hub) Subscribe(userId uint, lastEventId string) (*Subscription, []Message, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subscription := &Subscription{UserId: userId, Messages: make(chan Message, subscriptionBuffer)}
+	if h.subscriptions[userId] == nil {
+		h.subscriptions[userId] = map[*Subscription]bool{}
+	}
+	h.subscriptions[userId][subscription] = true
+
+	if lastEventId == "" {
+		return subscription, nil, true
+	}
+
+	last, err := strconv.ParseUint(lastEventId, 10, 64)
+	// ids start over when the server restarts
+	if err != nil || last > h.lastId {
+		return subscription, nil, false
+	}
+	if last < h.lastId && (len(h.replay) == 0 || h.replay[0].ID > last+1) {
+		return subscription, nil, false
+	}
+
+	var missed []Message
+	for _, message := range h.replay {
+		if message.ID > last && message.UserId == userId {
+			missed = append(missed, message)
+		}
+	}
+
+	return subscription, missed, true
+}
+
+func (h *hub) Unsubscribe(subscription *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.remove(subscription)
+}
+
+// remove : needs h.mu to be held
+func (h *hub) remove(subscription *Subscription) {
+	subscriptions := h.subscriptions[subscription.UserId]
+	if !subscriptions[subscription] {
+		return
+	}
+
+	delete(subscriptions, subscription)
+	if len(subscriptions) == 0 {
+		delete(h.subscriptions, subscription.UserId)
+	}
+	close(subscription.Messages)
+}
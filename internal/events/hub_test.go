@@ -0,0 +1,102 @@
+package events
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"todo-app/internal/todos"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func event(eventType string, userId uint, id uint) todos.Event {
+	return todos.Event{Type: eventType, UserId: userId, Item: todos.ToDoItem{Model: gorm.Model{ID: id}, UserId: userId}}
+}
+
+func TestHub_Publish(t *testing.T) {
+	h := GetHub(zap.NewNop().Sugar())
+	ctx := context.Background()
+
+	own, _, _ := h.Subscribe(1, "")
+	other, _, _ := h.Subscribe(2, "")
+
+	h.Publish(ctx, event(todos.EventCreated, 1, 10))
+	h.Publish(ctx, event(todos.EventUpdated, 2, 20))
+
+	message := <-own.Messages
+	assert.Equal(t, uint64(1), message.ID)
+	assert.Equal(t, todos.EventCreated, message.Type)
+	assert.Equal(t, uint(10), message.Item.ID)
+	assert.Len(t, own.Messages, 0)
+
+	message = <-other.Messages
+	assert.Equal(t, uint64(2), message.ID)
+
+	t.Run("slow subscriptions are dropped", func(t *testing.T) {
+		for i := 0; i <= subscriptionBuffer; i++ {
+			h.Publish(ctx, event(todos.EventUpdated, 1, 10))
+		}
+
+		count := 0
+		for range own.Messages {
+			count++
+		}
+		assert.Equal(t, subscriptionBuffer, count)
+
+		// unsubscribing a dropped subscription does not close its channel twice
+		h.Unsubscribe(own)
+	})
+
+	t.Run("unsubscribe closes the channel", func(t *testing.T) {
+		h.Unsubscribe(other)
+		_, ok := <-other.Messages
+		assert.False(t, ok)
+	})
+}
+
+func TestHub_Subscribe(t *testing.T) {
+	h := GetHub(zap.NewNop().Sugar())
+	ctx := context.Background()
+
+	for i := uint(1); i <= 4; i++ {
+		h.Publish(ctx, event(todos.EventCreated, 1+i%2, i))
+	}
+
+	t.Run("replays the user's events after the last id", func(t *testing.T) {
+		_, missed, resumed := h.Subscribe(2, "1")
+		assert.True(t, resumed)
+		assert.Len(t, missed, 1)
+		assert.Equal(t, uint64(3), missed[0].ID)
+	})
+
+	t.Run("nothing missed", func(t *testing.T) {
+		_, missed, resumed := h.Subscribe(1, "4")
+		assert.True(t, resumed)
+		assert.Empty(t, missed)
+	})
+
+	t.Run("id from before a restart", func(t *testing.T) {
+		_, _, resumed := h.Subscribe(1, "99")
+		assert.False(t, resumed)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		_, _, resumed := h.Subscribe(1, "abc")
+		assert.False(t, resumed)
+	})
+
+	t.Run("events no longer buffered", func(t *testing.T) {
+		for i := 0; i < replaySize; i++ {
+			h.Publish(ctx, event(todos.EventUpdated, 3, 1))
+		}
+
+		_, _, resumed := h.Subscribe(1, "2")
+		assert.False(t, resumed)
+
+		_, missed, resumed := h.Subscribe(3, strconv.Itoa(replaySize))
+		assert.True(t, resumed)
+		assert.Len(t, missed, 4)
+	})
+}
@@ -0,0 +1,82 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/events/hub.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/events/hub.go -destination=internal/events/mock_hub.go -package=events
+//
+
+// Package events is a generated GoMock package.
+package events
+
+import (
+	context "context"
+	reflect "reflect"
+	todos "todo-app/internal/todos"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockHub is a mock of Hub interface.
+type MockHub struct {
+	ctrl     *gomock.Controller
+	recorder *MockHubMockRecorder
+	isgomock struct{}
+}
+
+// MockHubMockRecorder is the mock recorder for MockHub.
+type MockHubMockRecorder struct {
+	mock *MockHub
+}
+
+// NewMockHub creates a new mock instance.
+func NewMockHub(ctrl *gomock.Controller) *MockHub {
+	mock := &MockHub{ctrl: ctrl}
+	mock.recorder = &MockHubMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHub) EXPECT() *MockHubMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockHub) Publish(ctx context.Context, event todos.Event) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Publish", ctx, event)
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockHubMockRecorder) Publish(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockHub)(nil).Publish), ctx, event)
+}
+
+// Subscribe mocks base method.
+func (m *MockHub) Subscribe(userId uint, lastEventId string) (*Subscription, []Message, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", userId, lastEventId)
+	ret0, _ := ret[0].(*Subscription)
+	ret1, _ := ret[1].([]Message)
+	ret2, _ := ret[2].(bool)
+	return ret0, ret1, ret2
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockHubMockRecorder) Subscribe(userId, lastEventId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockHub)(nil).Subscribe), userId, lastEventId)
+}
+
+// Unsubscribe mocks base method.
+func (m *MockHub) Unsubscribe(subscription *Subscription) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Unsubscribe", subscription)
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe.
+func (mr *MockHubMockRecorder) Unsubscribe(subscription any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockHub)(nil).Unsubscribe), subscription)
+}
@@ -0,0 +1,73 @@
+package events
+
+import (
+	"time"
+	"todo-app/internal/todos"
+)
+
+// Message : a todo event as it is sent to clients. IDs increase with every event of
+// the server process, clients resume from the last ID they saw.
+type Message struct {
+	ID     uint64         `json:"id"`
+	UserId uint           `json:"-"`
+	Type   string         `json:"type"`
+	Item   todos.ToDoItem `json:"item"`
+	At     time.Time      `json:"at"`
+	// Origin : the WebSocket session that made the change, empty for other clients
+	Origin string `json:"-"`
+}
+
+// Subscription : the messages for one connected client. Messages is closed when the
+// client falls too far behind, it then has to reconnect and resume.
+type Subscription struct {
+	UserId   uint
+	Messages chan Message
+}
+
+const (
+	MessageSubscribe   = "subscribe"
+	MessageUnsubscribe = "unsubscribe"
+	MessageCreate      = "create"
+	MessageUpdate      = "update"
+	MessageDelete      = "delete"
+	MessagePing        = "ping"
+
+	MessageAck   = "ack"
+	MessageError = "error"
+	MessageEvent = "event"
+	MessagePong  = "pong"
+)
+
+const (
+	// CollectionTodos : all todo items of the user
+	CollectionTodos = "todos"
+	// CollectionHabits : the habits of the user
+	CollectionHabits = "habits"
+	// CollectionTagPrefix : tag:<name>, the items with the tag
+	CollectionTagPrefix = "tag:"
+	// CollectionItemPrefix : item:<id>, the item and its sub-tasks
+	CollectionItemPrefix = "item:"
+)
+
+// ClientMessage : a request sent over the WebSocket. ID is chosen by the client and
+// repeated in the answer.
+type ClientMessage struct {
+	ID         string                     `json:"id"`
+	Type       string                     `json:"type"`
+	Collection string                     `json:"collection,omitempty"`
+	ItemId     uint                       `json:"item_id,omitempty"`
+	Item       *todos.ToDoItem            `json:"item,omitempty"`
+	Changes    *todos.ToDoItemUpdateInput `json:"changes,omitempty"`
+}
+
+// ServerMessage : an answer to a client message, or an event of a subscribed collection
+type ServerMessage struct {
+	ID          string          `json:"id,omitempty"`
+	Type        string          `json:"type"`
+	Event       string          `json:"event,omitempty"`
+	EventId     uint64          `json:"event_id,omitempty"`
+	Collections []string        `json:"collections,omitempty"`
+	Item        *todos.ToDoItem `json:"item,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	Details     string          `json:"details,omitempty"`
+}
@@ -0,0 +1,349 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"todo-app/internal/todos"
+	"todo-app/pkg/authz"
+	e "todo-app/pkg/errors"
+	"todo-app/pkg/locale"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxMessageSize : the largest message a client may send, in bytes
+	maxMessageSize = 64 << 10
+	// pongWait : how long a connection may stay silent before it is closed
+	pongWait = 60 * time.Second
+	// pingPeriod : how often the server pings, must be shorter than pongWait
+	pingPeriod = 50 * time.Second
+	// writeWait : how long writing a single message may take
+	writeWait = 10 * time.Second
+	// sendBuffer : the answers waiting to be written before the session is closed
+	sendBuffer = 64
+)
+
+// session : one WebSocket connection. The handler goroutine reads and handles client
+// messages, a second goroutine writes answers and the events of subscribed collections.
+type session struct {
+	id           string
+	userId       uint
+	logger       *zap.SugaredLogger
+	conn         *websocket.Conn
+	todoService  todos.Service
+	subscription *Subscription
+	send         chan ServerMessage
+	done         chan struct{}
+
+	mu          sync.Mutex
+	collections map[string]bool
+}
+
+// websocketToken : the JWT from the Authorization header, or from the access_token
+// parameter since browsers cannot set headers on WebSocket requests
+func websocketToken(ctx echo.Context) string {
+	if token := strings.TrimPrefix(ctx.Request().Header.Get("Authorization"), "Bearer "); token != "" {
+		return token
+	}
+
+	return ctx.QueryParam("access_token")
+}
+
+// @Summary WebSocket
+// @Description This endpoint upgrades to a WebSocket for live collaboration. The JWT is checked on connect and taken
+// @Description from the Authorization header or the access_token parameter. Clients subscribe to collections
+// @Description (todos, habits, tag:<name>, item:<id>), get their create, update and delete events, and create,
+// @Description update and delete items over the socket. Other sessions of the user get the events of these changes.
+// @Tags events
+// @ID websocket
+// @Param access_token query string false "JWT, when the Authorization header cannot be set"
+// @Success 101 {string} string "Switching Protocols"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Router /ws [get]
+func (h *endpointHandler) connect(ctx echo.Context) error {
+	h.logger.Infow("opening websocket...")
+
+	token := websocketToken(ctx)
+	if token == "" {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: locale.ErrorMissingToken})
+	}
+	claims, err := h.authService.ValidateToken(token)
+	if err != nil {
+		h.logger.Warnw("invalid websocket token", "error", err.Error())
+
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: locale.ErrorInvalidToken})
+	}
+	if err := h.authService.CheckRevocation(ctx.Request().Context(), claims); err != nil {
+		h.logger.Warnw("revoked websocket token", "user_id", claims.UserID, "error", err.Error())
+
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: locale.ErrorInvalidToken})
+	}
+
+	conn, err := h.upgrader.Upgrade(ctx.Response(), ctx.Request(), nil)
+	if err != nil {
+		// the upgrader already answered the request
+		h.logger.Warnw("could not upgrade to websocket", "error", err.Error())
+
+		return nil
+	}
+
+	subscription, _, _ := h.hub.Subscribe(claims.UserID, "")
+	s := &session{
+		id:           newSessionId(),
+		userId:       claims.UserID,
+		logger:       h.logger,
+		conn:         conn,
+		todoService:  h.todoService,
+		subscription: subscription,
+		send:         make(chan ServerMessage, sendBuffer),
+		done:         make(chan struct{}),
+		collections:  map[string]bool{},
+	}
+
+	go s.write()
+	s.read()
+
+	close(s.done)
+	h.hub.Unsubscribe(subscription)
+
+	return nil
+}
+
+func newSessionId() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// read : handles client messages until the connection is closed
+func (s *session) read() {
+	defer s.conn.Close()
+
+	s.conn.SetReadLimit(maxMessageSize)
+	_ = s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				s.logger.Warnw("websocket closed", "user_id", s.userId, "error", err)
+			}
+			return
+		}
+
+		var message ClientMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			s.reply(ServerMessage{Type: MessageError, Error: locale.ErrorInvalidMessage, Details: err.Error()})
+			continue
+		}
+		if !s.reply(s.handle(message)) {
+			return
+		}
+	}
+}
+
+// reply : queues an answer, false when the client does not read its answers
+func (s *session) reply(message ServerMessage) bool {
+	select {
+	case s.send <- message:
+		return true
+	default:
+		s.logger.Warnw("closing websocket of slow client", "user_id", s.userId)
+		return false
+	}
+}
+
+// write : writes answers, the events of subscribed collections and pings until the
+// session ends
+func (s *session) write() {
+	ping := time.NewTicker(pingPeriod)
+	defer func() {
+		ping.Stop()
+		s.conn.Close()
+	}()
+
+	for {
+		var err error
+		select {
+		case <-s.done:
+			return
+		case message := <-s.send:
+			err = s.writeJSON(message)
+		case event, ok := <-s.subscription.Messages:
+			if !ok {
+				// dropped by the hub for falling behind
+				return
+			}
+			if collections := s.matching(event); len(collections) > 0 {
+				item := event.Item
+				err = s.writeJSON(ServerMessage{Type: MessageEvent, Event: event.Type, EventId: event.ID, Collections: collections, Item: &item})
+			}
+		case <-ping.C:
+			_ = s.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			err = s.conn.WriteMessage(websocket.PingMessage, nil)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *session) writeJSON(message ServerMessage) error {
+	_ = s.conn.SetWriteDeadline(time.Now().Add(writeWait))
+
+	return s.conn.WriteJSON(message)
+}
+
+// matching : the subscribed collections the event's item belongs to, none for changes
+// the session made itself since it already got them as answers
+func (s *session) matching(event Message) []string {
+	if event.Origin == s.id {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var collections []string
+	for collection := range s.collections {
+		if inCollection(collection, event.Item) {
+			collections = append(collections, collection)
+		}
+	}
+	sort.Strings(collections)
+
+	return collections
+}
+
+func inCollection(collection string, item todos.ToDoItem) bool {
+	switch {
+	case collection == CollectionTodos:
+		return true
+	case collection == CollectionHabits:
+		return item.Type == todos.TypeHabit
+	case strings.HasPrefix(collection, CollectionTagPrefix):
+		name := strings.TrimPrefix(collection, CollectionTagPrefix)
+		for _, tag := range item.Tags {
+			if tag.Name == name {
+				return true
+			}
+		}
+	case strings.HasPrefix(collection, CollectionItemPrefix):
+		id, _ := strconv.ParseUint(strings.TrimPrefix(collection, CollectionItemPrefix), 10, 64)
+		return item.ID == uint(id) || (item.ParentId != nil && *item.ParentId == uint(id))
+	}
+
+	return false
+}
+
+// validCollection : the collection name as it is matched, tags are stored lowercased
+func validCollection(collection string) (string, bool) {
+	switch {
+	case collection == CollectionTodos || collection == CollectionHabits:
+		return collection, true
+	case strings.HasPrefix(collection, CollectionTagPrefix):
+		name := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(collection, CollectionTagPrefix)))
+		return CollectionTagPrefix + name, name != ""
+	case strings.HasPrefix(collection, CollectionItemPrefix):
+		id, err := strconv.ParseUint(strings.TrimPrefix(collection, CollectionItemPrefix), 10, 64)
+		return CollectionItemPrefix + strconv.FormatUint(id, 10), err == nil && id > 0
+	}
+
+	return "", false
+}
+
+// handle : the answer to a client message
+func (s *session) handle(message ClientMessage) ServerMessage {
+	ctx := WithOrigin(context.Background(), s.id)
+
+	switch message.Type {
+	case MessagePing:
+		return ServerMessage{ID: message.ID, Type: MessagePong}
+	case MessageSubscribe, MessageUnsubscribe:
+		collection, ok := validCollection(message.Collection)
+		if !ok {
+			return s.error(message, locale.ErrorInvalidCollection, nil)
+		}
+		s.mu.Lock()
+		if message.Type == MessageSubscribe {
+			s.collections[collection] = true
+		} else {
+			delete(s.collections, collection)
+		}
+		s.mu.Unlock()
+
+		return ServerMessage{ID: message.ID, Type: MessageAck, Collections: []string{collection}}
+	case MessageCreate:
+		if message.Item == nil {
+			return s.error(message, locale.ErrorInvalidMessage, nil)
+		}
+		item := *message.Item
+		item.Model = gorm.Model{}
+		item.UserId = s.userId
+		if err := s.todoService.Create(ctx, &item); err != nil {
+			return s.error(message, locale.ErrorInvalidTodoItem, err)
+		}
+
+		return ServerMessage{ID: message.ID, Type: MessageAck, Item: &item}
+	case MessageUpdate:
+		if message.Changes == nil {
+			return s.error(message, locale.ErrorInvalidMessage, nil)
+		}
+		if err := s.checkOwner(ctx, message.ItemId, authz.ActionUpdate); err != nil {
+			return s.error(message, err.Error(), nil)
+		}
+		item, err := s.todoService.UpdateById(ctx, message.ItemId, *message.Changes)
+		if err != nil {
+			return s.error(message, locale.ErrorInvalidTodoItem, err)
+		}
+
+		return ServerMessage{ID: message.ID, Type: MessageAck, Item: &item}
+	case MessageDelete:
+		if err := s.checkOwner(ctx, message.ItemId, authz.ActionDelete); err != nil {
+			return s.error(message, err.Error(), nil)
+		}
+		if err := s.todoService.DeleteById(ctx, message.ItemId); err != nil {
+			return s.error(message, locale.ErrorCouldNotDelete, err)
+		}
+
+		return ServerMessage{ID: message.ID, Type: MessageAck}
+	}
+
+	return s.error(message, locale.ErrorInvalidMessage, nil)
+}
+
+// checkOwner : whether the user of the session may perform the action on the item
+func (s *session) checkOwner(ctx context.Context, id uint, action authz.Action) error {
+	item, err := s.todoService.GetById(ctx, id)
+	if err != nil {
+		return errors.New(locale.ErrorNotFoundRecord)
+	}
+
+	return authz.Authorize(todos.ItemPolicy, s.userId, action, item)
+}
+
+func (s *session) error(message ClientMessage, key string, err error) ServerMessage {
+	answer := ServerMessage{ID: message.ID, Type: MessageError, Error: key}
+	if err != nil {
+		answer.Details = err.Error()
+	}
+
+	return answer
+}
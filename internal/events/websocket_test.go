@@ -0,0 +1,181 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/auth"
+	"todo-app/internal/todos"
+	"todo-app/pkg/locale"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestHandler_WebSocket(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockAuthService := auth.NewMockService(ctrl)
+	mockTodoService := todos.NewMockService(ctrl)
+	logger := zap.NewNop().Sugar()
+	e := echo.New()
+	eventHub := GetHub(logger).(*hub)
+	h := GetEndpointHandler(logger, eventHub, mockAuthService, mockTodoService, e)
+	h.AddEndpoints()
+
+	server := httptest.NewServer(e)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+
+	mockAuthService.EXPECT().ValidateToken("valid").Return(&auth.JWTClaims{UserID: 1}, nil).AnyTimes()
+	mockAuthService.EXPECT().ValidateToken("other").Return(&auth.JWTClaims{UserID: 2}, nil).AnyTimes()
+	mockAuthService.EXPECT().ValidateToken("expired").Return(nil, errors.New("token expired")).AnyTimes()
+	mockAuthService.EXPECT().CheckRevocation(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	dial := func(token string) *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial(url+"?access_token="+token, nil)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return conn
+	}
+	request := func(conn *websocket.Conn, message ClientMessage) ServerMessage {
+		assert.NoError(t, conn.WriteJSON(message))
+		var answer ServerMessage
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		assert.NoError(t, conn.ReadJSON(&answer))
+		return answer
+	}
+
+	t.Run("changes reach the other sessions of the user", func(t *testing.T) {
+		writer := dial("valid")
+		defer writer.Close()
+		reader := dial("valid")
+		defer reader.Close()
+		stranger := dial("other")
+		defer stranger.Close()
+
+		for _, conn := range []*websocket.Conn{writer, reader, stranger} {
+			answer := request(conn, ClientMessage{ID: "s", Type: MessageSubscribe, Collection: "tag:Work"})
+			assert.Equal(t, MessageAck, answer.Type)
+			assert.Equal(t, []string{"tag:work"}, answer.Collections)
+		}
+
+		mockTodoService.
+			EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, item *todos.ToDoItem) error {
+				item.ID = 7
+				// what the todo service does after writing
+				eventHub.Publish(ctx, todos.Event{Type: todos.EventCreated, UserId: item.UserId, Item: *item})
+				return nil
+			}).
+			Times(1)
+
+		answer := request(writer, ClientMessage{ID: "1", Type: MessageCreate, Item: &todos.ToDoItem{Model: gorm.Model{ID: 99}, UserId: 2, Text: "Report", Tags: []todos.Tag{{Name: "work"}}}})
+		assert.Equal(t, "1", answer.ID)
+		assert.Equal(t, MessageAck, answer.Type)
+		assert.Equal(t, uint(7), answer.Item.ID)
+		assert.Equal(t, uint(1), answer.Item.UserId)
+
+		var event ServerMessage
+		_ = reader.SetReadDeadline(time.Now().Add(time.Second))
+		assert.NoError(t, reader.ReadJSON(&event))
+		assert.Equal(t, MessageEvent, event.Type)
+		assert.Equal(t, todos.EventCreated, event.Event)
+		assert.Equal(t, []string{"tag:work"}, event.Collections)
+		assert.Equal(t, uint(7), event.Item.ID)
+
+		// neither the writer nor the other user get the event, the next answer comes first
+		assert.Equal(t, MessagePong, request(writer, ClientMessage{ID: "p", Type: MessagePing}).Type)
+		assert.Equal(t, MessagePong, request(stranger, ClientMessage{ID: "p", Type: MessagePing}).Type)
+	})
+
+	t.Run("items of other users cannot be changed", func(t *testing.T) {
+		conn := dial("valid")
+		defer conn.Close()
+
+		mockTodoService.EXPECT().GetById(gomock.Any(), uint(8)).Return(todos.ToDoItem{Model: gorm.Model{ID: 8}, UserId: 2}, nil).Times(2)
+
+		text := "Mine now"
+		answer := request(conn, ClientMessage{ID: "u", Type: MessageUpdate, ItemId: 8, Changes: &todos.ToDoItemUpdateInput{Text: &text}})
+		assert.Equal(t, MessageError, answer.Type)
+		assert.Equal(t, locale.ErrorNotFoundRecord, answer.Error)
+
+		answer = request(conn, ClientMessage{ID: "d", Type: MessageDelete, ItemId: 8})
+		assert.Equal(t, MessageError, answer.Type)
+		assert.Equal(t, locale.ErrorNotFoundRecord, answer.Error)
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		conn := dial("valid")
+		defer conn.Close()
+
+		own := todos.ToDoItem{Model: gorm.Model{ID: 9}, UserId: 1, Text: "Old"}
+		text := "New"
+		mockTodoService.EXPECT().GetById(gomock.Any(), uint(9)).Return(own, nil).Times(2)
+		mockTodoService.
+			EXPECT().
+			UpdateById(gomock.Any(), uint(9), todos.ToDoItemUpdateInput{Text: &text}).
+			Return(todos.ToDoItem{Model: gorm.Model{ID: 9}, UserId: 1, Text: "New"}, nil).
+			Times(1)
+		mockTodoService.EXPECT().DeleteById(gomock.Any(), uint(9)).Return(nil).Times(1)
+
+		answer := request(conn, ClientMessage{ID: "u", Type: MessageUpdate, ItemId: 9, Changes: &todos.ToDoItemUpdateInput{Text: &text}})
+		assert.Equal(t, MessageAck, answer.Type)
+		assert.Equal(t, "New", answer.Item.Text)
+
+		answer = request(conn, ClientMessage{ID: "d", Type: MessageDelete, ItemId: 9})
+		assert.Equal(t, MessageAck, answer.Type)
+	})
+
+	t.Run("invalid messages", func(t *testing.T) {
+		conn := dial("valid")
+		defer conn.Close()
+
+		answer := request(conn, ClientMessage{ID: "1", Type: MessageSubscribe, Collection: "item:abc"})
+		assert.Equal(t, locale.ErrorInvalidCollection, answer.Error)
+
+		answer = request(conn, ClientMessage{ID: "2", Type: "shout"})
+		assert.Equal(t, locale.ErrorInvalidMessage, answer.Error)
+
+		assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("{")))
+		var reply ServerMessage
+		assert.NoError(t, conn.ReadJSON(&reply))
+		assert.Equal(t, locale.ErrorInvalidMessage, reply.Error)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		_, response, err := websocket.DefaultDialer.Dial(url+"?access_token=expired", nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+		_, response, err = websocket.DefaultDialer.Dial(url, nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	})
+
+	ctrl.Finish()
+}
+
+func TestInCollection(t *testing.T) {
+	parentId := uint(3)
+	item := todos.ToDoItem{Model: gorm.Model{ID: 5}, ParentId: &parentId, Type: todos.TypeTask, Tags: []todos.Tag{{Name: "home"}}}
+
+	assert.True(t, inCollection(CollectionTodos, item))
+	assert.False(t, inCollection(CollectionHabits, item))
+	assert.True(t, inCollection("tag:home", item))
+	assert.False(t, inCollection("tag:work", item))
+	assert.True(t, inCollection("item:5", item))
+	assert.True(t, inCollection("item:3", item))
+	assert.False(t, inCollection("item:4", item))
+}
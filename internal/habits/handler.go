@@ -0,0 +1,301 @@
+package habits
+
+import (
+	"net/http"
+	"todo-app/internal/auth"
+	"todo-app/internal/todos"
+	"todo-app/pkg/authz"
+	e "todo-app/pkg/errors"
+	"todo-app/pkg/handlers"
+	"todo-app/pkg/locale"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type endpointHandler struct {
+	logger      *zap.SugaredLogger
+	service     Service
+	todoService todos.Service
+	e           *echo.Echo
+}
+
+func GetEndpointHandler(
+	logger *zap.SugaredLogger,
+	service Service,
+	todoService todos.Service,
+	e *echo.Echo,
+) handlers.EndpointHandler {
+	return &endpointHandler{
+		logger:      logger,
+		service:     service,
+		todoService: todoService,
+		e:           e,
+	}
+}
+
+func (h *endpointHandler) AddEndpoints() {
+	var endpoints = []handlers.Endpoint{
+		{
+			Method:  http.MethodGet,
+			Path:    "/habits",
+			Handler: h.getAll,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/habits/heatmap",
+			Handler: h.getHeatmap,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/habits/:id",
+			Handler: h.getById,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/habits/:id/check-ins",
+			Handler: h.checkIn,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/habits/:id/check-ins/:day",
+			Handler: h.undoCheckIn,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/habits/:id/heatmap",
+			Handler: h.getHabitHeatmap,
+		},
+	}
+
+	for _, endpoint := range endpoints {
+		handlers.Method(h.e, endpoint.Method, endpoint.Path, endpoint.Handler)
+	}
+}
+
+// @Summary Get all habits
+// @Description This endpoint returns the habits of the current user with their streaks
+// @Tags habits
+// @ID getAllHabits
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} Status
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /habits [get]
+func (h *endpointHandler) getAll(ctx echo.Context) error {
+	h.logger.Infow("reading habits...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	statuses, err := h.service.GetStatuses(ctx.Request().Context(), userId)
+	if err != nil {
+		h.logger.Warn("could not read habits", "error", err.Error())
+
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorCouldNotReadHabit})
+	}
+
+	return ctx.JSON(http.StatusOK, statuses)
+}
+
+// @Summary Get a habit by ID
+// @Description This endpoint returns a habit with its current and longest streak. Daily habits count
+// @Description streaks in days, habits with a target per week count the consecutive weeks that met it.
+// @Tags habits
+// @ID getHabitById
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Habit ID"
+// @Success 200 {object} Status
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /habits/{id} [get]
+func (h *endpointHandler) getById(ctx echo.Context) error {
+	habit, ok, err := h.getOwnHabit(ctx, authz.ActionRead)
+	if !ok {
+		return err
+	}
+
+	status, err := h.service.GetStatus(ctx.Request().Context(), habit)
+	if err != nil {
+		h.logger.Warn("could not read habit status", "error", err.Error())
+
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorCouldNotReadHabit})
+	}
+
+	return ctx.JSON(http.StatusOK, status)
+}
+
+// @Summary Check in a habit
+// @Description This endpoint checks in a habit for a day, today in the habit's timezone by default.
+// @Description Checking in twice on the same day has no effect.
+// @Tags habits
+// @ID checkInHabit
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Habit ID"
+// @Param input body CheckInInput false "Day to check in"
+// @Success 200 {object} CheckIn
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /habits/{id}/check-ins [post]
+func (h *endpointHandler) checkIn(ctx echo.Context) error {
+	h.logger.Infow("checking in habit...")
+
+	habit, ok, err := h.getOwnHabit(ctx, authz.ActionUpdate)
+	if !ok {
+		return err
+	}
+
+	input := CheckInInput{}
+	err = ctx.Bind(&input)
+	if err != nil {
+		h.logger.Warn("could not bind body to check-in struct", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+	}
+
+	checkIn, err := h.service.CheckIn(ctx.Request().Context(), habit, input)
+	if err != nil {
+		h.logger.Warn("could not check in habit", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidCheckIn, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, checkIn)
+}
+
+// @Summary Undo a habit check-in
+// @Description This endpoint removes the check-in of a habit for a day
+// @Tags habits
+// @ID undoCheckInHabit
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Habit ID"
+// @Param day path string true "Day (2006-01-02)"
+// @Success 200 {string} string ""
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /habits/{id}/check-ins/{day} [delete]
+func (h *endpointHandler) undoCheckIn(ctx echo.Context) error {
+	h.logger.Infow("undoing habit check-in...")
+
+	habit, ok, err := h.getOwnHabit(ctx, authz.ActionUpdate)
+	if !ok {
+		return err
+	}
+
+	err = h.service.UndoCheckIn(ctx.Request().Context(), habit, ctx.Param("day"))
+	if err != nil {
+		h.logger.Warn("could not undo check-in", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidCheckIn, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, "")
+}
+
+// @Summary Heatmap of all habits
+// @Description This endpoint returns the number of check-ins per day over all habits of the current user.
+// @Description from and to are inclusive days and default to the last year.
+// @Tags habits
+// @ID getHabitsHeatmap
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "First day (2006-01-02)"
+// @Param to query string false "Last day (2006-01-02)"
+// @Param timezone query string false "IANA timezone deciding today, UTC by default"
+// @Success 200 {object} Heatmap
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Router /habits/heatmap [get]
+func (h *endpointHandler) getHeatmap(ctx echo.Context) error {
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	return h.heatmap(ctx, userId, HeatmapQuery{Timezone: ctx.QueryParam("timezone")})
+}
+
+// @Summary Heatmap of a habit
+// @Description This endpoint returns the check-ins of a habit per day.
+// @Description from and to are inclusive days and default to the last year.
+// @Tags habits
+// @ID getHabitHeatmap
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Habit ID"
+// @Param from query string false "First day (2006-01-02)"
+// @Param to query string false "Last day (2006-01-02)"
+// @Success 200 {object} Heatmap
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /habits/{id}/heatmap [get]
+func (h *endpointHandler) getHabitHeatmap(ctx echo.Context) error {
+	habit, ok, err := h.getOwnHabit(ctx, authz.ActionRead)
+	if !ok {
+		return err
+	}
+
+	return h.heatmap(ctx, habit.UserId, HeatmapQuery{HabitId: habit.ID, Timezone: habit.Timezone})
+}
+
+func (h *endpointHandler) heatmap(ctx echo.Context, userId uint, query HeatmapQuery) error {
+	query.From = ctx.QueryParam("from")
+	query.To = ctx.QueryParam("to")
+
+	heatmap, err := h.service.GetHeatmap(ctx.Request().Context(), userId, query)
+	if err != nil {
+		h.logger.Warn("could not read heatmap", "error", err.Error())
+
+		if err.Error() == locale.ErrorInvalidHeatmapQuery {
+			return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidHeatmapQuery})
+		}
+
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorCouldNotReadHabit})
+	}
+
+	return ctx.JSON(http.StatusOK, heatmap)
+}
+
+// getOwnHabit : loads the habit from the :id param and makes sure the current user may
+// perform the action on it. When ok is false the error response has already been written.
+func (h *endpointHandler) getOwnHabit(ctx echo.Context, action authz.Action) (todos.ToDoItem, bool, error) {
+	userId := auth.GetUserIdFromContext(ctx)
+
+	id, err := handlers.GetUrlId(ctx, h.logger)
+	if err != nil {
+		h.logger.Warn("could not get id from url", "error", err.Error())
+
+		return todos.ToDoItem{}, false, ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidID})
+	}
+
+	habit, err := h.todoService.GetById(ctx.Request().Context(), id)
+	if err != nil {
+		if authz.Missing(err) {
+			return todos.ToDoItem{}, false, authz.Deny(ctx)
+		}
+		h.logger.Error("could not get habit", "error", err.Error())
+
+		return todos.ToDoItem{}, false, ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorCouldNotReadHabit})
+	}
+	if err := authz.Authorize(todos.ItemPolicy, userId, action, habit); err != nil {
+		h.logger.Info("user tried to access habit of other user")
+
+		return todos.ToDoItem{}, false, authz.Deny(ctx)
+	}
+	if habit.Type != todos.TypeHabit {
+		return todos.ToDoItem{}, false, ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorNotAHabit})
+	}
+
+	return habit, true, nil
+}
@@ -0,0 +1,191 @@
+package habits
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"todo-app/internal/todos"
+	"todo-app/pkg/locale"
+
+	localErr "todo-app/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestHandler_CheckIn(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	mockTodoService := todos.NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, todoService: mockTodoService, e: e}
+
+	habit := todos.ToDoItem{Model: gorm.Model{ID: 4}, UserId: 1, Text: "Read", Type: todos.TypeHabit}
+
+	newContext := func(body string, userId uint) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/habits/4/check-ins", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", userId)
+		ctx.SetPath("/habits/:id/check-ins")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues("4")
+
+		return ctx, rec
+	}
+
+	t.Run("check in for a day", func(t *testing.T) {
+		ctx, rec := newContext(`{"day":"2025-03-01"}`, 1)
+
+		mockTodoService.EXPECT().GetById(ctx.Request().Context(), uint(4)).Return(habit, nil).Times(1)
+		mockService.
+			EXPECT().
+			CheckIn(ctx.Request().Context(), habit, CheckInInput{Day: "2025-03-01"}).
+			Return(CheckIn{ID: 8, HabitID: 4, UserId: 1, Day: "2025-03-01"}, nil).
+			Times(1)
+
+		if assert.NoError(t, h.checkIn(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var response CheckIn
+			err := json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			assert.Equal(t, "2025-03-01", response.Day)
+		}
+	})
+
+	t.Run("todo is not a habit", func(t *testing.T) {
+		ctx, rec := newContext(`{}`, 1)
+
+		mockTodoService.
+			EXPECT().
+			GetById(ctx.Request().Context(), uint(4)).
+			Return(todos.ToDoItem{Model: gorm.Model{ID: 4}, UserId: 1, Type: todos.TypeTask}, nil).
+			Times(1)
+
+		if assert.NoError(t, h.checkIn(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var responseError localErr.ResponseError
+			err := json.Unmarshal(rec.Body.Bytes(), &responseError)
+			assert.NoError(t, err)
+
+			assert.Equal(t, locale.ErrorNotAHabit, responseError.Message)
+		}
+	})
+
+	t.Run("habit of other user", func(t *testing.T) {
+		ctx, rec := newContext(`{}`, 2)
+
+		mockTodoService.EXPECT().GetById(ctx.Request().Context(), uint(4)).Return(habit, nil).Times(1)
+
+		if assert.NoError(t, h.checkIn(ctx)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+		}
+	})
+}
+
+func TestHandler_GetHeatmap(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	mockTodoService := todos.NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, todoService: mockTodoService, e: e}
+
+	req := httptest.NewRequest(http.MethodGet, "/habits/heatmap?from=2025-03-01&to=2025-03-02&timezone=Europe/Berlin", nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.Set("user_id", uint(1))
+
+	mockService.
+		EXPECT().
+		GetHeatmap(ctx.Request().Context(), uint(1), HeatmapQuery{Timezone: "Europe/Berlin", From: "2025-03-01", To: "2025-03-02"}).
+		Return(Heatmap{From: "2025-03-01", To: "2025-03-02", Days: []HeatmapDay{{Day: "2025-03-01", Count: 2}, {Day: "2025-03-02"}}}, nil).
+		Times(1)
+
+	if assert.NoError(t, h.getHeatmap(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response Heatmap
+		err := json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NoError(t, err)
+
+		assert.Len(t, response.Days, 2)
+		assert.Equal(t, 2, response.Days[0].Count)
+	}
+}
+
+func TestHandler_CrossUserAccess(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	mockTodoService := todos.NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, todoService: mockTodoService, e: e}
+
+	// habit 4 belongs to user 2, user 1 tries to reach it
+	habit := todos.ToDoItem{Model: gorm.Model{ID: 4}, UserId: 2, Text: "Read", Type: todos.TypeHabit}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		id      string
+		body    string
+		handler echo.HandlerFunc
+	}{
+		{"read habit", http.MethodGet, "/habits/:id", "4", "", h.getById},
+		{"check in", http.MethodPost, "/habits/:id/check-ins", "4", `{}`, h.checkIn},
+		{"undo check-in", http.MethodDelete, "/habits/:id/check-ins/:day", "4", "", h.undoCheckIn},
+		{"read heatmap", http.MethodGet, "/habits/:id/heatmap", "4", "", h.getHabitHeatmap},
+		// ids that do not exist answer the same
+		{"read nonexistent habit", http.MethodGet, "/habits/:id", "404", "", h.getById},
+		{"check in to nonexistent habit", http.MethodPost, "/habits/:id/check-ins", "404", `{}`, h.checkIn},
+	}
+	var denied string
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, "/", strings.NewReader(test.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(req, rec)
+			ctx.Set("user_id", uint(1))
+			ctx.SetPath(test.path)
+			ctx.SetParamNames("id", "day")
+			ctx.SetParamValues(test.id, "2024-03-01")
+
+			// only the lookup happens, nothing is changed
+			mockTodoService.EXPECT().GetById(gomock.Any(), habit.ID).Return(habit, nil).AnyTimes()
+			mockTodoService.EXPECT().GetById(gomock.Any(), uint(404)).Return(todos.ToDoItem{}, gorm.ErrRecordNotFound).AnyTimes()
+
+			if assert.NoError(t, test.handler(ctx)) {
+				assert.Equal(t, http.StatusNotFound, rec.Code)
+
+				var responseError localErr.ResponseError
+				err := json.Unmarshal(rec.Body.Bytes(), &responseError)
+				assert.NoError(t, err)
+				assert.Equal(t, locale.ErrorNotFoundRecord, responseError.Message)
+
+				if denied == "" {
+					denied = rec.Body.String()
+				}
+				assert.Equal(t, denied, rec.Body.String())
+			}
+		})
+	}
+
+	ctrl.Finish()
+}
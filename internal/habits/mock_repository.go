@@ -0,0 +1,144 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/habits/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/habits/repository.go -destination=internal/habits/mock_repository.go -package=habits
+//
+
+// Package habits is a generated GoMock package.
+package habits
+
+import (
+	context "context"
+	reflect "reflect"
+	todos "todo-app/internal/todos"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountPerDay mocks base method.
+func (m *MockRepository) CountPerDay(ctx context.Context, userId, habitId uint, from, to string) (map[string]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPerDay", ctx, userId, habitId, from, to)
+	ret0, _ := ret[0].(map[string]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPerDay indicates an expected call of CountPerDay.
+func (mr *MockRepositoryMockRecorder) CountPerDay(ctx, userId, habitId, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPerDay", reflect.TypeOf((*MockRepository)(nil).CountPerDay), ctx, userId, habitId, from, to)
+}
+
+// CreateCheckIn mocks base method.
+func (m *MockRepository) CreateCheckIn(ctx context.Context, checkIn *CheckIn) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCheckIn", ctx, checkIn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCheckIn indicates an expected call of CreateCheckIn.
+func (mr *MockRepositoryMockRecorder) CreateCheckIn(ctx, checkIn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCheckIn", reflect.TypeOf((*MockRepository)(nil).CreateCheckIn), ctx, checkIn)
+}
+
+// DeleteCheckIn mocks base method.
+func (m *MockRepository) DeleteCheckIn(ctx context.Context, habitId uint, day string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCheckIn", ctx, habitId, day)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCheckIn indicates an expected call of DeleteCheckIn.
+func (mr *MockRepositoryMockRecorder) DeleteCheckIn(ctx, habitId, day any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCheckIn", reflect.TypeOf((*MockRepository)(nil).DeleteCheckIn), ctx, habitId, day)
+}
+
+// DeleteCheckIns mocks base method.
+func (m *MockRepository) DeleteCheckIns(ctx context.Context, habitId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCheckIns", ctx, habitId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCheckIns indicates an expected call of DeleteCheckIns.
+func (mr *MockRepositoryMockRecorder) DeleteCheckIns(ctx, habitId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCheckIns", reflect.TypeOf((*MockRepository)(nil).DeleteCheckIns), ctx, habitId)
+}
+
+// GetDays mocks base method.
+func (m *MockRepository) GetDays(ctx context.Context, habitId uint) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDays", ctx, habitId)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDays indicates an expected call of GetDays.
+func (mr *MockRepositoryMockRecorder) GetDays(ctx, habitId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDays", reflect.TypeOf((*MockRepository)(nil).GetDays), ctx, habitId)
+}
+
+// GetDaysForHabits mocks base method.
+func (m *MockRepository) GetDaysForHabits(ctx context.Context, habitIds []uint) (map[uint][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDaysForHabits", ctx, habitIds)
+	ret0, _ := ret[0].(map[uint][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDaysForHabits indicates an expected call of GetDaysForHabits.
+func (mr *MockRepositoryMockRecorder) GetDaysForHabits(ctx, habitIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDaysForHabits", reflect.TypeOf((*MockRepository)(nil).GetDaysForHabits), ctx, habitIds)
+}
+
+// GetHabitsForUser mocks base method.
+func (m *MockRepository) GetHabitsForUser(ctx context.Context, userId uint) ([]todos.ToDoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHabitsForUser", ctx, userId)
+	ret0, _ := ret[0].([]todos.ToDoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHabitsForUser indicates an expected call of GetHabitsForUser.
+func (mr *MockRepositoryMockRecorder) GetHabitsForUser(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHabitsForUser", reflect.TypeOf((*MockRepository)(nil).GetHabitsForUser), ctx, userId)
+}
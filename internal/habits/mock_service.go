@@ -0,0 +1,130 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/habits/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/habits/service.go -destination=internal/habits/mock_service.go -package=habits
+//
+
+// Package habits is a generated GoMock package.
+package habits
+
+import (
+	context "context"
+	reflect "reflect"
+	todos "todo-app/internal/todos"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// CheckIn mocks base method.
+func (m *MockService) CheckIn(ctx context.Context, habit todos.ToDoItem, input CheckInInput) (CheckIn, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckIn", ctx, habit, input)
+	ret0, _ := ret[0].(CheckIn)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckIn indicates an expected call of CheckIn.
+func (mr *MockServiceMockRecorder) CheckIn(ctx, habit, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckIn", reflect.TypeOf((*MockService)(nil).CheckIn), ctx, habit, input)
+}
+
+// DeleteCheckIns mocks base method.
+func (m *MockService) DeleteCheckIns(ctx context.Context, item todos.ToDoItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCheckIns", ctx, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCheckIns indicates an expected call of DeleteCheckIns.
+func (mr *MockServiceMockRecorder) DeleteCheckIns(ctx, item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCheckIns", reflect.TypeOf((*MockService)(nil).DeleteCheckIns), ctx, item)
+}
+
+// GetHeatmap mocks base method.
+func (m *MockService) GetHeatmap(ctx context.Context, userId uint, query HeatmapQuery) (Heatmap, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHeatmap", ctx, userId, query)
+	ret0, _ := ret[0].(Heatmap)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHeatmap indicates an expected call of GetHeatmap.
+func (mr *MockServiceMockRecorder) GetHeatmap(ctx, userId, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHeatmap", reflect.TypeOf((*MockService)(nil).GetHeatmap), ctx, userId, query)
+}
+
+// GetStatus mocks base method.
+func (m *MockService) GetStatus(ctx context.Context, habit todos.ToDoItem) (Status, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatus", ctx, habit)
+	ret0, _ := ret[0].(Status)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatus indicates an expected call of GetStatus.
+func (mr *MockServiceMockRecorder) GetStatus(ctx, habit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatus", reflect.TypeOf((*MockService)(nil).GetStatus), ctx, habit)
+}
+
+// GetStatuses mocks base method.
+func (m *MockService) GetStatuses(ctx context.Context, userId uint) ([]Status, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatuses", ctx, userId)
+	ret0, _ := ret[0].([]Status)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatuses indicates an expected call of GetStatuses.
+func (mr *MockServiceMockRecorder) GetStatuses(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatuses", reflect.TypeOf((*MockService)(nil).GetStatuses), ctx, userId)
+}
+
+// UndoCheckIn mocks base method.
+func (m *MockService) UndoCheckIn(ctx context.Context, habit todos.ToDoItem, day string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UndoCheckIn", ctx, habit, day)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UndoCheckIn indicates an expected call of UndoCheckIn.
+func (mr *MockServiceMockRecorder) UndoCheckIn(ctx, habit, day any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UndoCheckIn", reflect.TypeOf((*MockService)(nil).UndoCheckIn), ctx, habit, day)
+}
@@ -0,0 +1,48 @@
+package habits
+
+import (
+	"time"
+	"todo-app/internal/todos"
+)
+
+const (
+	StreakUnitDay  = "day"
+	StreakUnitWeek = "week"
+)
+
+// CheckIn : a habit done on Day, a date (2006-01-02) in the habit's timezone
+type CheckIn struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	HabitID   uint      `gorm:"not null;uniqueIndex:idx_check_in_habit_day" json:"habit_id"`
+	UserId    uint      `gorm:"not null;index" json:"user_id"`
+	Day       string    `gorm:"type:char(10);not null;uniqueIndex:idx_check_in_habit_day" json:"day"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CheckInInput struct {
+	// Day : defaults to today in the habit's timezone
+	Day string `json:"day" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// Status : streaks of a habit. Daily habits count streaks in days, habits with a
+// weekly target count the consecutive weeks that met it.
+type Status struct {
+	Habit          todos.ToDoItem `json:"habit"`
+	Unit           string         `json:"unit"`
+	CurrentStreak  int            `json:"current_streak"`
+	LongestStreak  int            `json:"longest_streak"`
+	CheckedInToday bool           `json:"checked_in_today"`
+	ThisWeek       int            `json:"this_week"`
+	Total          int            `json:"total"`
+}
+
+type HeatmapDay struct {
+	Day   string `json:"day"`
+	Count int    `json:"count"`
+}
+
+type Heatmap struct {
+	From string       `json:"from"`
+	To   string       `json:"to"`
+	Days []HeatmapDay `json:"days"`
+}
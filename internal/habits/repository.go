@@ -0,0 +1,151 @@
+package habits
+
+import (
+	"context"
+	"todo-app/internal/todos"
+	"todo-app/pkg/database"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	GetHabitsForUser(ctx context.Context, userId uint) ([]todos.ToDoItem, error)
+	CreateCheckIn(ctx context.Context, checkIn *CheckIn) error
+	DeleteCheckIn(ctx context.Context, habitId uint, day string) error
+	DeleteCheckIns(ctx context.Context, habitId uint) error
+	GetDays(ctx context.Context, habitId uint) ([]string, error)
+	GetDaysForHabits(ctx context.Context, habitIds []uint) (map[uint][]string, error)
+	CountPerDay(ctx context.Context, userId uint, habitId uint, from string, to string) (map[string]int, error)
+}
+
+type repository struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func GetRepository(logger *zap.SugaredLogger, db *gorm.DB) Repository {
+	return &repository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (r *repository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
+func (r *repository) GetHabitsForUser(ctx context.Context, userId uint) ([]todos.ToDoItem, error) {
+	var items []todos.ToDoItem
+	result := r.conn(ctx).
+		Where("user_id = ? AND type = ?", userId, todos.TypeHabit).
+		Preload("Tags").
+		Order("id").
+		Find(&items)
+	if result.Error != nil {
+		r.logger.Errorw("failed to find habits for user", "user_id", userId, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return items, nil
+}
+
+// CreateCheckIn : checking in twice on the same day keeps the first check-in
+func (r *repository) CreateCheckIn(ctx context.Context, checkIn *CheckIn) error {
+	result := r.conn(ctx).
+		Where(CheckIn{HabitID: checkIn.HabitID, Day: checkIn.Day}).
+		FirstOrCreate(checkIn)
+	if result.Error != nil {
+		r.logger.Errorw("failed to create check-in", "habit_id", checkIn.HabitID, "day", checkIn.Day, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) DeleteCheckIn(ctx context.Context, habitId uint, day string) error {
+	result := r.conn(ctx).Where("habit_id = ? AND day = ?", habitId, day).Delete(&CheckIn{})
+	if result.Error != nil {
+		r.logger.Errorw("failed to delete check-in", "habit_id", habitId, "day", day, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+// DeleteCheckIns : deletes all check-ins of the habit
+func (r *repository) DeleteCheckIns(ctx context.Context, habitId uint) error {
+	result := r.conn(ctx).Where("habit_id = ?", habitId).Delete(&CheckIn{})
+	if result.Error != nil {
+		r.logger.Errorw("failed to delete check-ins", "habit_id", habitId, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+// GetDays : all days the habit was checked in, ascending
+func (r *repository) GetDays(ctx context.Context, habitId uint) ([]string, error) {
+	var days []string
+	result := r.conn(ctx).Model(&CheckIn{}).Where("habit_id = ?", habitId).Order("day").Pluck("day", &days)
+	if result.Error != nil {
+		r.logger.Errorw("failed to find check-in days", "habit_id", habitId, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return days, nil
+}
+
+// GetDaysForHabits : the days each habit was checked in, ascending. Habits without
+// check-ins are left out.
+func (r *repository) GetDaysForHabits(ctx context.Context, habitIds []uint) (map[uint][]string, error) {
+	var checkIns []CheckIn
+	result := r.conn(ctx).Select("habit_id", "day").Where("habit_id IN ?", habitIds).Order("habit_id, day").Find(&checkIns)
+	if result.Error != nil {
+		r.logger.Errorw("failed to find check-in days of habits", "habit_ids", habitIds, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	days := map[uint][]string{}
+	for _, checkIn := range checkIns {
+		days[checkIn.HabitID] = append(days[checkIn.HabitID], checkIn.Day)
+	}
+
+	return days, nil
+}
+
+// CountPerDay : check-ins per day between from and to, both inclusive. A habitId of 0
+// counts the check-ins of all habits of the user.
+func (r *repository) CountPerDay(ctx context.Context, userId uint, habitId uint, from string, to string) (map[string]int, error) {
+	var rows []struct {
+		Day   string
+		Count int
+	}
+	db := r.conn(ctx).
+		Model(&CheckIn{}).
+		Select("day, COUNT(*) AS count").
+		Where("user_id = ? AND day >= ? AND day <= ?", userId, from, to)
+	if habitId != 0 {
+		db = db.Where("habit_id = ?", habitId)
+	}
+
+	result := db.Group("day").Scan(&rows)
+	if result.Error != nil {
+		r.logger.Errorw("failed to count check-ins per day", "user_id", userId, "habit_id", habitId, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Day] = row.Count
+	}
+
+	return counts, nil
+}
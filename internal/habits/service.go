@@ -0,0 +1,292 @@
+package habits
+
+import (
+	"context"
+	"errors"
+	"time"
+	"todo-app/internal/todos"
+	"todo-app/pkg/locale"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+const dayLayout = "2006-01-02"
+
+// heatmapDays : the number of days a heatmap covers by default and at most
+const heatmapDays = 366
+
+type Service interface {
+	GetStatuses(ctx context.Context, userId uint) ([]Status, error)
+	GetStatus(ctx context.Context, habit todos.ToDoItem) (Status, error)
+	CheckIn(ctx context.Context, habit todos.ToDoItem, input CheckInInput) (CheckIn, error)
+	UndoCheckIn(ctx context.Context, habit todos.ToDoItem, day string) error
+	GetHeatmap(ctx context.Context, userId uint, query HeatmapQuery) (Heatmap, error)
+	DeleteCheckIns(ctx context.Context, item todos.ToDoItem) error
+}
+
+// HeatmapQuery : From and To are inclusive days, they default to the year up to today
+// in Timezone. A HabitId of 0 includes all habits of the user.
+type HeatmapQuery struct {
+	HabitId  uint
+	Timezone string
+	From     string
+	To       string
+}
+
+type service struct {
+	logger     *zap.SugaredLogger
+	repository Repository
+	validator  *validator.Validate
+	now        func() time.Time
+}
+
+func GetService(logger *zap.SugaredLogger, repo Repository, validator *validator.Validate) Service {
+	return &service{
+		logger:     logger,
+		repository: repo,
+		validator:  validator,
+		now:        time.Now,
+	}
+}
+
+// GetStatuses : the status of every habit of the user, the check-ins of all of them
+// are loaded at once
+func (s *service) GetStatuses(ctx context.Context, userId uint) ([]Status, error) {
+	habits, err := s.repository.GetHabitsForUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if len(habits) == 0 {
+		return []Status{}, nil
+	}
+
+	ids := make([]uint, len(habits))
+	for i, habit := range habits {
+		ids[i] = habit.ID
+	}
+	days, err := s.repository.GetDaysForHabits(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(habits))
+	for _, habit := range habits {
+		statuses = append(statuses, s.status(habit, days[habit.ID]))
+	}
+
+	return statuses, nil
+}
+
+func (s *service) GetStatus(ctx context.Context, habit todos.ToDoItem) (Status, error) {
+	if habit.Type != todos.TypeHabit {
+		return Status{}, errors.New(locale.ErrorNotAHabit)
+	}
+
+	days, err := s.repository.GetDays(ctx, habit.ID)
+	if err != nil {
+		return Status{}, err
+	}
+
+	return s.status(habit, days), nil
+}
+
+// status : the status of the habit from its ascending check-in days
+func (s *service) status(habit todos.ToDoItem, days []string) Status {
+	today := s.today(habit.Timezone)
+	status := Status{
+		Habit:          habit,
+		Total:          len(days),
+		ThisWeek:       countInWeek(days, today),
+		CheckedInToday: len(days) > 0 && days[len(days)-1] == today.Format(dayLayout),
+	}
+
+	if habit.TargetPerWeek == nil {
+		status.Unit = StreakUnitDay
+		status.CurrentStreak, status.LongestStreak = dailyStreaks(days, today)
+	} else {
+		status.Unit = StreakUnitWeek
+		status.CurrentStreak, status.LongestStreak = weeklyStreaks(days, *habit.TargetPerWeek, today)
+	}
+
+	return status
+}
+
+func (s *service) CheckIn(ctx context.Context, habit todos.ToDoItem, input CheckInInput) (CheckIn, error) {
+	if habit.Type != todos.TypeHabit {
+		return CheckIn{}, errors.New(locale.ErrorNotAHabit)
+	}
+	if err := s.validator.Struct(input); err != nil {
+		return CheckIn{}, err
+	}
+
+	today := s.today(habit.Timezone).Format(dayLayout)
+	day := input.Day
+	if day == "" {
+		day = today
+	}
+	if day > today {
+		return CheckIn{}, errors.New(locale.ErrorInvalidCheckIn)
+	}
+
+	checkIn := CheckIn{HabitID: habit.ID, UserId: habit.UserId, Day: day}
+	err := s.repository.CreateCheckIn(ctx, &checkIn)
+	if err != nil {
+		return CheckIn{}, err
+	}
+
+	return checkIn, nil
+}
+
+func (s *service) UndoCheckIn(ctx context.Context, habit todos.ToDoItem, day string) error {
+	if habit.Type != todos.TypeHabit {
+		return errors.New(locale.ErrorNotAHabit)
+	}
+	if _, err := time.Parse(dayLayout, day); err != nil {
+		return errors.New(locale.ErrorInvalidCheckIn)
+	}
+
+	return s.repository.DeleteCheckIn(ctx, habit.ID, day)
+}
+
+// DeleteCheckIns : deletes the check-ins of a deleted todo item. It runs for every item,
+// since one that is a task now may have been checked in while it was a habit.
+func (s *service) DeleteCheckIns(ctx context.Context, item todos.ToDoItem) error {
+	return s.repository.DeleteCheckIns(ctx, item.ID)
+}
+
+func (s *service) GetHeatmap(ctx context.Context, userId uint, query HeatmapQuery) (Heatmap, error) {
+	to := s.today(query.Timezone)
+	if query.To != "" {
+		day, err := time.Parse(dayLayout, query.To)
+		if err != nil {
+			return Heatmap{}, errors.New(locale.ErrorInvalidHeatmapQuery)
+		}
+		to = day
+	}
+
+	from := to.AddDate(0, 0, -heatmapDays+1)
+	if query.From != "" {
+		day, err := time.Parse(dayLayout, query.From)
+		if err != nil {
+			return Heatmap{}, errors.New(locale.ErrorInvalidHeatmapQuery)
+		}
+		from = day
+	}
+	if from.After(to) || to.Sub(from) >= heatmapDays*24*time.Hour {
+		return Heatmap{}, errors.New(locale.ErrorInvalidHeatmapQuery)
+	}
+
+	heatmap := Heatmap{From: from.Format(dayLayout), To: to.Format(dayLayout), Days: []HeatmapDay{}}
+	counts, err := s.repository.CountPerDay(ctx, userId, query.HabitId, heatmap.From, heatmap.To)
+	if err != nil {
+		return Heatmap{}, err
+	}
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		key := day.Format(dayLayout)
+		heatmap.Days = append(heatmap.Days, HeatmapDay{Day: key, Count: counts[key]})
+	}
+
+	return heatmap, nil
+}
+
+// today : the current date in timezone, as midnight UTC so that dates compare and
+// add up without daylight saving shifts
+func (s *service) today(timezone string) time.Time {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	now := s.now().In(loc)
+
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// dailyStreaks : runs of consecutive days. The current streak survives until the end of
+// the day after the last check-in.
+func dailyStreaks(days []string, today time.Time) (current int, longest int) {
+	var previous time.Time
+	run := 0
+	for _, value := range days {
+		day, err := time.Parse(dayLayout, value)
+		if err != nil {
+			continue
+		}
+		if run > 0 && day.Equal(previous.AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		previous = day
+		longest = max(longest, run)
+	}
+
+	if run > 0 && !previous.Before(today.AddDate(0, 0, -1)) {
+		current = run
+	}
+
+	return current, longest
+}
+
+// weeklyStreaks : runs of consecutive ISO weeks with at least target check-ins. The
+// running week only adds to the streak once it meets the target, it never breaks it.
+func weeklyStreaks(days []string, target int, today time.Time) (current int, longest int) {
+	if len(days) == 0 {
+		return 0, 0
+	}
+
+	counts := map[time.Time]int{}
+	for _, value := range days {
+		day, err := time.Parse(dayLayout, value)
+		if err != nil {
+			continue
+		}
+		counts[weekStart(day)]++
+	}
+
+	first, err := time.Parse(dayLayout, days[0])
+	if err != nil {
+		return 0, 0
+	}
+
+	thisWeek := weekStart(today)
+	run := 0
+	for week := weekStart(first); week.Before(thisWeek); week = week.AddDate(0, 0, 7) {
+		if counts[week] >= target {
+			run++
+		} else {
+			run = 0
+		}
+		longest = max(longest, run)
+	}
+
+	current = run
+	if counts[thisWeek] >= target {
+		current++
+		longest = max(longest, current)
+	}
+
+	return current, longest
+}
+
+// countInWeek : check-ins in the ISO week of today
+func countInWeek(days []string, today time.Time) int {
+	start := weekStart(today)
+	count := 0
+	for _, value := range days {
+		day, err := time.Parse(dayLayout, value)
+		if err == nil && weekStart(day).Equal(start) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// weekStart : the Monday of day's ISO week
+func weekStart(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+
+	return day.AddDate(0, 0, -offset)
+}
@@ -0,0 +1,230 @@
+package habits
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-app/internal/todos"
+	"todo-app/pkg/locale"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func newTestService(repo Repository, now time.Time) *service {
+	s := GetService(zap.NewNop().Sugar(), repo, validator.New()).(*service)
+	s.now = func() time.Time { return now }
+
+	return s
+}
+
+func TestService_CheckIn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	// 23:30 UTC on March 9th is already March 10th in Berlin
+	service := newTestService(mockRepo, time.Date(2025, time.March, 9, 23, 30, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	habit := todos.ToDoItem{Model: gorm.Model{ID: 4}, UserId: 2, Type: todos.TypeHabit, Timezone: "Europe/Berlin"}
+
+	t.Run("defaults to today in the habit's timezone", func(t *testing.T) {
+		mockRepo.
+			EXPECT().
+			CreateCheckIn(ctx, &CheckIn{HabitID: 4, UserId: 2, Day: "2025-03-10"}).
+			Return(nil).
+			Times(1)
+
+		checkIn, err := service.CheckIn(ctx, habit, CheckInInput{})
+		assert.NoError(t, err)
+		assert.Equal(t, "2025-03-10", checkIn.Day)
+
+		ctrl.Finish()
+	})
+
+	t.Run("future day", func(t *testing.T) {
+		_, err := service.CheckIn(ctx, habit, CheckInInput{Day: "2025-03-11"})
+		assert.Error(t, err)
+		assert.Equal(t, locale.ErrorInvalidCheckIn, err.Error())
+
+		ctrl.Finish()
+	})
+
+	t.Run("invalid day", func(t *testing.T) {
+		_, err := service.CheckIn(ctx, habit, CheckInInput{Day: "10.03.2025"})
+		assert.Error(t, err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("not a habit", func(t *testing.T) {
+		_, err := service.CheckIn(ctx, todos.ToDoItem{Type: todos.TypeTask}, CheckInInput{})
+		assert.Error(t, err)
+		assert.Equal(t, locale.ErrorNotAHabit, err.Error())
+
+		ctrl.Finish()
+	})
+}
+
+func TestService_GetStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	// Monday March 10th
+	service := newTestService(mockRepo, time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	t.Run("daily habit", func(t *testing.T) {
+		habit := todos.ToDoItem{Model: gorm.Model{ID: 4}, Type: todos.TypeHabit}
+		mockRepo.
+			EXPECT().
+			GetDays(ctx, uint(4)).
+			Return([]string{"2025-03-01", "2025-03-02", "2025-03-03", "2025-03-09", "2025-03-10"}, nil).
+			Times(1)
+
+		status, err := service.GetStatus(ctx, habit)
+		assert.NoError(t, err)
+		assert.Equal(t, StreakUnitDay, status.Unit)
+		assert.Equal(t, 2, status.CurrentStreak)
+		assert.Equal(t, 3, status.LongestStreak)
+		assert.True(t, status.CheckedInToday)
+		assert.Equal(t, 1, status.ThisWeek)
+		assert.Equal(t, 5, status.Total)
+
+		ctrl.Finish()
+	})
+
+	t.Run("weekly target", func(t *testing.T) {
+		target := 2
+		habit := todos.ToDoItem{Model: gorm.Model{ID: 5}, Type: todos.TypeHabit, TargetPerWeek: &target}
+		mockRepo.
+			EXPECT().
+			GetDays(ctx, uint(5)).
+			Return([]string{"2025-02-17", "2025-02-19", "2025-02-24", "2025-02-26", "2025-03-04", "2025-03-06"}, nil).
+			Times(1)
+
+		status, err := service.GetStatus(ctx, habit)
+		assert.NoError(t, err)
+		assert.Equal(t, StreakUnitWeek, status.Unit)
+		assert.Equal(t, 3, status.CurrentStreak)
+		assert.Equal(t, 3, status.LongestStreak)
+		assert.False(t, status.CheckedInToday)
+
+		ctrl.Finish()
+	})
+}
+
+func TestService_GetStatuses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	// Monday March 10th
+	service := newTestService(mockRepo, time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	t.Run("check-ins of all habits in one query", func(t *testing.T) {
+		habits := []todos.ToDoItem{
+			{Model: gorm.Model{ID: 4}, Type: todos.TypeHabit},
+			{Model: gorm.Model{ID: 5}, Type: todos.TypeHabit},
+		}
+		mockRepo.EXPECT().GetHabitsForUser(ctx, uint(2)).Return(habits, nil).Times(1)
+		mockRepo.
+			EXPECT().
+			GetDaysForHabits(ctx, []uint{4, 5}).
+			Return(map[uint][]string{4: {"2025-03-09", "2025-03-10"}}, nil).
+			Times(1)
+
+		statuses, err := service.GetStatuses(ctx, 2)
+		assert.NoError(t, err)
+		assert.Len(t, statuses, 2)
+		assert.Equal(t, 2, statuses[0].CurrentStreak)
+		assert.True(t, statuses[0].CheckedInToday)
+		assert.Equal(t, 0, statuses[1].Total)
+		assert.False(t, statuses[1].CheckedInToday)
+
+		ctrl.Finish()
+	})
+
+	t.Run("no habits", func(t *testing.T) {
+		mockRepo.EXPECT().GetHabitsForUser(ctx, uint(3)).Return(nil, nil).Times(1)
+
+		statuses, err := service.GetStatuses(ctx, 3)
+		assert.NoError(t, err)
+		assert.Empty(t, statuses)
+
+		ctrl.Finish()
+	})
+}
+
+func TestWeeklyStreaks(t *testing.T) {
+	// Wednesday March 12th
+	today := time.Date(2025, time.March, 12, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		days            []string
+		target          int
+		expectedCurrent int
+		expectedLongest int
+	}{
+		{"no check-ins", nil, 3, 0, 0},
+		{"running week met", []string{"2025-03-03", "2025-03-10", "2025-03-11"}, 1, 2, 2},
+		{"running week not met yet", []string{"2025-03-03", "2025-03-04"}, 2, 1, 1},
+		{"missed last week", []string{"2025-02-24", "2025-02-25", "2025-03-11"}, 2, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, longest := weeklyStreaks(tt.days, tt.target, today)
+			assert.Equal(t, tt.expectedCurrent, current)
+			assert.Equal(t, tt.expectedLongest, longest)
+		})
+	}
+}
+
+func TestService_GetHeatmap(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	service := newTestService(mockRepo, time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	t.Run("fills days without check-ins", func(t *testing.T) {
+		mockRepo.
+			EXPECT().
+			CountPerDay(ctx, uint(2), uint(0), "2025-03-01", "2025-03-03").
+			Return(map[string]int{"2025-03-02": 3}, nil).
+			Times(1)
+
+		heatmap, err := service.GetHeatmap(ctx, 2, HeatmapQuery{From: "2025-03-01", To: "2025-03-03"})
+		assert.NoError(t, err)
+		assert.Equal(t, []HeatmapDay{
+			{Day: "2025-03-01"},
+			{Day: "2025-03-02", Count: 3},
+			{Day: "2025-03-03"},
+		}, heatmap.Days)
+
+		ctrl.Finish()
+	})
+
+	t.Run("defaults to the last year", func(t *testing.T) {
+		mockRepo.
+			EXPECT().
+			CountPerDay(ctx, uint(2), uint(4), "2024-03-10", "2025-03-10").
+			Return(map[string]int{}, nil).
+			Times(1)
+
+		heatmap, err := service.GetHeatmap(ctx, 2, HeatmapQuery{HabitId: 4})
+		assert.NoError(t, err)
+		assert.Len(t, heatmap.Days, 366)
+
+		ctrl.Finish()
+	})
+
+	t.Run("from after to", func(t *testing.T) {
+		_, err := service.GetHeatmap(ctx, 2, HeatmapQuery{From: "2025-03-04", To: "2025-03-03"})
+		assert.Error(t, err)
+		assert.Equal(t, locale.ErrorInvalidHeatmapQuery, err.Error())
+
+		ctrl.Finish()
+	})
+}
@@ -0,0 +1,195 @@
+package importers
+
+import (
+	"net/http"
+	"todo-app/internal/auth"
+	"todo-app/pkg/authz"
+	e "todo-app/pkg/errors"
+	"todo-app/pkg/handlers"
+	"todo-app/pkg/locale"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// maxFileSize : the largest export file accepted, in bytes
+const maxFileSize = 20 << 20
+
+type endpointHandler struct {
+	logger  *zap.SugaredLogger
+	service Service
+	e       *echo.Echo
+}
+
+func GetEndpointHandler(
+	logger *zap.SugaredLogger,
+	service Service,
+	e *echo.Echo,
+) handlers.EndpointHandler {
+	return &endpointHandler{
+		logger:  logger,
+		service: service,
+		e:       e,
+	}
+}
+
+func (h *endpointHandler) AddEndpoints() {
+	var endpoints = []handlers.Endpoint{
+		{
+			Method:  http.MethodPost,
+			Path:    "/imports",
+			Handler: h.start,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/imports",
+			Handler: h.getAll,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/imports/:id",
+			Handler: h.getById,
+		},
+	}
+
+	for _, endpoint := range endpoints {
+		handlers.Method(h.e, endpoint.Method, endpoint.Path, endpoint.Handler)
+	}
+}
+
+// @Summary Start import
+// @Description This endpoint imports an export file of another todo app in the background and returns the running job.
+// @Description Todoist CSV and JSON backups, Trello board JSON and Microsoft To Do task lists are supported.
+// @Description Items that were imported before are skipped, so a file can be imported again after changes.
+// @Tags imports
+// @ID startImport
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param source formData string true "todoist, trello or mstodo"
+// @Param file formData file true "Export file"
+// @Success 202 {object} Job
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 413 {object} errors.ResponseError "Request Entity Too Large"
+// @Router /imports [post]
+func (h *endpointHandler) start(ctx echo.Context) error {
+	h.logger.Infow("starting import...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	source := ctx.FormValue("source")
+	if _, ok := parsers[source]; !ok {
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidImportSource})
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorCouldNotReadImport, Details: err.Error()})
+	}
+	if header.Size > maxFileSize {
+		return ctx.JSON(http.StatusRequestEntityTooLarge, e.ResponseError{Message: locale.ErrorImportTooLarge})
+	}
+	file, err := header.Open()
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorCouldNotReadImport, Details: err.Error()})
+	}
+	defer file.Close()
+
+	job, err := h.service.Start(ctx.Request().Context(), userId, source, header.Filename, file)
+	if err != nil {
+		switch err.Error() {
+		case locale.ErrorInvalidImport, locale.ErrorInvalidImportSource:
+			return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: err.Error()})
+		case locale.ErrorImportTooLarge:
+			return ctx.JSON(http.StatusRequestEntityTooLarge, e.ResponseError{Message: err.Error()})
+		}
+		h.logger.Error("could not start import", "error", err.Error())
+
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorInternalServer, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusAccepted, job)
+}
+
+// @Summary Get imports
+// @Description This endpoint returns the import jobs of the current user, newest first
+// @Tags imports
+// @ID getImports
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} Job
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /imports [get]
+func (h *endpointHandler) getAll(ctx echo.Context) error {
+	h.logger.Infow("getting imports...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	jobs, err := h.service.GetJobsForUser(ctx.Request().Context(), userId)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorInternalServer, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, jobs)
+}
+
+// @Summary Get import
+// @Description This endpoint returns an import job with its progress and the errors of items that could not be created
+// @Tags imports
+// @ID getImport
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Import ID"
+// @Success 200 {object} Job
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /imports/{id} [get]
+func (h *endpointHandler) getById(ctx echo.Context) error {
+	h.logger.Infow("getting import...")
+
+	job, ok, err := h.getOwnJob(ctx, authz.ActionRead)
+	if !ok {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, job)
+}
+
+// getOwnJob : the job of the id in the url if it belongs to the current user, otherwise
+// the error response is written and ok is false
+func (h *endpointHandler) getOwnJob(ctx echo.Context, action authz.Action) (Job, bool, error) {
+	userId := auth.GetUserIdFromContext(ctx)
+
+	id, err := handlers.GetUrlId(ctx, h.logger)
+	if err != nil {
+		h.logger.Warn("could not get id from url", "error", err.Error())
+
+		return Job{}, false, ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidID})
+	}
+
+	job, err := h.service.GetJobById(ctx.Request().Context(), id)
+	if err != nil {
+		if authz.Missing(err) {
+			return Job{}, false, authz.Deny(ctx)
+		}
+		h.logger.Warn("could not get import job", "error", err.Error())
+
+		return Job{}, false, ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorNotFoundRecord})
+	}
+	if err := authz.Authorize(JobPolicy, userId, action, job); err != nil {
+		h.logger.Info("user tried to access import of other user")
+
+		return Job{}, false, authz.Deny(ctx)
+	}
+
+	return job, true, nil
+}
@@ -0,0 +1,242 @@
+package importers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"todo-app/pkg/locale"
+
+	localErr "todo-app/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestHandler_Start(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	newContext := func(source string, file string, userId uint) (echo.Context, *httptest.ResponseRecorder) {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		_ = writer.WriteField("source", source)
+		if file != "" {
+			part, _ := writer.CreateFormFile("file", "board.json")
+			_, _ = part.Write([]byte(file))
+		}
+		_ = writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/imports", &body)
+		req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", userId)
+
+		return ctx, rec
+	}
+
+	t.Run("starts the job", func(t *testing.T) {
+		ctx, rec := newContext(SourceTrello, `{"cards": []}`, 1)
+
+		mockService.
+			EXPECT().
+			Start(ctx.Request().Context(), uint(1), SourceTrello, "board.json", gomock.Any()).
+			DoAndReturn(func(_ interface{}, _ uint, _ string, _ string, r io.Reader) (Job, error) {
+				content, _ := io.ReadAll(r)
+				assert.Equal(t, `{"cards": []}`, string(content))
+				return Job{Model: gorm.Model{ID: 2}, UserId: 1, Source: SourceTrello, Status: JobRunning}, nil
+			}).
+			Times(1)
+
+		if assert.NoError(t, h.start(ctx)) {
+			assert.Equal(t, http.StatusAccepted, rec.Code)
+
+			var response Job
+			err := json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, uint(2), response.ID)
+			assert.Equal(t, JobRunning, response.Status)
+		}
+	})
+
+	t.Run("unknown source", func(t *testing.T) {
+		ctx, rec := newContext("asana", `{}`, 1)
+
+		if assert.NoError(t, h.start(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var response localErr.ResponseError
+			_ = json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.Equal(t, locale.ErrorInvalidImportSource, response.Message)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		ctx, rec := newContext(SourceTrello, "", 1)
+
+		if assert.NoError(t, h.start(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var response localErr.ResponseError
+			_ = json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.Equal(t, locale.ErrorCouldNotReadImport, response.Message)
+		}
+	})
+
+	t.Run("file cannot be parsed", func(t *testing.T) {
+		ctx, rec := newContext(SourceTrello, "nope", 1)
+
+		mockService.
+			EXPECT().
+			Start(ctx.Request().Context(), uint(1), SourceTrello, "board.json", gomock.Any()).
+			Return(Job{}, errors.New(locale.ErrorInvalidImport)).
+			Times(1)
+
+		if assert.NoError(t, h.start(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var response localErr.ResponseError
+			_ = json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.Equal(t, locale.ErrorInvalidImport, response.Message)
+		}
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		ctx, rec := newContext(SourceTrello, `{}`, 0)
+
+		if assert.NoError(t, h.start(ctx)) {
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		}
+	})
+
+	ctrl.Finish()
+}
+
+func TestHandler_GetById(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	newContext := func(id string, userId uint) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/imports/"+id, nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", userId)
+		ctx.SetPath("/imports/:id")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(id)
+
+		return ctx, rec
+	}
+
+	job := Job{Model: gorm.Model{ID: 2}, UserId: 1, Status: JobDone, Total: 3, Processed: 3, Created: 3}
+
+	t.Run("own job", func(t *testing.T) {
+		ctx, rec := newContext("2", 1)
+		mockService.EXPECT().GetJobById(ctx.Request().Context(), uint(2)).Return(job, nil).Times(1)
+
+		if assert.NoError(t, h.getById(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var response Job
+			err := json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, 3, response.Created)
+		}
+	})
+
+	t.Run("job of other user", func(t *testing.T) {
+		ctx, rec := newContext("2", 9)
+		mockService.EXPECT().GetJobById(ctx.Request().Context(), uint(2)).Return(job, nil).Times(1)
+
+		if assert.NoError(t, h.getById(ctx)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+		}
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		ctx, rec := newContext("abc", 1)
+
+		if assert.NoError(t, h.getById(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var response localErr.ResponseError
+			_ = json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.Equal(t, locale.ErrorInvalidID, response.Message)
+		}
+	})
+
+	ctrl.Finish()
+}
+
+func TestHandler_CrossUserAccess(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	h := &endpointHandler{logger: zap.NewNop().Sugar(), service: mockService, e: e}
+
+	// job 2 was started by user 2, user 1 tries to reach it
+	job := Job{Model: gorm.Model{ID: 2}, UserId: 2, Status: JobDone}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		id      string
+		handler echo.HandlerFunc
+	}{
+		{"read job", http.MethodGet, "/imports/:id", "2", h.getById},
+		// ids that do not exist answer the same
+		{"read nonexistent job", http.MethodGet, "/imports/:id", "404", h.getById},
+	}
+	var denied string
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, "/", nil)
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(req, rec)
+			ctx.Set("user_id", uint(1))
+			ctx.SetPath(test.path)
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(test.id)
+
+			// only the lookup happens, nothing is changed
+			mockService.EXPECT().GetJobById(gomock.Any(), job.ID).Return(job, nil).AnyTimes()
+			mockService.EXPECT().GetJobById(gomock.Any(), uint(404)).Return(Job{}, gorm.ErrRecordNotFound).AnyTimes()
+
+			if assert.NoError(t, test.handler(ctx)) {
+				assert.Equal(t, http.StatusNotFound, rec.Code)
+
+				var responseError localErr.ResponseError
+				err := json.Unmarshal(rec.Body.Bytes(), &responseError)
+				assert.NoError(t, err)
+				assert.Equal(t, locale.ErrorNotFoundRecord, responseError.Message)
+
+				if denied == "" {
+					denied = rec.Body.String()
+				}
+				assert.Equal(t, denied, rec.Body.String())
+			}
+		})
+	}
+
+	ctrl.Finish()
+}
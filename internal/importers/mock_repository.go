@@ -0,0 +1,129 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/importers/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/importers/repository.go -destination=internal/importers/mock_repository.go -package=importers
+//
+
+// Package importers is a generated GoMock package.
+package importers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateJob mocks base method.
+func (m *MockRepository) CreateJob(ctx context.Context, job *Job) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJob", ctx, job)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateJob indicates an expected call of CreateJob.
+func (mr *MockRepositoryMockRecorder) CreateJob(ctx, job any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJob", reflect.TypeOf((*MockRepository)(nil).CreateJob), ctx, job)
+}
+
+// FailRunningJobs mocks base method.
+func (m *MockRepository) FailRunningJobs(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailRunningJobs", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailRunningJobs indicates an expected call of FailRunningJobs.
+func (mr *MockRepositoryMockRecorder) FailRunningJobs(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailRunningJobs", reflect.TypeOf((*MockRepository)(nil).FailRunningJobs), ctx)
+}
+
+// GetItemIdsByExternalIds mocks base method.
+func (m *MockRepository) GetItemIdsByExternalIds(ctx context.Context, userId uint, externalIds []string) (map[string]uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItemIdsByExternalIds", ctx, userId, externalIds)
+	ret0, _ := ret[0].(map[string]uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItemIdsByExternalIds indicates an expected call of GetItemIdsByExternalIds.
+func (mr *MockRepositoryMockRecorder) GetItemIdsByExternalIds(ctx, userId, externalIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemIdsByExternalIds", reflect.TypeOf((*MockRepository)(nil).GetItemIdsByExternalIds), ctx, userId, externalIds)
+}
+
+// GetJobById mocks base method.
+func (m *MockRepository) GetJobById(ctx context.Context, id uint) (Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobById", ctx, id)
+	ret0, _ := ret[0].(Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobById indicates an expected call of GetJobById.
+func (mr *MockRepositoryMockRecorder) GetJobById(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobById", reflect.TypeOf((*MockRepository)(nil).GetJobById), ctx, id)
+}
+
+// GetJobsForUser mocks base method.
+func (m *MockRepository) GetJobsForUser(ctx context.Context, userId uint) ([]Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobsForUser", ctx, userId)
+	ret0, _ := ret[0].([]Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobsForUser indicates an expected call of GetJobsForUser.
+func (mr *MockRepositoryMockRecorder) GetJobsForUser(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobsForUser", reflect.TypeOf((*MockRepository)(nil).GetJobsForUser), ctx, userId)
+}
+
+// SaveJob mocks base method.
+func (m *MockRepository) SaveJob(ctx context.Context, job *Job) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveJob", ctx, job)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveJob indicates an expected call of SaveJob.
+func (mr *MockRepositoryMockRecorder) SaveJob(ctx, job any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveJob", reflect.TypeOf((*MockRepository)(nil).SaveJob), ctx, job)
+}
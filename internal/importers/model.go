@@ -0,0 +1,45 @@
+package importers
+
+import (
+	"time"
+	"todo-app/internal/transfer"
+
+	"gorm.io/gorm"
+)
+
+const (
+	SourceTodoist       = "todoist"
+	SourceTrello        = "trello"
+	SourceMicrosoftToDo = "mstodo"
+)
+
+const (
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// Job : an import running in the background. Total is known once the file is parsed,
+// Processed counts the items handled so far.
+type Job struct {
+	gorm.Model
+	UserId     uint   `gorm:"not null;index"`
+	Source     string `gorm:"type:varchar(16);not null"`
+	FileName   string `gorm:"type:varchar(255)"`
+	Status     string `gorm:"type:varchar(16);not null;index"`
+	Total      int
+	Processed  int
+	Created    int
+	Skipped    int
+	Failed     int
+	Errors     []transfer.RowError `gorm:"type:json;serializer:json"`
+	FinishedAt *time.Time
+}
+
+// Item : a todo read from an export file. Parent is the external id of the item it
+// belongs to, like the card of a Trello checklist item; parents come before their
+// children.
+type Item struct {
+	Record transfer.Record
+	Parent string
+}
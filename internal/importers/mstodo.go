@@ -0,0 +1,168 @@
+package importers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"todo-app/internal/todos"
+	"todo-app/internal/transfer"
+)
+
+type msDateTime struct {
+	DateTime string `json:"dateTime"`
+	TimeZone string `json:"timeZone"`
+}
+
+type msTaskList struct {
+	Id          string   `json:"id"`
+	DisplayName string   `json:"displayName"`
+	Tasks       []msTask `json:"tasks"`
+}
+
+// msTask : a task as Microsoft Graph returns it
+type msTask struct {
+	Id                string      `json:"id"`
+	Title             string      `json:"title"`
+	Status            string      `json:"status"`
+	Importance        string      `json:"importance"`
+	DueDateTime       *msDateTime `json:"dueDateTime"`
+	CompletedDateTime *msDateTime `json:"completedDateTime"`
+	Categories        []string    `json:"categories"`
+	ChecklistItems    []struct {
+		Id          string `json:"id"`
+		DisplayName string `json:"displayName"`
+		IsChecked   bool   `json:"isChecked"`
+	} `json:"checklistItems"`
+	Recurrence *struct {
+		Pattern struct {
+			Type       string   `json:"type"`
+			Interval   int      `json:"interval"`
+			DaysOfWeek []string `json:"daysOfWeek"`
+			DayOfMonth int      `json:"dayOfMonth"`
+		} `json:"pattern"`
+	} `json:"recurrence"`
+}
+
+// parseMicrosoftToDo : reads task lists with their tasks as Microsoft Graph returns
+// them, either a {"value": [...]} page or a plain array. Tasks are tagged with their
+// list and categories, checklist steps become their children.
+func parseMicrosoftToDo(r io.Reader, _ string, _ time.Time) ([]Item, error) {
+	reader := bufio.NewReader(r)
+	var lists []msTaskList
+	if firstByte(reader) == '[' {
+		if err := json.NewDecoder(reader).Decode(&lists); err != nil {
+			return nil, err
+		}
+	} else {
+		var page struct {
+			Value []msTaskList `json:"value"`
+		}
+		if err := json.NewDecoder(reader).Decode(&page); err != nil {
+			return nil, err
+		}
+		lists = page.Value
+	}
+
+	var items []Item
+	for _, list := range lists {
+		for _, task := range list.Tasks {
+			record := transfer.Record{
+				ExternalId: "mstodo:" + task.Id,
+				Text:       strings.TrimSpace(task.Title),
+				Done:       task.Status == "completed",
+				Priority:   msPriority(task.Importance),
+				Recurrence: msRecurrence(task),
+				Tags:       tags(append([]string{list.DisplayName}, task.Categories...)...),
+			}
+			if task.DueDateTime != nil {
+				record.DueAt = msDue(*task.DueDateTime)
+			}
+			if record.Done && task.CompletedDateTime != nil {
+				record.CompletedAt = parseDue(task.CompletedDateTime.DateTime, loadLocation(task.CompletedDateTime.TimeZone))
+			}
+			items = append(items, Item{Record: record})
+
+			for _, step := range task.ChecklistItems {
+				items = append(items, Item{
+					Record: transfer.Record{
+						ExternalId: "mstodo:" + step.Id,
+						Text:       strings.TrimSpace(step.DisplayName),
+						Done:       step.IsChecked,
+					},
+					Parent: record.ExternalId,
+				})
+			}
+		}
+	}
+
+	return orderByParent(items), nil
+}
+
+func msPriority(importance string) string {
+	switch importance {
+	case "high":
+		return todos.PriorityHigh
+	case "low":
+		return todos.PriorityLow
+	default:
+		return ""
+	}
+}
+
+// msDue : To Do only stores due days, as midnight of the day; they are due at the end
+// of the day like our date-only due dates
+func msDue(due msDateTime) *time.Time {
+	loc := loadLocation(due.TimeZone)
+	t, err := time.ParseInLocation("2006-01-02T15:04:05.9999999", due.DateTime, loc)
+	if err != nil {
+		return parseDue(due.DateTime, loc)
+	}
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		t = endOfDay(t)
+	}
+	utc := t.UTC()
+
+	return &utc
+}
+
+// msRecurrence : the RRULE of a recurrence pattern, empty for patterns we cannot express
+func msRecurrence(task msTask) string {
+	if task.Recurrence == nil {
+		return ""
+	}
+	pattern := task.Recurrence.Pattern
+
+	var parts []string
+	switch pattern.Type {
+	case "daily":
+		parts = append(parts, "FREQ=DAILY")
+	case "weekly":
+		parts = append(parts, "FREQ=WEEKLY")
+	case "absoluteMonthly":
+		parts = append(parts, "FREQ=MONTHLY")
+	case "absoluteYearly":
+		parts = append(parts, "FREQ=YEARLY")
+	default:
+		return ""
+	}
+	if pattern.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", pattern.Interval))
+	}
+	if pattern.Type == "weekly" && len(pattern.DaysOfWeek) > 0 {
+		var days []string
+		for _, day := range pattern.DaysOfWeek {
+			if len(day) >= 2 {
+				days = append(days, strings.ToUpper(day[:2]))
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if pattern.Type == "absoluteMonthly" && pattern.DayOfMonth > 0 {
+		parts = append(parts, fmt.Sprintf("BYMONTHDAY=%d", pattern.DayOfMonth))
+	}
+
+	return strings.Join(parts, ";")
+}
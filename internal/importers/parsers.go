@@ -0,0 +1,169 @@
+package importers
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// maxTagLength : tags longer than the tag column are cut
+const maxTagLength = 64
+
+// parser : reads the items of an export file, fileName is the name it was uploaded
+// under and now is used for relative dates
+type parser func(r io.Reader, fileName string, now time.Time) ([]Item, error)
+
+var parsers = map[string]parser{
+	SourceTodoist:       parseTodoist,
+	SourceTrello:        parseTrello,
+	SourceMicrosoftToDo: parseMicrosoftToDo,
+}
+
+// flexibleId : an id that older exports write as a number and newer ones as a string
+type flexibleId string
+
+func (id *flexibleId) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*id = ""
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		*id = flexibleId(value)
+		return nil
+	}
+
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+	*id = flexibleId(number.String())
+
+	return nil
+}
+
+// firstByte : the first byte of r that is not whitespace or a byte order mark, without
+// consuming it, to tell JSON from CSV
+func firstByte(r *bufio.Reader) byte {
+	if bom, err := r.Peek(3); err == nil && string(bom) == "\xef\xbb\xbf" {
+		_, _ = r.Discard(3)
+	}
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return 0
+		}
+		if !unicode.IsSpace(rune(b[0])) {
+			return b[0]
+		}
+		_, _ = r.Discard(1)
+	}
+}
+
+// tags : trimmed, cut and deduplicated tag names, empty ones dropped
+func tags(names ...string) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, name := range names {
+		tag := strings.TrimSpace(name)
+		if len(tag) > maxTagLength {
+			tag = strings.ToValidUTF8(tag[:maxTagLength], "")
+		}
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		result = append(result, tag)
+	}
+
+	return result
+}
+
+// parseDue : reads an RFC 3339 time, a time without zone taken in loc, or a date, which
+// is due at the end of that day
+func parseDue(value string, loc *time.Location) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		utc := t.UTC()
+		return &utc
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05.9999999", "2006-01-02T15:04:05", "2006-01-02 15:04:05"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			utc := t.UTC()
+			return &utc
+		}
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		utc := endOfDay(t).UTC()
+		return &utc
+	}
+
+	return nil
+}
+
+// endOfDay : the last second of t's day, where date-only due dates fall like in quick-add
+func endOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, t.Location())
+}
+
+func loadLocation(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// orderByParent : items with every parent before its children, keeping the order of
+// siblings. Items whose parent is missing become top-level items.
+func orderByParent(items []Item) []Item {
+	known := map[string]bool{}
+	for _, item := range items {
+		known[item.Record.ExternalId] = true
+	}
+
+	children := map[string][]Item{}
+	var roots []Item
+	for _, item := range items {
+		if item.Parent == "" || !known[item.Parent] || item.Parent == item.Record.ExternalId {
+			item.Parent = ""
+			roots = append(roots, item)
+			continue
+		}
+		children[item.Parent] = append(children[item.Parent], item)
+	}
+
+	ordered := make([]Item, 0, len(items))
+	visited := map[string]bool{}
+	var visit func(item Item)
+	visit = func(item Item) {
+		if visited[item.Record.ExternalId] {
+			return
+		}
+		visited[item.Record.ExternalId] = true
+		ordered = append(ordered, item)
+		for _, child := range children[item.Record.ExternalId] {
+			visit(child)
+		}
+	}
+	for _, root := range roots {
+		visit(root)
+	}
+
+	return ordered
+}
@@ -0,0 +1,8 @@
+package importers
+
+import "todo-app/pkg/authz"
+
+// JobPolicy : import jobs are only read by the user who started them
+func JobPolicy(userId uint, _ authz.Action, job Job) bool {
+	return authz.Owns(userId, job.UserId)
+}
@@ -0,0 +1,118 @@
+package importers
+
+import (
+	"context"
+	"todo-app/internal/todos"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	CreateJob(ctx context.Context, job *Job) error
+	SaveJob(ctx context.Context, job *Job) error
+	GetJobById(ctx context.Context, id uint) (Job, error)
+	GetJobsForUser(ctx context.Context, userId uint) ([]Job, error)
+	FailRunningJobs(ctx context.Context) (int64, error)
+	GetItemIdsByExternalIds(ctx context.Context, userId uint, externalIds []string) (map[string]uint, error)
+}
+
+type repository struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func GetRepository(logger *zap.SugaredLogger, db *gorm.DB) Repository {
+	return &repository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (r *repository) CreateJob(ctx context.Context, job *Job) error {
+	result := r.db.WithContext(ctx).Create(job)
+	if result.Error != nil {
+		r.logger.Errorw("failed to create import job", "user_id", job.UserId, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) SaveJob(ctx context.Context, job *Job) error {
+	result := r.db.WithContext(ctx).Save(job)
+	if result.Error != nil {
+		r.logger.Errorw("failed to save import job", "id", job.ID, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) GetJobById(ctx context.Context, id uint) (Job, error) {
+	var job Job
+	result := r.db.WithContext(ctx).First(&job, id)
+	if result.Error != nil {
+		return Job{}, result.Error
+	}
+
+	return job, nil
+}
+
+func (r *repository) GetJobsForUser(ctx context.Context, userId uint) ([]Job, error) {
+	var jobs []Job
+	result := r.db.WithContext(ctx).Where("user_id = ?", userId).Order("id DESC").Find(&jobs)
+	if result.Error != nil {
+		r.logger.Errorw("failed to get import jobs", "user_id", userId, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return jobs, nil
+}
+
+// FailRunningJobs : marks jobs that were running when the server stopped as failed
+func (r *repository) FailRunningJobs(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&Job{}).
+		Where("status = ?", JobRunning).
+		Updates(map[string]interface{}{"status": JobFailed, "finished_at": gorm.Expr("UTC_TIMESTAMP()")})
+	if result.Error != nil {
+		r.logger.Errorw("failed to fail running import jobs", "error", result.Error)
+
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// GetItemIdsByExternalIds : the ids of the user's items with the given external ids,
+// deleted items included so that an import does not bring them back
+func (r *repository) GetItemIdsByExternalIds(ctx context.Context, userId uint, externalIds []string) (map[string]uint, error) {
+	ids := map[string]uint{}
+	if len(externalIds) == 0 {
+		return ids, nil
+	}
+
+	var items []todos.ToDoItem
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Select("id", "external_id").
+		Where("user_id = ? AND external_id IN ?", userId, externalIds).
+		Find(&items)
+	if result.Error != nil {
+		r.logger.Errorw("failed to get todo items by external id", "user_id", userId, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	for _, item := range items {
+		if item.ExternalId != nil {
+			ids[*item.ExternalId] = item.ID
+		}
+	}
+
+	return ids, nil
+}
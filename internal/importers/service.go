@@ -0,0 +1,179 @@
+package importers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+	"todo-app/internal/todos"
+	"todo-app/internal/transfer"
+	"todo-app/pkg/locale"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// maxImportItems : the most items a single import may contain
+	maxImportItems = 10000
+	// maxJobErrors : the most item errors a job keeps
+	maxJobErrors = 100
+	// progressEvery : a running job is saved after this many items
+	progressEvery = 25
+)
+
+type Service interface {
+	Start(ctx context.Context, userId uint, source string, fileName string, r io.Reader) (Job, error)
+	GetJobById(ctx context.Context, id uint) (Job, error)
+	GetJobsForUser(ctx context.Context, userId uint) ([]Job, error)
+	FailInterrupted(ctx context.Context) error
+}
+
+type service struct {
+	logger      *zap.SugaredLogger
+	repository  Repository
+	todoService todos.Service
+	now         func() time.Time
+	// async : runs an import job in the background
+	async func(f func())
+}
+
+func GetService(logger *zap.SugaredLogger, repo Repository, todoService todos.Service) Service {
+	return &service{
+		logger:      logger,
+		repository:  repo,
+		todoService: todoService,
+		now:         time.Now,
+		async:       func(f func()) { go f() },
+	}
+}
+
+// Start : parses the export file and creates the items of it in the background. The
+// returned job is running, its progress is read with GetJobById.
+func (s *service) Start(ctx context.Context, userId uint, source string, fileName string, r io.Reader) (Job, error) {
+	parse, ok := parsers[source]
+	if !ok {
+		return Job{}, errors.New(locale.ErrorInvalidImportSource)
+	}
+
+	items, err := parse(r, fileName, s.now())
+	if err != nil {
+		s.logger.Warnw("could not parse import", "source", source, "error", err)
+
+		return Job{}, errors.New(locale.ErrorInvalidImport)
+	}
+	if len(items) > maxImportItems {
+		return Job{}, errors.New(locale.ErrorImportTooLarge)
+	}
+
+	job := Job{
+		UserId:   userId,
+		Source:   source,
+		FileName: fileName,
+		Status:   JobRunning,
+		Total:    len(items),
+		Errors:   []transfer.RowError{},
+	}
+	if err := s.repository.CreateJob(ctx, &job); err != nil {
+		return Job{}, err
+	}
+
+	running := job
+	s.async(func() {
+		// the request is done by the time the job runs
+		s.run(context.Background(), &running, items)
+	})
+
+	return job, nil
+}
+
+// run : creates the items of the job, skipping those whose external id the user already
+// has so that importing a file again only adds what is new
+func (s *service) run(ctx context.Context, job *Job, items []Item) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Errorw("import job panicked", "id", job.ID, "panic", r)
+			s.finish(ctx, job, JobFailed)
+		}
+	}()
+
+	externalIds := make([]string, 0, len(items))
+	for _, item := range items {
+		externalIds = append(externalIds, item.Record.ExternalId)
+	}
+	ids, err := s.repository.GetItemIdsByExternalIds(ctx, job.UserId, externalIds)
+	if err != nil {
+		s.finish(ctx, job, JobFailed)
+		return
+	}
+
+	for i, item := range items {
+		if _, exists := ids[item.Record.ExternalId]; exists {
+			job.Skipped++
+		} else if err := s.create(ctx, job.UserId, item, ids); err != nil {
+			job.Failed++
+			if len(job.Errors) < maxJobErrors {
+				job.Errors = append(job.Errors, transfer.RowError{Row: i + 1, ExternalId: item.Record.ExternalId, Error: err.Error()})
+			}
+		} else {
+			job.Created++
+		}
+
+		job.Processed++
+		if job.Processed%progressEvery == 0 && job.Processed < job.Total {
+			if err := s.repository.SaveJob(ctx, job); err != nil {
+				s.logger.Warnw("could not save import progress", "id", job.ID, "error", err)
+			}
+		}
+	}
+
+	s.finish(ctx, job, JobDone)
+}
+
+func (s *service) create(ctx context.Context, userId uint, item Item, ids map[string]uint) error {
+	todo := item.Record.Item(userId)
+	if item.Parent != "" {
+		parentId, ok := ids[item.Parent]
+		if !ok {
+			return fmt.Errorf("parent %s was not imported", item.Parent)
+		}
+		todo.ParentId = &parentId
+	}
+
+	if err := s.todoService.Create(ctx, &todo); err != nil {
+		return err
+	}
+	ids[item.Record.ExternalId] = todo.ID
+
+	return nil
+}
+
+func (s *service) finish(ctx context.Context, job *Job, status string) {
+	finishedAt := s.now().UTC()
+	job.Status = status
+	job.FinishedAt = &finishedAt
+	if err := s.repository.SaveJob(ctx, job); err != nil {
+		s.logger.Errorw("could not save finished import job", "id", job.ID, "status", status, "error", err)
+	}
+}
+
+func (s *service) GetJobById(ctx context.Context, id uint) (Job, error) {
+	return s.repository.GetJobById(ctx, id)
+}
+
+func (s *service) GetJobsForUser(ctx context.Context, userId uint) ([]Job, error) {
+	return s.repository.GetJobsForUser(ctx, userId)
+}
+
+// FailInterrupted : jobs do not survive a restart, the ones still running are failed
+func (s *service) FailInterrupted(ctx context.Context) error {
+	count, err := s.repository.FailRunningJobs(ctx)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		s.logger.Warnw("failed interrupted import jobs", "count", count)
+	}
+
+	return nil
+}
@@ -0,0 +1,301 @@
+package importers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/todos"
+	"todo-app/pkg/locale"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+
+func externalIds(items []Item) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.Record.ExternalId)
+	}
+	return ids
+}
+
+func TestParseTodoist_CSV(t *testing.T) {
+	file := "\xef\xbb\xbfTYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE\n" +
+		"section,Errands,,,,,,,,\n" +
+		"task,Buy milk @shopping,,1,1,Ann,,tomorrow 9am,en,Europe/Berlin\n" +
+		"task,Oat milk,,4,2,Ann,,,en,\n" +
+		"note,Only the cheap one,,,,,,,,\n" +
+		",,,,,,,,,\n" +
+		"task,Water plants,,2,1,Ann,,every monday,en,\n"
+
+	items, err := parseTodoist(strings.NewReader(file), "Home.csv", testNow)
+	assert.NoError(t, err)
+	assert.Len(t, items, 3)
+
+	milk := items[0].Record
+	assert.Equal(t, "Buy milk", milk.Text)
+	assert.Equal(t, todos.PriorityHigh, milk.Priority)
+	assert.Equal(t, []string{"Home", "Errands", "shopping"}, milk.Tags)
+	assert.Equal(t, time.Date(2025, 3, 13, 8, 0, 0, 0, time.UTC), *milk.DueAt)
+	assert.Equal(t, "Europe/Berlin", milk.Timezone)
+	assert.True(t, strings.HasPrefix(milk.ExternalId, "todoist:"))
+
+	assert.Equal(t, milk.ExternalId, items[1].Parent)
+	assert.Empty(t, items[1].Record.Priority)
+	assert.Empty(t, items[2].Parent)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO", items[2].Record.Recurrence)
+
+	t.Run("the same file gives the same external ids", func(t *testing.T) {
+		again, err := parseTodoist(strings.NewReader(file), "Home.csv", testNow)
+		assert.NoError(t, err)
+		assert.Equal(t, externalIds(items), externalIds(again))
+	})
+
+	t.Run("missing content column", func(t *testing.T) {
+		_, err := parseTodoist(strings.NewReader("TYPE,NAME\ntask,x\n"), "Home.csv", testNow)
+		assert.Error(t, err)
+	})
+}
+
+func TestParseTodoist_JSON(t *testing.T) {
+	file := `{
+		"projects": [{"id": "p1", "name": "Work"}],
+		"sections": [{"id": 7, "name": "Q2"}],
+		"items": [
+			{"id": "3", "content": "Write report", "project_id": "p1", "parent_id": "1", "priority": 1, "checked": true, "completed_at": "2025-03-01T12:00:00Z"},
+			{"id": "1", "content": "Quarterly review", "project_id": "p1", "section_id": 7, "priority": 4, "labels": ["office"],
+			 "due": {"date": "2025-03-20", "string": "every 2 weeks", "is_recurring": true, "timezone": null}}
+		]
+	}`
+
+	items, err := parseTodoist(strings.NewReader(file), "backup.json", testNow)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"todoist:1", "todoist:3"}, externalIds(items))
+
+	review := items[0].Record
+	assert.Equal(t, todos.PriorityHigh, review.Priority)
+	assert.Equal(t, []string{"Work", "Q2", "office"}, review.Tags)
+	assert.Equal(t, time.Date(2025, 3, 20, 23, 59, 59, 0, time.UTC), *review.DueAt)
+	assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2", review.Recurrence)
+
+	report := items[1]
+	assert.Equal(t, "todoist:1", report.Parent)
+	assert.True(t, report.Record.Done)
+	assert.Equal(t, time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC), *report.Record.CompletedAt)
+	assert.Empty(t, report.Record.Priority)
+
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := parseTodoist(strings.NewReader(`{"items": [`), "backup.json", testNow)
+		assert.Error(t, err)
+	})
+}
+
+func TestParseTrello(t *testing.T) {
+	file := `{
+		"name": "Launch",
+		"lists": [{"id": "l1", "name": "Doing"}, {"id": "l2", "name": "Old", "closed": true}],
+		"cards": [
+			{"id": "c1", "name": "Landing page", "idList": "l1", "due": "2025-04-01T15:00:00.000Z", "dueComplete": true,
+			 "labels": [{"name": "web", "color": "green"}, {"name": "", "color": "red"}]},
+			{"id": "c2", "name": "Archived", "idList": "l1", "closed": true},
+			{"id": "c3", "name": "In closed list", "idList": "l2"}
+		],
+		"checklists": [
+			{"id": "k1", "idCard": "c1", "checkItems": [{"id": "i1", "name": "Copy", "state": "complete"}, {"id": "i2", "name": "Images", "state": "incomplete"}]},
+			{"id": "k2", "idCard": "c2", "checkItems": [{"id": "i3", "name": "Gone", "state": "incomplete"}]}
+		]
+	}`
+
+	items, err := parseTrello(strings.NewReader(file), "board.json", testNow)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"trello:c1", "trello:i1", "trello:i2"}, externalIds(items))
+
+	card := items[0].Record
+	assert.True(t, card.Done)
+	assert.Equal(t, time.Date(2025, 4, 1, 15, 0, 0, 0, time.UTC), *card.DueAt)
+	assert.Equal(t, []string{"Launch", "Doing", "web", "red"}, card.Tags)
+
+	assert.Equal(t, "trello:c1", items[1].Parent)
+	assert.True(t, items[1].Record.Done)
+	assert.False(t, items[2].Record.Done)
+}
+
+func TestParseMicrosoftToDo(t *testing.T) {
+	file := `{"value": [{
+		"id": "list1", "displayName": "Groceries",
+		"tasks": [
+			{"id": "t1", "title": "Bread", "status": "notStarted", "importance": "high", "categories": ["Red category"],
+			 "dueDateTime": {"dateTime": "2025-03-14T00:00:00.0000000", "timeZone": "UTC"},
+			 "recurrence": {"pattern": {"type": "weekly", "interval": 2, "daysOfWeek": ["monday", "friday"]}},
+			 "checklistItems": [{"id": "s1", "displayName": "Sourdough", "isChecked": true}]},
+			{"id": "t2", "title": "Rent", "status": "completed", "importance": "normal",
+			 "completedDateTime": {"dateTime": "2025-03-01T09:30:00.0000000", "timeZone": "UTC"},
+			 "recurrence": {"pattern": {"type": "absoluteMonthly", "interval": 1, "dayOfMonth": 1}}}
+		]
+	}]}`
+
+	items, err := parseMicrosoftToDo(strings.NewReader(file), "lists.json", testNow)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mstodo:t1", "mstodo:s1", "mstodo:t2"}, externalIds(items))
+
+	bread := items[0].Record
+	assert.Equal(t, todos.PriorityHigh, bread.Priority)
+	assert.Equal(t, []string{"Groceries", "Red category"}, bread.Tags)
+	assert.Equal(t, time.Date(2025, 3, 14, 23, 59, 59, 0, time.UTC), *bread.DueAt)
+	assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", bread.Recurrence)
+
+	assert.Equal(t, "mstodo:t1", items[1].Parent)
+	assert.True(t, items[1].Record.Done)
+
+	rent := items[2].Record
+	assert.True(t, rent.Done)
+	assert.Empty(t, rent.Priority)
+	assert.Equal(t, time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC), *rent.CompletedAt)
+	assert.Equal(t, "FREQ=MONTHLY;BYMONTHDAY=1", rent.Recurrence)
+
+	t.Run("plain array", func(t *testing.T) {
+		items, err := parseMicrosoftToDo(strings.NewReader(`[{"displayName": "Inbox", "tasks": [{"id": "t9", "title": "Call"}]}]`), "lists.json", testNow)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"mstodo:t9"}, externalIds(items))
+	})
+}
+
+func TestTags(t *testing.T) {
+	long := strings.Repeat("a", 80)
+	assert.Equal(t, []string{"Work", strings.Repeat("a", maxTagLength)}, tags(" Work ", "", "work", long))
+}
+
+func TestService_Start(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	mockTodoService := todos.NewMockService(ctrl)
+	var jobs []func()
+	s := &service{
+		logger:      zap.NewNop().Sugar(),
+		repository:  mockRepo,
+		todoService: mockTodoService,
+		now:         func() time.Time { return testNow },
+		async:       func(f func()) { jobs = append(jobs, f) },
+	}
+	ctx := context.Background()
+
+	file := `{"name": "Board", "lists": [{"id": "l1", "name": "Todo"}],
+		"cards": [{"id": "c1", "name": "Known", "idList": "l1"}, {"id": "c2", "name": "New", "idList": "l1"}, {"id": "c3", "name": "", "idList": "l1"}],
+		"checklists": [{"idCard": "c1", "checkItems": [{"id": "i1", "name": "Step"}]}]}`
+
+	t.Run("creates new items in the background", func(t *testing.T) {
+		jobs = nil
+		mockRepo.
+			EXPECT().
+			CreateJob(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, job *Job) error {
+				job.ID = 3
+				return nil
+			}).
+			Times(1)
+
+		job, err := s.Start(ctx, 5, SourceTrello, "board.json", strings.NewReader(file))
+		assert.NoError(t, err)
+		assert.Equal(t, uint(3), job.ID)
+		assert.Equal(t, JobRunning, job.Status)
+		assert.Equal(t, 4, job.Total)
+		assert.Len(t, jobs, 1)
+
+		mockRepo.
+			EXPECT().
+			GetItemIdsByExternalIds(gomock.Any(), uint(5), []string{"trello:c1", "trello:i1", "trello:c2", "trello:c3"}).
+			Return(map[string]uint{"trello:c1": 40}, nil).
+			Times(1)
+		var created []todos.ToDoItem
+		mockTodoService.
+			EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, item *todos.ToDoItem) error {
+				if item.Text == "" {
+					return errors.New("text is required")
+				}
+				item.ID = uint(50 + len(created))
+				created = append(created, *item)
+				return nil
+			}).
+			Times(3)
+		var finished Job
+		mockRepo.
+			EXPECT().
+			SaveJob(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, job *Job) error {
+				finished = *job
+				return nil
+			}).
+			Times(1)
+
+		jobs[0]()
+
+		assert.Len(t, created, 2)
+		assert.Equal(t, uint(40), *created[0].ParentId)
+		assert.Equal(t, uint(5), created[0].UserId)
+		assert.Equal(t, "trello:c2", *created[1].ExternalId)
+		assert.Equal(t, JobDone, finished.Status)
+		assert.Equal(t, 4, finished.Processed)
+		assert.Equal(t, 2, finished.Created)
+		assert.Equal(t, 1, finished.Skipped)
+		assert.Equal(t, 1, finished.Failed)
+		assert.Equal(t, "trello:c3", finished.Errors[0].ExternalId)
+		assert.Equal(t, testNow, *finished.FinishedAt)
+	})
+
+	t.Run("lookup fails", func(t *testing.T) {
+		jobs = nil
+		mockRepo.EXPECT().CreateJob(ctx, gomock.Any()).Return(nil).Times(1)
+		_, err := s.Start(ctx, 5, SourceTrello, "board.json", strings.NewReader(file))
+		assert.NoError(t, err)
+
+		mockRepo.
+			EXPECT().
+			GetItemIdsByExternalIds(gomock.Any(), uint(5), gomock.Any()).
+			Return(nil, errors.New("db down")).
+			Times(1)
+		mockRepo.
+			EXPECT().
+			SaveJob(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, job *Job) error {
+				assert.Equal(t, JobFailed, job.Status)
+				return nil
+			}).
+			Times(1)
+
+		jobs[0]()
+	})
+
+	t.Run("unknown source", func(t *testing.T) {
+		_, err := s.Start(ctx, 5, "asana", "board.json", strings.NewReader(file))
+		assert.EqualError(t, err, locale.ErrorInvalidImportSource)
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		_, err := s.Start(ctx, 5, SourceTrello, "board.json", strings.NewReader("not json"))
+		assert.EqualError(t, err, locale.ErrorInvalidImport)
+	})
+
+	ctrl.Finish()
+}
+
+func TestService_FailInterrupted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	service := GetService(zap.NewNop().Sugar(), mockRepo, todos.NewMockService(ctrl))
+	ctx := context.Background()
+
+	mockRepo.EXPECT().FailRunningJobs(ctx).Return(int64(2), nil).Times(1)
+	assert.NoError(t, service.FailInterrupted(ctx))
+
+	mockRepo.EXPECT().FailRunningJobs(ctx).Return(int64(0), errors.New("db down")).Times(1)
+	assert.Error(t, service.FailInterrupted(ctx))
+
+	ctrl.Finish()
+}
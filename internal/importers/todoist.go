@@ -0,0 +1,238 @@
+package importers
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/todos"
+	"todo-app/internal/transfer"
+)
+
+// todoistBackup : the parts of a Todoist sync backup the import reads
+type todoistBackup struct {
+	Projects []struct {
+		Id   flexibleId `json:"id"`
+		Name string     `json:"name"`
+	} `json:"projects"`
+	Sections []struct {
+		Id   flexibleId `json:"id"`
+		Name string     `json:"name"`
+	} `json:"sections"`
+	Items []todoistItem `json:"items"`
+}
+
+type todoistItem struct {
+	Id          flexibleId `json:"id"`
+	Content     string     `json:"content"`
+	ProjectId   flexibleId `json:"project_id"`
+	SectionId   flexibleId `json:"section_id"`
+	ParentId    flexibleId `json:"parent_id"`
+	Priority    int        `json:"priority"`
+	Labels      []string   `json:"labels"`
+	Checked     bool       `json:"checked"`
+	CompletedAt string     `json:"completed_at"`
+	Due         *struct {
+		Date        string `json:"date"`
+		String      string `json:"string"`
+		IsRecurring bool   `json:"is_recurring"`
+		Timezone    string `json:"timezone"`
+	} `json:"due"`
+}
+
+// parseTodoist : reads a Todoist sync backup (JSON) or a project exported as CSV
+func parseTodoist(r io.Reader, fileName string, now time.Time) ([]Item, error) {
+	reader := bufio.NewReader(r)
+	if firstByte(reader) == '{' {
+		return parseTodoistJSON(reader, now)
+	}
+
+	return parseTodoistCSV(reader, fileName, now)
+}
+
+func parseTodoistJSON(r io.Reader, now time.Time) ([]Item, error) {
+	var backup todoistBackup
+	if err := json.NewDecoder(r).Decode(&backup); err != nil {
+		return nil, err
+	}
+
+	projects := map[flexibleId]string{}
+	for _, project := range backup.Projects {
+		projects[project.Id] = project.Name
+	}
+	sections := map[flexibleId]string{}
+	for _, section := range backup.Sections {
+		sections[section.Id] = section.Name
+	}
+
+	items := make([]Item, 0, len(backup.Items))
+	for _, task := range backup.Items {
+		record := transfer.Record{
+			ExternalId: "todoist:" + string(task.Id),
+			Text:       strings.TrimSpace(task.Content),
+			Done:       task.Checked,
+			Priority:   todoistPriority(task.Priority, true),
+			Tags:       tags(append([]string{projects[task.ProjectId], sections[task.SectionId]}, task.Labels...)...),
+		}
+		if task.Checked {
+			record.CompletedAt = parseDue(task.CompletedAt, time.UTC)
+		}
+		if task.Due != nil {
+			loc := loadLocation(task.Due.Timezone)
+			record.DueAt = parseDue(task.Due.Date, loc)
+			if task.Due.IsRecurring {
+				parsed, _ := todos.ParseQuickAdd(task.Due.String, now, loc, "en")
+				record.Recurrence = parsed.Recurrence
+			}
+			if task.Due.Timezone != "" && loc != time.UTC {
+				record.Timezone = task.Due.Timezone
+			}
+		}
+
+		item := Item{Record: record}
+		if task.ParentId != "" {
+			item.Parent = "todoist:" + string(task.ParentId)
+		}
+		items = append(items, item)
+	}
+
+	return orderByParent(items), nil
+}
+
+// parseTodoistCSV : reads the CSV export of a Todoist project. The project is named
+// after the file, INDENT nests tasks below the task before them and DATE holds the
+// due date as it was typed, like "every monday 9am".
+func parseTodoistCSV(r io.Reader, fileName string, now time.Time) ([]Item, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToUpper(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["CONTENT"]; !ok {
+		return nil, errors.New("missing CONTENT column")
+	}
+	value := func(row []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	project := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+	sum := sha1.Sum([]byte(project))
+	prefix := "todoist:" + hex.EncodeToString(sum[:4]) + ":"
+
+	var items []Item
+	var section string
+	// parents[i] is the last task with indent i+1
+	var parents []string
+	line := 1
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, err
+		}
+
+		switch strings.ToLower(value(row, "TYPE")) {
+		case "section":
+			section = value(row, "CONTENT")
+			parents = nil
+			continue
+		case "task":
+		default:
+			// notes and empty rows
+			continue
+		}
+
+		text, labels := splitLabels(value(row, "CONTENT"))
+		record := transfer.Record{
+			ExternalId: prefix + strconv.Itoa(line),
+			Text:       text,
+			Tags:       tags(append([]string{project, section}, labels...)...),
+		}
+		priority, _ := strconv.Atoi(value(row, "PRIORITY"))
+		record.Priority = todoistPriority(priority, false)
+
+		if date := value(row, "DATE"); date != "" {
+			loc := loadLocation(value(row, "TIMEZONE"))
+			parsed, _ := todos.ParseQuickAdd(date, now, loc, value(row, "DATE_LANG"))
+			record.DueAt = parsed.DueAt
+			record.Recurrence = parsed.Recurrence
+			if loc != time.UTC {
+				record.Timezone = loc.String()
+			}
+		}
+
+		indent, _ := strconv.Atoi(value(row, "INDENT"))
+		if indent < 1 {
+			indent = 1
+		}
+		if indent > len(parents)+1 {
+			indent = len(parents) + 1
+		}
+		parents = append(parents[:indent-1], record.ExternalId)
+
+		item := Item{Record: record}
+		if indent > 1 {
+			item.Parent = parents[indent-2]
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// todoistPriority : our priority for a Todoist one. The API counts from 4 (p1, the
+// highest) down, the CSV export counts from 1 up; the lowest level means none.
+func todoistPriority(priority int, api bool) string {
+	if api {
+		priority = 5 - priority
+	}
+	switch priority {
+	case 1:
+		return todos.PriorityHigh
+	case 2:
+		return todos.PriorityMedium
+	case 3:
+		return todos.PriorityLow
+	default:
+		return ""
+	}
+}
+
+// splitLabels : the content without its @labels, and the labels
+func splitLabels(content string) (string, []string) {
+	var words, labels []string
+	for _, word := range strings.Fields(content) {
+		if len(word) > 1 && strings.HasPrefix(word, "@") {
+			labels = append(labels, word[1:])
+			continue
+		}
+		words = append(words, word)
+	}
+
+	text := strings.Join(words, " ")
+	if text == "" {
+		text = strings.TrimSpace(content)
+	}
+
+	return text, labels
+}
@@ -0,0 +1,114 @@
+package importers
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+	"todo-app/internal/transfer"
+)
+
+// trelloBoard : the parts of a Trello board export the import reads
+type trelloBoard struct {
+	Name  string `json:"name"`
+	Lists []struct {
+		Id     string `json:"id"`
+		Name   string `json:"name"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Cards []struct {
+		Id          string `json:"id"`
+		Name        string `json:"name"`
+		IdList      string `json:"idList"`
+		Closed      bool   `json:"closed"`
+		Due         string `json:"due"`
+		DueComplete bool   `json:"dueComplete"`
+		Labels      []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"labels"`
+	} `json:"cards"`
+	Checklists []struct {
+		Id         string `json:"id"`
+		IdCard     string `json:"idCard"`
+		CheckItems []struct {
+			Id    string `json:"id"`
+			Name  string `json:"name"`
+			State string `json:"state"`
+			Due   string `json:"due"`
+		} `json:"checkItems"`
+	} `json:"checklists"`
+}
+
+// parseTrello : reads a board exported as JSON. Every open card becomes a todo tagged
+// with the board, its list and its labels, checklist items become its children.
+func parseTrello(r io.Reader, _ string, _ time.Time) ([]Item, error) {
+	var board trelloBoard
+	if err := json.NewDecoder(r).Decode(&board); err != nil {
+		return nil, err
+	}
+
+	lists := map[string]string{}
+	closedLists := map[string]bool{}
+	for _, list := range board.Lists {
+		lists[list.Id] = list.Name
+		closedLists[list.Id] = list.Closed
+	}
+
+	var items []Item
+	for _, card := range board.Cards {
+		if card.Closed || closedLists[card.IdList] {
+			continue
+		}
+
+		names := []string{board.Name, lists[card.IdList]}
+		for _, label := range card.Labels {
+			if label.Name != "" {
+				names = append(names, label.Name)
+			} else {
+				names = append(names, label.Color)
+			}
+		}
+		items = append(items, Item{Record: transfer.Record{
+			ExternalId: "trello:" + card.Id,
+			Text:       strings.TrimSpace(card.Name),
+			Done:       card.DueComplete,
+			DueAt:      parseDue(card.Due, time.UTC),
+			Tags:       tags(names...),
+		}})
+	}
+
+	for _, checklist := range board.Checklists {
+		for _, checkItem := range checklist.CheckItems {
+			items = append(items, Item{
+				Record: transfer.Record{
+					ExternalId: "trello:" + checkItem.Id,
+					Text:       strings.TrimSpace(checkItem.Name),
+					Done:       checkItem.State == "complete",
+					DueAt:      parseDue(checkItem.Due, time.UTC),
+				},
+				Parent: "trello:" + checklist.IdCard,
+			})
+		}
+	}
+
+	return orderByParent(dropOrphans(items)), nil
+}
+
+// dropOrphans : items without the parent they belong to, like checklist items of
+// archived cards
+func dropOrphans(items []Item) []Item {
+	known := map[string]bool{}
+	for _, item := range items {
+		known[item.Record.ExternalId] = true
+	}
+
+	kept := items[:0]
+	for _, item := range items {
+		if item.Parent == "" || known[item.Parent] {
+			kept = append(kept, item)
+		}
+	}
+
+	return kept
+}
@@ -0,0 +1,223 @@
+package rules
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/todos"
+)
+
+// maxChainDepth : the most rules that may fire one after another because of each other's
+// writes. Together with rules not firing twice in a chain this stops loops.
+const maxChainDepth = 5
+
+type chainKey struct{}
+
+// firedRules : the rules whose actions led to the write being handled
+func firedRules(ctx context.Context) []uint {
+	ids, _ := ctx.Value(chainKey{}).([]uint)
+
+	return ids
+}
+
+func withRule(ctx context.Context, ruleId uint) context.Context {
+	return context.WithValue(ctx, chainKey{}, append(slices.Clone(firedRules(ctx)), ruleId))
+}
+
+// run : performs the actions of the rule if its conditions match the item. A failing
+// action is logged and does not stop the others.
+func (s *service) run(ctx context.Context, rule Rule, item todos.ToDoItem) {
+	chain := firedRules(ctx)
+	if len(chain) >= maxChainDepth || slices.Contains(chain, rule.ID) {
+		s.logger.Infow("rule not fired to prevent a loop", "rule_id", rule.ID, "chain", chain)
+
+		return
+	}
+	if !matches(rule.Conditions, item) {
+		return
+	}
+	if key := firingKey(rule.Trigger, item); key != "" {
+		first, err := s.repository.RecordFiring(ctx, &Firing{RuleId: rule.ID, ItemId: item.ID, Key: key})
+		if err != nil || !first {
+			return
+		}
+	}
+
+	ctx = withRule(ctx, rule.ID)
+	for _, action := range rule.Actions {
+		if err := s.perform(ctx, action, &item); err != nil {
+			s.logger.Warnw("rule action failed", "rule_id", rule.ID, "action", action.Type, "error", err)
+		}
+	}
+}
+
+// firingKey : what a rule fires once for, empty for rules that fire on every write
+func firingKey(trigger string, item todos.ToDoItem) string {
+	switch {
+	case trigger == TriggerCompleted && item.CompletedAt != nil:
+		return "completed:" + item.CompletedAt.UTC().Format(time.RFC3339Nano)
+	case trigger == TriggerDueSoon && item.DueAt != nil:
+		return "due:" + item.DueAt.UTC().Format(time.RFC3339)
+	}
+
+	return ""
+}
+
+// perform : runs the action, item is replaced when the action changed it so that the
+// next actions see the change
+func (s *service) perform(ctx context.Context, action Action, item *todos.ToDoItem) error {
+	switch action.Type {
+	case ActionSetField:
+		input, changed, err := setField(action.Field, expand(action.Value, *item), *item)
+		if err != nil || !changed {
+			return err
+		}
+		updated, err := s.todoService.UpdateById(ctx, item.ID, input)
+		if err != nil {
+			return err
+		}
+		*item = updated
+	case ActionCreateTodo:
+		created := todos.ToDoItem{UserId: item.UserId, Text: expand(action.Text, *item)}
+		for _, tag := range action.Tags {
+			created.Tags = append(created.Tags, todos.Tag{Name: tag})
+		}
+		if action.Subtask {
+			created.ParentId = &item.ID
+		}
+		return s.todoService.Create(ctx, &created)
+	case ActionSendEmail:
+		user, err := s.userRepository.GetById(ctx, item.UserId)
+		if err != nil {
+			return err
+		}
+		subject := expand(action.Subject, *item)
+		body := expand(action.Body, *item)
+		if body == "" {
+			body = item.Text
+		}
+		s.async(func() {
+			if err := s.emailService.Send(user.Email, subject, body); err != nil {
+				s.logger.Warnw("could not send rule email", "user_id", user.ID, "error", err)
+			}
+		})
+	}
+
+	return nil
+}
+
+// setField : the update setting the field to the value, changed is false when the item
+// already has it
+func setField(field string, value string, item todos.ToDoItem) (todos.ToDoItemUpdateInput, bool, error) {
+	var input todos.ToDoItemUpdateInput
+	switch field {
+	case "text":
+		input.Text = &value
+		return input, item.Text != value, nil
+	case "priority":
+		input.Priority = &value
+		return input, item.Priority != value, nil
+	case "recurrence":
+		input.Recurrence = &value
+		return input, item.Recurrence != value, nil
+	case "type":
+		input.Type = &value
+		return input, item.Type != value, nil
+	case "done":
+		done, err := strconv.ParseBool(value)
+		if err != nil {
+			return input, false, err
+		}
+		input.Done = &done
+		return input, item.Done != done, nil
+	}
+
+	return input, false, nil
+}
+
+func expand(template string, item todos.ToDoItem) string {
+	dueAt := ""
+	if item.DueAt != nil {
+		dueAt = item.DueAt.UTC().Format(time.RFC3339)
+	}
+
+	return strings.NewReplacer(
+		"{text}", item.Text,
+		"{priority}", item.Priority,
+		"{due_at}", dueAt,
+		"{id}", strconv.FormatUint(uint64(item.ID), 10),
+	).Replace(template)
+}
+
+func matches(conditions []Condition, item todos.ToDoItem) bool {
+	for _, condition := range conditions {
+		if !condition.matches(item) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c Condition) matches(item todos.ToDoItem) bool {
+	values := fieldValues(c.Field, item)
+	value := strings.ToLower(c.Value)
+
+	anyValue := func(match func(string) bool) bool {
+		return slices.ContainsFunc(values, match)
+	}
+	switch c.Op {
+	case OpIsSet:
+		return len(values) > 0
+	case OpIsNotSet:
+		return len(values) == 0
+	case OpEquals:
+		return anyValue(func(v string) bool { return v == value })
+	case OpNotEquals:
+		return !anyValue(func(v string) bool { return v == value })
+	case OpContains:
+		return anyValue(func(v string) bool { return strings.Contains(v, value) })
+	case OpNotContains:
+		return !anyValue(func(v string) bool { return strings.Contains(v, value) })
+	case OpStartsWith:
+		return anyValue(func(v string) bool { return strings.HasPrefix(v, value) })
+	}
+
+	return false
+}
+
+// fieldValues : the lowercased values of the field, none when it is empty
+func fieldValues(field string, item todos.ToDoItem) []string {
+	var values []string
+	switch field {
+	case "text":
+		values = []string{item.Text}
+	case "priority":
+		values = []string{item.Priority}
+	case "type":
+		values = []string{item.Type}
+	case "recurrence":
+		values = []string{item.Recurrence}
+	case "done":
+		values = []string{strconv.FormatBool(item.Done)}
+	case "due_at":
+		if item.DueAt != nil {
+			values = []string{item.DueAt.UTC().Format(time.RFC3339)}
+		}
+	case "tags":
+		for _, tag := range item.Tags {
+			values = append(values, tag.Name)
+		}
+	}
+
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			result = append(result, strings.ToLower(value))
+		}
+	}
+
+	return result
+}
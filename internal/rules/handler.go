@@ -0,0 +1,254 @@
+package rules
+
+import (
+	"net/http"
+	"todo-app/internal/auth"
+	"todo-app/pkg/authz"
+	e "todo-app/pkg/errors"
+	"todo-app/pkg/handlers"
+	"todo-app/pkg/locale"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type endpointHandler struct {
+	logger  *zap.SugaredLogger
+	service Service
+	e       *echo.Echo
+}
+
+func GetEndpointHandler(
+	logger *zap.SugaredLogger,
+	service Service,
+	e *echo.Echo,
+) handlers.EndpointHandler {
+	return &endpointHandler{
+		logger:  logger,
+		service: service,
+		e:       e,
+	}
+}
+
+func (h *endpointHandler) AddEndpoints() {
+	var endpoints = []handlers.Endpoint{
+		{
+			Method:  http.MethodPost,
+			Path:    "/rules",
+			Handler: h.create,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/rules",
+			Handler: h.getAll,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/rules/:id",
+			Handler: h.getById,
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/rules/:id",
+			Handler: h.update,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/rules/:id",
+			Handler: h.delete,
+		},
+	}
+
+	for _, endpoint := range endpoints {
+		handlers.Method(h.e, endpoint.Method, endpoint.Path, endpoint.Handler)
+	}
+}
+
+// @Summary Create rule
+// @Description This endpoint creates a rule that runs its actions on a todo item of the current user when the trigger happens and the conditions match.
+// @Description Triggers are created, updated, completed and due_soon; actions set a field, create a todo item or send an email.
+// @Tags rules
+// @ID createRule
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param rule body RuleInput true "Rule"
+// @Success 201 {object} Rule
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Router /rules [post]
+func (h *endpointHandler) create(ctx echo.Context) error {
+	h.logger.Infow("creating rule...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	var input RuleInput
+	err := ctx.Bind(&input)
+	if err != nil {
+		h.logger.Warn("could not bind body to rule struct", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+	}
+
+	rule, err := h.service.Create(ctx.Request().Context(), userId, input)
+	if err != nil {
+		h.logger.Warn("could not create rule", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusCreated, rule)
+}
+
+// @Summary Get rules
+// @Description This endpoint returns the rules of the current user
+// @Tags rules
+// @ID getRules
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} Rule
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /rules [get]
+func (h *endpointHandler) getAll(ctx echo.Context) error {
+	h.logger.Infow("getting rules...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	rules, err := h.service.GetAllForUser(ctx.Request().Context(), userId)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorInternalServer, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, rules)
+}
+
+// @Summary Get rule
+// @Description This endpoint returns a rule of the current user
+// @Tags rules
+// @ID getRule
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Rule ID"
+// @Success 200 {object} Rule
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /rules/{id} [get]
+func (h *endpointHandler) getById(ctx echo.Context) error {
+	h.logger.Infow("getting rule...")
+
+	rule, ok, err := h.getOwnRule(ctx, authz.ActionRead)
+	if !ok {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, rule)
+}
+
+// @Summary Update rule
+// @Description This endpoint replaces a rule
+// @Tags rules
+// @ID updateRule
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Rule ID"
+// @Param rule body RuleInput true "Rule"
+// @Success 200 {object} Rule
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /rules/{id} [put]
+func (h *endpointHandler) update(ctx echo.Context) error {
+	h.logger.Infow("updating rule...")
+
+	rule, ok, err := h.getOwnRule(ctx, authz.ActionUpdate)
+	if !ok {
+		return err
+	}
+
+	var input RuleInput
+	err = ctx.Bind(&input)
+	if err != nil {
+		h.logger.Warn("could not bind body to rule struct", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+	}
+
+	rule, err = h.service.Update(ctx.Request().Context(), rule, input)
+	if err != nil {
+		h.logger.Warn("could not update rule", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, rule)
+}
+
+// @Summary Delete rule
+// @Description This endpoint deletes a rule
+// @Tags rules
+// @ID deleteRule
+// @Security BearerAuth
+// @Param id path int true "Rule ID"
+// @Success 204
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /rules/{id} [delete]
+func (h *endpointHandler) delete(ctx echo.Context) error {
+	h.logger.Infow("deleting rule...")
+
+	rule, ok, err := h.getOwnRule(ctx, authz.ActionDelete)
+	if !ok {
+		return err
+	}
+
+	err = h.service.Delete(ctx.Request().Context(), rule.ID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorCouldNotDelete, Details: err.Error()})
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// getOwnRule : the rule of the id in the url if it belongs to the current user,
+// otherwise the error response is written and ok is false
+func (h *endpointHandler) getOwnRule(ctx echo.Context, action authz.Action) (Rule, bool, error) {
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return Rule{}, false, ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	id, err := handlers.GetUrlId(ctx, h.logger)
+	if err != nil {
+		h.logger.Warn("could not get id from url", "error", err.Error())
+
+		return Rule{}, false, ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidID})
+	}
+
+	rule, err := h.service.GetById(ctx.Request().Context(), id)
+	if err != nil {
+		if authz.Missing(err) {
+			return Rule{}, false, authz.Deny(ctx)
+		}
+		h.logger.Warn("could not get rule", "error", err.Error())
+
+		return Rule{}, false, ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorNotFoundRecord})
+	}
+	if err := authz.Authorize(RulePolicy, userId, action, rule); err != nil {
+		h.logger.Info("user tried to access rule of other user")
+
+		return Rule{}, false, authz.Deny(ctx)
+	}
+
+	return rule, true, nil
+}
@@ -0,0 +1,181 @@
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"todo-app/pkg/locale"
+
+	localErr "todo-app/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestHandler_Create(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	h := &endpointHandler{logger: zap.NewNop().Sugar(), service: mockService, e: e}
+
+	newContext := func(body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/rules", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+
+		return ctx, rec
+	}
+
+	t.Run("creates the rule", func(t *testing.T) {
+		ctx, rec := newContext(`{"name": "Follow up", "trigger": "completed", "actions": [{"type": "create_todo", "text": "Follow up on {text}"}]}`)
+
+		mockService.
+			EXPECT().
+			Create(ctx.Request().Context(), uint(1), gomock.Any()).
+			DoAndReturn(func(_ interface{}, _ uint, input RuleInput) (Rule, error) {
+				assert.Equal(t, TriggerCompleted, input.Trigger)
+				assert.Equal(t, "Follow up on {text}", input.Actions[0].Text)
+				return Rule{Model: gorm.Model{ID: 2}, Name: input.Name, Trigger: input.Trigger, Actions: input.Actions, Active: true}, nil
+			}).
+			Times(1)
+
+		if assert.NoError(t, h.create(ctx)) {
+			assert.Equal(t, http.StatusCreated, rec.Code)
+
+			var response Rule
+			err := json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, uint(2), response.ID)
+			assert.True(t, response.Active)
+		}
+	})
+
+	t.Run("invalid rule", func(t *testing.T) {
+		ctx, rec := newContext(`{"name": "Nothing", "trigger": "deleted"}`)
+
+		mockService.EXPECT().Create(ctx.Request().Context(), uint(1), gomock.Any()).Return(Rule{}, errors.New("Key: 'RuleInput.Trigger' Error")).Times(1)
+
+		if assert.NoError(t, h.create(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var response localErr.ResponseError
+			err := json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, locale.ErrorInvalidBody, response.Message)
+		}
+	})
+
+	ctrl.Finish()
+}
+
+func TestHandler_Delete(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	h := &endpointHandler{logger: zap.NewNop().Sugar(), service: mockService, e: e}
+
+	newContext := func(userId uint) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetPath("/rules/:id")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues("2")
+		ctx.Set("user_id", userId)
+
+		return ctx, rec
+	}
+
+	t.Run("deletes the rule", func(t *testing.T) {
+		ctx, rec := newContext(1)
+
+		mockService.EXPECT().GetById(ctx.Request().Context(), uint(2)).Return(Rule{Model: gorm.Model{ID: 2}, UserId: 1}, nil).Times(1)
+		mockService.EXPECT().Delete(ctx.Request().Context(), uint(2)).Return(nil).Times(1)
+
+		if assert.NoError(t, h.delete(ctx)) {
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+		}
+	})
+
+	t.Run("rule of another user", func(t *testing.T) {
+		ctx, rec := newContext(3)
+
+		mockService.EXPECT().GetById(ctx.Request().Context(), uint(2)).Return(Rule{Model: gorm.Model{ID: 2}, UserId: 1}, nil).Times(1)
+
+		if assert.NoError(t, h.delete(ctx)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+		}
+	})
+
+	ctrl.Finish()
+}
+
+func TestHandler_CrossUserAccess(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	h := &endpointHandler{logger: zap.NewNop().Sugar(), service: mockService, e: e}
+
+	// rule 2 belongs to user 2, user 1 tries to reach it
+	rule := Rule{Model: gorm.Model{ID: 2}, UserId: 2}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		id      string
+		handler echo.HandlerFunc
+	}{
+		{"read rule", http.MethodGet, "/rules/:id", "2", h.getById},
+		{"update rule", http.MethodPut, "/rules/:id", "2", h.update},
+		{"delete rule", http.MethodDelete, "/rules/:id", "2", h.delete},
+		// ids that do not exist answer the same
+		{"read nonexistent rule", http.MethodGet, "/rules/:id", "404", h.getById},
+		{"delete nonexistent rule", http.MethodDelete, "/rules/:id", "404", h.delete},
+	}
+	var denied string
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, "/", strings.NewReader(`{}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(req, rec)
+			ctx.Set("user_id", uint(1))
+			ctx.SetPath(test.path)
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(test.id)
+
+			// only the lookup happens, nothing is changed
+			mockService.EXPECT().GetById(gomock.Any(), rule.ID).Return(rule, nil).AnyTimes()
+			mockService.EXPECT().GetById(gomock.Any(), uint(404)).Return(Rule{}, gorm.ErrRecordNotFound).AnyTimes()
+
+			if assert.NoError(t, test.handler(ctx)) {
+				assert.Equal(t, http.StatusNotFound, rec.Code)
+
+				var responseError localErr.ResponseError
+				err := json.Unmarshal(rec.Body.Bytes(), &responseError)
+				assert.NoError(t, err)
+				assert.Equal(t, locale.ErrorNotFoundRecord, responseError.Message)
+
+				if denied == "" {
+					denied = rec.Body.String()
+				}
+				assert.Equal(t, denied, rec.Body.String())
+			}
+		})
+	}
+
+	ctrl.Finish()
+}
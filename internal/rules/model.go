@@ -0,0 +1,87 @@
+package rules
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	TriggerCreated   = "created"
+	TriggerUpdated   = "updated"
+	TriggerCompleted = "completed"
+	TriggerDueSoon   = "due_soon"
+)
+
+const (
+	OpEquals      = "equals"
+	OpNotEquals   = "not_equals"
+	OpContains    = "contains"
+	OpNotContains = "not_contains"
+	OpStartsWith  = "starts_with"
+	OpIsSet       = "is_set"
+	OpIsNotSet    = "is_not_set"
+)
+
+const (
+	ActionSetField   = "set_field"
+	ActionCreateTodo = "create_todo"
+	ActionSendEmail  = "send_email"
+)
+
+// Rule : actions run on a todo item of the user when the trigger happens and all
+// conditions match the item
+type Rule struct {
+	gorm.Model
+	UserId  uint   `gorm:"not null;index" json:"-"`
+	Name    string `gorm:"type:varchar(100);not null" json:"name"`
+	Trigger string `gorm:"type:varchar(16);not null;index" json:"trigger"`
+	// DueWithin : minutes before the due date a due_soon rule fires
+	DueWithin  int         `json:"due_within,omitempty"`
+	Conditions []Condition `gorm:"type:json;serializer:json" json:"conditions"`
+	Actions    []Action    `gorm:"type:json;serializer:json" json:"actions"`
+	Active     bool        `gorm:"default:true" json:"active"`
+}
+
+// Condition : compares a field of the item with the value, ignoring case. Tags match
+// when any tag of the item does.
+type Condition struct {
+	Field string `json:"field" validate:"required,oneof=text priority type done tags due_at recurrence"`
+	Op    string `json:"op" validate:"required,oneof=equals not_equals contains not_contains starts_with is_set is_not_set"`
+	Value string `json:"value" validate:"max=255"`
+}
+
+// Action : Value, Text, Subject and Body may contain {text}, {priority}, {due_at} and
+// {id}, which are replaced with the fields of the item
+type Action struct {
+	Type string `json:"type" validate:"required,oneof=set_field create_todo send_email"`
+	// Field, Value : the field set_field sets and its new value
+	Field string `json:"field,omitempty" validate:"required_if=Type set_field,omitempty,oneof=text priority done recurrence type"`
+	Value string `json:"value,omitempty" validate:"max=1000"`
+	// Text, Tags, Subtask : the item create_todo creates, as sub-task of the item if set
+	Text    string   `json:"text,omitempty" validate:"required_if=Type create_todo,max=1000"`
+	Tags    []string `json:"tags,omitempty" validate:"max=10,dive,max=64"`
+	Subtask bool     `json:"subtask,omitempty"`
+	// Subject, Body : the email send_email sends to the user
+	Subject string `json:"subject,omitempty" validate:"required_if=Type send_email,max=255"`
+	Body    string `json:"body,omitempty" validate:"max=5000"`
+}
+
+type RuleInput struct {
+	Name       string      `json:"name" validate:"required,max=100"`
+	Trigger    string      `json:"trigger" validate:"required,oneof=created updated completed due_soon"`
+	DueWithin  int         `json:"due_within" validate:"omitempty,min=1,max=10080"`
+	Conditions []Condition `json:"conditions" validate:"max=10,dive"`
+	Actions    []Action    `json:"actions" validate:"required,min=1,max=10,dive"`
+	Active     *bool       `json:"active"`
+}
+
+// Firing : a rule that fired for an item. Completed and due_soon rules fire once per
+// completion and due date, the key tells them apart.
+type Firing struct {
+	ID        uint   `gorm:"primaryKey"`
+	RuleId    uint   `gorm:"not null;uniqueIndex:idx_rule_firing"`
+	ItemId    uint   `gorm:"not null;uniqueIndex:idx_rule_firing"`
+	Key       string `gorm:"type:varchar(64);not null;uniqueIndex:idx_rule_firing"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,8 @@
+package rules
+
+import "todo-app/pkg/authz"
+
+// RulePolicy : rules are only read and changed by their owner
+func RulePolicy(userId uint, _ authz.Action, rule Rule) bool {
+	return authz.Owns(userId, rule.UserId)
+}
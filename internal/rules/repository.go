@@ -0,0 +1,147 @@
+package rules
+
+import (
+	"context"
+	"time"
+	"todo-app/internal/todos"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	Create(ctx context.Context, rule *Rule) error
+	Save(ctx context.Context, rule *Rule) error
+	Delete(ctx context.Context, id uint) error
+	GetById(ctx context.Context, id uint) (Rule, error)
+	GetAllForUser(ctx context.Context, userId uint) ([]Rule, error)
+	GetActive(ctx context.Context, userId uint, trigger string) ([]Rule, error)
+	GetActiveDueSoon(ctx context.Context) ([]Rule, error)
+	GetItemsDueBetween(ctx context.Context, userId uint, from time.Time, to time.Time) ([]todos.ToDoItem, error)
+	RecordFiring(ctx context.Context, firing *Firing) (bool, error)
+}
+
+type repository struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func GetRepository(logger *zap.SugaredLogger, db *gorm.DB) Repository {
+	return &repository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (r *repository) Create(ctx context.Context, rule *Rule) error {
+	result := r.db.WithContext(ctx).Create(rule)
+	if result.Error != nil {
+		r.logger.Errorw("failed to create rule", "user_id", rule.UserId, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) Save(ctx context.Context, rule *Rule) error {
+	result := r.db.WithContext(ctx).Save(rule)
+	if result.Error != nil {
+		r.logger.Errorw("failed to save rule", "id", rule.ID, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&Rule{}, id)
+	if result.Error != nil {
+		r.logger.Errorw("failed to delete rule", "id", id, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) GetById(ctx context.Context, id uint) (Rule, error) {
+	var rule Rule
+	result := r.db.WithContext(ctx).First(&rule, id)
+	if result.Error != nil {
+		return Rule{}, result.Error
+	}
+
+	return rule, nil
+}
+
+func (r *repository) GetAllForUser(ctx context.Context, userId uint) ([]Rule, error) {
+	var rules []Rule
+	result := r.db.WithContext(ctx).Where("user_id = ?", userId).Order("id").Find(&rules)
+	if result.Error != nil {
+		r.logger.Errorw("failed to get rules", "user_id", userId, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return rules, nil
+}
+
+// GetActive : the active rules of the user for the trigger, in the order they were created
+func (r *repository) GetActive(ctx context.Context, userId uint, trigger string) ([]Rule, error) {
+	var rules []Rule
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND `trigger` = ? AND active = ?", userId, trigger, true).
+		Order("id").
+		Find(&rules)
+	if result.Error != nil {
+		r.logger.Errorw("failed to get active rules", "user_id", userId, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return rules, nil
+}
+
+// GetActiveDueSoon : the active due_soon rules of all users
+func (r *repository) GetActiveDueSoon(ctx context.Context) ([]Rule, error) {
+	var rules []Rule
+	result := r.db.WithContext(ctx).Where("`trigger` = ? AND active = ?", TriggerDueSoon, true).Order("id").Find(&rules)
+	if result.Error != nil {
+		r.logger.Errorw("failed to get due soon rules", "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return rules, nil
+}
+
+// GetItemsDueBetween : the open items of the user due after from and up to to
+func (r *repository) GetItemsDueBetween(ctx context.Context, userId uint, from time.Time, to time.Time) ([]todos.ToDoItem, error) {
+	var items []todos.ToDoItem
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND done = ? AND due_at > ? AND due_at <= ?", userId, false, from, to).
+		Preload("Tags").
+		Find(&items)
+	if result.Error != nil {
+		r.logger.Errorw("failed to get items due soon", "user_id", userId, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return items, nil
+}
+
+// RecordFiring : false when the rule already fired for the item and key
+func (r *repository) RecordFiring(ctx context.Context, firing *Firing) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(firing)
+	if result.Error != nil {
+		r.logger.Errorw("failed to record rule firing", "rule_id", firing.RuleId, "error", result.Error)
+
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
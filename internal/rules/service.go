@@ -0,0 +1,182 @@
+package rules
+
+import (
+	"context"
+	"time"
+	"todo-app/internal/todos"
+	"todo-app/internal/users"
+	"todo-app/pkg/email"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultDueWithin : minutes before the due date a due_soon rule fires, when not set
+	defaultDueWithin = 60
+	// checkInterval : how often items are checked for due_soon rules
+	checkInterval = time.Minute
+)
+
+type Service interface {
+	Create(ctx context.Context, userId uint, input RuleInput) (Rule, error)
+	Update(ctx context.Context, rule Rule, input RuleInput) (Rule, error)
+	Delete(ctx context.Context, id uint) error
+	GetById(ctx context.Context, id uint) (Rule, error)
+	GetAllForUser(ctx context.Context, userId uint) ([]Rule, error)
+	HandleTodoEvent(ctx context.Context, event todos.Event)
+	Run(ctx context.Context)
+}
+
+type service struct {
+	logger         *zap.SugaredLogger
+	repository     Repository
+	userRepository users.Repository
+	todoService    todos.Service
+	emailService   email.Service
+	validator      *validator.Validate
+	now            func() time.Time
+	// async : sends emails in the background, so writes do not wait for the mail server
+	async func(f func())
+}
+
+func GetService(
+	logger *zap.SugaredLogger,
+	repo Repository,
+	userRepository users.Repository,
+	todoService todos.Service,
+	emailService email.Service,
+	validator *validator.Validate,
+) Service {
+	return &service{
+		logger:         logger,
+		repository:     repo,
+		userRepository: userRepository,
+		todoService:    todoService,
+		emailService:   emailService,
+		validator:      validator,
+		now:            time.Now,
+		async:          func(f func()) { go f() },
+	}
+}
+
+func (s *service) Create(ctx context.Context, userId uint, input RuleInput) (Rule, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return Rule{}, err
+	}
+
+	rule := Rule{UserId: userId, Active: true}
+	apply(&rule, input)
+	if err := s.repository.Create(ctx, &rule); err != nil {
+		return Rule{}, err
+	}
+
+	return rule, nil
+}
+
+func (s *service) Update(ctx context.Context, rule Rule, input RuleInput) (Rule, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return Rule{}, err
+	}
+
+	apply(&rule, input)
+	if err := s.repository.Save(ctx, &rule); err != nil {
+		return Rule{}, err
+	}
+
+	return rule, nil
+}
+
+func apply(rule *Rule, input RuleInput) {
+	rule.Name = input.Name
+	rule.Trigger = input.Trigger
+	rule.DueWithin = 0
+	if input.Trigger == TriggerDueSoon {
+		rule.DueWithin = input.DueWithin
+		if rule.DueWithin == 0 {
+			rule.DueWithin = defaultDueWithin
+		}
+	}
+	rule.Conditions = input.Conditions
+	if rule.Conditions == nil {
+		rule.Conditions = []Condition{}
+	}
+	rule.Actions = input.Actions
+	if input.Active != nil {
+		rule.Active = *input.Active
+	}
+}
+
+func (s *service) Delete(ctx context.Context, id uint) error {
+	return s.repository.Delete(ctx, id)
+}
+
+func (s *service) GetById(ctx context.Context, id uint) (Rule, error) {
+	return s.repository.GetById(ctx, id)
+}
+
+func (s *service) GetAllForUser(ctx context.Context, userId uint) ([]Rule, error) {
+	return s.repository.GetAllForUser(ctx, userId)
+}
+
+// HandleTodoEvent : runs the rules of the user the write triggers. Completed rules fire
+// when an item is created or updated done, once per completion.
+func (s *service) HandleTodoEvent(ctx context.Context, event todos.Event) {
+	switch event.Type {
+	case todos.EventCreated:
+		s.fire(ctx, event.UserId, TriggerCreated, event.Item)
+	case todos.EventUpdated:
+		s.fire(ctx, event.UserId, TriggerUpdated, event.Item)
+	default:
+		return
+	}
+
+	if event.Item.Done && event.Item.CompletedAt != nil {
+		s.fire(ctx, event.UserId, TriggerCompleted, event.Item)
+	}
+}
+
+// Run : fires due_soon rules every check interval until the context is done
+func (s *service) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		s.checkDueSoon(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *service) checkDueSoon(ctx context.Context) {
+	rules, err := s.repository.GetActiveDueSoon(ctx)
+	if err != nil {
+		return
+	}
+
+	now := s.now()
+	for _, rule := range rules {
+		items, err := s.repository.GetItemsDueBetween(ctx, rule.UserId, now, now.Add(time.Duration(rule.DueWithin)*time.Minute))
+		if err != nil {
+			continue
+		}
+		for _, item := range items {
+			s.run(ctx, rule, item)
+		}
+	}
+}
+
+func (s *service) fire(ctx context.Context, userId uint, trigger string, item todos.ToDoItem) {
+	rules, err := s.repository.GetActive(ctx, userId, trigger)
+	if err != nil {
+		return
+	}
+
+	for _, rule := range rules {
+		s.run(ctx, rule, item)
+	}
+}
@@ -0,0 +1,218 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-app/internal/todos"
+	"todo-app/internal/users"
+	"todo-app/pkg/email"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var testNow = time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+type mocks struct {
+	repository     *MockRepository
+	userRepository *users.MockRepository
+	todoService    *todos.MockService
+	emailService   *email.MockService
+}
+
+func newTestService(ctrl *gomock.Controller) (*service, mocks) {
+	m := mocks{
+		repository:     NewMockRepository(ctrl),
+		userRepository: users.NewMockRepository(ctrl),
+		todoService:    todos.NewMockService(ctrl),
+		emailService:   email.NewMockService(ctrl),
+	}
+	s := GetService(zap.NewNop().Sugar(), m.repository, m.userRepository, m.todoService, m.emailService, validator.New()).(*service)
+	s.now = func() time.Time { return testNow }
+	s.async = func(f func()) { f() }
+
+	return s, m
+}
+
+func TestCondition_Matches(t *testing.T) {
+	dueAt := testNow
+	item := todos.ToDoItem{Text: "Fix login Bug", Priority: "high", Done: false, DueAt: &dueAt, Tags: []todos.Tag{{Name: "work"}, {Name: "backend"}}}
+
+	tests := []struct {
+		condition Condition
+		expected  bool
+	}{
+		{Condition{Field: "text", Op: OpContains, Value: "bug"}, true},
+		{Condition{Field: "text", Op: OpStartsWith, Value: "fix"}, true},
+		{Condition{Field: "text", Op: OpNotContains, Value: "bug"}, false},
+		{Condition{Field: "priority", Op: OpEquals, Value: "High"}, true},
+		{Condition{Field: "priority", Op: OpNotEquals, Value: "high"}, false},
+		{Condition{Field: "done", Op: OpEquals, Value: "false"}, true},
+		{Condition{Field: "tags", Op: OpEquals, Value: "backend"}, true},
+		{Condition{Field: "tags", Op: OpNotEquals, Value: "home"}, true},
+		{Condition{Field: "due_at", Op: OpIsSet}, true},
+		{Condition{Field: "recurrence", Op: OpIsNotSet}, true},
+		{Condition{Field: "recurrence", Op: OpEquals, Value: ""}, false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.condition.matches(item), "%+v", test.condition)
+	}
+}
+
+func TestService_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	s, m := newTestService(ctrl)
+	ctx := context.Background()
+
+	t.Run("due soon rules get a default window", func(t *testing.T) {
+		m.repository.EXPECT().Create(ctx, gomock.Any()).Return(nil).Times(1)
+
+		rule, err := s.Create(ctx, 1, RuleInput{Name: "Soon", Trigger: TriggerDueSoon, Actions: []Action{{Type: ActionSendEmail, Subject: "{text} is due"}}})
+		assert.NoError(t, err)
+		assert.Equal(t, defaultDueWithin, rule.DueWithin)
+		assert.True(t, rule.Active)
+		assert.Equal(t, []Condition{}, rule.Conditions)
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		_, err := s.Create(ctx, 1, RuleInput{Name: "None", Trigger: TriggerCreated})
+		assert.Error(t, err)
+
+		_, err = s.Create(ctx, 1, RuleInput{Name: "Field", Trigger: TriggerCreated, Actions: []Action{{Type: ActionSetField, Field: "user_id", Value: "2"}}})
+		assert.Error(t, err)
+
+		_, err = s.Create(ctx, 1, RuleInput{Name: "Text", Trigger: TriggerCreated, Actions: []Action{{Type: ActionCreateTodo}}})
+		assert.Error(t, err)
+	})
+
+	ctrl.Finish()
+}
+
+func TestService_HandleTodoEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	s, m := newTestService(ctrl)
+	ctx := context.Background()
+
+	t.Run("prefixes bugs and emails the user", func(t *testing.T) {
+		item := todos.ToDoItem{Model: gorm.Model{ID: 7}, UserId: 1, Text: "Login bug"}
+		rule := Rule{
+			Model:      gorm.Model{ID: 3},
+			Trigger:    TriggerCreated,
+			Conditions: []Condition{{Field: "text", Op: OpContains, Value: "bug"}},
+			Actions: []Action{
+				{Type: ActionSetField, Field: "text", Value: "[BUG] {text}"},
+				{Type: ActionSendEmail, Subject: "New bug: {text}"},
+			},
+		}
+		m.repository.EXPECT().GetActive(ctx, uint(1), TriggerCreated).Return([]Rule{rule}, nil).Times(1)
+		text := "[BUG] Login bug"
+		m.todoService.
+			EXPECT().
+			UpdateById(gomock.Any(), uint(7), todos.ToDoItemUpdateInput{Text: &text}).
+			Return(todos.ToDoItem{Model: gorm.Model{ID: 7}, UserId: 1, Text: text}, nil).
+			Times(1)
+		m.userRepository.EXPECT().GetById(gomock.Any(), uint(1)).Return(users.User{Email: "ann@example.com"}, nil).Times(1)
+		m.emailService.EXPECT().Send("ann@example.com", "New bug: [BUG] Login bug", "[BUG] Login bug").Return(nil).Times(1)
+
+		s.HandleTodoEvent(ctx, todos.Event{Type: todos.EventCreated, UserId: 1, Item: item})
+	})
+
+	t.Run("rules do not fire for their own writes", func(t *testing.T) {
+		item := todos.ToDoItem{Model: gorm.Model{ID: 8}, UserId: 2, Text: "Plan"}
+		prefix := Rule{Model: gorm.Model{ID: 4}, Trigger: TriggerUpdated, Actions: []Action{{Type: ActionSetField, Field: "text", Value: "> {text}"}}}
+		m.repository.EXPECT().GetActive(gomock.Any(), uint(2), TriggerUpdated).Return([]Rule{prefix}, nil).Times(2)
+		m.todoService.
+			EXPECT().
+			UpdateById(gomock.Any(), uint(8), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, id uint, input todos.ToDoItemUpdateInput) (todos.ToDoItem, error) {
+				updated := todos.ToDoItem{Model: gorm.Model{ID: id}, UserId: 2, Text: *input.Text}
+				// what the todo service does after writing
+				s.HandleTodoEvent(ctx, todos.Event{Type: todos.EventUpdated, UserId: 2, Item: updated})
+				return updated, nil
+			}).
+			Times(1)
+
+		s.HandleTodoEvent(ctx, todos.Event{Type: todos.EventUpdated, UserId: 2, Item: item})
+	})
+
+	t.Run("completed rules fire once per completion", func(t *testing.T) {
+		completedAt := testNow
+		item := todos.ToDoItem{Model: gorm.Model{ID: 9}, UserId: 3, Text: "Invoice", Done: true, CompletedAt: &completedAt}
+		rule := Rule{Model: gorm.Model{ID: 5}, Trigger: TriggerCompleted, Actions: []Action{{Type: ActionCreateTodo, Text: "Follow up on {text}", Subtask: true, Tags: []string{"later"}}}}
+		m.repository.EXPECT().GetActive(ctx, uint(3), TriggerUpdated).Return(nil, nil).Times(2)
+		m.repository.EXPECT().GetActive(ctx, uint(3), TriggerCompleted).Return([]Rule{rule}, nil).Times(2)
+		firing := &Firing{RuleId: 5, ItemId: 9, Key: "completed:2025-03-01T09:00:00Z"}
+		gomock.InOrder(
+			m.repository.EXPECT().RecordFiring(ctx, firing).Return(true, nil),
+			m.repository.EXPECT().RecordFiring(ctx, firing).Return(false, nil),
+		)
+		m.todoService.
+			EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, created *todos.ToDoItem) error {
+				assert.Equal(t, "Follow up on Invoice", created.Text)
+				assert.Equal(t, uint(3), created.UserId)
+				assert.Equal(t, uint(9), *created.ParentId)
+				assert.Equal(t, "later", created.Tags[0].Name)
+				return nil
+			}).
+			Times(1)
+
+		s.HandleTodoEvent(ctx, todos.Event{Type: todos.EventUpdated, UserId: 3, Item: item})
+		// marked done again, the completion time is kept
+		s.HandleTodoEvent(ctx, todos.Event{Type: todos.EventUpdated, UserId: 3, Item: item})
+	})
+
+	t.Run("completed rules fire for items created done", func(t *testing.T) {
+		completedAt := testNow
+		item := todos.ToDoItem{Model: gorm.Model{ID: 12}, UserId: 4, Text: "Paid rent", Done: true, CompletedAt: &completedAt}
+		rule := Rule{Model: gorm.Model{ID: 7}, Trigger: TriggerCompleted, Actions: []Action{{Type: ActionSetField, Field: "priority", Value: "low"}}}
+		m.repository.EXPECT().GetActive(ctx, uint(4), TriggerCreated).Return(nil, nil).Times(1)
+		m.repository.EXPECT().GetActive(ctx, uint(4), TriggerCompleted).Return([]Rule{rule}, nil).Times(1)
+		m.repository.EXPECT().RecordFiring(ctx, &Firing{RuleId: 7, ItemId: 12, Key: "completed:2025-03-01T09:00:00Z"}).Return(true, nil).Times(1)
+		low := "low"
+		m.todoService.EXPECT().UpdateById(gomock.Any(), uint(12), todos.ToDoItemUpdateInput{Priority: &low}).Return(todos.ToDoItem{}, nil).Times(1)
+
+		s.HandleTodoEvent(ctx, todos.Event{Type: todos.EventCreated, UserId: 4, Item: item})
+	})
+
+	t.Run("open items created do not fire completed rules", func(t *testing.T) {
+		item := todos.ToDoItem{Model: gorm.Model{ID: 13}, UserId: 4, Text: "Pay rent"}
+		m.repository.EXPECT().GetActive(ctx, uint(4), TriggerCreated).Return(nil, nil).Times(1)
+
+		s.HandleTodoEvent(ctx, todos.Event{Type: todos.EventCreated, UserId: 4, Item: item})
+	})
+
+	ctrl.Finish()
+}
+
+func TestService_CheckDueSoon(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	s, m := newTestService(ctrl)
+	ctx := context.Background()
+
+	dueAt := testNow.Add(30 * time.Minute)
+	rule := Rule{Model: gorm.Model{ID: 6}, UserId: 1, Trigger: TriggerDueSoon, DueWithin: 60, Actions: []Action{{Type: ActionSetField, Field: "priority", Value: "high"}}}
+	m.repository.EXPECT().GetActiveDueSoon(ctx).Return([]Rule{rule}, nil).Times(1)
+	m.repository.
+		EXPECT().
+		GetItemsDueBetween(ctx, uint(1), testNow, testNow.Add(time.Hour)).
+		Return([]todos.ToDoItem{
+			{Model: gorm.Model{ID: 10}, UserId: 1, DueAt: &dueAt},
+			{Model: gorm.Model{ID: 11}, UserId: 1, DueAt: &dueAt, Priority: "high"},
+		}, nil).
+		Times(1)
+	m.repository.EXPECT().RecordFiring(ctx, &Firing{RuleId: 6, ItemId: 10, Key: "due:2025-03-01T09:30:00Z"}).Return(true, nil).Times(1)
+	m.repository.EXPECT().RecordFiring(ctx, &Firing{RuleId: 6, ItemId: 11, Key: "due:2025-03-01T09:30:00Z"}).Return(true, nil).Times(1)
+	high := "high"
+	// item 11 already has the priority
+	m.todoService.EXPECT().UpdateById(gomock.Any(), uint(10), todos.ToDoItemUpdateInput{Priority: &high}).Return(todos.ToDoItem{}, nil).Times(1)
+
+	s.checkDueSoon(ctx)
+
+	ctrl.Finish()
+}
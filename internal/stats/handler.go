@@ -0,0 +1,108 @@
+package stats
+
+import (
+	"net/http"
+	"time"
+	"todo-app/internal/auth"
+	e "todo-app/pkg/errors"
+	"todo-app/pkg/handlers"
+	"todo-app/pkg/locale"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// defaultDays : the range covered when the request gives no from date
+const defaultDays = 30
+
+type endpointHandler struct {
+	logger  *zap.SugaredLogger
+	service Service
+	e       *echo.Echo
+}
+
+func GetEndpointHandler(
+	logger *zap.SugaredLogger,
+	service Service,
+	e *echo.Echo,
+) handlers.EndpointHandler {
+	return &endpointHandler{
+		logger:  logger,
+		service: service,
+		e:       e,
+	}
+}
+
+func (h *endpointHandler) AddEndpoints() {
+	var endpoints = []handlers.Endpoint{
+		{
+			Method:  http.MethodGet,
+			Path:    "/stats",
+			Handler: h.getStats,
+		},
+	}
+
+	for _, endpoint := range endpoints {
+		handlers.Method(h.e, endpoint.Method, endpoint.Path, endpoint.Handler)
+	}
+}
+
+// @Summary Productivity statistics
+// @Description This endpoint returns statistics of the current user: created and completed items per day or week,
+// @Description completion streaks, average time to complete, overdue counts and a breakdown per tag.
+// @Description Days are UTC days. from and to are inclusive dates and default to the last 30 days.
+// @Tags stats
+// @ID getStats
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "First day (2006-01-02)"
+// @Param to query string false "Last day (2006-01-02)"
+// @Param group_by query string false "day (default) or week"
+// @Success 200 {object} Stats
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /stats [get]
+func (h *endpointHandler) getStats(ctx echo.Context) error {
+	h.logger.Infow("reading stats...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	now := time.Now().UTC()
+	query := Query{
+		To:      time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1),
+		GroupBy: ctx.QueryParam("group_by"),
+	}
+
+	if to := ctx.QueryParam("to"); to != "" {
+		day, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidStatsQuery, Details: err.Error()})
+		}
+		query.To = day.AddDate(0, 0, 1)
+	}
+	query.From = query.To.AddDate(0, 0, -defaultDays)
+	if from := ctx.QueryParam("from"); from != "" {
+		day, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidStatsQuery, Details: err.Error()})
+		}
+		query.From = day
+	}
+
+	stats, err := h.service.GetStats(ctx.Request().Context(), userId, query)
+	if err != nil {
+		h.logger.Warn("could not read stats", "error", err.Error())
+
+		if err.Error() == locale.ErrorInvalidStatsQuery {
+			return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidStatsQuery})
+		}
+
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorCouldNotReadStats})
+	}
+
+	return ctx.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,90 @@
+package stats
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+	"todo-app/pkg/locale"
+
+	localErr "todo-app/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestHandler_GetStats(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	t.Run("stats for date range", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/stats?from=2025-03-01&to=2025-03-31&group_by=week", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+
+		query := Query{
+			From:    time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+			To:      time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC),
+			GroupBy: GroupByWeek,
+		}
+		mockService.
+			EXPECT().
+			GetStats(ctx.Request().Context(), uint(1), query).
+			Return(Stats{GroupBy: GroupByWeek, Streaks: Streaks{Current: 2, Longest: 5}}, nil).
+			Times(1)
+
+		if assert.NoError(t, h.getStats(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var response Stats
+			err := json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			assert.Equal(t, 5, response.Streaks.Longest)
+		}
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/stats?from=march", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+
+		if assert.NoError(t, h.getStats(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var responseError localErr.ResponseError
+			err := json.Unmarshal(rec.Body.Bytes(), &responseError)
+			assert.NoError(t, err)
+
+			assert.Equal(t, locale.ErrorInvalidStatsQuery, responseError.Message)
+		}
+	})
+
+	t.Run("invalid group by", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/stats?group_by=month", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+
+		mockService.
+			EXPECT().
+			GetStats(ctx.Request().Context(), uint(1), gomock.Any()).
+			Return(Stats{}, errors.New(locale.ErrorInvalidStatsQuery)).
+			Times(1)
+
+		if assert.NoError(t, h.getStats(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
@@ -0,0 +1,116 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/stats/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/stats/repository.go -destination=internal/stats/mock_repository.go -package=stats
+//
+
+// Package stats is a generated GoMock package.
+package stats
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetCompletedPerPeriod mocks base method.
+func (m *MockRepository) GetCompletedPerPeriod(ctx context.Context, userId uint, query Query) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCompletedPerPeriod", ctx, userId, query)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCompletedPerPeriod indicates an expected call of GetCompletedPerPeriod.
+func (mr *MockRepositoryMockRecorder) GetCompletedPerPeriod(ctx, userId, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompletedPerPeriod", reflect.TypeOf((*MockRepository)(nil).GetCompletedPerPeriod), ctx, userId, query)
+}
+
+// GetCompletionDays mocks base method.
+func (m *MockRepository) GetCompletionDays(ctx context.Context, userId uint) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCompletionDays", ctx, userId)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCompletionDays indicates an expected call of GetCompletionDays.
+func (mr *MockRepositoryMockRecorder) GetCompletionDays(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompletionDays", reflect.TypeOf((*MockRepository)(nil).GetCompletionDays), ctx, userId)
+}
+
+// GetCreatedPerPeriod mocks base method.
+func (m *MockRepository) GetCreatedPerPeriod(ctx context.Context, userId uint, query Query) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCreatedPerPeriod", ctx, userId, query)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCreatedPerPeriod indicates an expected call of GetCreatedPerPeriod.
+func (mr *MockRepositoryMockRecorder) GetCreatedPerPeriod(ctx, userId, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCreatedPerPeriod", reflect.TypeOf((*MockRepository)(nil).GetCreatedPerPeriod), ctx, userId, query)
+}
+
+// GetSummary mocks base method.
+func (m *MockRepository) GetSummary(ctx context.Context, userId uint, query Query) (Summary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSummary", ctx, userId, query)
+	ret0, _ := ret[0].(Summary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSummary indicates an expected call of GetSummary.
+func (mr *MockRepositoryMockRecorder) GetSummary(ctx, userId, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSummary", reflect.TypeOf((*MockRepository)(nil).GetSummary), ctx, userId, query)
+}
+
+// GetTagStats mocks base method.
+func (m *MockRepository) GetTagStats(ctx context.Context, userId uint) ([]TagStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTagStats", ctx, userId)
+	ret0, _ := ret[0].([]TagStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTagStats indicates an expected call of GetTagStats.
+func (mr *MockRepositoryMockRecorder) GetTagStats(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTagStats", reflect.TypeOf((*MockRepository)(nil).GetTagStats), ctx, userId)
+}
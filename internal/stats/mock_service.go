@@ -0,0 +1,69 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/stats/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/stats/service.go -destination=internal/stats/mock_service.go -package=stats
+//
+
+// Package stats is a generated GoMock package.
+package stats
+
+import (
+	context "context"
+	reflect "reflect"
+	todos "todo-app/internal/todos"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// GetStats mocks base method.
+func (m *MockService) GetStats(ctx context.Context, userId uint, query Query) (Stats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStats", ctx, userId, query)
+	ret0, _ := ret[0].(Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockServiceMockRecorder) GetStats(ctx, userId, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockService)(nil).GetStats), ctx, userId, query)
+}
+
+// HandleTodoEvent mocks base method.
+func (m *MockService) HandleTodoEvent(ctx context.Context, event todos.Event) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "HandleTodoEvent", ctx, event)
+}
+
+// HandleTodoEvent indicates an expected call of HandleTodoEvent.
+func (mr *MockServiceMockRecorder) HandleTodoEvent(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleTodoEvent", reflect.TypeOf((*MockService)(nil).HandleTodoEvent), ctx, event)
+}
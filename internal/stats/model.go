@@ -0,0 +1,54 @@
+package stats
+
+import "time"
+
+const (
+	GroupByDay  = "day"
+	GroupByWeek = "week"
+)
+
+type Query struct {
+	From    time.Time
+	To      time.Time
+	GroupBy string
+}
+
+// Period : items created and completed within one day (2006-01-02) or ISO week (2006-W01)
+type Period struct {
+	Period    string `json:"period"`
+	Created   int64  `json:"created"`
+	Completed int64  `json:"completed"`
+}
+
+// Streaks : consecutive days with at least one completed item. The current streak
+// is kept alive until the end of the day after the last completion.
+type Streaks struct {
+	Current int `json:"current"`
+	Longest int `json:"longest"`
+}
+
+type TagStats struct {
+	Tag       string `json:"tag"`
+	Total     int64  `json:"total"`
+	Completed int64  `json:"completed"`
+	Overdue   int64  `json:"overdue"`
+}
+
+// Summary : counts for the query range, Open and Overdue describe the items right now
+type Summary struct {
+	Created                  int64   `json:"created"`
+	Completed                int64   `json:"completed"`
+	Open                     int64   `json:"open"`
+	Overdue                  int64   `json:"overdue"`
+	AverageCompletionSeconds float64 `json:"average_completion_seconds"`
+}
+
+type Stats struct {
+	From    time.Time  `json:"from"`
+	To      time.Time  `json:"to"`
+	GroupBy string     `json:"group_by"`
+	Summary Summary    `json:"summary"`
+	Periods []Period   `json:"periods"`
+	Streaks Streaks    `json:"streaks"`
+	Tags    []TagStats `json:"tags"`
+}
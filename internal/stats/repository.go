@@ -0,0 +1,133 @@
+package stats
+
+import (
+	"context"
+	"todo-app/internal/todos"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	GetSummary(ctx context.Context, userId uint, query Query) (Summary, error)
+	GetCreatedPerPeriod(ctx context.Context, userId uint, query Query) (map[string]int64, error)
+	GetCompletedPerPeriod(ctx context.Context, userId uint, query Query) (map[string]int64, error)
+	GetCompletionDays(ctx context.Context, userId uint) ([]string, error)
+	GetTagStats(ctx context.Context, userId uint) ([]TagStats, error)
+}
+
+type repository struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func GetRepository(logger *zap.SugaredLogger, db *gorm.DB) Repository {
+	return &repository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// overdueCondition : open items whose due date has passed
+const overdueCondition = "to_do_items.done = false AND to_do_items.due_at < UTC_TIMESTAMP()"
+
+// items : the user's tasks, habits are checked in rather than completed and are left out
+func (r *repository) items(ctx context.Context, userId uint) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Model(&todos.ToDoItem{}).
+		Where("to_do_items.user_id = ? AND to_do_items.type <> ?", userId, todos.TypeHabit)
+}
+
+func (r *repository) GetSummary(ctx context.Context, userId uint, query Query) (Summary, error) {
+	var summary Summary
+	result := r.items(ctx, userId).
+		Select(`COALESCE(SUM(created_at >= @from AND created_at < @to), 0) AS created,
+			COALESCE(SUM(completed_at >= @from AND completed_at < @to), 0) AS completed,
+			COALESCE(SUM(done = false), 0) AS open,
+			COALESCE(SUM(`+overdueCondition+`), 0) AS overdue,
+			COALESCE(AVG(CASE WHEN completed_at >= @from AND completed_at < @to
+				THEN TIMESTAMPDIFF(SECOND, created_at, completed_at) END), 0) AS average_completion_seconds`,
+			map[string]interface{}{"from": query.From, "to": query.To}).
+		Scan(&summary)
+	if result.Error != nil {
+		r.logger.Errorw("failed to summarize todo items", "user_id", userId, "error", result.Error)
+
+		return Summary{}, result.Error
+	}
+
+	return summary, nil
+}
+
+func (r *repository) GetCreatedPerPeriod(ctx context.Context, userId uint, query Query) (map[string]int64, error) {
+	return r.countPerPeriod(ctx, userId, "created_at", query)
+}
+
+func (r *repository) GetCompletedPerPeriod(ctx context.Context, userId uint, query Query) (map[string]int64, error) {
+	return r.countPerPeriod(ctx, userId, "completed_at", query)
+}
+
+func (r *repository) countPerPeriod(ctx context.Context, userId uint, column string, query Query) (map[string]int64, error) {
+	format := "%Y-%m-%d"
+	if query.GroupBy == GroupByWeek {
+		format = "%x-W%v"
+	}
+
+	var rows []struct {
+		Period string
+		Count  int64
+	}
+	result := r.items(ctx, userId).
+		Select("DATE_FORMAT("+column+", ?) AS period, COUNT(*) AS count", format).
+		Where(column+" >= ? AND "+column+" < ?", query.From, query.To).
+		Group("period").
+		Scan(&rows)
+	if result.Error != nil {
+		r.logger.Errorw("failed to count todo items per period", "user_id", userId, "column", column, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Period] = row.Count
+	}
+
+	return counts, nil
+}
+
+// GetCompletionDays : the distinct days (2006-01-02) on which the user completed items, ascending
+func (r *repository) GetCompletionDays(ctx context.Context, userId uint) ([]string, error) {
+	var days []string
+	result := r.items(ctx, userId).
+		Where("completed_at IS NOT NULL").
+		Distinct("DATE_FORMAT(completed_at, '%Y-%m-%d') AS day").
+		Order("day").
+		Pluck("day", &days)
+	if result.Error != nil {
+		r.logger.Errorw("failed to find completion days", "user_id", userId, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return days, nil
+}
+
+func (r *repository) GetTagStats(ctx context.Context, userId uint) ([]TagStats, error) {
+	var tags []TagStats
+	result := r.items(ctx, userId).
+		Joins("JOIN tags ON tags.to_do_item_id = to_do_items.id").
+		Select(`tags.name AS tag,
+			COUNT(*) AS total,
+			COALESCE(SUM(to_do_items.done), 0) AS completed,
+			COALESCE(SUM(` + overdueCondition + `), 0) AS overdue`).
+		Group("tags.name").
+		Order("total DESC, tags.name").
+		Scan(&tags)
+	if result.Error != nil {
+		r.logger.Errorw("failed to count todo items per tag", "user_id", userId, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,194 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"todo-app/internal/todos"
+	"todo-app/pkg/locale"
+	"todo-app/pkg/lru"
+
+	"go.uber.org/zap"
+)
+
+// cacheTTL : how long computed stats are reused. Writes to todos invalidate them
+// earlier, the TTL only bounds how stale overdue counts and streaks can get.
+const cacheTTL = 5 * time.Minute
+
+// The stats of at most cacheUsers users are cached, each with at most cachePerUser
+// queries. The range of a query is chosen by the client.
+const (
+	cacheUsers   = 1000
+	cachePerUser = 20
+)
+
+// maxRange : the longest range stats are computed for
+const maxRange = 366 * 24 * time.Hour
+
+type Service interface {
+	GetStats(ctx context.Context, userId uint, query Query) (Stats, error)
+	HandleTodoEvent(ctx context.Context, event todos.Event)
+}
+
+type service struct {
+	logger     *zap.SugaredLogger
+	repository Repository
+	// mu : guards adding the cache of a user
+	mu    sync.Mutex
+	cache *lru.Cache[uint, *lru.Cache[Query, Stats]]
+	now   func() time.Time
+}
+
+func GetService(logger *zap.SugaredLogger, repo Repository) Service {
+	return &service{
+		logger:     logger,
+		repository: repo,
+		cache:      lru.New[uint, *lru.Cache[Query, Stats]](cacheUsers),
+		now:        time.Now,
+	}
+}
+
+func (s *service) GetStats(ctx context.Context, userId uint, query Query) (Stats, error) {
+	if query.GroupBy == "" {
+		query.GroupBy = GroupByDay
+	}
+	if query.GroupBy != GroupByDay && query.GroupBy != GroupByWeek {
+		return Stats{}, errors.New(locale.ErrorInvalidStatsQuery)
+	}
+	if !query.To.After(query.From) || query.To.Sub(query.From) > maxRange {
+		return Stats{}, errors.New(locale.ErrorInvalidStatsQuery)
+	}
+
+	if userCache, ok := s.cache.Get(userId); ok {
+		if stats, ok := userCache.Get(query); ok {
+			return stats, nil
+		}
+	}
+
+	stats, err := s.compute(ctx, userId, query)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	s.userCache(userId).Add(query, stats, cacheTTL)
+
+	return stats, nil
+}
+
+// HandleTodoEvent : drops the cached stats of the user whose todo item changed
+func (s *service) HandleTodoEvent(_ context.Context, event todos.Event) {
+	s.cache.Remove(event.UserId)
+}
+
+// userCache : the cached stats of the user, kept as long as its newest entry
+func (s *service) userCache(userId uint) *lru.Cache[Query, Stats] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	userCache, ok := s.cache.Get(userId)
+	if !ok {
+		userCache = lru.New[Query, Stats](cachePerUser)
+	}
+	s.cache.Add(userId, userCache, cacheTTL)
+
+	return userCache
+}
+
+func (s *service) compute(ctx context.Context, userId uint, query Query) (Stats, error) {
+	summary, err := s.repository.GetSummary(ctx, userId, query)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	created, err := s.repository.GetCreatedPerPeriod(ctx, userId, query)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	completed, err := s.repository.GetCompletedPerPeriod(ctx, userId, query)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	days, err := s.repository.GetCompletionDays(ctx, userId)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	tags, err := s.repository.GetTagStats(ctx, userId)
+	if err != nil {
+		return Stats{}, err
+	}
+	if tags == nil {
+		tags = []TagStats{}
+	}
+
+	periods := []Period{}
+	for _, key := range periodKeys(query) {
+		periods = append(periods, Period{Period: key, Created: created[key], Completed: completed[key]})
+	}
+
+	return Stats{
+		From:    query.From,
+		To:      query.To,
+		GroupBy: query.GroupBy,
+		Summary: summary,
+		Periods: periods,
+		Streaks: streaks(days, s.now().UTC()),
+		Tags:    tags,
+	}, nil
+}
+
+// periodKeys : every day or ISO week touched by the query range, formatted like the
+// repository groups them, so that periods without activity are reported as zero
+func periodKeys(query Query) []string {
+	var keys []string
+	seen := map[string]bool{}
+	for day := query.From.UTC(); day.Before(query.To); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		if query.GroupBy == GroupByWeek {
+			year, week := day.ISOWeek()
+			key = fmt.Sprintf("%d-W%02d", year, week)
+		}
+		if !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+
+	return keys
+}
+
+// streaks : computes the streaks from ascending completion days. A streak that ended
+// yesterday is still current, since the user may complete something today.
+func streaks(days []string, today time.Time) Streaks {
+	var result Streaks
+	var previous time.Time
+	run := 0
+	for _, value := range days {
+		day, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			continue
+		}
+
+		if run > 0 && day.Equal(previous.AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		previous = day
+
+		if run > result.Longest {
+			result.Longest = run
+		}
+	}
+
+	todayDate := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	if run > 0 && !previous.Before(todayDate.AddDate(0, 0, -1)) {
+		result.Current = run
+	}
+
+	return result
+}
@@ -0,0 +1,140 @@
+package stats
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-app/internal/todos"
+	"todo-app/pkg/locale"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestService_GetStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockRepo)
+	ctx := context.Background()
+
+	from := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	query := Query{From: from, To: from.AddDate(0, 0, 3), GroupBy: GroupByDay}
+
+	expectCompute := func(userId uint) {
+		mockRepo.EXPECT().GetSummary(ctx, userId, query).Return(Summary{Created: 3, Completed: 2, Overdue: 1}, nil).Times(1)
+		mockRepo.EXPECT().GetCreatedPerPeriod(ctx, userId, query).Return(map[string]int64{"2025-03-01": 3}, nil).Times(1)
+		mockRepo.EXPECT().GetCompletedPerPeriod(ctx, userId, query).Return(map[string]int64{"2025-03-03": 2}, nil).Times(1)
+		mockRepo.EXPECT().GetCompletionDays(ctx, userId).Return([]string{"2025-03-03"}, nil).Times(1)
+		mockRepo.EXPECT().GetTagStats(ctx, userId).Return([]TagStats{{Tag: "work", Total: 2, Completed: 1}}, nil).Times(1)
+	}
+
+	t.Run("fills periods without activity", func(t *testing.T) {
+		expectCompute(1)
+
+		stats, err := service.GetStats(ctx, 1, Query{From: query.From, To: query.To})
+		assert.NoError(t, err)
+		assert.Equal(t, GroupByDay, stats.GroupBy)
+		assert.Equal(t, []Period{
+			{Period: "2025-03-01", Created: 3},
+			{Period: "2025-03-02"},
+			{Period: "2025-03-03", Completed: 2},
+		}, stats.Periods)
+		assert.Equal(t, int64(1), stats.Summary.Overdue)
+
+		ctrl.Finish()
+	})
+
+	t.Run("served from cache until a todo changes", func(t *testing.T) {
+		_, err := service.GetStats(ctx, 1, query)
+		assert.NoError(t, err)
+
+		service.HandleTodoEvent(ctx, todos.Event{Type: todos.EventUpdated, UserId: 2})
+		_, err = service.GetStats(ctx, 1, query)
+		assert.NoError(t, err)
+
+		service.HandleTodoEvent(ctx, todos.Event{Type: todos.EventUpdated, UserId: 1})
+		expectCompute(1)
+		_, err = service.GetStats(ctx, 1, query)
+		assert.NoError(t, err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("queries beyond the cap of a user evict the least recently used", func(t *testing.T) {
+		computes := cachePerUser + 2
+		mockRepo.EXPECT().GetSummary(ctx, uint(4), gomock.Any()).Return(Summary{}, nil).Times(computes)
+		mockRepo.EXPECT().GetCreatedPerPeriod(ctx, uint(4), gomock.Any()).Return(map[string]int64{}, nil).Times(computes)
+		mockRepo.EXPECT().GetCompletedPerPeriod(ctx, uint(4), gomock.Any()).Return(map[string]int64{}, nil).Times(computes)
+		mockRepo.EXPECT().GetCompletionDays(ctx, uint(4)).Return(nil, nil).Times(computes)
+		mockRepo.EXPECT().GetTagStats(ctx, uint(4)).Return(nil, nil).Times(computes)
+
+		for i := 0; i <= cachePerUser; i++ {
+			_, err := service.GetStats(ctx, 4, Query{From: from, To: from.AddDate(0, 0, i+1)})
+			assert.NoError(t, err)
+		}
+
+		// the newest query is still cached, the first one was evicted
+		_, err := service.GetStats(ctx, 4, Query{From: from, To: from.AddDate(0, 0, cachePerUser+1)})
+		assert.NoError(t, err)
+		_, err = service.GetStats(ctx, 4, Query{From: from, To: from.AddDate(0, 0, 1)})
+		assert.NoError(t, err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("repository error is not cached", func(t *testing.T) {
+		mockRepo.EXPECT().GetSummary(ctx, uint(3), query).Return(Summary{}, gorm.ErrInvalidDB).Times(1)
+
+		_, err := service.GetStats(ctx, 3, query)
+		assert.Equal(t, gorm.ErrInvalidDB, err)
+
+		ctrl.Finish()
+	})
+
+	t.Run("invalid group by", func(t *testing.T) {
+		_, err := service.GetStats(ctx, 1, Query{From: query.From, To: query.To, GroupBy: "month"})
+		assert.Error(t, err)
+		assert.Equal(t, locale.ErrorInvalidStatsQuery, err.Error())
+
+		ctrl.Finish()
+	})
+
+	t.Run("range too long", func(t *testing.T) {
+		_, err := service.GetStats(ctx, 1, Query{From: from.AddDate(-2, 0, 0), To: from})
+		assert.Error(t, err)
+
+		ctrl.Finish()
+	})
+}
+
+func TestStreaks(t *testing.T) {
+	today := time.Date(2025, time.March, 10, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		days     []string
+		expected Streaks
+	}{
+		{"no completions", nil, Streaks{}},
+		{"current streak ending today", []string{"2025-03-08", "2025-03-09", "2025-03-10"}, Streaks{Current: 3, Longest: 3}},
+		{"current streak ending yesterday", []string{"2025-03-08", "2025-03-09"}, Streaks{Current: 2, Longest: 2}},
+		{"broken streak", []string{"2025-03-01", "2025-03-02", "2025-03-03", "2025-03-07"}, Streaks{Current: 0, Longest: 3}},
+		{"streak across months", []string{"2025-02-28", "2025-03-01"}, Streaks{Current: 0, Longest: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, streaks(tt.days, today))
+		})
+	}
+}
+
+func TestPeriodKeys(t *testing.T) {
+	from := time.Date(2024, time.December, 28, 0, 0, 0, 0, time.UTC)
+	query := Query{From: from, To: from.AddDate(0, 0, 10), GroupBy: GroupByWeek}
+
+	assert.Equal(t, []string{"2024-W52", "2025-W01", "2025-W02"}, periodKeys(query))
+}
@@ -0,0 +1,304 @@
+package templates
+
+import (
+	"net/http"
+	"strings"
+	"todo-app/internal/auth"
+	"todo-app/pkg/authz"
+	e "todo-app/pkg/errors"
+	"todo-app/pkg/handlers"
+	"todo-app/pkg/locale"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type endpointHandler struct {
+	logger  *zap.SugaredLogger
+	service Service
+	e       *echo.Echo
+}
+
+func GetEndpointHandler(
+	logger *zap.SugaredLogger,
+	service Service,
+	e *echo.Echo,
+) handlers.EndpointHandler {
+	return &endpointHandler{
+		logger:  logger,
+		service: service,
+		e:       e,
+	}
+}
+
+func (h *endpointHandler) AddEndpoints() {
+	var endpoints = []handlers.Endpoint{
+		{
+			Method:  http.MethodGet,
+			Path:    "/templates",
+			Handler: h.getAll,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/templates",
+			Handler: h.create,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/templates/from-todos",
+			Handler: h.createFromTodos,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/templates/:id",
+			Handler: h.getById,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/templates/:id",
+			Handler: h.deleteById,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/templates/:id/instantiate",
+			Handler: h.instantiate,
+		},
+	}
+
+	for _, endpoint := range endpoints {
+		handlers.Method(h.e, endpoint.Method, endpoint.Path, endpoint.Handler)
+	}
+}
+
+// @Summary Get all templates
+// @Description This endpoint returns all todo templates of the current user
+// @Tags templates
+// @ID getAllTemplates
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} Template
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /templates [get]
+func (h *endpointHandler) getAll(ctx echo.Context) error {
+	h.logger.Infow("reading templates...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	templates, err := h.service.GetAllForUser(ctx.Request().Context(), userId)
+	if err != nil {
+		h.logger.Warn("could not read templates", "error", err.Error())
+
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorCouldNotReadTemplate})
+	}
+
+	return ctx.JSON(http.StatusOK, templates)
+}
+
+// @Summary Create a template
+// @Description This endpoint creates a todo template from a tree of blueprints. Blueprint texts and tags may contain {{placeholders}}.
+// @Tags templates
+// @ID createTemplate
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param template body Template true "Template to create"
+// @Success 200 {object} Template
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Router /templates [post]
+func (h *endpointHandler) create(ctx echo.Context) error {
+	h.logger.Infow("creating template...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	template := Template{}
+	err := ctx.Bind(&template)
+	if err != nil {
+		h.logger.Warn("could not bind body to template struct", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+	}
+	template.ID = 0
+	template.UserId = userId
+
+	err = h.service.Create(ctx.Request().Context(), &template)
+	if err != nil {
+		h.logger.Warn("could not create template", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidTemplate, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, template)
+}
+
+// @Summary Save todos as a template
+// @Description This endpoint saves existing todo items as a template, keeping their hierarchy and relative due dates
+// @Tags templates
+// @ID createTemplateFromTodos
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body FromTodosInput true "Todo items to save"
+// @Success 200 {object} Template
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Router /templates/from-todos [post]
+func (h *endpointHandler) createFromTodos(ctx echo.Context) error {
+	h.logger.Infow("creating template from todos...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	input := FromTodosInput{}
+	err := ctx.Bind(&input)
+	if err != nil {
+		h.logger.Warn("could not bind body to from-todos struct", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+	}
+
+	template, err := h.service.CreateFromTodos(ctx.Request().Context(), userId, input)
+	if err != nil {
+		h.logger.Warn("could not create template from todos", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidTemplate, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, template)
+}
+
+// @Summary Get a template by ID
+// @Description This endpoint returns a todo template with the placeholders it uses
+// @Tags templates
+// @ID getTemplateById
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {object} Template
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /templates/{id} [get]
+func (h *endpointHandler) getById(ctx echo.Context) error {
+	template, ok, err := h.getOwnTemplate(ctx, authz.ActionRead)
+	if !ok {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, template)
+}
+
+// @Summary Delete a template by ID
+// @Description This endpoint deletes a todo template
+// @Tags templates
+// @ID deleteTemplateById
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {string} string ""
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /templates/{id} [delete]
+func (h *endpointHandler) deleteById(ctx echo.Context) error {
+	h.logger.Infow("deleting template...")
+
+	template, ok, err := h.getOwnTemplate(ctx, authz.ActionDelete)
+	if !ok {
+		return err
+	}
+
+	err = h.service.DeleteById(ctx.Request().Context(), template.ID)
+	if err != nil {
+		h.logger.Warn("could not delete template", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorCouldNotDelete, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, "")
+}
+
+// @Summary Instantiate a template
+// @Description This endpoint creates all todo items of a template in one transaction, filling in placeholders
+// @Description and setting due dates relative to start_at (now when omitted)
+// @Tags templates
+// @ID instantiateTemplate
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Template ID"
+// @Param input body InstantiateInput true "Placeholder values and start time"
+// @Success 200 {object} InstantiateResponse
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /templates/{id}/instantiate [post]
+func (h *endpointHandler) instantiate(ctx echo.Context) error {
+	h.logger.Infow("instantiating template...")
+
+	template, ok, err := h.getOwnTemplate(ctx, authz.ActionRead)
+	if !ok {
+		return err
+	}
+
+	input := InstantiateInput{}
+	err = ctx.Bind(&input)
+	if err != nil {
+		h.logger.Warn("could not bind body to instantiate struct", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+	}
+
+	items, err := h.service.Instantiate(ctx.Request().Context(), template, input)
+	if err != nil {
+		h.logger.Warn("could not instantiate template", "error", err.Error())
+
+		message := locale.ErrorInvalidTodoItem
+		if strings.HasPrefix(err.Error(), locale.ErrorMissingTemplateValues) {
+			message = locale.ErrorMissingTemplateValues
+		}
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: message, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, InstantiateResponse{Items: items})
+}
+
+// getOwnTemplate : loads the template from the :id param and makes sure it belongs to
+// the current user. When ok is false the error response has already been written.
+func (h *endpointHandler) getOwnTemplate(ctx echo.Context, action authz.Action) (Template, bool, error) {
+	userId := auth.GetUserIdFromContext(ctx)
+
+	id, err := handlers.GetUrlId(ctx, h.logger)
+	if err != nil {
+		h.logger.Warn("could not get id from url", "error", err.Error())
+
+		return Template{}, false, ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidID})
+	}
+
+	template, err := h.service.GetById(ctx.Request().Context(), id)
+	if err != nil {
+		if authz.Missing(err) {
+			return Template{}, false, authz.Deny(ctx)
+		}
+		h.logger.Error("could not get template", "error", err.Error())
+
+		return Template{}, false, ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorCouldNotReadTemplate})
+	}
+	if err := authz.Authorize(Policy, userId, action, template); err != nil {
+		h.logger.Info("user tried to access template of other user")
+
+		return Template{}, false, authz.Deny(ctx)
+	}
+
+	return template, true, nil
+}
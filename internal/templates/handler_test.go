@@ -0,0 +1,202 @@
+package templates
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"todo-app/internal/todos"
+	"todo-app/pkg/locale"
+
+	localErr "todo-app/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestHandler_Create(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	body := `{"name":"Onboarding","items":[{"text":"Welcome {{name}}","children":[{"text":"Laptop"}]}]}`
+	template := Template{
+		UserId: 1,
+		Name:   "Onboarding",
+		Items:  []Blueprint{{Text: "Welcome {{name}}", Children: []Blueprint{{Text: "Laptop"}}}},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/templates", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.Set("user_id", uint(1))
+
+	mockService.
+		EXPECT().
+		Create(ctx.Request().Context(), &template).
+		Return(nil).
+		Times(1)
+
+	if assert.NoError(t, h.create(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response Template
+		err := json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NoError(t, err)
+
+		assert.Equal(t, template.Items, response.Items)
+	}
+}
+
+func TestHandler_Instantiate(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	template := Template{
+		Model:  gorm.Model{ID: 5},
+		UserId: 1,
+		Name:   "Onboarding",
+		Items:  []Blueprint{{Text: "Welcome {{name}}"}},
+	}
+
+	newContext := func(body string, userId uint) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/templates/5/instantiate", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", userId)
+		ctx.SetPath("/templates/:id/instantiate")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues("5")
+
+		return ctx, rec
+	}
+
+	t.Run("instantiate template", func(t *testing.T) {
+		ctx, rec := newContext(`{"values":{"name":"Ada"}}`, 1)
+		items := []todos.ToDoItem{{Text: "Welcome Ada", UserId: 1}}
+
+		mockService.EXPECT().GetById(ctx.Request().Context(), uint(5)).Return(template, nil).Times(1)
+		mockService.
+			EXPECT().
+			Instantiate(ctx.Request().Context(), template, InstantiateInput{Values: map[string]string{"name": "Ada"}}).
+			Return(items, nil).
+			Times(1)
+
+		if assert.NoError(t, h.instantiate(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var response InstantiateResponse
+			err := json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			assert.Equal(t, "Welcome Ada", response.Items[0].Text)
+		}
+	})
+
+	t.Run("missing values", func(t *testing.T) {
+		ctx, rec := newContext(`{}`, 1)
+
+		mockService.EXPECT().GetById(ctx.Request().Context(), uint(5)).Return(template, nil).Times(1)
+		mockService.
+			EXPECT().
+			Instantiate(ctx.Request().Context(), template, InstantiateInput{}).
+			Return(nil, errors.New(locale.ErrorMissingTemplateValues+": name")).
+			Times(1)
+
+		if assert.NoError(t, h.instantiate(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var responseError localErr.ResponseError
+			err := json.Unmarshal(rec.Body.Bytes(), &responseError)
+			assert.NoError(t, err)
+
+			assert.Equal(t, locale.ErrorMissingTemplateValues, responseError.Message)
+		}
+	})
+
+	t.Run("template of other user", func(t *testing.T) {
+		ctx, rec := newContext(`{}`, 2)
+
+		mockService.EXPECT().GetById(ctx.Request().Context(), uint(5)).Return(template, nil).Times(1)
+
+		if assert.NoError(t, h.instantiate(ctx)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+		}
+	})
+}
+
+func TestHandler_CrossUserAccess(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	// template 5 belongs to user 2, user 1 tries to reach it
+	template := Template{Model: gorm.Model{ID: 5}, UserId: 2, Name: "Onboarding"}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		id      string
+		body    string
+		handler echo.HandlerFunc
+	}{
+		{"read template", http.MethodGet, "/templates/:id", "5", "", h.getById},
+		{"delete template", http.MethodDelete, "/templates/:id", "5", "", h.deleteById},
+		{"instantiate template", http.MethodPost, "/templates/:id/instantiate", "5", `{}`, h.instantiate},
+		// ids that do not exist answer the same
+		{"read nonexistent template", http.MethodGet, "/templates/:id", "404", "", h.getById},
+		{"delete nonexistent template", http.MethodDelete, "/templates/:id", "404", "", h.deleteById},
+	}
+	var denied string
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, "/", strings.NewReader(test.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(req, rec)
+			ctx.Set("user_id", uint(1))
+			ctx.SetPath(test.path)
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(test.id)
+
+			// only the lookup happens, nothing is changed
+			mockService.EXPECT().GetById(gomock.Any(), template.ID).Return(template, nil).AnyTimes()
+			mockService.EXPECT().GetById(gomock.Any(), uint(404)).Return(Template{}, gorm.ErrRecordNotFound).AnyTimes()
+
+			if assert.NoError(t, test.handler(ctx)) {
+				assert.Equal(t, http.StatusNotFound, rec.Code)
+
+				var responseError localErr.ResponseError
+				err := json.Unmarshal(rec.Body.Bytes(), &responseError)
+				assert.NoError(t, err)
+				assert.Equal(t, locale.ErrorNotFoundRecord, responseError.Message)
+
+				if denied == "" {
+					denied = rec.Body.String()
+				}
+				assert.Equal(t, denied, rec.Body.String())
+			}
+		})
+	}
+
+	ctrl.Finish()
+}
@@ -0,0 +1,130 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/templates/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/templates/service.go -destination=internal/templates/mock_service.go -package=templates
+//
+
+// Package templates is a generated GoMock package.
+package templates
+
+import (
+	context "context"
+	reflect "reflect"
+	todos "todo-app/internal/todos"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockService) Create(ctx context.Context, template *Template) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, template)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockServiceMockRecorder) Create(ctx, template any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockService)(nil).Create), ctx, template)
+}
+
+// CreateFromTodos mocks base method.
+func (m *MockService) CreateFromTodos(ctx context.Context, userId uint, input FromTodosInput) (Template, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFromTodos", ctx, userId, input)
+	ret0, _ := ret[0].(Template)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFromTodos indicates an expected call of CreateFromTodos.
+func (mr *MockServiceMockRecorder) CreateFromTodos(ctx, userId, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFromTodos", reflect.TypeOf((*MockService)(nil).CreateFromTodos), ctx, userId, input)
+}
+
+// DeleteById mocks base method.
+func (m *MockService) DeleteById(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteById", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteById indicates an expected call of DeleteById.
+func (mr *MockServiceMockRecorder) DeleteById(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteById", reflect.TypeOf((*MockService)(nil).DeleteById), ctx, id)
+}
+
+// GetAllForUser mocks base method.
+func (m *MockService) GetAllForUser(ctx context.Context, userId uint) ([]Template, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllForUser", ctx, userId)
+	ret0, _ := ret[0].([]Template)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllForUser indicates an expected call of GetAllForUser.
+func (mr *MockServiceMockRecorder) GetAllForUser(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllForUser", reflect.TypeOf((*MockService)(nil).GetAllForUser), ctx, userId)
+}
+
+// GetById mocks base method.
+func (m *MockService) GetById(ctx context.Context, id uint) (Template, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", ctx, id)
+	ret0, _ := ret[0].(Template)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockServiceMockRecorder) GetById(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockService)(nil).GetById), ctx, id)
+}
+
+// Instantiate mocks base method.
+func (m *MockService) Instantiate(ctx context.Context, template Template, input InstantiateInput) ([]todos.ToDoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Instantiate", ctx, template, input)
+	ret0, _ := ret[0].([]todos.ToDoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Instantiate indicates an expected call of Instantiate.
+func (mr *MockServiceMockRecorder) Instantiate(ctx, template, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Instantiate", reflect.TypeOf((*MockService)(nil).Instantiate), ctx, template, input)
+}
@@ -0,0 +1,43 @@
+package templates
+
+import (
+	"time"
+	"todo-app/internal/todos"
+
+	"gorm.io/gorm"
+)
+
+type Template struct {
+	gorm.Model
+	UserId       uint   `gorm:"not null;index"`
+	Name         string `gorm:"not null" validate:"required"`
+	Description  string
+	Items        []Blueprint `gorm:"type:json;serializer:json" validate:"required,min=1,dive"`
+	Placeholders []string    `gorm:"-"`
+}
+
+// Blueprint : a todo created when the template is instantiated. Text may contain
+// {{placeholders}}; the due date is the instantiation start plus DueOffsetMinutes.
+type Blueprint struct {
+	Text             string      `json:"text" validate:"required"`
+	DueOffsetMinutes *int        `json:"due_offset_minutes,omitempty"`
+	Priority         string      `json:"priority,omitempty" validate:"omitempty,oneof=low medium high"`
+	Recurrence       string      `json:"recurrence,omitempty"`
+	Tags             []string    `json:"tags,omitempty"`
+	Children         []Blueprint `json:"children,omitempty" validate:"dive"`
+}
+
+type InstantiateInput struct {
+	Values  map[string]string `json:"values"`
+	StartAt *time.Time        `json:"start_at"`
+}
+
+type InstantiateResponse struct {
+	Items []todos.ToDoItem `json:"items"`
+}
+
+type FromTodosInput struct {
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description"`
+	TodoIds     []uint `json:"todo_ids" validate:"required,min=1"`
+}
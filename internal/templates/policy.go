@@ -0,0 +1,8 @@
+package templates
+
+import "todo-app/pkg/authz"
+
+// Policy : templates are only read, used and deleted by their owner
+func Policy(userId uint, _ authz.Action, template Template) bool {
+	return authz.Owns(userId, template.UserId)
+}
@@ -0,0 +1,73 @@
+package templates
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(ctx context.Context, template *Template) error
+	GetAllForUser(ctx context.Context, userId uint) ([]Template, error)
+	GetById(ctx context.Context, id uint) (Template, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+type repository struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func GetRepository(logger *zap.SugaredLogger, db *gorm.DB) Repository {
+	return &repository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (r *repository) Create(ctx context.Context, template *Template) error {
+	result := r.db.WithContext(ctx).Create(template)
+	if result.Error != nil {
+		r.logger.Errorw("failed to create template", "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) GetAllForUser(ctx context.Context, userId uint) ([]Template, error) {
+	var templates []Template
+	result := r.db.WithContext(ctx).Where("user_id = ?", userId).Order("name").Find(&templates)
+	if result.Error != nil {
+		r.logger.Errorw("failed to find templates for user", "user_id", userId, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return templates, nil
+}
+
+func (r *repository) GetById(ctx context.Context, id uint) (Template, error) {
+	var template Template
+	result := r.db.WithContext(ctx).First(&template, id)
+	if result.Error != nil {
+		r.logger.Errorw("failed to find template by id", "id", id, "error", result.Error)
+
+		return Template{}, result.Error
+	}
+
+	return template, nil
+}
+
+func (r *repository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&Template{}, id)
+	if result.Error != nil {
+		r.logger.Errorw("failed to delete template", "id", id, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
@@ -0,0 +1,284 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"todo-app/internal/todos"
+	"todo-app/pkg/authz"
+	"todo-app/pkg/database"
+	"todo-app/pkg/locale"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+type Service interface {
+	Create(ctx context.Context, template *Template) error
+	GetAllForUser(ctx context.Context, userId uint) ([]Template, error)
+	GetById(ctx context.Context, id uint) (Template, error)
+	DeleteById(ctx context.Context, id uint) error
+	Instantiate(ctx context.Context, template Template, input InstantiateInput) ([]todos.ToDoItem, error)
+	CreateFromTodos(ctx context.Context, userId uint, input FromTodosInput) (Template, error)
+}
+
+type service struct {
+	logger      *zap.SugaredLogger
+	repository  Repository
+	todoService todos.Service
+	transactor  database.Transactor
+	validator   *validator.Validate
+}
+
+func GetService(
+	logger *zap.SugaredLogger,
+	repo Repository,
+	todoService todos.Service,
+	transactor database.Transactor,
+	validator *validator.Validate,
+) Service {
+	return &service{
+		logger:      logger,
+		repository:  repo,
+		todoService: todoService,
+		transactor:  transactor,
+		validator:   validator,
+	}
+}
+
+func (s *service) Create(ctx context.Context, template *Template) error {
+	if err := s.validator.Struct(template); err != nil {
+		return err
+	}
+
+	err := s.repository.Create(ctx, template)
+	if err != nil {
+		return err
+	}
+	template.Placeholders = placeholders(template.Items)
+
+	return nil
+}
+
+func (s *service) GetAllForUser(ctx context.Context, userId uint) ([]Template, error) {
+	templates, err := s.repository.GetAllForUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range templates {
+		templates[i].Placeholders = placeholders(templates[i].Items)
+	}
+
+	return templates, nil
+}
+
+func (s *service) GetById(ctx context.Context, id uint) (Template, error) {
+	template, err := s.repository.GetById(ctx, id)
+	if err != nil {
+		return Template{}, err
+	}
+	template.Placeholders = placeholders(template.Items)
+
+	return template, nil
+}
+
+func (s *service) DeleteById(ctx context.Context, id uint) error {
+	return s.repository.Delete(ctx, id)
+}
+
+// Instantiate : creates a todo for every blueprint of the template, all or nothing.
+// Due dates are offset from input.StartAt, or from now when it is not given.
+func (s *service) Instantiate(ctx context.Context, template Template, input InstantiateInput) ([]todos.ToDoItem, error) {
+	var missing []string
+	for _, name := range placeholders(template.Items) {
+		if _, ok := input.Values[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%s: %s", locale.ErrorMissingTemplateValues, strings.Join(missing, ", "))
+	}
+
+	start := time.Now()
+	if input.StartAt != nil {
+		start = *input.StartAt
+	}
+
+	var created []todos.ToDoItem
+	err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		created = nil
+
+		return s.instantiateItems(ctx, template.UserId, template.Items, nil, start, input.Values, &created)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (s *service) instantiateItems(
+	ctx context.Context,
+	userId uint,
+	blueprints []Blueprint,
+	parentId *uint,
+	start time.Time,
+	values map[string]string,
+	created *[]todos.ToDoItem,
+) error {
+	for _, blueprint := range blueprints {
+		item := todos.ToDoItem{
+			Text:       fill(blueprint.Text, values),
+			UserId:     userId,
+			ParentId:   parentId,
+			Priority:   blueprint.Priority,
+			Recurrence: blueprint.Recurrence,
+		}
+		if blueprint.DueOffsetMinutes != nil {
+			due := start.Add(time.Duration(*blueprint.DueOffsetMinutes) * time.Minute).UTC()
+			item.DueAt = &due
+		}
+		for _, tag := range blueprint.Tags {
+			item.Tags = append(item.Tags, todos.Tag{Name: fill(tag, values)})
+		}
+
+		if err := s.todoService.Create(ctx, &item); err != nil {
+			return err
+		}
+		*created = append(*created, item)
+
+		if len(blueprint.Children) > 0 {
+			id := item.ID
+			if err := s.instantiateItems(ctx, userId, blueprint.Children, &id, start, values, created); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// CreateFromTodos : saves the given todos as a template. Parent/child relations
+// between the selected todos are kept and due dates become offsets from the
+// earliest due date among them.
+func (s *service) CreateFromTodos(ctx context.Context, userId uint, input FromTodosInput) (Template, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return Template{}, err
+	}
+
+	items := make([]todos.ToDoItem, 0, len(input.TodoIds))
+	selected := map[uint]bool{}
+	for _, id := range input.TodoIds {
+		if selected[id] {
+			continue
+		}
+
+		item, err := s.todoService.GetById(ctx, id)
+		if err != nil {
+			return Template{}, errors.New(locale.ErrorNotFoundRecord)
+		}
+		if err := authz.Authorize(todos.ItemPolicy, userId, authz.ActionRead, item); err != nil {
+			return Template{}, err
+		}
+		items = append(items, item)
+		selected[id] = true
+	}
+
+	var anchor *time.Time
+	for _, item := range items {
+		if item.DueAt != nil && (anchor == nil || item.DueAt.Before(*anchor)) {
+			anchor = item.DueAt
+		}
+	}
+
+	children := map[uint][]todos.ToDoItem{}
+	var roots []todos.ToDoItem
+	for _, item := range items {
+		if item.ParentId != nil && selected[*item.ParentId] {
+			children[*item.ParentId] = append(children[*item.ParentId], item)
+			continue
+		}
+		roots = append(roots, item)
+	}
+
+	var build func(items []todos.ToDoItem) []Blueprint
+	build = func(items []todos.ToDoItem) []Blueprint {
+		blueprints := make([]Blueprint, 0, len(items))
+		for _, item := range items {
+			blueprint := Blueprint{
+				Text:       item.Text,
+				Priority:   item.Priority,
+				Recurrence: item.Recurrence,
+				Children:   build(children[item.ID]),
+			}
+			if item.DueAt != nil && anchor != nil {
+				offset := int(item.DueAt.Sub(*anchor).Minutes())
+				blueprint.DueOffsetMinutes = &offset
+			}
+			for _, tag := range item.Tags {
+				blueprint.Tags = append(blueprint.Tags, tag.Name)
+			}
+			if len(blueprint.Children) == 0 {
+				blueprint.Children = nil
+			}
+			blueprints = append(blueprints, blueprint)
+		}
+
+		return blueprints
+	}
+
+	template := Template{
+		UserId:      userId,
+		Name:        input.Name,
+		Description: input.Description,
+		Items:       build(roots),
+	}
+
+	err := s.Create(ctx, &template)
+	if err != nil {
+		return Template{}, err
+	}
+
+	return template, nil
+}
+
+// placeholders : the distinct placeholder names used anywhere in the blueprints, sorted
+func placeholders(blueprints []Blueprint) []string {
+	seen := map[string]bool{}
+	var walk func(blueprints []Blueprint)
+	walk = func(blueprints []Blueprint) {
+		for _, blueprint := range blueprints {
+			texts := append([]string{blueprint.Text}, blueprint.Tags...)
+			for _, text := range texts {
+				for _, m := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+					seen[m[1]] = true
+				}
+			}
+			walk(blueprint.Children)
+		}
+	}
+	walk(blueprints)
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func fill(text string, values map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+
+		return values[name]
+	})
+}
@@ -0,0 +1,202 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo-app/internal/todos"
+	"todo-app/pkg/database"
+	"todo-app/pkg/locale"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestService_Instantiate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	mockTodoService := todos.NewMockService(ctrl)
+	mockTransactor := database.NewMockTransactor(ctrl)
+	v := validator.New()
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockRepo, mockTodoService, mockTransactor, v)
+	ctx := context.Background()
+
+	offset := 60
+	template := Template{
+		UserId: 3,
+		Items: []Blueprint{
+			{
+				Text:             "Onboard {{name}}",
+				DueOffsetMinutes: &offset,
+				Tags:             []string{"onboarding"},
+				Children: []Blueprint{
+					{Text: "Create account for {{ name }}"},
+				},
+			},
+		},
+	}
+	start := time.Date(2025, time.March, 3, 9, 0, 0, 0, time.UTC)
+
+	t.Run("creates the tree in a transaction", func(t *testing.T) {
+		mockTransactor.
+			EXPECT().
+			WithTransaction(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+				return fn(ctx)
+			}).
+			Times(1)
+
+		nextId := uint(10)
+		mockTodoService.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, item *todos.ToDoItem) error {
+				item.ID = nextId
+				nextId++
+				return nil
+			}).
+			Times(2)
+
+		items, err := service.Instantiate(ctx, template, InstantiateInput{Values: map[string]string{"name": "Ada"}, StartAt: &start})
+		assert.NoError(t, err)
+		assert.Len(t, items, 2)
+
+		assert.Equal(t, "Onboard Ada", items[0].Text)
+		assert.Equal(t, uint(3), items[0].UserId)
+		assert.Nil(t, items[0].ParentId)
+		assert.Equal(t, start.Add(time.Hour), *items[0].DueAt)
+		assert.Equal(t, []todos.Tag{{Name: "onboarding"}}, items[0].Tags)
+
+		assert.Equal(t, "Create account for Ada", items[1].Text)
+		assert.Equal(t, uint(10), *items[1].ParentId)
+		assert.Nil(t, items[1].DueAt)
+
+		ctrl.Finish()
+	})
+
+	t.Run("missing placeholder values", func(t *testing.T) {
+		_, err := service.Instantiate(ctx, template, InstantiateInput{})
+		assert.Error(t, err)
+		assert.Equal(t, locale.ErrorMissingTemplateValues+": name", err.Error())
+
+		ctrl.Finish()
+	})
+
+	t.Run("create fails", func(t *testing.T) {
+		mockTransactor.
+			EXPECT().
+			WithTransaction(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+				return fn(ctx)
+			}).
+			Times(1)
+
+		mockTodoService.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			Return(gorm.ErrInvalidDB).
+			Times(1)
+
+		items, err := service.Instantiate(ctx, template, InstantiateInput{Values: map[string]string{"name": "Ada"}})
+		assert.Error(t, err)
+		assert.Nil(t, items)
+
+		ctrl.Finish()
+	})
+}
+
+func TestService_CreateFromTodos(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	mockTodoService := todos.NewMockService(ctrl)
+	mockTransactor := database.NewMockTransactor(ctrl)
+	v := validator.New()
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockRepo, mockTodoService, mockTransactor, v)
+	ctx := context.Background()
+
+	due := time.Date(2025, time.March, 3, 9, 0, 0, 0, time.UTC)
+	later := due.Add(2 * time.Hour)
+	parentId := uint(1)
+	parent := todos.ToDoItem{Model: gorm.Model{ID: 1}, Text: "Release", UserId: 3, DueAt: &due}
+	child := todos.ToDoItem{Model: gorm.Model{ID: 2}, Text: "Tag build", UserId: 3, ParentId: &parentId, DueAt: &later, Tags: []todos.Tag{{Name: "ops"}}}
+
+	t.Run("keeps hierarchy and offsets", func(t *testing.T) {
+		mockTodoService.EXPECT().GetById(ctx, uint(1)).Return(parent, nil).Times(1)
+		mockTodoService.EXPECT().GetById(ctx, uint(2)).Return(child, nil).Times(1)
+		mockRepo.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		template, err := service.CreateFromTodos(ctx, 3, FromTodosInput{Name: "Release checklist", TodoIds: []uint{1, 2}})
+		assert.NoError(t, err)
+
+		zero, twoHours := 0, 120
+		assert.Equal(t, []Blueprint{
+			{
+				Text:             "Release",
+				DueOffsetMinutes: &zero,
+				Children: []Blueprint{
+					{Text: "Tag build", DueOffsetMinutes: &twoHours, Tags: []string{"ops"}},
+				},
+			},
+		}, template.Items)
+		assert.Equal(t, uint(3), template.UserId)
+
+		ctrl.Finish()
+	})
+
+	t.Run("todo of other user", func(t *testing.T) {
+		mockTodoService.EXPECT().GetById(ctx, uint(1)).Return(todos.ToDoItem{UserId: 4}, nil).Times(1)
+
+		_, err := service.CreateFromTodos(ctx, 3, FromTodosInput{Name: "Release checklist", TodoIds: []uint{1}})
+		assert.Error(t, err)
+		assert.Equal(t, errors.New(locale.ErrorNotFoundRecord), err)
+
+		ctrl.Finish()
+	})
+}
+
+func TestService_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	mockTodoService := todos.NewMockService(ctrl)
+	mockTransactor := database.NewMockTransactor(ctrl)
+	v := validator.New()
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockRepo, mockTodoService, mockTransactor, v)
+	ctx := context.Background()
+
+	t.Run("successful creation", func(t *testing.T) {
+		template := &Template{
+			Name:  "Onboarding",
+			Items: []Blueprint{{Text: "Welcome {{name}}", Tags: []string{"{{team}}"}}},
+		}
+		mockRepo.EXPECT().Create(ctx, template).Return(nil).Times(1)
+
+		err := service.Create(ctx, template)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"name", "team"}, template.Placeholders)
+
+		ctrl.Finish()
+	})
+
+	t.Run("nested blueprint without text", func(t *testing.T) {
+		template := &Template{
+			Name:  "Onboarding",
+			Items: []Blueprint{{Text: "Welcome", Children: []Blueprint{{Text: ""}}}},
+		}
+
+		err := service.Create(ctx, template)
+		assert.Error(t, err)
+
+		ctrl.Finish()
+	})
+}
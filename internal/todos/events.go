@@ -0,0 +1,53 @@
+package todos
+
+import "context"
+
+const (
+	EventCreated = "created"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+)
+
+// Event : a change to a todo item. Item holds the state after the change, or the last
+// state for deleted items.
+type Event struct {
+	Type   string
+	UserId uint
+	Item   ToDoItem
+}
+
+// Listener : called synchronously once the write of a todo item is committed, not at
+// all when it is rolled back. Listeners that do slow work should hand it off to a
+// goroutine.
+type Listener func(ctx context.Context, event Event)
+
+// DeleteHook : called inside the transaction that deletes a todo item, an error rolls the
+// deletion back. Packages storing rows of their own per item delete them here.
+type DeleteHook func(ctx context.Context, item ToDoItem) error
+
+func (s *service) Subscribe(listener Listener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.listeners = append(s.listeners, listener)
+}
+
+func (s *service) OnDelete(hook DeleteHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteHooks = append(s.deleteHooks, hook)
+}
+
+func (s *service) publish(ctx context.Context, eventType string, item ToDoItem) {
+	s.mu.RLock()
+	listeners := s.listeners
+	s.mu.RUnlock()
+
+	event := Event{Type: eventType, UserId: item.UserId, Item: item}
+	s.transactor.AfterCommit(ctx, func(ctx context.Context) {
+		for _, listener := range listeners {
+			listener(ctx, event)
+		}
+	})
+}
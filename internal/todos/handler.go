@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 	"time"
+	"todo-app/pkg/authz"
 	e "todo-app/pkg/errors"
 	"todo-app/pkg/handlers"
 	"todo-app/pkg/locale"
@@ -49,6 +50,11 @@ func (h *endpointHandler) AddEndpoints() {
 			Path:    "/todos",
 			Handler: h.create,
 		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/todos/quick",
+			Handler: h.quickAdd,
+		},
 		{
 			Method:  http.MethodPut,
 			Path:    "/todos/:id",
@@ -59,6 +65,41 @@ func (h *endpointHandler) AddEndpoints() {
 			Path:    "/todos/:id",
 			Handler: h.deleteById,
 		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/todos/:id/timer/start",
+			Handler: h.startTimer,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/todos/:id/timer/stop",
+			Handler: h.stopTimer,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/timer",
+			Handler: h.getRunningTimer,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/todos/:id/time-entries",
+			Handler: h.getTimeEntries,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/todos/:id/time-entries",
+			Handler: h.addTimeEntry,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/time-entries/:id",
+			Handler: h.deleteTimeEntry,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/reports/time",
+			Handler: h.getTimeReport,
+		},
 	}
 
 	for _, endpoint := range endpoints {
@@ -156,6 +197,54 @@ func (h *endpointHandler) create(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, item)
 }
 
+// @Summary Quick-add a todo item from text
+// @Description This endpoint parses a sentence such as "Pay rent every month on the 1st #finance !high tomorrow 9am"
+// @Description into a todo item, recognizing dates, times, recurrence, tags and priority in the given timezone and locale.
+// @Description With dry_run the parsed item is returned without being saved.
+// @Tags todos
+// @ID quickAdd
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body QuickAddInput true "Quick-add text"
+// @Param dry_run query bool false "Only parse the text, do not create the item"
+// @Success 200 {object} QuickAddResponse
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Router /todos/quick [post]
+func (h *endpointHandler) quickAdd(ctx echo.Context) error {
+	h.logger.Infow("quick-adding todo item...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	input := QuickAddInput{}
+	err := ctx.Bind(&input)
+	if err != nil {
+		h.logger.Warn("could not bind body to quick-add struct", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+	}
+
+	if dryRun := ctx.QueryParam("dry_run"); dryRun != "" {
+		input.DryRun, err = strconv.ParseBool(dryRun)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+		}
+	}
+
+	response, err := h.service.QuickAdd(ctx.Request().Context(), userId, input)
+	if err != nil {
+		h.logger.Warn("could not quick-add todo-item", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidTodoItem, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
 // @Summary Update a todo item by ID
 // @Description This endpoint updates a todo item by its ID
 // @Tags todos
@@ -168,6 +257,7 @@ func (h *endpointHandler) create(ctx echo.Context) error {
 // @Success 200 {object} ToDoItem
 // @Failure 400 {object} errors.ResponseError "Bad Request"
 // @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
 // @Router /todos/{id} [put]
 func (h *endpointHandler) updateById(ctx echo.Context) error {
 	h.logger.Infow("updating todo item...")
@@ -182,14 +272,17 @@ func (h *endpointHandler) updateById(ctx echo.Context) error {
 
 	item, err := h.service.GetById(ctx.Request().Context(), id)
 	if err != nil {
+		if authz.Missing(err) {
+			return authz.Deny(ctx)
+		}
 		h.logger.Error("could not get item", "error", err.Error())
 
 		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorCouldNotReadTodoItem})
 	}
-	if item.UserId != userId {
+	if err := authz.Authorize(ItemPolicy, userId, authz.ActionUpdate, item); err != nil {
 		h.logger.Info("user tried to modify todo of other user")
 
-		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: locale.ErrorNotFoundRecord})
+		return authz.Deny(ctx)
 	}
 
 	itemInput := ToDoItemUpdateInput{}
@@ -220,6 +313,7 @@ func (h *endpointHandler) updateById(ctx echo.Context) error {
 // @Success 200 {string} string ""
 // @Failure 400 {object} errors.ResponseError "Bad Request"
 // @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
 // @Router /todos/{id} [delete]
 func (h *endpointHandler) deleteById(ctx echo.Context) error {
 	h.logger.Infow("deleting todo item...")
@@ -234,14 +328,17 @@ func (h *endpointHandler) deleteById(ctx echo.Context) error {
 
 	item, err := h.service.GetById(ctx.Request().Context(), id)
 	if err != nil {
+		if authz.Missing(err) {
+			return authz.Deny(ctx)
+		}
 		h.logger.Error("could not get item", "error", err.Error())
 
 		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorCouldNotReadTodoItem})
 	}
-	if item.UserId != userId {
+	if err := authz.Authorize(ItemPolicy, userId, authz.ActionDelete, item); err != nil {
 		h.logger.Info("user tried to delete todo of other user")
 
-		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: locale.ErrorNotFoundRecord})
+		return authz.Deny(ctx)
 	}
 
 	err = h.service.DeleteById(ctx.Request().Context(), id)
@@ -253,3 +350,308 @@ func (h *endpointHandler) deleteById(ctx echo.Context) error {
 
 	return ctx.JSON(http.StatusOK, "")
 }
+
+// @Summary Start a timer on a todo item
+// @Description This endpoint starts tracking time on a todo item. A user has at most one running timer,
+// @Description a timer already running on any item is stopped and returned as well.
+// @Tags time tracking
+// @ID startTimer
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ToDo Item ID"
+// @Success 200 {object} TimerResponse
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /todos/{id}/timer/start [post]
+func (h *endpointHandler) startTimer(ctx echo.Context) error {
+	h.logger.Infow("starting timer...")
+
+	item, ok, err := h.getOwnItem(ctx, authz.ActionUpdate)
+	if !ok {
+		return err
+	}
+
+	response, err := h.service.StartTimer(ctx.Request().Context(), item)
+	if err != nil {
+		h.logger.Warn("could not start timer", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInternalServer, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// @Summary Stop the timer of a todo item
+// @Description This endpoint stops the running timer of a todo item
+// @Tags time tracking
+// @ID stopTimer
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ToDo Item ID"
+// @Success 200 {object} TimeEntry
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /todos/{id}/timer/stop [post]
+func (h *endpointHandler) stopTimer(ctx echo.Context) error {
+	h.logger.Infow("stopping timer...")
+
+	item, ok, err := h.getOwnItem(ctx, authz.ActionUpdate)
+	if !ok {
+		return err
+	}
+
+	entry, err := h.service.StopTimer(ctx.Request().Context(), item)
+	if err != nil {
+		h.logger.Warn("could not stop timer", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, entry)
+}
+
+// @Summary Get the running timer
+// @Description This endpoint returns the time entry of the current user's running timer
+// @Tags time tracking
+// @ID getRunningTimer
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} TimeEntry
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "No running timer"
+// @Router /timer [get]
+func (h *endpointHandler) getRunningTimer(ctx echo.Context) error {
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	entry, err := h.service.GetRunningTimer(ctx.Request().Context(), userId)
+	if err != nil {
+		return ctx.JSON(http.StatusNotFound, e.ResponseError{Message: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, entry)
+}
+
+// @Summary Get the time entries of a todo item
+// @Description This endpoint returns all time entries of a todo item
+// @Tags time tracking
+// @ID getTimeEntries
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ToDo Item ID"
+// @Success 200 {array} TimeEntry
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /todos/{id}/time-entries [get]
+func (h *endpointHandler) getTimeEntries(ctx echo.Context) error {
+	item, ok, err := h.getOwnItem(ctx, authz.ActionRead)
+	if !ok {
+		return err
+	}
+
+	entries, err := h.service.GetTimeEntries(ctx.Request().Context(), item.ID)
+	if err != nil {
+		h.logger.Warn("could not read time entries", "error", err.Error())
+
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorCouldNotReadTimeEntry})
+	}
+
+	return ctx.JSON(http.StatusOK, entries)
+}
+
+// @Summary Add a time entry to a todo item
+// @Description This endpoint records time spent on a todo item manually, with either ended_at or duration_minutes
+// @Tags time tracking
+// @ID addTimeEntry
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ToDo Item ID"
+// @Param entry body TimeEntryInput true "Time entry"
+// @Success 200 {object} TimeEntry
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /todos/{id}/time-entries [post]
+func (h *endpointHandler) addTimeEntry(ctx echo.Context) error {
+	h.logger.Infow("adding time entry...")
+
+	item, ok, err := h.getOwnItem(ctx, authz.ActionUpdate)
+	if !ok {
+		return err
+	}
+
+	input := TimeEntryInput{}
+	err = ctx.Bind(&input)
+	if err != nil {
+		h.logger.Warn("could not bind body to time entry struct", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+	}
+
+	entry, err := h.service.AddTimeEntry(ctx.Request().Context(), item, input)
+	if err != nil {
+		h.logger.Warn("could not add time entry", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidTimeEntry, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, entry)
+}
+
+// @Summary Delete a time entry
+// @Description This endpoint deletes a time entry, stopping it if it is running
+// @Tags time tracking
+// @ID deleteTimeEntry
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Time entry ID"
+// @Success 200 {string} string ""
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /time-entries/{id} [delete]
+func (h *endpointHandler) deleteTimeEntry(ctx echo.Context) error {
+	h.logger.Infow("deleting time entry...")
+	userId := auth.GetUserIdFromContext(ctx)
+
+	id, err := handlers.GetUrlId(ctx, h.logger)
+	if err != nil {
+		h.logger.Warn("could not get id from url", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidID})
+	}
+
+	entry, err := h.service.GetTimeEntryById(ctx.Request().Context(), id)
+	if err != nil {
+		if authz.Missing(err) {
+			return authz.Deny(ctx)
+		}
+		h.logger.Error("could not get time entry", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorCouldNotReadTimeEntry})
+	}
+	if err := authz.Authorize(TimeEntryPolicy, userId, authz.ActionDelete, entry); err != nil {
+		h.logger.Info("user tried to delete time entry of other user")
+
+		return authz.Deny(ctx)
+	}
+
+	err = h.service.DeleteTimeEntry(ctx.Request().Context(), id)
+	if err != nil {
+		h.logger.Warn("could not delete time entry", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorCouldNotDelete, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, "")
+}
+
+// @Summary Time report
+// @Description This endpoint sums the tracked time of the current user, grouped by day or by tag.
+// @Description from and to accept dates (2006-01-02, to is inclusive) or RFC 3339 timestamps and default to the last 30 days.
+// @Description Days, of the range and of the groups, are those of tz.
+// @Tags time tracking
+// @ID getTimeReport
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Start of the range"
+// @Param to query string false "End of the range"
+// @Param group_by query string false "day (default) or tag"
+// @Param tz query string false "IANA timezone, e.g. Europe/Berlin, UTC by default"
+// @Success 200 {object} TimeReport
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Router /reports/time [get]
+func (h *endpointHandler) getTimeReport(ctx echo.Context) error {
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	query := TimeReportQuery{
+		To:       time.Now().UTC(),
+		GroupBy:  ctx.QueryParam("group_by"),
+		Timezone: ctx.QueryParam("tz"),
+	}
+	query.From = query.To.AddDate(0, 0, -30)
+
+	loc, err := LoadTimezone(query.Timezone)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: err.Error()})
+	}
+	if from := ctx.QueryParam("from"); from != "" {
+		query.From, err = parseRangeTime(from, false, loc)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidReportQuery, Details: err.Error()})
+		}
+	}
+	if to := ctx.QueryParam("to"); to != "" {
+		query.To, err = parseRangeTime(to, true, loc)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidReportQuery, Details: err.Error()})
+		}
+	}
+
+	report, err := h.service.GetTimeReport(ctx.Request().Context(), userId, query)
+	if err != nil {
+		h.logger.Warn("could not build time report", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, report)
+}
+
+// parseRangeTime : parses an RFC 3339 timestamp or a plain date, which starts at midnight
+// in loc. A plain date used as the end of a range includes the whole day.
+func parseRangeTime(value string, end bool, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+
+	return t.UTC(), nil
+}
+
+// getOwnItem : loads the todo item from the :id param and makes sure the current user may
+// perform the action on it. When ok is false the error response has already been written.
+func (h *endpointHandler) getOwnItem(ctx echo.Context, action authz.Action) (ToDoItem, bool, error) {
+	userId := auth.GetUserIdFromContext(ctx)
+
+	id, err := handlers.GetUrlId(ctx, h.logger)
+	if err != nil {
+		h.logger.Warn("could not get id from url", "error", err.Error())
+
+		return ToDoItem{}, false, ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidID})
+	}
+
+	item, err := h.service.GetById(ctx.Request().Context(), id)
+	if err != nil {
+		if authz.Missing(err) {
+			return ToDoItem{}, false, authz.Deny(ctx)
+		}
+		h.logger.Error("could not get item", "error", err.Error())
+
+		return ToDoItem{}, false, ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorCouldNotReadTodoItem})
+	}
+	if err := authz.Authorize(ItemPolicy, userId, action, item); err != nil {
+		h.logger.Info("user tried to access todo of other user")
+
+		return ToDoItem{}, false, authz.Deny(ctx)
+	}
+
+	return item, true, nil
+}
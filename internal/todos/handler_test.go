@@ -15,6 +15,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 	"todo-app/pkg/locale"
 
 	localErr "todo-app/pkg/errors"
@@ -150,6 +151,73 @@ func TestHandler_GetAll(t *testing.T) {
 	})
 }
 
+func TestHandler_QuickAdd(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	t.Run("dry run from query", func(t *testing.T) {
+		body := `{"text":"Pay rent #finance","timezone":"Europe/Berlin"}`
+		input := QuickAddInput{Text: "Pay rent #finance", Timezone: "Europe/Berlin", DryRun: true}
+		response := QuickAddResponse{
+			Item:       ToDoItem{Text: "Pay rent", UserId: 1, Tags: []Tag{{Name: "finance"}}},
+			Recognized: []RecognizedToken{{Type: TokenTypeTag, Text: "#finance", Value: "finance"}},
+			DryRun:     true,
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/todos/quick?dry_run=true", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+
+		mockService.
+			EXPECT().
+			QuickAdd(ctx.Request().Context(), uint(1), input).
+			Return(response, nil).
+			Times(1)
+
+		if assert.NoError(t, h.quickAdd(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var responseData QuickAddResponse
+			err := json.Unmarshal(rec.Body.Bytes(), &responseData)
+			assert.NoError(t, err)
+
+			assert.Equal(t, response.Item.Text, responseData.Item.Text)
+			assert.Equal(t, response.Recognized, responseData.Recognized)
+			assert.True(t, responseData.DryRun)
+		}
+	})
+
+	t.Run("invalid text", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/todos/quick", strings.NewReader(`{"text":"#finance"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+
+		mockService.
+			EXPECT().
+			QuickAdd(ctx.Request().Context(), uint(1), QuickAddInput{Text: "#finance"}).
+			Return(QuickAddResponse{}, errors.New("Key: 'ToDoItem.Text' Error:Field validation for 'Text' failed on the 'required' tag")).
+			Times(1)
+
+		if assert.NoError(t, h.quickAdd(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var responseError localErr.ResponseError
+			err := json.Unmarshal(rec.Body.Bytes(), &responseError)
+			assert.NoError(t, err)
+
+			assert.Equal(t, locale.ErrorInvalidTodoItem, responseError.Message)
+		}
+	})
+}
+
 func TestHandler_UpdateById(t *testing.T) {
 	os.Setenv("APP_ENV", "test")
 	ctrl := gomock.NewController(t)
@@ -387,3 +455,231 @@ func TestHandler_DeleteById(t *testing.T) {
 		}
 	})
 }
+
+func TestHandler_StartTimer(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	item := ToDoItem{Model: gorm.Model{ID: 3}, UserId: 1}
+
+	newContext := func(userId uint) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/todos/3/timer/start", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", userId)
+		ctx.SetPath("/todos/:id/timer/start")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues("3")
+
+		return ctx, rec
+	}
+
+	t.Run("start timer", func(t *testing.T) {
+		ctx, rec := newContext(1)
+		started := TimeEntry{Model: gorm.Model{ID: 9}, ToDoItemID: 3, UserId: 1}
+
+		mockService.EXPECT().GetById(ctx.Request().Context(), uint(3)).Return(item, nil).Times(1)
+		mockService.
+			EXPECT().
+			StartTimer(ctx.Request().Context(), item).
+			Return(TimerResponse{Started: started}, nil).
+			Times(1)
+
+		if assert.NoError(t, h.startTimer(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var response TimerResponse
+			err := json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			assert.Equal(t, uint(9), response.Started.ID)
+			assert.Nil(t, response.Stopped)
+		}
+	})
+
+	t.Run("item of other user", func(t *testing.T) {
+		ctx, rec := newContext(2)
+
+		mockService.EXPECT().GetById(ctx.Request().Context(), uint(3)).Return(item, nil).Times(1)
+
+		if assert.NoError(t, h.startTimer(ctx)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+		}
+	})
+}
+
+func TestHandler_GetTimeReport(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	t.Run("report for date range", func(t *testing.T) {
+		q := make(url.Values)
+		q.Set("from", "2025-03-01")
+		q.Set("to", "2025-03-07")
+		q.Set("group_by", "tag")
+		req := httptest.NewRequest(http.MethodGet, "/reports/time?"+q.Encode(), nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+
+		query := TimeReportQuery{
+			From:    time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+			To:      time.Date(2025, time.March, 8, 0, 0, 0, 0, time.UTC),
+			GroupBy: ReportGroupByTag,
+		}
+		mockService.
+			EXPECT().
+			GetTimeReport(ctx.Request().Context(), uint(1), query).
+			Return(TimeReport{GroupBy: ReportGroupByTag, Groups: []TimeReportGroup{{Key: "work", Seconds: 60, Entries: 1}}, TotalSeconds: 60}, nil).
+			Times(1)
+
+		if assert.NoError(t, h.getTimeReport(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var response TimeReport
+			err := json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			assert.Equal(t, int64(60), response.TotalSeconds)
+		}
+	})
+
+	t.Run("dates in the timezone", func(t *testing.T) {
+		q := make(url.Values)
+		q.Set("from", "2025-03-01")
+		q.Set("to", "2025-03-07")
+		q.Set("tz", "Europe/Berlin")
+		req := httptest.NewRequest(http.MethodGet, "/reports/time?"+q.Encode(), nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+
+		// midnight in Berlin is 23:00 UTC the day before
+		query := TimeReportQuery{
+			From:     time.Date(2025, time.February, 28, 23, 0, 0, 0, time.UTC),
+			To:       time.Date(2025, time.March, 7, 23, 0, 0, 0, time.UTC),
+			Timezone: "Europe/Berlin",
+		}
+		mockService.
+			EXPECT().
+			GetTimeReport(ctx.Request().Context(), uint(1), query).
+			Return(TimeReport{GroupBy: ReportGroupByDay, Timezone: "Europe/Berlin", Groups: []TimeReportGroup{}}, nil).
+			Times(1)
+
+		if assert.NoError(t, h.getTimeReport(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("invalid timezone", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/reports/time?tz=Mars/Olympus", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+
+		if assert.NoError(t, h.getTimeReport(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var responseError localErr.ResponseError
+			err := json.Unmarshal(rec.Body.Bytes(), &responseError)
+			assert.NoError(t, err)
+
+			assert.Equal(t, locale.ErrorInvalidTimezone, responseError.Message)
+		}
+	})
+
+	t.Run("invalid from", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/reports/time?from=yesterday", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+
+		if assert.NoError(t, h.getTimeReport(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var responseError localErr.ResponseError
+			err := json.Unmarshal(rec.Body.Bytes(), &responseError)
+			assert.NoError(t, err)
+
+			assert.Equal(t, locale.ErrorInvalidReportQuery, responseError.Message)
+		}
+	})
+}
+
+func TestHandler_CrossUserAccess(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	// item 3 and time entry 9 belong to user 2, user 1 tries to reach them
+	item := ToDoItem{Model: gorm.Model{ID: 3}, UserId: 2}
+	entry := TimeEntry{Model: gorm.Model{ID: 9}, ToDoItemID: 3, UserId: 2}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		id      string
+		body    string
+		handler echo.HandlerFunc
+	}{
+		{"update item", http.MethodPut, "/todos/:id", "3", `{"text":"mine now"}`, h.updateById},
+		{"delete item", http.MethodDelete, "/todos/:id", "3", "", h.deleteById},
+		{"start timer", http.MethodPost, "/todos/:id/timer/start", "3", "", h.startTimer},
+		{"stop timer", http.MethodPost, "/todos/:id/timer/stop", "3", "", h.stopTimer},
+		{"read time entries", http.MethodGet, "/todos/:id/time-entries", "3", "", h.getTimeEntries},
+		{"add time entry", http.MethodPost, "/todos/:id/time-entries", "3", `{"duration_minutes":30}`, h.addTimeEntry},
+		{"delete time entry", http.MethodDelete, "/time-entries/:id", "9", "", h.deleteTimeEntry},
+		// ids that do not exist answer the same
+		{"update nonexistent item", http.MethodPut, "/todos/:id", "404", `{"text":"mine now"}`, h.updateById},
+		{"delete nonexistent item", http.MethodDelete, "/todos/:id", "404", "", h.deleteById},
+		{"start timer of nonexistent item", http.MethodPost, "/todos/:id/timer/start", "404", "", h.startTimer},
+		{"delete nonexistent time entry", http.MethodDelete, "/time-entries/:id", "404", "", h.deleteTimeEntry},
+	}
+	var denied string
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, "/", strings.NewReader(test.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(req, rec)
+			ctx.Set("user_id", uint(1))
+			ctx.SetPath(test.path)
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(test.id)
+
+			// only the lookup happens, nothing is changed
+			mockService.EXPECT().GetById(gomock.Any(), item.ID).Return(item, nil).AnyTimes()
+			mockService.EXPECT().GetTimeEntryById(gomock.Any(), entry.ID).Return(entry, nil).AnyTimes()
+			mockService.EXPECT().GetById(gomock.Any(), uint(404)).Return(ToDoItem{}, gorm.ErrRecordNotFound).AnyTimes()
+			mockService.EXPECT().GetTimeEntryById(gomock.Any(), uint(404)).Return(TimeEntry{}, gorm.ErrRecordNotFound).AnyTimes()
+
+			if assert.NoError(t, test.handler(ctx)) {
+				assert.Equal(t, http.StatusNotFound, rec.Code)
+
+				var responseError localErr.ResponseError
+				err := json.Unmarshal(rec.Body.Bytes(), &responseError)
+				assert.NoError(t, err)
+				assert.Equal(t, locale.ErrorNotFoundRecord, responseError.Message)
+
+				if denied == "" {
+					denied = rec.Body.String()
+				}
+				assert.Equal(t, denied, rec.Body.String())
+			}
+		})
+	}
+
+	ctrl.Finish()
+}
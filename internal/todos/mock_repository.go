@@ -12,6 +12,7 @@ package todos
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -68,6 +69,20 @@ func (mr *MockRepositoryMockRecorder) Create(ctx, item any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), ctx, item)
 }
 
+// CreateTimeEntry mocks base method.
+func (m *MockRepository) CreateTimeEntry(ctx context.Context, entry *TimeEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTimeEntry", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTimeEntry indicates an expected call of CreateTimeEntry.
+func (mr *MockRepositoryMockRecorder) CreateTimeEntry(ctx, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTimeEntry", reflect.TypeOf((*MockRepository)(nil).CreateTimeEntry), ctx, entry)
+}
+
 // Delete mocks base method.
 func (m *MockRepository) Delete(ctx context.Context, id uint) error {
 	m.ctrl.T.Helper()
@@ -82,6 +97,20 @@ func (mr *MockRepositoryMockRecorder) Delete(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), ctx, id)
 }
 
+// DeleteTimeEntry mocks base method.
+func (m *MockRepository) DeleteTimeEntry(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTimeEntry", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTimeEntry indicates an expected call of DeleteTimeEntry.
+func (mr *MockRepositoryMockRecorder) DeleteTimeEntry(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTimeEntry", reflect.TypeOf((*MockRepository)(nil).DeleteTimeEntry), ctx, id)
+}
+
 // GetAll mocks base method.
 func (m *MockRepository) GetAll(ctx context.Context, details PaginationDetails) ([]ToDoItem, error) {
 	m.ctrl.T.Helper()
@@ -128,6 +157,125 @@ func (mr *MockRepositoryMockRecorder) GetById(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockRepository)(nil).GetById), ctx, id)
 }
 
+// GetEntryDurations mocks base method.
+func (m *MockRepository) GetEntryDurations(ctx context.Context, userId uint, query TimeReportQuery) ([]EntryDuration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntryDurations", ctx, userId, query)
+	ret0, _ := ret[0].([]EntryDuration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntryDurations indicates an expected call of GetEntryDurations.
+func (mr *MockRepositoryMockRecorder) GetEntryDurations(ctx, userId, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntryDurations", reflect.TypeOf((*MockRepository)(nil).GetEntryDurations), ctx, userId, query)
+}
+
+// GetRunningTimeEntry mocks base method.
+func (m *MockRepository) GetRunningTimeEntry(ctx context.Context, userId uint) (TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRunningTimeEntry", ctx, userId)
+	ret0, _ := ret[0].(TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRunningTimeEntry indicates an expected call of GetRunningTimeEntry.
+func (mr *MockRepositoryMockRecorder) GetRunningTimeEntry(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRunningTimeEntry", reflect.TypeOf((*MockRepository)(nil).GetRunningTimeEntry), ctx, userId)
+}
+
+// GetTimeEntriesForItem mocks base method.
+func (m *MockRepository) GetTimeEntriesForItem(ctx context.Context, itemId uint) ([]TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTimeEntriesForItem", ctx, itemId)
+	ret0, _ := ret[0].([]TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTimeEntriesForItem indicates an expected call of GetTimeEntriesForItem.
+func (mr *MockRepositoryMockRecorder) GetTimeEntriesForItem(ctx, itemId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimeEntriesForItem", reflect.TypeOf((*MockRepository)(nil).GetTimeEntriesForItem), ctx, itemId)
+}
+
+// GetTimeEntryById mocks base method.
+func (m *MockRepository) GetTimeEntryById(ctx context.Context, id uint) (TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTimeEntryById", ctx, id)
+	ret0, _ := ret[0].(TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTimeEntryById indicates an expected call of GetTimeEntryById.
+func (mr *MockRepositoryMockRecorder) GetTimeEntryById(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimeEntryById", reflect.TypeOf((*MockRepository)(nil).GetTimeEntryById), ctx, id)
+}
+
+// GetTimeReportByTag mocks base method.
+func (m *MockRepository) GetTimeReportByTag(ctx context.Context, userId uint, query TimeReportQuery) ([]TimeReportGroup, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTimeReportByTag", ctx, userId, query)
+	ret0, _ := ret[0].([]TimeReportGroup)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTimeReportByTag indicates an expected call of GetTimeReportByTag.
+func (mr *MockRepositoryMockRecorder) GetTimeReportByTag(ctx, userId, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimeReportByTag", reflect.TypeOf((*MockRepository)(nil).GetTimeReportByTag), ctx, userId, query)
+}
+
+// ReplaceTags mocks base method.
+func (m *MockRepository) ReplaceTags(ctx context.Context, id uint, names []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceTags", ctx, id, names)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceTags indicates an expected call of ReplaceTags.
+func (mr *MockRepositoryMockRecorder) ReplaceTags(ctx, id, names any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceTags", reflect.TypeOf((*MockRepository)(nil).ReplaceTags), ctx, id, names)
+}
+
+// StartTimeEntry mocks base method.
+func (m *MockRepository) StartTimeEntry(ctx context.Context, entry *TimeEntry) (*TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartTimeEntry", ctx, entry)
+	ret0, _ := ret[0].(*TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartTimeEntry indicates an expected call of StartTimeEntry.
+func (mr *MockRepositoryMockRecorder) StartTimeEntry(ctx, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartTimeEntry", reflect.TypeOf((*MockRepository)(nil).StartTimeEntry), ctx, entry)
+}
+
+// StopTimeEntry mocks base method.
+func (m *MockRepository) StopTimeEntry(ctx context.Context, id uint, endedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StopTimeEntry", ctx, id, endedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StopTimeEntry indicates an expected call of StopTimeEntry.
+func (mr *MockRepositoryMockRecorder) StopTimeEntry(ctx, id, endedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopTimeEntry", reflect.TypeOf((*MockRepository)(nil).StopTimeEntry), ctx, id, endedAt)
+}
+
 // Update mocks base method.
 func (m *MockRepository) Update(ctx context.Context, id uint, updates map[string]any) error {
 	m.ctrl.T.Helper()
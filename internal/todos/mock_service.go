@@ -40,6 +40,21 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// AddTimeEntry mocks base method.
+func (m *MockService) AddTimeEntry(ctx context.Context, item ToDoItem, input TimeEntryInput) (TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTimeEntry", ctx, item, input)
+	ret0, _ := ret[0].(TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddTimeEntry indicates an expected call of AddTimeEntry.
+func (mr *MockServiceMockRecorder) AddTimeEntry(ctx, item, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTimeEntry", reflect.TypeOf((*MockService)(nil).AddTimeEntry), ctx, item, input)
+}
+
 // Create mocks base method.
 func (m *MockService) Create(ctx context.Context, item *ToDoItem) error {
 	m.ctrl.T.Helper()
@@ -68,6 +83,20 @@ func (mr *MockServiceMockRecorder) DeleteById(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteById", reflect.TypeOf((*MockService)(nil).DeleteById), ctx, id)
 }
 
+// DeleteTimeEntry mocks base method.
+func (m *MockService) DeleteTimeEntry(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTimeEntry", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTimeEntry indicates an expected call of DeleteTimeEntry.
+func (mr *MockServiceMockRecorder) DeleteTimeEntry(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTimeEntry", reflect.TypeOf((*MockService)(nil).DeleteTimeEntry), ctx, id)
+}
+
 // GetAllForUser mocks base method.
 func (m *MockService) GetAllForUser(ctx context.Context, userId uint, details PaginationDetails) ([]ToDoItem, PaginationMetadata, error) {
 	m.ctrl.T.Helper()
@@ -99,6 +128,135 @@ func (mr *MockServiceMockRecorder) GetById(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockService)(nil).GetById), ctx, id)
 }
 
+// GetRunningTimer mocks base method.
+func (m *MockService) GetRunningTimer(ctx context.Context, userId uint) (TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRunningTimer", ctx, userId)
+	ret0, _ := ret[0].(TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRunningTimer indicates an expected call of GetRunningTimer.
+func (mr *MockServiceMockRecorder) GetRunningTimer(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRunningTimer", reflect.TypeOf((*MockService)(nil).GetRunningTimer), ctx, userId)
+}
+
+// GetTimeEntries mocks base method.
+func (m *MockService) GetTimeEntries(ctx context.Context, itemId uint) ([]TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTimeEntries", ctx, itemId)
+	ret0, _ := ret[0].([]TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTimeEntries indicates an expected call of GetTimeEntries.
+func (mr *MockServiceMockRecorder) GetTimeEntries(ctx, itemId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimeEntries", reflect.TypeOf((*MockService)(nil).GetTimeEntries), ctx, itemId)
+}
+
+// GetTimeEntryById mocks base method.
+func (m *MockService) GetTimeEntryById(ctx context.Context, id uint) (TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTimeEntryById", ctx, id)
+	ret0, _ := ret[0].(TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTimeEntryById indicates an expected call of GetTimeEntryById.
+func (mr *MockServiceMockRecorder) GetTimeEntryById(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimeEntryById", reflect.TypeOf((*MockService)(nil).GetTimeEntryById), ctx, id)
+}
+
+// GetTimeReport mocks base method.
+func (m *MockService) GetTimeReport(ctx context.Context, userId uint, query TimeReportQuery) (TimeReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTimeReport", ctx, userId, query)
+	ret0, _ := ret[0].(TimeReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTimeReport indicates an expected call of GetTimeReport.
+func (mr *MockServiceMockRecorder) GetTimeReport(ctx, userId, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimeReport", reflect.TypeOf((*MockService)(nil).GetTimeReport), ctx, userId, query)
+}
+
+// OnDelete mocks base method.
+func (m *MockService) OnDelete(hook DeleteHook) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnDelete", hook)
+}
+
+// OnDelete indicates an expected call of OnDelete.
+func (mr *MockServiceMockRecorder) OnDelete(hook any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnDelete", reflect.TypeOf((*MockService)(nil).OnDelete), hook)
+}
+
+// QuickAdd mocks base method.
+func (m *MockService) QuickAdd(ctx context.Context, userId uint, input QuickAddInput) (QuickAddResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuickAdd", ctx, userId, input)
+	ret0, _ := ret[0].(QuickAddResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QuickAdd indicates an expected call of QuickAdd.
+func (mr *MockServiceMockRecorder) QuickAdd(ctx, userId, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuickAdd", reflect.TypeOf((*MockService)(nil).QuickAdd), ctx, userId, input)
+}
+
+// StartTimer mocks base method.
+func (m *MockService) StartTimer(ctx context.Context, item ToDoItem) (TimerResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartTimer", ctx, item)
+	ret0, _ := ret[0].(TimerResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartTimer indicates an expected call of StartTimer.
+func (mr *MockServiceMockRecorder) StartTimer(ctx, item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartTimer", reflect.TypeOf((*MockService)(nil).StartTimer), ctx, item)
+}
+
+// StopTimer mocks base method.
+func (m *MockService) StopTimer(ctx context.Context, item ToDoItem) (TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StopTimer", ctx, item)
+	ret0, _ := ret[0].(TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StopTimer indicates an expected call of StopTimer.
+func (mr *MockServiceMockRecorder) StopTimer(ctx, item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopTimer", reflect.TypeOf((*MockService)(nil).StopTimer), ctx, item)
+}
+
+// Subscribe mocks base method.
+func (m *MockService) Subscribe(listener Listener) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Subscribe", listener)
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockServiceMockRecorder) Subscribe(listener any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockService)(nil).Subscribe), listener)
+}
+
 // UpdateById mocks base method.
 func (m *MockService) UpdateById(ctx context.Context, id uint, item ToDoItemUpdateInput) (ToDoItem, error) {
 	m.ctrl.T.Helper()
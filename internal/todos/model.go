@@ -1,19 +1,124 @@
 package todos
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+)
+
+const (
+	TypeTask  = "task"
+	TypeHabit = "habit"
+)
+
 type ToDoItem struct {
 	gorm.Model
-	Text   string `gorm:"not null" validate:"required"`
-	Done   bool   `gorm:"default:false"`
-	UserId uint   `gorm:"not null"`
+	Text string `gorm:"not null" validate:"required"`
+	Done bool   `gorm:"default:false"`
+	// CompletedAt : when the item was last marked done, nil while it is open
+	CompletedAt *time.Time
+	UserId      uint `gorm:"not null;uniqueIndex:idx_todo_user_external"`
+	// ExternalId : id of the item in the system it was imported from, unique per user
+	ExternalId *string `gorm:"type:varchar(255);uniqueIndex:idx_todo_user_external"`
+	ParentId   *uint   `gorm:"index"`
+	DueAt      *time.Time
+	Priority   string `gorm:"type:varchar(16)" validate:"omitempty,oneof=low medium high"`
+	Recurrence string `gorm:"type:varchar(255)"`
+	Estimate   *int   `validate:"omitempty,min=0"` // minutes
+	Tags       []Tag  `gorm:"foreignKey:ToDoItemID;constraint:OnDelete:CASCADE"`
+	// Type : a task is done once, a habit is checked in once per day
+	Type string `gorm:"type:varchar(16);default:task;index" validate:"omitempty,oneof=task habit"`
+	// TargetPerWeek : check-ins per week a habit aims for, nil for every day
+	TargetPerWeek *int `validate:"omitempty,min=1,max=7"`
+	// Timezone : IANA zone whose days count for habit check-ins, UTC when empty
+	Timezone string `gorm:"type:varchar(64)" validate:"omitempty,timezone"`
+	// TrackedSeconds : total of all time entries, including a running timer
+	TrackedSeconds int64 `gorm:"->;-:migration"`
+}
+
+// Tag : label attached to a todo item, stored lowercased
+type Tag struct {
+	ID         uint   `gorm:"primaryKey"`
+	ToDoItemID uint   `gorm:"not null;uniqueIndex:idx_tag_item_name"`
+	Name       string `gorm:"type:varchar(64);not null;uniqueIndex:idx_tag_item_name;index"`
 }
 
 type ToDoItemUpdateInput struct {
-	Text *string `json:"text"`
-	Done *bool   `json:"done"`
+	Text          *string    `json:"text"`
+	Done          *bool      `json:"done"`
+	DueAt         *time.Time `json:"due_at"`
+	Priority      *string    `json:"priority"`
+	Recurrence    *string    `json:"recurrence"`
+	Tags          *[]string  `json:"tags"`
+	Estimate      *int       `json:"estimate"`
+	Type          *string    `json:"type"`
+	TargetPerWeek *int       `json:"target_per_week"`
+	Timezone      *string    `json:"timezone"`
+}
+
+const (
+	ReportGroupByDay = "day"
+	ReportGroupByTag = "tag"
+)
+
+// TimeEntry : time spent on a todo item. EndedAt is nil while the timer is running.
+type TimeEntry struct {
+	gorm.Model
+	ToDoItemID uint      `gorm:"not null;index"`
+	UserId     uint      `gorm:"not null;index"`
+	StartedAt  time.Time `gorm:"not null"`
+	EndedAt    *time.Time
+	Note       string
+	// RunningUserId : set to UserId while the timer runs, the unique index allows only
+	// one running timer per user
+	RunningUserId *uint `gorm:"uniqueIndex" json:"-"`
+}
+
+type TimeEntryInput struct {
+	StartedAt       time.Time  `json:"started_at" validate:"required"`
+	EndedAt         *time.Time `json:"ended_at"`
+	DurationMinutes *int       `json:"duration_minutes" validate:"omitempty,min=1"`
+	Note            string     `json:"note"`
+}
+
+type TimerResponse struct {
+	Started TimeEntry  `json:"started"`
+	Stopped *TimeEntry `json:"stopped,omitempty"`
+}
+
+type TimeReportQuery struct {
+	From    time.Time
+	To      time.Time
+	GroupBy string
+	// Timezone : IANA zone whose days entries are grouped by, UTC when empty
+	Timezone string
+}
+
+// EntryDuration : when a time entry started and how long it ran, a running timer up to now
+type EntryDuration struct {
+	StartedAt time.Time
+	Seconds   int64
+}
+
+type TimeReportGroup struct {
+	Key     string `json:"key"`
+	Seconds int64  `json:"seconds"`
+	Entries int    `json:"entries"`
+}
+
+type TimeReport struct {
+	From         time.Time         `json:"from"`
+	To           time.Time         `json:"to"`
+	GroupBy      string            `json:"group_by"`
+	Timezone     string            `json:"timezone"`
+	Groups       []TimeReportGroup `json:"groups"`
+	TotalSeconds int64             `json:"total_seconds"`
 }
 
 type PaginationDetails struct {
@@ -29,5 +134,25 @@ type PaginationMetadata struct {
 
 type PaginatedResponse struct {
 	Data []ToDoItem         `json:"data"`
-	Meta PaginationMetadata `json:"metadata, omitempty"`
+	Meta PaginationMetadata `json:"metadata,omitempty"`
+}
+
+type QuickAddInput struct {
+	Text     string `json:"text" validate:"required"`
+	Timezone string `json:"timezone"`
+	Locale   string `json:"locale"`
+	DryRun   bool   `json:"dry_run"`
+}
+
+// RecognizedToken : a fragment of the quick-add text that was turned into a field
+type RecognizedToken struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+type QuickAddResponse struct {
+	Item       ToDoItem          `json:"item"`
+	Recognized []RecognizedToken `json:"recognized"`
+	DryRun     bool              `json:"dry_run"`
 }
@@ -0,0 +1,13 @@
+package todos
+
+import "todo-app/pkg/authz"
+
+// ItemPolicy : todo items are only read and changed by their owner
+func ItemPolicy(userId uint, _ authz.Action, item ToDoItem) bool {
+	return authz.Owns(userId, item.UserId)
+}
+
+// TimeEntryPolicy : time entries are only read and changed by the user who tracked them
+func TimeEntryPolicy(userId uint, _ authz.Action, entry TimeEntry) bool {
+	return authz.Owns(userId, entry.UserId)
+}
@@ -0,0 +1,656 @@
+package todos
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TokenTypeDate       = "date"
+	TokenTypeTime       = "time"
+	TokenTypeRecurrence = "recurrence"
+	TokenTypeTag        = "tag"
+	TokenTypePriority   = "priority"
+)
+
+var (
+	meridiemTimePattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)$`)
+	clockTimePattern    = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+	isoDatePattern      = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	slashDatePattern    = regexp.MustCompile(`^(\d{1,2})/(\d{1,2})(?:/(\d{2}|\d{4}))?$`)
+	dottedDatePattern   = regexp.MustCompile(`^(\d{1,2})\.(\d{1,2})\.(\d{2}|\d{4})$`)
+	tagPattern          = regexp.MustCompile(`^#([\p{L}\d_-]*\p{L}[\p{L}\d_-]*)$`)
+)
+
+var priorityWords = map[string]string{
+	"!high":   PriorityHigh,
+	"!urgent": PriorityHigh,
+	"!medium": PriorityMedium,
+	"!med":    PriorityMedium,
+	"!low":    PriorityLow,
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+var rruleDays = map[time.Weekday]string{
+	time.Sunday:    "SU",
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+}
+
+var months = map[string]time.Month{
+	"jan": time.January, "january": time.January,
+	"feb": time.February, "february": time.February,
+	"mar": time.March, "march": time.March,
+	"apr": time.April, "april": time.April,
+	"may": time.May,
+	"jun": time.June, "june": time.June,
+	"jul": time.July, "july": time.July,
+	"aug": time.August, "august": time.August,
+	"sep": time.September, "sept": time.September, "september": time.September,
+	"oct": time.October, "october": time.October,
+	"nov": time.November, "november": time.November,
+	"dec": time.December, "december": time.December,
+}
+
+type quickAddParser struct {
+	words      []string
+	clean      []string
+	now        time.Time
+	monthFirst bool
+
+	date    *time.Time
+	hour    int
+	minute  int
+	hasTime bool
+
+	freq       string
+	interval   int
+	byDay      []time.Weekday
+	byMonthDay int
+
+	tags       []string
+	priority   string
+	recognized []RecognizedToken
+	rest       []string
+}
+
+// ParseQuickAdd : turns a free-form sentence into a todo item. Dates and times are
+// interpreted relative to now in loc; locale decides whether 3/4 is March 4th or
+// April 3rd.
+func ParseQuickAdd(input string, now time.Time, loc *time.Location, locale string) (ToDoItem, []RecognizedToken) {
+	words := strings.Fields(input)
+	p := &quickAddParser{
+		words:      words,
+		clean:      make([]string, len(words)),
+		now:        now.In(loc),
+		monthFirst: isMonthFirstLocale(locale),
+		recognized: []RecognizedToken{},
+	}
+	for i, w := range words {
+		p.clean[i] = strings.TrimRight(strings.ToLower(w), ",.;?")
+	}
+
+	for i := 0; i < len(words); {
+		if n := p.match(i); n > 0 {
+			i += n
+			continue
+		}
+		p.rest = append(p.rest, words[i])
+		i++
+	}
+
+	item := ToDoItem{
+		Text:       strings.Join(p.rest, " "),
+		Priority:   p.priority,
+		Recurrence: p.rrule(),
+	}
+	for _, tag := range p.tags {
+		item.Tags = append(item.Tags, Tag{Name: tag})
+	}
+	if due := p.dueAt(); due != nil {
+		utc := due.UTC()
+		item.DueAt = &utc
+	}
+
+	return item, p.recognized
+}
+
+func isMonthFirstLocale(locale string) bool {
+	l := strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+
+	return l == "" || l == "en" || l == "en-us" || l == "en-ph" || l == "en-ca"
+}
+
+func (p *quickAddParser) match(i int) int {
+	for _, matcher := range []func(int) int{p.matchTag, p.matchPriority, p.matchRecurrence, p.matchDate, p.matchTime} {
+		if n := matcher(i); n > 0 {
+			return n
+		}
+	}
+
+	return 0
+}
+
+func (p *quickAddParser) recognize(tokenType string, from, n int, value string) int {
+	p.recognized = append(p.recognized, RecognizedToken{
+		Type:  tokenType,
+		Text:  strings.Join(p.words[from:from+n], " "),
+		Value: value,
+	})
+
+	return n
+}
+
+func (p *quickAddParser) word(i int) string {
+	if i < 0 || i >= len(p.clean) {
+		return ""
+	}
+
+	return p.clean[i]
+}
+
+func (p *quickAddParser) matchTag(i int) int {
+	m := tagPattern.FindStringSubmatch(p.clean[i])
+	if m == nil {
+		return 0
+	}
+
+	name := m[1]
+	for _, tag := range p.tags {
+		if tag == name {
+			return p.recognize(TokenTypeTag, i, 1, name)
+		}
+	}
+	p.tags = append(p.tags, name)
+
+	return p.recognize(TokenTypeTag, i, 1, name)
+}
+
+func (p *quickAddParser) matchPriority(i int) int {
+	priority, ok := priorityWords[p.clean[i]]
+	if !ok {
+		return 0
+	}
+	p.priority = priority
+
+	return p.recognize(TokenTypePriority, i, 1, priority)
+}
+
+func (p *quickAddParser) matchRecurrence(i int) int {
+	if p.freq != "" {
+		return 0
+	}
+
+	j := i
+	interval := 1
+	switch p.word(j) {
+	case "daily":
+		p.freq = "DAILY"
+	case "weekly":
+		p.freq = "WEEKLY"
+	case "monthly":
+		p.freq = "MONTHLY"
+	case "yearly", "annually":
+		p.freq = "YEARLY"
+	case "every":
+		j++
+		if p.word(j) == "other" {
+			interval = 2
+			j++
+		} else if n, err := strconv.Atoi(p.word(j)); err == nil && n > 0 {
+			interval = n
+			j++
+		}
+
+		switch w := p.word(j); {
+		case w == "day" || w == "days":
+			p.freq = "DAILY"
+		case w == "week" || w == "weeks":
+			p.freq = "WEEKLY"
+		case w == "month" || w == "months":
+			p.freq = "MONTHLY"
+		case w == "year" || w == "years":
+			p.freq = "YEARLY"
+		case w == "weekday" || w == "weekdays":
+			p.freq = "WEEKLY"
+			p.byDay = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+		case w == "weekend" || w == "weekends":
+			p.freq = "WEEKLY"
+			p.byDay = []time.Weekday{time.Saturday, time.Sunday}
+		default:
+			if day, ok := parseOrdinal(w); ok && interval == 1 {
+				p.freq = "MONTHLY"
+				p.byMonthDay = day
+				break
+			}
+			days, n := p.weekdayList(j)
+			if n == 0 {
+				return 0
+			}
+			p.freq = "WEEKLY"
+			p.byDay = days
+			j += n - 1
+		}
+	default:
+		return 0
+	}
+	j++
+	p.interval = interval
+
+	// optional anchor, e.g. "on the 1st" or "on friday"
+	if p.word(j) == "on" {
+		k := j + 1
+		if p.word(k) == "the" {
+			k++
+		}
+		if day, ok := parseOrdinal(p.word(k)); ok && (p.freq == "MONTHLY" || p.word(j+1) == "the") {
+			p.freq = "MONTHLY"
+			p.byMonthDay = day
+			j = k + 1
+		} else if days, n := p.weekdayList(j + 1); n > 0 && p.freq == "WEEKLY" {
+			p.byDay = days
+			j += 1 + n
+		}
+	}
+
+	return p.recognize(TokenTypeRecurrence, i, j-i, p.rrule())
+}
+
+// weekdayList : reads "monday", "monday and friday" or "monday, wednesday and friday"
+func (p *quickAddParser) weekdayList(i int) ([]time.Weekday, int) {
+	var days []time.Weekday
+	j := i
+	for {
+		day, ok := weekdays[p.word(j)]
+		if !ok {
+			break
+		}
+		days = append(days, day)
+		j++
+		if p.word(j) == "and" {
+			if _, ok := weekdays[p.word(j+1)]; ok {
+				j++
+			}
+		}
+	}
+
+	return days, j - i
+}
+
+func (p *quickAddParser) rrule() string {
+	if p.freq == "" {
+		return ""
+	}
+
+	parts := []string{"FREQ=" + p.freq}
+	if p.interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(p.interval))
+	}
+	if len(p.byDay) > 0 {
+		days := make([]string, len(p.byDay))
+		for i, day := range p.byDay {
+			days[i] = rruleDays[day]
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if p.byMonthDay > 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(p.byMonthDay))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+func (p *quickAddParser) matchDate(i int) int {
+	if p.date != nil {
+		return 0
+	}
+
+	j := i
+	if p.word(j) == "due" {
+		j++
+	}
+	if w := p.word(j); w == "on" || w == "by" {
+		j++
+	}
+
+	date, n := p.parseDate(j)
+	if n == 0 {
+		return 0
+	}
+	p.date = &date
+
+	return p.recognize(TokenTypeDate, i, j-i+n, date.Format("2006-01-02"))
+}
+
+func (p *quickAddParser) parseDate(i int) (time.Time, int) {
+	today := time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+	w := p.word(i)
+
+	switch w {
+	case "today":
+		return today, 1
+	case "tonight":
+		if !p.hasTime {
+			p.hour, p.minute, p.hasTime = 20, 0, true
+		}
+		return today, 1
+	case "tomorrow", "tmrw", "tmr":
+		return today.AddDate(0, 0, 1), 1
+	case "next":
+		switch next := p.word(i + 1); next {
+		case "week":
+			return startOfWeek(today).AddDate(0, 0, 7), 2
+		case "month":
+			return time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location()), 2
+		case "year":
+			return time.Date(today.Year()+1, time.January, 1, 0, 0, 0, 0, today.Location()), 2
+		default:
+			if day, ok := weekdays[next]; ok {
+				monday := startOfWeek(today).AddDate(0, 0, 7)
+				return monday.AddDate(0, 0, (int(day)+6)%7), 2
+			}
+		}
+		return time.Time{}, 0
+	case "in":
+		return p.parseRelative(i + 1)
+	}
+
+	if day, ok := weekdays[w]; ok {
+		offset := (int(day) - int(today.Weekday()) + 7) % 7
+		return today.AddDate(0, 0, offset), 1
+	}
+
+	if m := isoDatePattern.FindStringSubmatch(w); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		day, _ := strconv.Atoi(m[3])
+		if date, ok := makeDate(year, month, day, today.Location()); ok {
+			return date, 1
+		}
+		return time.Time{}, 0
+	}
+
+	m := slashDatePattern.FindStringSubmatch(w)
+	if m == nil {
+		m = dottedDatePattern.FindStringSubmatch(w)
+	}
+	if m != nil {
+		first, _ := strconv.Atoi(m[1])
+		second, _ := strconv.Atoi(m[2])
+		month, day := second, first
+		if p.monthFirst {
+			month, day = first, second
+		}
+		if date, ok := completeYear(today, m[3], month, day); ok {
+			return date, 1
+		}
+		return time.Time{}, 0
+	}
+
+	if month, ok := months[w]; ok {
+		if day, ok := parseOrdinal(p.word(i + 1)); ok {
+			year := p.yearAt(i + 2)
+			if date, ok := completeYear(today, year, int(month), day); ok {
+				return date, 2 + wordCount(year)
+			}
+		}
+	}
+
+	if day, ok := parseOrdinal(w); ok {
+		k := i + 1
+		if p.word(k) == "of" {
+			k++
+		}
+		if month, ok := months[p.word(k)]; ok {
+			year := p.yearAt(k + 1)
+			if date, ok := completeYear(today, year, int(month), day); ok {
+				return date, k - i + 1 + wordCount(year)
+			}
+		}
+	}
+
+	return time.Time{}, 0
+}
+
+func (p *quickAddParser) parseRelative(i int) (time.Time, int) {
+	amount := 0
+	switch w := p.word(i); w {
+	case "a", "an":
+		amount = 1
+	default:
+		n, err := strconv.Atoi(w)
+		if err != nil || n <= 0 {
+			return time.Time{}, 0
+		}
+		amount = n
+	}
+
+	today := time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+	switch strings.TrimSuffix(p.word(i+1), "s") {
+	case "day":
+		return today.AddDate(0, 0, amount), 3
+	case "week":
+		return today.AddDate(0, 0, 7*amount), 3
+	case "month":
+		return today.AddDate(0, amount, 0), 3
+	case "year":
+		return today.AddDate(amount, 0, 0), 3
+	case "hour", "minute":
+		unit := time.Hour
+		if strings.HasPrefix(p.word(i+1), "minute") {
+			unit = time.Minute
+		}
+		at := p.now.Add(time.Duration(amount) * unit)
+		p.hour, p.minute, p.hasTime = at.Hour(), at.Minute(), true
+		return time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location()), 3
+	}
+
+	return time.Time{}, 0
+}
+
+// yearAt : returns the word at i when it looks like a four digit year
+func (p *quickAddParser) yearAt(i int) string {
+	if w := p.word(i); len(w) == 4 {
+		if _, err := strconv.Atoi(w); err == nil {
+			return w
+		}
+	}
+
+	return ""
+}
+
+func wordCount(s string) int {
+	if s == "" {
+		return 0
+	}
+
+	return 1
+}
+
+// completeYear : builds a date from month and day, rolling over to next year when
+// no year was given and the date has already passed
+func completeYear(today time.Time, yearText string, month, day int) (time.Time, bool) {
+	if yearText != "" {
+		year, _ := strconv.Atoi(yearText)
+		if year < 100 {
+			year += 2000
+		}
+		return makeDate(year, month, day, today.Location())
+	}
+
+	date, ok := makeDate(today.Year(), month, day, today.Location())
+	if !ok {
+		return time.Time{}, false
+	}
+	if date.Before(today) {
+		return makeDate(today.Year()+1, month, day, today.Location())
+	}
+
+	return date, true
+}
+
+func makeDate(year, month, day int, loc *time.Location) (time.Time, bool) {
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return time.Time{}, false
+	}
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc)
+	if date.Day() != day {
+		return time.Time{}, false
+	}
+
+	return date, true
+}
+
+func startOfWeek(day time.Time) time.Time {
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+func parseOrdinal(w string) (int, bool) {
+	for _, suffix := range []string{"st", "nd", "rd", "th"} {
+		w = strings.TrimSuffix(w, suffix)
+	}
+	n, err := strconv.Atoi(w)
+	if err != nil || n < 1 || n > 31 {
+		return 0, false
+	}
+
+	return n, true
+}
+
+func (p *quickAddParser) matchTime(i int) int {
+	if p.hasTime {
+		return 0
+	}
+
+	j := i
+	prefixed := false
+	if w := p.word(j); w == "at" || w == "@" {
+		j++
+		prefixed = true
+	}
+
+	hour, minute, n := p.parseTime(j, prefixed)
+	if n == 0 {
+		return 0
+	}
+	p.hour, p.minute, p.hasTime = hour, minute, true
+
+	return p.recognize(TokenTypeTime, i, j-i+n, fmt.Sprintf("%02d:%02d", hour, minute))
+}
+
+func (p *quickAddParser) parseTime(i int, prefixed bool) (int, int, int) {
+	w := p.word(i)
+	switch w {
+	case "noon", "midday":
+		return 12, 0, 1
+	case "midnight":
+		return 0, 0, 1
+	}
+
+	consumed := 1
+	if next := p.word(i + 1); next == "am" || next == "pm" {
+		w += next
+		consumed = 2
+	}
+
+	if m := meridiemTimePattern.FindStringSubmatch(w); m != nil {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		if hour < 1 || hour > 12 || minute > 59 {
+			return 0, 0, 0
+		}
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+		return hour, minute, consumed
+	}
+
+	if m := clockTimePattern.FindStringSubmatch(w); m != nil {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		if hour > 23 || minute > 59 {
+			return 0, 0, 0
+		}
+		return hour, minute, 1
+	}
+
+	if prefixed {
+		if hour, err := strconv.Atoi(w); err == nil && hour >= 0 && hour <= 23 {
+			return hour, 0, 1
+		}
+	}
+
+	return 0, 0, 0
+}
+
+func (p *quickAddParser) dueAt() *time.Time {
+	today := time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+
+	date := p.date
+	if date == nil && p.hasTime {
+		d := today
+		if time.Date(d.Year(), d.Month(), d.Day(), p.hour, p.minute, 0, 0, d.Location()).Before(p.now) {
+			d = d.AddDate(0, 0, 1)
+		}
+		date = &d
+	}
+	if date == nil {
+		date = p.firstOccurrence(today)
+	}
+	if date == nil {
+		return nil
+	}
+
+	var due time.Time
+	if p.hasTime {
+		due = time.Date(date.Year(), date.Month(), date.Day(), p.hour, p.minute, 0, 0, date.Location())
+	} else {
+		// without a time the item is due by the end of that day
+		due = time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 0, date.Location())
+	}
+
+	return &due
+}
+
+// firstOccurrence : the first day a recurring item falls on when no explicit date was given
+func (p *quickAddParser) firstOccurrence(today time.Time) *time.Time {
+	switch {
+	case p.freq == "DAILY":
+		return &today
+	case p.freq == "WEEKLY" && len(p.byDay) > 0:
+		for offset := 0; offset < 7; offset++ {
+			day := today.AddDate(0, 0, offset)
+			for _, weekday := range p.byDay {
+				if day.Weekday() == weekday {
+					return &day
+				}
+			}
+		}
+	case p.freq == "MONTHLY" && p.byMonthDay > 0:
+		for offset := 0; offset < 12; offset++ {
+			month := time.Date(today.Year(), today.Month()+time.Month(offset), 1, 0, 0, 0, 0, today.Location())
+			if day, ok := makeDate(month.Year(), int(month.Month()), p.byMonthDay, today.Location()); ok && !day.Before(today) {
+				return &day
+			}
+		}
+	}
+
+	return nil
+}
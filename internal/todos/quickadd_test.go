@@ -0,0 +1,151 @@
+package todos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuickAdd(t *testing.T) {
+	// Wednesday, 15 January 2025, 14:30 in Berlin
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	now := time.Date(2025, time.January, 15, 14, 30, 0, 0, berlin)
+
+	at := func(year int, month time.Month, day, hour, minute, second int) *time.Time {
+		due := time.Date(year, month, day, hour, minute, second, 0, berlin).UTC()
+		return &due
+	}
+
+	tests := []struct {
+		name       string
+		input      string
+		locale     string
+		text       string
+		due        *time.Time
+		recurrence string
+		tags       []string
+		priority   string
+	}{
+		{
+			name:       "full example",
+			input:      "Pay rent every month on the 1st #finance !high tomorrow 9am",
+			text:       "Pay rent",
+			due:        at(2025, time.January, 16, 9, 0, 0),
+			recurrence: "FREQ=MONTHLY;BYMONTHDAY=1",
+			tags:       []string{"finance"},
+			priority:   PriorityHigh,
+		},
+		{
+			name:  "plain text",
+			input: "go for a run",
+			text:  "go for a run",
+		},
+		{
+			name:  "date only is due by end of day",
+			input: "submit report today",
+			text:  "submit report",
+			due:   at(2025, time.January, 15, 23, 59, 59),
+		},
+		{
+			name:  "time already passed rolls over to tomorrow",
+			input: "call mom at 9",
+			text:  "call mom",
+			due:   at(2025, time.January, 16, 9, 0, 0),
+		},
+		{
+			name:  "time later today",
+			input: "standup 16:15",
+			text:  "standup",
+			due:   at(2025, time.January, 15, 16, 15, 0),
+		},
+		{
+			name:  "next weekday",
+			input: "dentist next monday 10:30am",
+			text:  "dentist",
+			due:   at(2025, time.January, 20, 10, 30, 0),
+		},
+		{
+			name:  "relative days",
+			input: "renew passport in 3 days",
+			text:  "renew passport",
+			due:   at(2025, time.January, 18, 23, 59, 59),
+		},
+		{
+			name:  "month first numeric date in en-US",
+			input: "party 2/3",
+			text:  "party",
+			due:   at(2025, time.February, 3, 23, 59, 59),
+		},
+		{
+			name:   "day first numeric date in en-GB",
+			input:  "party 2/3",
+			locale: "en-GB",
+			text:   "party",
+			due:    at(2025, time.March, 2, 23, 59, 59),
+		},
+		{
+			name:  "past month name rolls to next year",
+			input: "taxes due jan 5th",
+			text:  "taxes",
+			due:   at(2026, time.January, 5, 23, 59, 59),
+		},
+		{
+			name:  "iso date with year",
+			input: "conference 2025-06-12 noon",
+			text:  "conference",
+			due:   at(2025, time.June, 12, 12, 0, 0),
+		},
+		{
+			name:       "weekly recurrence starts on next matching day",
+			input:      "gym every monday and thursday #health #Health",
+			text:       "gym",
+			due:        at(2025, time.January, 16, 23, 59, 59),
+			recurrence: "FREQ=WEEKLY;BYDAY=MO,TH",
+			tags:       []string{"health"},
+		},
+		{
+			name:       "interval recurrence without anchor has no due date",
+			input:      "water plants every 2 weeks !low",
+			text:       "water plants",
+			recurrence: "FREQ=WEEKLY;INTERVAL=2",
+			priority:   PriorityLow,
+		},
+		{
+			name:  "ordinary words are kept",
+			input: "read chapter 3 in a book about may",
+			text:  "read chapter 3 in a book about may",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, _ := ParseQuickAdd(tt.input, now, berlin, tt.locale)
+
+			assert.Equal(t, tt.text, item.Text)
+			assert.Equal(t, tt.due, item.DueAt)
+			assert.Equal(t, tt.recurrence, item.Recurrence)
+			assert.Equal(t, tt.priority, item.Priority)
+
+			var tags []string
+			for _, tag := range item.Tags {
+				tags = append(tags, tag.Name)
+			}
+			assert.Equal(t, tt.tags, tags)
+		})
+	}
+}
+
+func TestParseQuickAdd_Recognized(t *testing.T) {
+	now := time.Date(2025, time.January, 15, 8, 0, 0, 0, time.UTC)
+
+	_, recognized := ParseQuickAdd("Pay rent every month on the 1st #finance !high tomorrow 9am", now, time.UTC, "en")
+
+	assert.Equal(t, []RecognizedToken{
+		{Type: TokenTypeRecurrence, Text: "every month on the 1st", Value: "FREQ=MONTHLY;BYMONTHDAY=1"},
+		{Type: TokenTypeTag, Text: "#finance", Value: "finance"},
+		{Type: TokenTypePriority, Text: "!high", Value: PriorityHigh},
+		{Type: TokenTypeDate, Text: "tomorrow", Value: "2025-01-16"},
+		{Type: TokenTypeTime, Text: "9am", Value: "09:00"},
+	}, recognized)
+}
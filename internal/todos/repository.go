@@ -2,6 +2,9 @@ package todos
 
 import (
 	"context"
+	"errors"
+	"time"
+	"todo-app/pkg/database"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -15,8 +18,27 @@ type Repository interface {
 	Delete(ctx context.Context, id uint) error
 	CountAll(ctx context.Context) int
 	GetAllForUser(ctx context.Context, userId uint, details PaginationDetails) ([]ToDoItem, PaginationMetadata, error)
+	ReplaceTags(ctx context.Context, id uint, names []string) error
+	StartTimeEntry(ctx context.Context, entry *TimeEntry) (*TimeEntry, error)
+	StopTimeEntry(ctx context.Context, id uint, endedAt time.Time) error
+	CreateTimeEntry(ctx context.Context, entry *TimeEntry) error
+	GetRunningTimeEntry(ctx context.Context, userId uint) (TimeEntry, error)
+	GetTimeEntryById(ctx context.Context, id uint) (TimeEntry, error)
+	GetTimeEntriesForItem(ctx context.Context, itemId uint) ([]TimeEntry, error)
+	DeleteTimeEntry(ctx context.Context, id uint) error
+	GetTimeReportByTag(ctx context.Context, userId uint, query TimeReportQuery) ([]TimeReportGroup, int64, error)
+	GetEntryDurations(ctx context.Context, userId uint, query TimeReportQuery) ([]EntryDuration, error)
 }
 
+// trackedSecondsColumn : sums the time entries of each todo item, counting a running
+// timer up to now
+const trackedSecondsColumn = `(SELECT COALESCE(SUM(TIMESTAMPDIFF(SECOND, time_entries.started_at, COALESCE(time_entries.ended_at, UTC_TIMESTAMP()))), 0)
+	FROM time_entries
+	WHERE time_entries.to_do_item_id = to_do_items.id AND time_entries.deleted_at IS NULL) AS tracked_seconds`
+
+// entrySecondsExpression : duration of a single time entry in seconds
+const entrySecondsExpression = "TIMESTAMPDIFF(SECOND, time_entries.started_at, COALESCE(time_entries.ended_at, UTC_TIMESTAMP()))"
+
 type repository struct {
 	logger *zap.SugaredLogger
 	db     *gorm.DB
@@ -29,8 +51,13 @@ func GetRepository(logger *zap.SugaredLogger, db *gorm.DB) Repository {
 	}
 }
 
+// conn : the connection to use for ctx, joining a transaction started by the caller
+func (r *repository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
 func (r *repository) Create(ctx context.Context, item *ToDoItem) error {
-	result := r.db.WithContext(ctx).Create(item)
+	result := r.conn(ctx).Create(item)
 	if result.Error != nil {
 		r.logger.Errorw("failed to create todo item", "error", result.Error)
 
@@ -42,7 +69,7 @@ func (r *repository) Create(ctx context.Context, item *ToDoItem) error {
 
 func (r *repository) GetAll(ctx context.Context, details PaginationDetails) ([]ToDoItem, error) {
 	var items []ToDoItem
-	db := r.db.WithContext(ctx).Model(&ToDoItem{})
+	db := r.conn(ctx).Model(&ToDoItem{})
 
 	if details.Page > 0 && details.Limit > 0 {
 		db = db.Offset((details.Page - 1) * details.Limit).Limit(details.Limit)
@@ -64,7 +91,7 @@ func (r *repository) GetAll(ctx context.Context, details PaginationDetails) ([]T
 
 func (r *repository) GetById(ctx context.Context, id uint) (ToDoItem, error) {
 	var item ToDoItem
-	result := r.db.WithContext(ctx).First(&item, id)
+	result := r.conn(ctx).Select("to_do_items.*, "+trackedSecondsColumn).Preload("Tags").First(&item, id)
 	if result.Error != nil {
 		r.logger.Errorw("failed to find todo item by id", "id", id, "error", result.Error)
 
@@ -75,7 +102,7 @@ func (r *repository) GetById(ctx context.Context, id uint) (ToDoItem, error) {
 }
 
 func (r *repository) Update(ctx context.Context, id uint, updates map[string]interface{}) error {
-	result := r.db.WithContext(ctx).Model(&ToDoItem{}).Where("id = ?", id).Updates(updates)
+	result := r.conn(ctx).Model(&ToDoItem{}).Where("id = ?", id).Updates(updates)
 	if result.Error != nil {
 		r.logger.Errorw("failed to update todo item", "id", id, "error", result.Error)
 
@@ -86,7 +113,7 @@ func (r *repository) Update(ctx context.Context, id uint, updates map[string]int
 }
 
 func (r *repository) Delete(ctx context.Context, id uint) error {
-	result := r.db.WithContext(ctx).Delete(&ToDoItem{}, id)
+	result := r.conn(ctx).Delete(&ToDoItem{}, id)
 	if result.Error != nil {
 		r.logger.Errorw("failed to delete todo item", "id", id, "error", result.Error)
 
@@ -98,7 +125,7 @@ func (r *repository) Delete(ctx context.Context, id uint) error {
 
 func (r *repository) CountAll(ctx context.Context) int {
 	var count int64
-	r.db.WithContext(ctx).Model(&ToDoItem{}).Count(&count)
+	r.conn(ctx).Model(&ToDoItem{}).Count(&count)
 
 	return int(count)
 }
@@ -107,7 +134,7 @@ func (r *repository) GetAllForUser(ctx context.Context, userID uint, details Pag
 	var items []ToDoItem
 	var totalCount int64
 
-	db := r.db.Model(&ToDoItem{}).Where("user_id = ?", userID)
+	db := r.conn(ctx).Model(&ToDoItem{}).Where("user_id = ?", userID)
 
 	// Count total items for the user
 	err := db.Count(&totalCount).Error
@@ -126,7 +153,7 @@ func (r *repository) GetAllForUser(ctx context.Context, userID uint, details Pag
 	}
 
 	// Fetch the items
-	err = db.Find(&items).Error
+	err = db.Select("to_do_items.*, " + trackedSecondsColumn).Preload("Tags").Find(&items).Error
 	if err != nil {
 		r.logger.Errorw("failed to get all todo items for user", "user_id", userID, "error", err)
 		return nil, PaginationMetadata{}, err
@@ -139,3 +166,197 @@ func (r *repository) GetAllForUser(ctx context.Context, userID uint, details Pag
 
 	return items, metadata, nil
 }
+
+func (r *repository) ReplaceTags(ctx context.Context, id uint, names []string) error {
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("to_do_item_id = ?", id).Delete(&Tag{}).Error; err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return nil
+		}
+
+		tags := make([]Tag, len(names))
+		for i, name := range names {
+			tags[i] = Tag{ToDoItemID: id, Name: name}
+		}
+
+		return tx.Create(&tags).Error
+	})
+	if err != nil {
+		r.logger.Errorw("failed to replace todo item tags", "id", id, "error", err)
+
+		return err
+	}
+
+	return nil
+}
+
+// StartTimeEntry : stops the user's running timer, if any, and starts entry in the same
+// transaction. The stopped entry is returned.
+func (r *repository) StartTimeEntry(ctx context.Context, entry *TimeEntry) (*TimeEntry, error) {
+	var stopped *TimeEntry
+
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		var running TimeEntry
+		err := tx.Where("running_user_id = ?", entry.UserId).First(&running).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			endedAt := entry.StartedAt
+			err = tx.Model(&running).Updates(map[string]interface{}{"ended_at": endedAt, "running_user_id": nil}).Error
+			if err != nil {
+				return err
+			}
+			running.EndedAt = &endedAt
+			running.RunningUserId = nil
+			stopped = &running
+		}
+
+		userId := entry.UserId
+		entry.RunningUserId = &userId
+
+		return tx.Create(entry).Error
+	})
+	if err != nil {
+		r.logger.Errorw("failed to start timer", "user_id", entry.UserId, "error", err)
+
+		return nil, err
+	}
+
+	return stopped, nil
+}
+
+func (r *repository) StopTimeEntry(ctx context.Context, id uint, endedAt time.Time) error {
+	updates := map[string]interface{}{
+		"ended_at":        endedAt,
+		"running_user_id": nil,
+	}
+
+	result := r.conn(ctx).Model(&TimeEntry{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		r.logger.Errorw("failed to stop timer", "id", id, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) CreateTimeEntry(ctx context.Context, entry *TimeEntry) error {
+	result := r.conn(ctx).Create(entry)
+	if result.Error != nil {
+		r.logger.Errorw("failed to create time entry", "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) GetRunningTimeEntry(ctx context.Context, userId uint) (TimeEntry, error) {
+	var entry TimeEntry
+	result := r.conn(ctx).Where("running_user_id = ?", userId).First(&entry)
+	if result.Error != nil {
+		return TimeEntry{}, result.Error
+	}
+
+	return entry, nil
+}
+
+func (r *repository) GetTimeEntryById(ctx context.Context, id uint) (TimeEntry, error) {
+	var entry TimeEntry
+	result := r.conn(ctx).First(&entry, id)
+	if result.Error != nil {
+		r.logger.Errorw("failed to find time entry by id", "id", id, "error", result.Error)
+
+		return TimeEntry{}, result.Error
+	}
+
+	return entry, nil
+}
+
+func (r *repository) GetTimeEntriesForItem(ctx context.Context, itemId uint) ([]TimeEntry, error) {
+	var entries []TimeEntry
+	result := r.conn(ctx).Where("to_do_item_id = ?", itemId).Order("started_at").Find(&entries)
+	if result.Error != nil {
+		r.logger.Errorw("failed to find time entries for todo item", "id", itemId, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return entries, nil
+}
+
+func (r *repository) DeleteTimeEntry(ctx context.Context, id uint) error {
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&TimeEntry{}).Where("id = ?", id).Update("running_user_id", nil).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&TimeEntry{}, id).Error
+	})
+	if err != nil {
+		r.logger.Errorw("failed to delete time entry", "id", id, "error", err)
+
+		return err
+	}
+
+	return nil
+}
+
+// GetTimeReportByTag : sums the user's time entries started within the query range by
+// tag. An entry counts towards every tag of its todo item, entries of untagged items are
+// grouped under an empty key.
+func (r *repository) GetTimeReportByTag(ctx context.Context, userId uint, query TimeReportQuery) ([]TimeReportGroup, int64, error) {
+	base := r.timeEntriesIn(ctx, userId, query)
+
+	var total struct {
+		Seconds int64
+	}
+	err := base.Session(&gorm.Session{}).Select("COALESCE(SUM(" + entrySecondsExpression + "), 0) AS seconds").Scan(&total).Error
+	if err != nil {
+		r.logger.Errorw("failed to sum time entries", "user_id", userId, "error", err)
+
+		return nil, 0, err
+	}
+
+	var groups []TimeReportGroup
+	err = base.Session(&gorm.Session{}).
+		Joins("LEFT JOIN tags ON tags.to_do_item_id = time_entries.to_do_item_id").
+		Select("COALESCE(tags.name, '') AS `key`, SUM(" + entrySecondsExpression + ") AS seconds, COUNT(*) AS entries").
+		Group("tags.name").
+		Order("seconds DESC").
+		Scan(&groups).Error
+	if err != nil {
+		r.logger.Errorw("failed to group time entries by tag", "user_id", userId, "error", err)
+
+		return nil, 0, err
+	}
+
+	return groups, total.Seconds, nil
+}
+
+// GetEntryDurations : the user's time entries started within the query range, ordered by
+// start. Grouping them by day is left to the caller, which knows the timezone.
+func (r *repository) GetEntryDurations(ctx context.Context, userId uint, query TimeReportQuery) ([]EntryDuration, error) {
+	var entries []EntryDuration
+	err := r.timeEntriesIn(ctx, userId, query).
+		Select("time_entries.started_at, " + entrySecondsExpression + " AS seconds").
+		Order("time_entries.started_at").
+		Scan(&entries).Error
+	if err != nil {
+		r.logger.Errorw("failed to find time entries", "user_id", userId, "error", err)
+
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *repository) timeEntriesIn(ctx context.Context, userId uint, query TimeReportQuery) *gorm.DB {
+	return r.conn(ctx).
+		Model(&TimeEntry{}).
+		Where("time_entries.user_id = ? AND time_entries.started_at >= ? AND time_entries.started_at < ?", userId, query.From, query.To)
+}
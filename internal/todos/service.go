@@ -3,10 +3,15 @@ package todos
 import (
 	"context"
 	"errors"
+	"strings"
+	"sync"
+	"time"
+	"todo-app/pkg/database"
 	"todo-app/pkg/locale"
 
 	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type Service interface {
@@ -15,18 +20,35 @@ type Service interface {
 	GetById(ctx context.Context, id uint) (ToDoItem, error)
 	UpdateById(ctx context.Context, id uint, item ToDoItemUpdateInput) (ToDoItem, error)
 	DeleteById(ctx context.Context, id uint) error
+	QuickAdd(ctx context.Context, userId uint, input QuickAddInput) (QuickAddResponse, error)
+	StartTimer(ctx context.Context, item ToDoItem) (TimerResponse, error)
+	StopTimer(ctx context.Context, item ToDoItem) (TimeEntry, error)
+	GetRunningTimer(ctx context.Context, userId uint) (TimeEntry, error)
+	AddTimeEntry(ctx context.Context, item ToDoItem, input TimeEntryInput) (TimeEntry, error)
+	GetTimeEntries(ctx context.Context, itemId uint) ([]TimeEntry, error)
+	GetTimeEntryById(ctx context.Context, id uint) (TimeEntry, error)
+	DeleteTimeEntry(ctx context.Context, id uint) error
+	GetTimeReport(ctx context.Context, userId uint, query TimeReportQuery) (TimeReport, error)
+	Subscribe(listener Listener)
+	OnDelete(hook DeleteHook)
 }
 
 type service struct {
 	logger     *zap.SugaredLogger
 	repository Repository
+	transactor database.Transactor
 	validator  *validator.Validate
+	mu         sync.RWMutex
+	listeners  []Listener
+	// deleteHooks : run in the transaction of every deletion
+	deleteHooks []DeleteHook
 }
 
-func GetService(logger *zap.SugaredLogger, repo Repository, validator *validator.Validate) Service {
+func GetService(logger *zap.SugaredLogger, repo Repository, transactor database.Transactor, validator *validator.Validate) Service {
 	return &service{
 		logger:     logger,
 		repository: repo,
+		transactor: transactor,
 		validator:  validator,
 	}
 }
@@ -36,7 +58,33 @@ func (s *service) Create(ctx context.Context, item *ToDoItem) error {
 		return err
 	}
 
-	return s.repository.Create(ctx, item)
+	if item.ParentId != nil {
+		parent, err := s.repository.GetById(ctx, *item.ParentId)
+		if err != nil || parent.UserId != item.UserId {
+			return errors.New(locale.ErrorInvalidParent)
+		}
+	}
+
+	names := make([]string, len(item.Tags))
+	for i, tag := range item.Tags {
+		names[i] = tag.Name
+	}
+	item.Tags = nil
+	for _, name := range normalizeTags(names) {
+		item.Tags = append(item.Tags, Tag{Name: name})
+	}
+	if item.Done && item.CompletedAt == nil {
+		now := time.Now().UTC()
+		item.CompletedAt = &now
+	}
+
+	err := s.repository.Create(ctx, item)
+	if err != nil {
+		return err
+	}
+	s.publish(ctx, EventCreated, *item)
+
+	return nil
 }
 
 func (s *service) GetAllForUser(ctx context.Context, userId uint, details PaginationDetails) ([]ToDoItem, PaginationMetadata, error) {
@@ -62,25 +110,314 @@ func (s *service) UpdateById(ctx context.Context, id uint, item ToDoItemUpdateIn
 	}
 	if item.Done != nil {
 		updates["done"] = *item.Done
+		updates["completed_at"] = nil
+		if *item.Done {
+			// keep the original completion time when a done item is marked done again
+			updates["completed_at"] = gorm.Expr("COALESCE(completed_at, UTC_TIMESTAMP())")
+		}
+	}
+	if item.DueAt != nil {
+		updates["due_at"] = *item.DueAt
+	}
+	if item.Priority != nil {
+		if err := s.validator.Var(*item.Priority, "omitempty,oneof=low medium high"); err != nil {
+			return ToDoItem{}, err
+		}
+		updates["priority"] = *item.Priority
+	}
+	if item.Recurrence != nil {
+		updates["recurrence"] = *item.Recurrence
+	}
+	if item.Estimate != nil {
+		if *item.Estimate < 0 {
+			return ToDoItem{}, errors.New(locale.ErrorInvalidEstimate)
+		}
+		updates["estimate"] = *item.Estimate
+	}
+	if item.Type != nil {
+		if err := s.validator.Var(*item.Type, "oneof=task habit"); err != nil {
+			return ToDoItem{}, err
+		}
+		updates["type"] = *item.Type
+	}
+	if item.TargetPerWeek != nil {
+		// 0 clears the target, the habit is then due every day
+		updates["target_per_week"] = nil
+		if *item.TargetPerWeek != 0 {
+			if err := s.validator.Var(*item.TargetPerWeek, "min=1,max=7"); err != nil {
+				return ToDoItem{}, err
+			}
+			updates["target_per_week"] = *item.TargetPerWeek
+		}
+	}
+	if item.Timezone != nil {
+		if err := s.validator.Var(*item.Timezone, "omitempty,timezone"); err != nil {
+			return ToDoItem{}, err
+		}
+		updates["timezone"] = *item.Timezone
 	}
 
-	if len(updates) == 0 {
+	if len(updates) == 0 && item.Tags == nil {
 		return ToDoItem{}, errors.New(locale.ErrorNotFoundUpdates)
 	}
 
-	err := s.repository.Update(ctx, id, updates)
-	if err != nil {
-		return ToDoItem{}, err
+	if len(updates) > 0 {
+		err := s.repository.Update(ctx, id, updates)
+		if err != nil {
+			return ToDoItem{}, err
+		}
+	}
+
+	if item.Tags != nil {
+		err := s.repository.ReplaceTags(ctx, id, normalizeTags(*item.Tags))
+		if err != nil {
+			return ToDoItem{}, err
+		}
 	}
 
 	updatedItem, err := s.repository.GetById(ctx, id)
 	if err != nil {
 		return ToDoItem{}, errors.New(locale.ErrorNotFoundRecord)
 	}
+	s.publish(ctx, EventUpdated, updatedItem)
 
 	return updatedItem, nil
 }
 
 func (s *service) DeleteById(ctx context.Context, id uint) error {
-	return s.repository.Delete(ctx, id)
+	item, err := s.repository.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	hooks := s.deleteHooks
+	s.mu.RUnlock()
+
+	return s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repository.Delete(ctx, id); err != nil {
+			return err
+		}
+		for _, hook := range hooks {
+			if err := hook(ctx, item); err != nil {
+				return err
+			}
+		}
+		s.publish(ctx, EventDeleted, item)
+
+		return nil
+	})
+}
+
+func (s *service) QuickAdd(ctx context.Context, userId uint, input QuickAddInput) (QuickAddResponse, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return QuickAddResponse{}, err
+	}
+
+	loc := time.UTC
+	if input.Timezone != "" {
+		l, err := time.LoadLocation(input.Timezone)
+		if err != nil {
+			return QuickAddResponse{}, errors.New(locale.ErrorInvalidTimezone)
+		}
+		loc = l
+	}
+
+	item, recognized := ParseQuickAdd(input.Text, time.Now(), loc, input.Locale)
+	item.UserId = userId
+
+	if input.DryRun {
+		if err := s.validator.Struct(item); err != nil {
+			return QuickAddResponse{}, err
+		}
+
+		return QuickAddResponse{Item: item, Recognized: recognized, DryRun: true}, nil
+	}
+
+	if err := s.Create(ctx, &item); err != nil {
+		return QuickAddResponse{}, err
+	}
+
+	return QuickAddResponse{Item: item, Recognized: recognized}, nil
+}
+
+// StartTimer : starts a timer on item, stopping the user's running timer first
+func (s *service) StartTimer(ctx context.Context, item ToDoItem) (TimerResponse, error) {
+	entry := TimeEntry{
+		ToDoItemID: item.ID,
+		UserId:     item.UserId,
+		StartedAt:  time.Now().UTC(),
+	}
+
+	stopped, err := s.repository.StartTimeEntry(ctx, &entry)
+	if err != nil {
+		return TimerResponse{}, err
+	}
+
+	return TimerResponse{Started: entry, Stopped: stopped}, nil
+}
+
+func (s *service) StopTimer(ctx context.Context, item ToDoItem) (TimeEntry, error) {
+	entry, err := s.repository.GetRunningTimeEntry(ctx, item.UserId)
+	if err != nil || entry.ToDoItemID != item.ID {
+		return TimeEntry{}, errors.New(locale.ErrorNoRunningTimer)
+	}
+
+	endedAt := time.Now().UTC()
+	err = s.repository.StopTimeEntry(ctx, entry.ID, endedAt)
+	if err != nil {
+		return TimeEntry{}, err
+	}
+	entry.EndedAt = &endedAt
+	entry.RunningUserId = nil
+
+	return entry, nil
+}
+
+func (s *service) GetRunningTimer(ctx context.Context, userId uint) (TimeEntry, error) {
+	entry, err := s.repository.GetRunningTimeEntry(ctx, userId)
+	if err != nil {
+		return TimeEntry{}, errors.New(locale.ErrorNoRunningTimer)
+	}
+
+	return entry, nil
+}
+
+// AddTimeEntry : records time spent on item manually, either with an end time or a duration
+func (s *service) AddTimeEntry(ctx context.Context, item ToDoItem, input TimeEntryInput) (TimeEntry, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return TimeEntry{}, err
+	}
+
+	endedAt := input.EndedAt
+	if endedAt == nil && input.DurationMinutes != nil {
+		end := input.StartedAt.Add(time.Duration(*input.DurationMinutes) * time.Minute)
+		endedAt = &end
+	}
+	if endedAt == nil || !endedAt.After(input.StartedAt) {
+		return TimeEntry{}, errors.New(locale.ErrorInvalidTimeEntry)
+	}
+
+	startedAt := input.StartedAt.UTC()
+	end := endedAt.UTC()
+	entry := TimeEntry{
+		ToDoItemID: item.ID,
+		UserId:     item.UserId,
+		StartedAt:  startedAt,
+		EndedAt:    &end,
+		Note:       input.Note,
+	}
+
+	err := s.repository.CreateTimeEntry(ctx, &entry)
+	if err != nil {
+		return TimeEntry{}, err
+	}
+
+	return entry, nil
+}
+
+func (s *service) GetTimeEntries(ctx context.Context, itemId uint) ([]TimeEntry, error) {
+	return s.repository.GetTimeEntriesForItem(ctx, itemId)
+}
+
+func (s *service) GetTimeEntryById(ctx context.Context, id uint) (TimeEntry, error) {
+	return s.repository.GetTimeEntryById(ctx, id)
+}
+
+func (s *service) DeleteTimeEntry(ctx context.Context, id uint) error {
+	return s.repository.DeleteTimeEntry(ctx, id)
+}
+
+// GetTimeReport : sums the tracked time of the user. Days are those of query.Timezone,
+// like habits count check-ins in the habit's timezone.
+func (s *service) GetTimeReport(ctx context.Context, userId uint, query TimeReportQuery) (TimeReport, error) {
+	if query.GroupBy == "" {
+		query.GroupBy = ReportGroupByDay
+	}
+	if query.GroupBy != ReportGroupByDay && query.GroupBy != ReportGroupByTag {
+		return TimeReport{}, errors.New(locale.ErrorInvalidReportQuery)
+	}
+	if !query.To.After(query.From) {
+		return TimeReport{}, errors.New(locale.ErrorInvalidReportQuery)
+	}
+	loc, err := LoadTimezone(query.Timezone)
+	if err != nil {
+		return TimeReport{}, err
+	}
+
+	var groups []TimeReportGroup
+	var total int64
+	switch query.GroupBy {
+	case ReportGroupByTag:
+		groups, total, err = s.repository.GetTimeReportByTag(ctx, userId, query)
+		if err != nil {
+			return TimeReport{}, err
+		}
+	default:
+		entries, err := s.repository.GetEntryDurations(ctx, userId, query)
+		if err != nil {
+			return TimeReport{}, err
+		}
+		groups, total = groupByDay(entries, loc)
+	}
+	if groups == nil {
+		groups = []TimeReportGroup{}
+	}
+
+	return TimeReport{
+		From:         query.From,
+		To:           query.To,
+		GroupBy:      query.GroupBy,
+		Timezone:     loc.String(),
+		Groups:       groups,
+		TotalSeconds: total,
+	}, nil
+}
+
+// LoadTimezone : the IANA zone of the name, UTC for an empty name. The zone of the
+// server is not a valid choice.
+func LoadTimezone(name string) (*time.Location, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return nil, errors.New(locale.ErrorInvalidTimezone)
+	}
+
+	return loc, nil
+}
+
+// groupByDay : sums entries ordered by start per day in loc, the days in order
+func groupByDay(entries []EntryDuration, loc *time.Location) ([]TimeReportGroup, int64) {
+	var groups []TimeReportGroup
+	var total int64
+	for _, entry := range entries {
+		day := entry.StartedAt.In(loc).Format("2006-01-02")
+		if len(groups) == 0 || groups[len(groups)-1].Key != day {
+			groups = append(groups, TimeReportGroup{Key: day})
+		}
+		groups[len(groups)-1].Seconds += entry.Seconds
+		groups[len(groups)-1].Entries++
+		total += entry.Seconds
+	}
+
+	return groups, total
+}
+
+func normalizeTag(name string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "#"))
+}
+
+// normalizeTags : lowercases, trims and deduplicates tag names, dropping empty ones
+func normalizeTags(names []string) []string {
+	seen := map[string]bool{}
+	tags := []string{}
+	for _, name := range names {
+		tag := normalizeTag(name)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+
+	return tags
 }
@@ -5,6 +5,8 @@ import (
 	"go.uber.org/mock/gomock"
 	"go.uber.org/zap"
 	"testing"
+	"time"
+	"todo-app/pkg/database"
 	"todo-app/pkg/locale"
 
 	"github.com/go-playground/validator/v10"
@@ -12,12 +14,29 @@ import (
 	"gorm.io/gorm"
 )
 
+// noTransaction : a transactor outside of any transaction, events are published right away
+func noTransaction(ctrl *gomock.Controller) database.Transactor {
+	mockTransactor := database.NewMockTransactor(ctrl)
+	mockTransactor.
+		EXPECT().
+		WithTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) }).
+		AnyTimes()
+	mockTransactor.
+		EXPECT().
+		AfterCommit(gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, fn func(ctx context.Context)) { fn(ctx) }).
+		AnyTimes()
+
+	return mockTransactor
+}
+
 func TestService_Login(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockRepo := NewMockRepository(ctrl)
 	v := validator.New()
 	logger := zap.NewNop().Sugar()
-	service := GetService(logger, mockRepo, v)
+	service := GetService(logger, mockRepo, noTransaction(ctrl), v)
 	ctx := context.Background()
 
 	t.Run("successful creation", func(t *testing.T) {
@@ -34,6 +53,21 @@ func TestService_Login(t *testing.T) {
 		ctrl.Finish()
 	})
 
+	t.Run("duplicate tags", func(t *testing.T) {
+		todo := &ToDoItem{Text: "pay rent", Tags: []Tag{{Name: "A"}, {Name: " a "}, {Name: ""}, {Name: "Bills"}}}
+		mockRepo.
+			EXPECT().
+			Create(ctx, todo).
+			Return(nil).
+			Times(1)
+
+		err := service.Create(ctx, todo)
+		assert.NoError(t, err)
+		assert.Equal(t, []Tag{{Name: "a"}, {Name: "bills"}}, todo.Tags)
+
+		ctrl.Finish()
+	})
+
 	t.Run("validation error", func(t *testing.T) {
 		todo := &ToDoItem{Text: ""} // Empty text should fail validation
 
@@ -42,6 +76,22 @@ func TestService_Login(t *testing.T) {
 
 		ctrl.Finish()
 	})
+
+	t.Run("parent of other user", func(t *testing.T) {
+		parentId := uint(4)
+		todo := &ToDoItem{Text: "buy oat milk", UserId: 1, ParentId: &parentId}
+		mockRepo.
+			EXPECT().
+			GetById(ctx, parentId).
+			Return(ToDoItem{UserId: 2}, nil).
+			Times(1)
+
+		err := service.Create(ctx, todo)
+		assert.Error(t, err)
+		assert.Equal(t, locale.ErrorInvalidParent, err.Error())
+
+		ctrl.Finish()
+	})
 }
 
 func TestService_GetAll(t *testing.T) {
@@ -49,7 +99,7 @@ func TestService_GetAll(t *testing.T) {
 	mockRepo := NewMockRepository(ctrl)
 	v := validator.New()
 	logger := zap.NewNop().Sugar()
-	service := GetService(logger, mockRepo, v)
+	service := GetService(logger, mockRepo, noTransaction(ctrl), v)
 	ctx := context.Background()
 
 	expectedTodos := []ToDoItem{
@@ -94,7 +144,7 @@ func TestService_GetById(t *testing.T) {
 	mockRepo := NewMockRepository(ctrl)
 	v := validator.New()
 	logger := zap.NewNop().Sugar()
-	service := GetService(logger, mockRepo, v)
+	service := GetService(logger, mockRepo, noTransaction(ctrl), v)
 	ctx := context.Background()
 
 	t.Run("successful get", func(t *testing.T) {
@@ -133,7 +183,7 @@ func TestService_UpdateById(t *testing.T) {
 	mockRepo := NewMockRepository(ctrl)
 	v := validator.New()
 	logger := zap.NewNop().Sugar()
-	service := GetService(logger, mockRepo, v)
+	service := GetService(logger, mockRepo, noTransaction(ctrl), v)
 	ctx := context.Background()
 
 	t.Run("successful update", func(t *testing.T) {
@@ -172,7 +222,10 @@ func TestService_UpdateById(t *testing.T) {
 
 	t.Run("update fails", func(t *testing.T) {
 		updateInput := ToDoItemUpdateInput{Done: boolPtr(true)}
-		updates := map[string]interface{}{"done": true}
+		updates := map[string]interface{}{
+			"done":         true,
+			"completed_at": gorm.Expr("COALESCE(completed_at, UTC_TIMESTAMP())"),
+		}
 		mockRepo.
 			EXPECT().
 			Update(ctx, uint(1), updates).
@@ -192,10 +245,15 @@ func TestService_DeleteById(t *testing.T) {
 	mockRepo := NewMockRepository(ctrl)
 	v := validator.New()
 	logger := zap.NewNop().Sugar()
-	service := GetService(logger, mockRepo, v)
+	service := GetService(logger, mockRepo, noTransaction(ctrl), v)
 	ctx := context.Background()
 
 	t.Run("successful delete", func(t *testing.T) {
+		mockRepo.
+			EXPECT().
+			GetById(ctx, uint(1)).
+			Return(ToDoItem{Model: gorm.Model{ID: 1}, UserId: 2}, nil).
+			Times(1)
 		mockRepo.
 			EXPECT().
 			Delete(ctx, uint(1)).
@@ -209,6 +267,11 @@ func TestService_DeleteById(t *testing.T) {
 	})
 
 	t.Run("delete fails", func(t *testing.T) {
+		mockRepo.
+			EXPECT().
+			GetById(ctx, uint(1)).
+			Return(ToDoItem{Model: gorm.Model{ID: 1}, UserId: 2}, nil).
+			Times(1)
 		mockRepo.
 			EXPECT().
 			Delete(ctx, uint(1)).
@@ -221,6 +284,369 @@ func TestService_DeleteById(t *testing.T) {
 
 		ctrl.Finish()
 	})
+
+	t.Run("delete hooks get the deleted item", func(t *testing.T) {
+		var deleted []ToDoItem
+		hooked := GetService(logger, mockRepo, noTransaction(ctrl), v)
+		hooked.OnDelete(func(_ context.Context, item ToDoItem) error {
+			deleted = append(deleted, item)
+			return nil
+		})
+		mockRepo.EXPECT().GetById(ctx, uint(1)).Return(ToDoItem{Model: gorm.Model{ID: 1}, UserId: 2}, nil).Times(1)
+		mockRepo.EXPECT().Delete(ctx, uint(1)).Return(nil).Times(1)
+
+		assert.NoError(t, hooked.DeleteById(ctx, 1))
+		assert.Len(t, deleted, 1)
+		assert.Equal(t, uint(1), deleted[0].ID)
+
+		ctrl.Finish()
+	})
+
+	t.Run("failing delete hook fails the deletion", func(t *testing.T) {
+		hooked := GetService(logger, mockRepo, noTransaction(ctrl), v)
+		hooked.OnDelete(func(context.Context, ToDoItem) error { return gorm.ErrInvalidDB })
+		mockRepo.EXPECT().GetById(ctx, uint(1)).Return(ToDoItem{Model: gorm.Model{ID: 1}, UserId: 2}, nil).Times(1)
+		mockRepo.EXPECT().Delete(ctx, uint(1)).Return(nil).Times(1)
+
+		assert.Equal(t, gorm.ErrInvalidDB, hooked.DeleteById(ctx, 1))
+
+		ctrl.Finish()
+	})
+}
+
+func TestService_Subscribe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	v := validator.New()
+	logger := zap.NewNop().Sugar()
+	mockTransactor := database.NewMockTransactor(ctrl)
+	service := GetService(logger, mockRepo, mockTransactor, v)
+	ctx := context.Background()
+
+	var events []Event
+	service.Subscribe(func(_ context.Context, event Event) {
+		events = append(events, event)
+	})
+
+	t.Run("create and delete are published", func(t *testing.T) {
+		todo := &ToDoItem{Text: "buy milk", UserId: 2, Done: true}
+		mockTransactor.
+			EXPECT().
+			AfterCommit(ctx, gomock.Any()).
+			Do(func(ctx context.Context, fn func(ctx context.Context)) { fn(ctx) }).
+			Times(2)
+		mockTransactor.
+			EXPECT().
+			WithTransaction(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) }).
+			Times(1)
+		mockRepo.EXPECT().Create(ctx, todo).Return(nil).Times(1)
+		mockRepo.EXPECT().GetById(ctx, uint(1)).Return(ToDoItem{Model: gorm.Model{ID: 1}, UserId: 2}, nil).Times(1)
+		mockRepo.EXPECT().Delete(ctx, uint(1)).Return(nil).Times(1)
+
+		assert.NoError(t, service.Create(ctx, todo))
+		assert.NotNil(t, todo.CompletedAt)
+		assert.NoError(t, service.DeleteById(ctx, 1))
+
+		assert.Len(t, events, 2)
+		assert.Equal(t, EventCreated, events[0].Type)
+		assert.Equal(t, uint(2), events[0].UserId)
+		assert.Equal(t, EventDeleted, events[1].Type)
+		assert.Equal(t, uint(1), events[1].Item.ID)
+
+		ctrl.Finish()
+	})
+
+	t.Run("events wait for the commit", func(t *testing.T) {
+		events = nil
+		todo := &ToDoItem{Text: "buy milk", UserId: 2}
+		var afterCommit func(ctx context.Context)
+		mockTransactor.
+			EXPECT().
+			AfterCommit(ctx, gomock.Any()).
+			Do(func(_ context.Context, fn func(ctx context.Context)) { afterCommit = fn }).
+			Times(1)
+		mockRepo.EXPECT().Create(ctx, todo).Return(nil).Times(1)
+
+		assert.NoError(t, service.Create(ctx, todo))
+		assert.Empty(t, events)
+
+		afterCommit(ctx)
+		assert.Len(t, events, 1)
+		assert.Equal(t, EventCreated, events[0].Type)
+
+		ctrl.Finish()
+	})
+
+	t.Run("failed writes are not published", func(t *testing.T) {
+		events = nil
+		mockRepo.EXPECT().GetById(ctx, uint(1)).Return(ToDoItem{}, gorm.ErrRecordNotFound).Times(1)
+
+		assert.Error(t, service.DeleteById(ctx, 1))
+		assert.Empty(t, events)
+
+		ctrl.Finish()
+	})
+}
+
+func TestService_QuickAdd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	v := validator.New()
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockRepo, noTransaction(ctrl), v)
+	ctx := context.Background()
+
+	t.Run("creates parsed item", func(t *testing.T) {
+		mockRepo.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, item *ToDoItem) error {
+				assert.Equal(t, "Pay rent", item.Text)
+				assert.Equal(t, uint(7), item.UserId)
+				assert.Equal(t, PriorityHigh, item.Priority)
+				assert.Equal(t, []Tag{{Name: "finance"}}, item.Tags)
+				return nil
+			}).
+			Times(1)
+
+		response, err := service.QuickAdd(ctx, 7, QuickAddInput{Text: "Pay rent #finance !high", Timezone: "Europe/Berlin"})
+		assert.NoError(t, err)
+		assert.False(t, response.DryRun)
+		assert.Len(t, response.Recognized, 2)
+
+		ctrl.Finish()
+	})
+
+	t.Run("dry run does not create", func(t *testing.T) {
+		response, err := service.QuickAdd(ctx, 7, QuickAddInput{Text: "Pay rent every month on the 1st", DryRun: true})
+		assert.NoError(t, err)
+		assert.True(t, response.DryRun)
+		assert.Equal(t, "FREQ=MONTHLY;BYMONTHDAY=1", response.Item.Recurrence)
+		assert.NotNil(t, response.Item.DueAt)
+
+		ctrl.Finish()
+	})
+
+	t.Run("invalid timezone", func(t *testing.T) {
+		_, err := service.QuickAdd(ctx, 7, QuickAddInput{Text: "Pay rent", Timezone: "Mars/Olympus"})
+		assert.Error(t, err)
+		assert.Equal(t, locale.ErrorInvalidTimezone, err.Error())
+
+		ctrl.Finish()
+	})
+
+	t.Run("nothing left for the text", func(t *testing.T) {
+		_, err := service.QuickAdd(ctx, 7, QuickAddInput{Text: "#finance tomorrow", DryRun: true})
+		assert.Error(t, err)
+
+		ctrl.Finish()
+	})
+}
+
+func TestService_Timer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	v := validator.New()
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockRepo, noTransaction(ctrl), v)
+	ctx := context.Background()
+
+	item := ToDoItem{Model: gorm.Model{ID: 3}, UserId: 7}
+
+	t.Run("start stops running timer", func(t *testing.T) {
+		running := &TimeEntry{Model: gorm.Model{ID: 1}, ToDoItemID: 2, UserId: 7}
+		mockRepo.
+			EXPECT().
+			StartTimeEntry(ctx, gomock.Any()).
+			Return(running, nil).
+			Times(1)
+
+		response, err := service.StartTimer(ctx, item)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(3), response.Started.ToDoItemID)
+		assert.Equal(t, uint(7), response.Started.UserId)
+		assert.Equal(t, running, response.Stopped)
+
+		ctrl.Finish()
+	})
+
+	t.Run("stop timer of other item", func(t *testing.T) {
+		mockRepo.
+			EXPECT().
+			GetRunningTimeEntry(ctx, uint(7)).
+			Return(TimeEntry{Model: gorm.Model{ID: 1}, ToDoItemID: 2, UserId: 7}, nil).
+			Times(1)
+
+		_, err := service.StopTimer(ctx, item)
+		assert.Error(t, err)
+		assert.Equal(t, locale.ErrorNoRunningTimer, err.Error())
+
+		ctrl.Finish()
+	})
+
+	t.Run("stop running timer", func(t *testing.T) {
+		mockRepo.
+			EXPECT().
+			GetRunningTimeEntry(ctx, uint(7)).
+			Return(TimeEntry{Model: gorm.Model{ID: 1}, ToDoItemID: 3, UserId: 7}, nil).
+			Times(1)
+		mockRepo.
+			EXPECT().
+			StopTimeEntry(ctx, uint(1), gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		entry, err := service.StopTimer(ctx, item)
+		assert.NoError(t, err)
+		assert.NotNil(t, entry.EndedAt)
+
+		ctrl.Finish()
+	})
+}
+
+func TestService_AddTimeEntry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	v := validator.New()
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockRepo, noTransaction(ctrl), v)
+	ctx := context.Background()
+
+	item := ToDoItem{Model: gorm.Model{ID: 3}, UserId: 7}
+	start := time.Date(2025, time.March, 3, 9, 0, 0, 0, time.UTC)
+
+	t.Run("entry with duration", func(t *testing.T) {
+		duration := 90
+		mockRepo.
+			EXPECT().
+			CreateTimeEntry(ctx, gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		entry, err := service.AddTimeEntry(ctx, item, TimeEntryInput{StartedAt: start, DurationMinutes: &duration, Note: "review"})
+		assert.NoError(t, err)
+		assert.Equal(t, start.Add(90*time.Minute), *entry.EndedAt)
+		assert.Equal(t, uint(3), entry.ToDoItemID)
+		assert.Equal(t, "review", entry.Note)
+
+		ctrl.Finish()
+	})
+
+	t.Run("end before start", func(t *testing.T) {
+		end := start.Add(-time.Minute)
+
+		_, err := service.AddTimeEntry(ctx, item, TimeEntryInput{StartedAt: start, EndedAt: &end})
+		assert.Error(t, err)
+		assert.Equal(t, locale.ErrorInvalidTimeEntry, err.Error())
+
+		ctrl.Finish()
+	})
+
+	t.Run("neither end nor duration", func(t *testing.T) {
+		_, err := service.AddTimeEntry(ctx, item, TimeEntryInput{StartedAt: start})
+		assert.Error(t, err)
+
+		ctrl.Finish()
+	})
+}
+
+func TestService_GetTimeReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	v := validator.New()
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockRepo, noTransaction(ctrl), v)
+	ctx := context.Background()
+
+	from := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+
+	t.Run("defaults to group by day", func(t *testing.T) {
+		query := TimeReportQuery{From: from, To: to, GroupBy: ReportGroupByDay}
+		mockRepo.
+			EXPECT().
+			GetEntryDurations(ctx, uint(7), query).
+			Return([]EntryDuration{
+				{StartedAt: from.Add(9 * time.Hour), Seconds: 1800},
+				{StartedAt: from.Add(23 * time.Hour), Seconds: 1800},
+				{StartedAt: from.Add(33 * time.Hour), Seconds: 600},
+			}, nil).
+			Times(1)
+
+		report, err := service.GetTimeReport(ctx, 7, TimeReportQuery{From: from, To: to})
+		assert.NoError(t, err)
+		assert.Equal(t, ReportGroupByDay, report.GroupBy)
+		assert.Equal(t, "UTC", report.Timezone)
+		assert.Equal(t, []TimeReportGroup{
+			{Key: "2025-03-01", Seconds: 3600, Entries: 2},
+			{Key: "2025-03-02", Seconds: 600, Entries: 1},
+		}, report.Groups)
+		assert.Equal(t, int64(4200), report.TotalSeconds)
+
+		ctrl.Finish()
+	})
+
+	t.Run("days of the timezone", func(t *testing.T) {
+		query := TimeReportQuery{From: from, To: to, GroupBy: ReportGroupByDay, Timezone: "Europe/Berlin"}
+		// 23:00 UTC is midnight in Berlin
+		mockRepo.
+			EXPECT().
+			GetEntryDurations(ctx, uint(7), query).
+			Return([]EntryDuration{
+				{StartedAt: from.Add(9 * time.Hour), Seconds: 1800},
+				{StartedAt: from.Add(23 * time.Hour), Seconds: 1800},
+			}, nil).
+			Times(1)
+
+		report, err := service.GetTimeReport(ctx, 7, query)
+		assert.NoError(t, err)
+		assert.Equal(t, "Europe/Berlin", report.Timezone)
+		assert.Equal(t, []TimeReportGroup{
+			{Key: "2025-03-01", Seconds: 1800, Entries: 1},
+			{Key: "2025-03-02", Seconds: 1800, Entries: 1},
+		}, report.Groups)
+
+		ctrl.Finish()
+	})
+
+	t.Run("group by tag", func(t *testing.T) {
+		query := TimeReportQuery{From: from, To: to, GroupBy: ReportGroupByTag}
+		groups := []TimeReportGroup{{Key: "work", Seconds: 3600, Entries: 2}}
+		mockRepo.EXPECT().GetTimeReportByTag(ctx, uint(7), query).Return(groups, int64(3600), nil).Times(1)
+
+		report, err := service.GetTimeReport(ctx, 7, query)
+		assert.NoError(t, err)
+		assert.Equal(t, groups, report.Groups)
+		assert.Equal(t, int64(3600), report.TotalSeconds)
+
+		ctrl.Finish()
+	})
+
+	t.Run("invalid timezone", func(t *testing.T) {
+		for _, timezone := range []string{"Mars/Olympus", "Local"} {
+			_, err := service.GetTimeReport(ctx, 7, TimeReportQuery{From: from, To: to, Timezone: timezone})
+			assert.Error(t, err)
+			assert.Equal(t, locale.ErrorInvalidTimezone, err.Error())
+		}
+
+		ctrl.Finish()
+	})
+
+	t.Run("invalid group by", func(t *testing.T) {
+		_, err := service.GetTimeReport(ctx, 7, TimeReportQuery{From: from, To: to, GroupBy: "week"})
+		assert.Error(t, err)
+		assert.Equal(t, locale.ErrorInvalidReportQuery, err.Error())
+
+		ctrl.Finish()
+	})
+
+	t.Run("empty range", func(t *testing.T) {
+		_, err := service.GetTimeReport(ctx, 7, TimeReportQuery{From: to, To: from})
+		assert.Error(t, err)
+
+		ctrl.Finish()
+	})
 }
 
 // Helper functions for creating pointers
@@ -0,0 +1,407 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var csvColumns = []string{
+	"id", "external_id", "text", "done", "completed_at", "due_at", "priority",
+	"recurrence", "estimate", "type", "target_per_week", "timezone", "tags", "created_at",
+}
+
+// maxLineSize : the longest NDJSON line accepted on import
+const maxLineSize = 1 << 20
+
+// encoder : writes records in one of the export formats, Begin and End frame the output
+type encoder interface {
+	Begin() error
+	Encode(record Record) error
+	End() error
+}
+
+// decoder : reads records one at a time, returning io.EOF after the last one. A
+// rowError means only the current record was unreadable and decoding can go on.
+type decoder interface {
+	Next() (Record, error)
+}
+
+type rowError struct {
+	err error
+}
+
+func (e rowError) Error() string {
+	return e.err.Error()
+}
+
+func newEncoder(format string, w io.Writer) (encoder, error) {
+	switch format {
+	case FormatCSV:
+		return &csvEncoder{w: csv.NewWriter(w)}, nil
+	case FormatJSON:
+		return &jsonEncoder{w: w}, nil
+	case FormatNDJSON:
+		return &ndjsonEncoder{encoder: json.NewEncoder(w)}, nil
+	}
+
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+func newDecoder(format string, r io.Reader) (decoder, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVDecoder(r)
+	case FormatJSON:
+		return newJSONDecoder(r)
+	case FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+		return &ndjsonDecoder{scanner: scanner}, nil
+	}
+
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// ContentType : the MIME type of an export format
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	}
+
+	return "application/json"
+}
+
+// FileExtension : the extension of an exported file
+func FileExtension(format string) string {
+	if format == FormatMarkdown {
+		return "md"
+	}
+
+	return format
+}
+
+// FormatFromContentType : the import format matching a request content type, json
+// when it is not recognized
+func FormatFromContentType(contentType string) string {
+	switch {
+	case strings.HasPrefix(contentType, "text/csv"):
+		return FormatCSV
+	case strings.HasPrefix(contentType, "application/x-ndjson"):
+		return FormatNDJSON
+	}
+
+	return FormatJSON
+}
+
+func flush(w io.Writer) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvEncoder) Begin() error {
+	return e.w.Write(csvColumns)
+}
+
+func (e *csvEncoder) Encode(record Record) error {
+	return e.w.Write([]string{
+		formatUint(record.ID),
+		record.ExternalId,
+		record.Text,
+		strconv.FormatBool(record.Done),
+		formatTime(record.CompletedAt),
+		formatTime(record.DueAt),
+		record.Priority,
+		record.Recurrence,
+		formatInt(record.Estimate),
+		record.Type,
+		formatInt(record.TargetPerWeek),
+		record.Timezone,
+		strings.Join(record.Tags, ","),
+		formatTime(record.CreatedAt),
+	})
+}
+
+func (e *csvEncoder) End() error {
+	e.w.Flush()
+
+	return e.w.Error()
+}
+
+type jsonEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonEncoder) Begin() error {
+	_, err := io.WriteString(e.w, "[")
+
+	return err
+}
+
+func (e *jsonEncoder) Encode(record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if e.count > 0 {
+		data = append([]byte(","), data...)
+	}
+	e.count++
+
+	_, err = e.w.Write(data)
+
+	return err
+}
+
+func (e *jsonEncoder) End() error {
+	_, err := io.WriteString(e.w, "]\n")
+
+	return err
+}
+
+type ndjsonEncoder struct {
+	encoder *json.Encoder
+}
+
+func (e *ndjsonEncoder) Begin() error {
+	return nil
+}
+
+func (e *ndjsonEncoder) Encode(record Record) error {
+	return e.encoder.Encode(record)
+}
+
+func (e *ndjsonEncoder) End() error {
+	return nil
+}
+
+type csvDecoder struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+func newCSVDecoder(r io.Reader) (decoder, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("could not read csv header: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["text"]; !ok {
+		return nil, errors.New("csv header has no text column")
+	}
+
+	return &csvDecoder{r: reader, columns: columns}, nil
+}
+
+func (d *csvDecoder) Next() (Record, error) {
+	row, err := d.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return Record{}, rowError{err}
+		}
+
+		return Record{}, err
+	}
+
+	field := func(name string) string {
+		i, ok := d.columns[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+
+		return strings.TrimSpace(row[i])
+	}
+
+	record := Record{
+		ExternalId: field("external_id"),
+		Text:       field("text"),
+		Priority:   field("priority"),
+		Recurrence: field("recurrence"),
+		Type:       field("type"),
+		Timezone:   field("timezone"),
+	}
+	if tags := field("tags"); tags != "" {
+		for _, tag := range strings.Split(tags, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				record.Tags = append(record.Tags, tag)
+			}
+		}
+	}
+
+	if value := field("done"); value != "" {
+		record.Done, err = strconv.ParseBool(value)
+		if err != nil {
+			return Record{}, rowError{fmt.Errorf("done: %w", err)}
+		}
+	}
+	if record.CompletedAt, err = parseTime(field("completed_at")); err != nil {
+		return Record{}, rowError{fmt.Errorf("completed_at: %w", err)}
+	}
+	if record.DueAt, err = parseTime(field("due_at")); err != nil {
+		return Record{}, rowError{fmt.Errorf("due_at: %w", err)}
+	}
+	if record.Estimate, err = parseInt(field("estimate")); err != nil {
+		return Record{}, rowError{fmt.Errorf("estimate: %w", err)}
+	}
+	if record.TargetPerWeek, err = parseInt(field("target_per_week")); err != nil {
+		return Record{}, rowError{fmt.Errorf("target_per_week: %w", err)}
+	}
+
+	return record, nil
+}
+
+type jsonDecoder struct {
+	decoder *json.Decoder
+}
+
+func newJSONDecoder(r io.Reader) (decoder, error) {
+	decoder := json.NewDecoder(r)
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, fmt.Errorf("could not read json: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, errors.New("json import must be an array of items")
+	}
+
+	return &jsonDecoder{decoder: decoder}, nil
+}
+
+func (d *jsonDecoder) Next() (Record, error) {
+	if !d.decoder.More() {
+		return Record{}, io.EOF
+	}
+
+	var record Record
+	err := d.decoder.Decode(&record)
+	if err != nil {
+		// the decoder reads the whole value before unmarshaling it, only broken json
+		// stops the import
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return Record{}, err
+		}
+
+		return Record{}, rowError{err}
+	}
+
+	return record, nil
+}
+
+type ndjsonDecoder struct {
+	scanner *bufio.Scanner
+}
+
+func (d *ndjsonDecoder) Next() (Record, error) {
+	for d.scanner.Scan() {
+		line := strings.TrimSpace(d.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var record Record
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return Record{}, rowError{err}
+		}
+
+		return record, nil
+	}
+	if err := d.scanner.Err(); err != nil {
+		return Record{}, err
+	}
+
+	return Record{}, io.EOF
+}
+
+type sliceDecoder struct {
+	records []Record
+}
+
+func (d *sliceDecoder) Next() (Record, error) {
+	if len(d.records) == 0 {
+		return Record{}, io.EOF
+	}
+	record := d.records[0]
+	d.records = d.records[1:]
+
+	return record, nil
+}
+
+func formatUint(value uint) string {
+	if value == 0 {
+		return ""
+	}
+
+	return strconv.FormatUint(uint64(value), 10)
+}
+
+func formatInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+
+	return strconv.Itoa(*value)
+}
+
+func formatTime(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+
+	return value.UTC().Format(time.RFC3339)
+}
+
+func parseInt(value string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &i, nil
+}
+
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,197 @@
+package transfer
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"todo-app/internal/auth"
+	e "todo-app/pkg/errors"
+	"todo-app/pkg/handlers"
+	"todo-app/pkg/locale"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type endpointHandler struct {
+	logger  *zap.SugaredLogger
+	service Service
+	e       *echo.Echo
+}
+
+func GetEndpointHandler(
+	logger *zap.SugaredLogger,
+	service Service,
+	e *echo.Echo,
+) handlers.EndpointHandler {
+	return &endpointHandler{
+		logger:  logger,
+		service: service,
+		e:       e,
+	}
+}
+
+func (h *endpointHandler) AddEndpoints() {
+	var endpoints = []handlers.Endpoint{
+		{
+			Method:  http.MethodGet,
+			Path:    "/todos/export",
+			Handler: h.export,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/todos/import",
+			Handler: h.importTodos,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/todos/import/markdown",
+			Handler: h.importMarkdown,
+		},
+	}
+
+	for _, endpoint := range endpoints {
+		handlers.Method(h.e, endpoint.Method, endpoint.Path, endpoint.Handler)
+	}
+}
+
+// @Summary Export todos
+// @Description This endpoint streams all todo items of the current user as csv, a json array or ndjson,
+// @Description or writes them as a Markdown task list with sub-tasks indented below their parent
+// @Tags transfer
+// @ID exportTodos
+// @Security BearerAuth
+// @Produce json
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce text/markdown
+// @Param format query string false "csv, json (default), ndjson or markdown"
+// @Success 200 {array} Record
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Router /todos/export [get]
+func (h *endpointHandler) export(ctx echo.Context) error {
+	h.logger.Infow("exporting todos...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	format := ctx.QueryParam("format")
+	if format == "" {
+		format = FormatJSON
+	}
+	if format != FormatCSV && format != FormatJSON && format != FormatNDJSON && format != FormatMarkdown {
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidTransferFormat})
+	}
+
+	response := ctx.Response()
+	response.Header().Set(echo.HeaderContentType, ContentType(format))
+	response.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", "todos."+FileExtension(format)))
+	response.WriteHeader(http.StatusOK)
+
+	err := h.service.Export(ctx.Request().Context(), userId, format, response)
+	if err != nil {
+		// the status is already sent, the client sees a truncated file
+		h.logger.Errorw("could not export todos", "user_id", userId, "error", err)
+	}
+
+	return nil
+}
+
+// @Summary Import todos
+// @Description This endpoint imports todo items from csv, a json array or ndjson, in the format produced by the export.
+// @Description Every row is validated on its own, invalid rows are reported and skipped.
+// @Description In upsert mode rows update the item with the same external_id and create it otherwise,
+// @Description so importing the same file twice gives the same result.
+// @Tags transfer
+// @ID importTodos
+// @Security BearerAuth
+// @Accept json
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param format query string false "csv, json or ndjson, taken from the Content-Type by default"
+// @Param mode query string false "create (default) or upsert"
+// @Param dry_run query bool false "Only validate and report"
+// @Success 200 {object} ImportResult
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Router /todos/import [post]
+func (h *endpointHandler) importTodos(ctx echo.Context) error {
+	h.logger.Infow("importing todos...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	options := ImportOptions{
+		Format: ctx.QueryParam("format"),
+		Mode:   ctx.QueryParam("mode"),
+	}
+	if options.Format == "" {
+		options.Format = FormatFromContentType(ctx.Request().Header.Get(echo.HeaderContentType))
+	}
+	if options.Format != FormatCSV && options.Format != FormatJSON && options.Format != FormatNDJSON {
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidTransferFormat})
+	}
+	if dryRun := ctx.QueryParam("dry_run"); dryRun != "" {
+		value, err := strconv.ParseBool(dryRun)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+		}
+		options.DryRun = value
+	}
+
+	result, err := h.service.Import(ctx.Request().Context(), userId, ctx.Request().Body, options)
+	if err != nil {
+		h.logger.Warn("could not import todos", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidImport, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// @Summary Import Markdown todos
+// @Description This endpoint creates a todo item for every task of the GitHub task lists (- [ ] / - [x]) in a Markdown document.
+// @Description Nested tasks become sub-tasks of the task they are indented below and #tags at the end of a task become tags.
+// @Description Other lines are ignored. Errors are reported with the line of the task.
+// @Tags transfer
+// @ID importMarkdown
+// @Security BearerAuth
+// @Accept text/markdown
+// @Produce json
+// @Param dry_run query bool false "Only validate and report"
+// @Success 200 {object} ImportResult
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Router /todos/import/markdown [post]
+func (h *endpointHandler) importMarkdown(ctx echo.Context) error {
+	h.logger.Infow("importing markdown todos...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	dryRun := false
+	if value := ctx.QueryParam("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+		}
+		dryRun = parsed
+	}
+
+	result, err := h.service.ImportMarkdown(ctx.Request().Context(), userId, ctx.Request().Body, dryRun)
+	if err != nil {
+		h.logger.Warn("could not import markdown todos", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidImport, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
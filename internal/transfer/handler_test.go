@@ -0,0 +1,169 @@
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"todo-app/pkg/locale"
+
+	localErr "todo-app/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestHandler_Export(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	t.Run("ndjson export", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/todos/export?format=ndjson", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+
+		mockService.
+			EXPECT().
+			Export(ctx.Request().Context(), uint(1), FormatNDJSON, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uint, _ string, w io.Writer) error {
+				_, err := io.WriteString(w, `{"text":"Buy milk","done":false}`+"\n")
+				return err
+			}).
+			Times(1)
+
+		if assert.NoError(t, h.export(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "application/x-ndjson", rec.Header().Get(echo.HeaderContentType))
+			assert.Equal(t, `attachment; filename="todos.ndjson"`, rec.Header().Get(echo.HeaderContentDisposition))
+			assert.Equal(t, `{"text":"Buy milk","done":false}`+"\n", rec.Body.String())
+		}
+	})
+
+	t.Run("markdown export", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/todos/export?format=markdown", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+
+		mockService.
+			EXPECT().
+			Export(ctx.Request().Context(), uint(1), FormatMarkdown, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uint, _ string, w io.Writer) error {
+				_, err := io.WriteString(w, "- [ ] Buy milk\n")
+				return err
+			}).
+			Times(1)
+
+		if assert.NoError(t, h.export(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "text/markdown; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+			assert.Equal(t, `attachment; filename="todos.md"`, rec.Header().Get(echo.HeaderContentDisposition))
+			assert.Equal(t, "- [ ] Buy milk\n", rec.Body.String())
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/todos/export?format=xml", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+
+		if assert.NoError(t, h.export(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var responseError localErr.ResponseError
+			err := json.Unmarshal(rec.Body.Bytes(), &responseError)
+			assert.NoError(t, err)
+
+			assert.Equal(t, locale.ErrorInvalidTransferFormat, responseError.Message)
+		}
+	})
+}
+
+func TestHandler_Import(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	req := httptest.NewRequest(http.MethodPost, "/todos/import?mode=upsert&dry_run=true", strings.NewReader("external_id,text\na,Buy milk\n"))
+	req.Header.Set(echo.HeaderContentType, "text/csv")
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.Set("user_id", uint(1))
+
+	mockService.
+		EXPECT().
+		Import(ctx.Request().Context(), uint(1), gomock.Any(), ImportOptions{Format: FormatCSV, Mode: ModeUpsert, DryRun: true}).
+		Return(ImportResult{DryRun: true, Mode: ModeUpsert, Created: 1, Errors: []RowError{}}, nil).
+		Times(1)
+
+	if assert.NoError(t, h.importTodos(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response ImportResult
+		err := json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 1, response.Created)
+		assert.True(t, response.DryRun)
+	}
+}
+
+func TestHandler_ImportMarkdown(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	t.Run("imports the task list", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/todos/import/markdown?dry_run=true", strings.NewReader("- [ ] Buy milk\n"))
+		req.Header.Set(echo.HeaderContentType, "text/markdown")
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+
+		mockService.
+			EXPECT().
+			ImportMarkdown(ctx.Request().Context(), uint(1), gomock.Any(), true).
+			Return(ImportResult{DryRun: true, Mode: ModeCreate, Created: 1, Errors: []RowError{}}, nil).
+			Times(1)
+
+		if assert.NoError(t, h.importMarkdown(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var response ImportResult
+			err := json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, response.Created)
+		}
+	})
+
+	t.Run("invalid dry_run", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/todos/import/markdown?dry_run=maybe", strings.NewReader(""))
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+
+		if assert.NoError(t, h.importMarkdown(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	ctrl.Finish()
+}
@@ -0,0 +1,104 @@
+package transfer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"todo-app/internal/todos"
+)
+
+// markdownIndent : the indentation of one nesting level in exported task lists
+const markdownIndent = "  "
+
+// taskLine : a GitHub task list item, "- [ ] text", "* [x] text" or "1. [ ] text"
+var taskLine = regexp.MustCompile(`^([ \t]*)(?:[-*+]|\d+[.)])[ \t]+\[([ xX])\](?:[ \t]+(.*))?$`)
+
+// markdownTask : a task read from a task list. Parent is the index of the task it is
+// nested in, -1 for top-level tasks.
+type markdownTask struct {
+	Line   int
+	Parent int
+	Record Record
+}
+
+// parseMarkdown : the tasks of all task lists in a Markdown document. A task indented
+// deeper than the task before it is nested in it; other lines are ignored.
+func parseMarkdown(r io.Reader) ([]markdownTask, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	var tasks []markdownTask
+	// open : the indices of the tasks the next one may be nested in, outermost first
+	var open []int
+	indents := map[int]int{}
+	for line := 1; scanner.Scan(); line++ {
+		match := taskLine.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+
+		indent := indentWidth(match[1])
+		for len(open) > 0 && indents[open[len(open)-1]] >= indent {
+			open = open[:len(open)-1]
+		}
+		parent := -1
+		if len(open) > 0 {
+			parent = open[len(open)-1]
+		}
+
+		text, tags := splitHashtags(match[3])
+		tasks = append(tasks, markdownTask{
+			Line:   line,
+			Parent: parent,
+			Record: Record{Text: text, Done: match[2] != " ", Tags: tags},
+		})
+		indents[len(tasks)-1] = indent
+		open = append(open, len(tasks)-1)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// indentWidth : the width of leading whitespace, a tab counts as four spaces
+func indentWidth(indent string) int {
+	return len(strings.ReplaceAll(indent, "\t", "    "))
+}
+
+// splitHashtags : the text without the #tags at its end, and the tags
+func splitHashtags(text string) (string, []string) {
+	words := strings.Fields(text)
+	end := len(words)
+	for end > 0 && len(words[end-1]) > 1 && strings.HasPrefix(words[end-1], "#") {
+		end--
+	}
+
+	var tags []string
+	for _, word := range words[end:] {
+		tags = append(tags, strings.TrimPrefix(word, "#"))
+	}
+
+	return strings.Join(words[:end], " "), tags
+}
+
+// writeMarkdownTask : writes the item as a task, indented depth levels. Tags follow the
+// text as #tags, those containing spaces are left out since they could not be read back.
+func writeMarkdownTask(w io.Writer, item todos.ToDoItem, depth int) error {
+	check := " "
+	if item.Done {
+		check = "x"
+	}
+	line := strings.Join(strings.Fields(item.Text), " ")
+	for _, tag := range item.Tags {
+		if tag.Name != "" && !strings.ContainsAny(tag.Name, " \t") {
+			line += " #" + tag.Name
+		}
+	}
+	_, err := fmt.Fprintf(w, "%s- [%s] %s\n", strings.Repeat(markdownIndent, depth), check, line)
+
+	return err
+}
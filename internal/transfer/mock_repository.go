@@ -0,0 +1,115 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/transfer/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/transfer/repository.go -destination=internal/transfer/mock_repository.go -package=transfer
+//
+
+// Package transfer is a generated GoMock package.
+package transfer
+
+import (
+	context "context"
+	reflect "reflect"
+	todos "todo-app/internal/todos"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ExportForUser mocks base method.
+func (m *MockRepository) ExportForUser(ctx context.Context, userId uint, fn func([]todos.ToDoItem) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportForUser", ctx, userId, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportForUser indicates an expected call of ExportForUser.
+func (mr *MockRepositoryMockRecorder) ExportForUser(ctx, userId, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportForUser", reflect.TypeOf((*MockRepository)(nil).ExportForUser), ctx, userId, fn)
+}
+
+// ExportRootsForUser mocks base method.
+func (m *MockRepository) ExportRootsForUser(ctx context.Context, userId uint, fn func([]todos.ToDoItem) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportRootsForUser", ctx, userId, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportRootsForUser indicates an expected call of ExportRootsForUser.
+func (mr *MockRepositoryMockRecorder) ExportRootsForUser(ctx, userId, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportRootsForUser", reflect.TypeOf((*MockRepository)(nil).ExportRootsForUser), ctx, userId, fn)
+}
+
+// GetByExternalId mocks base method.
+func (m *MockRepository) GetByExternalId(ctx context.Context, userId uint, externalId string) (todos.ToDoItem, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByExternalId", ctx, userId, externalId)
+	ret0, _ := ret[0].(todos.ToDoItem)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetByExternalId indicates an expected call of GetByExternalId.
+func (mr *MockRepositoryMockRecorder) GetByExternalId(ctx, userId, externalId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByExternalId", reflect.TypeOf((*MockRepository)(nil).GetByExternalId), ctx, userId, externalId)
+}
+
+// GetChildren mocks base method.
+func (m *MockRepository) GetChildren(ctx context.Context, userId, parentId uint) ([]todos.ToDoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChildren", ctx, userId, parentId)
+	ret0, _ := ret[0].([]todos.ToDoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChildren indicates an expected call of GetChildren.
+func (mr *MockRepositoryMockRecorder) GetChildren(ctx, userId, parentId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChildren", reflect.TypeOf((*MockRepository)(nil).GetChildren), ctx, userId, parentId)
+}
+
+// Restore mocks base method.
+func (m *MockRepository) Restore(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockRepositoryMockRecorder) Restore(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockRepository)(nil).Restore), ctx, id)
+}
@@ -0,0 +1,101 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/transfer/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/transfer/service.go -destination=internal/transfer/mock_service.go -package=transfer
+//
+
+// Package transfer is a generated GoMock package.
+package transfer
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// Export mocks base method.
+func (m *MockService) Export(ctx context.Context, userId uint, format string, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, userId, format, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockServiceMockRecorder) Export(ctx, userId, format, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockService)(nil).Export), ctx, userId, format, w)
+}
+
+// Import mocks base method.
+func (m *MockService) Import(ctx context.Context, userId uint, r io.Reader, options ImportOptions) (ImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", ctx, userId, r, options)
+	ret0, _ := ret[0].(ImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockServiceMockRecorder) Import(ctx, userId, r, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockService)(nil).Import), ctx, userId, r, options)
+}
+
+// ImportMarkdown mocks base method.
+func (m *MockService) ImportMarkdown(ctx context.Context, userId uint, r io.Reader, dryRun bool) (ImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportMarkdown", ctx, userId, r, dryRun)
+	ret0, _ := ret[0].(ImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportMarkdown indicates an expected call of ImportMarkdown.
+func (mr *MockServiceMockRecorder) ImportMarkdown(ctx, userId, r, dryRun any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportMarkdown", reflect.TypeOf((*MockService)(nil).ImportMarkdown), ctx, userId, r, dryRun)
+}
+
+// ImportRecords mocks base method.
+func (m *MockService) ImportRecords(ctx context.Context, userId uint, records []Record, options ImportOptions) (ImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportRecords", ctx, userId, records, options)
+	ret0, _ := ret[0].(ImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportRecords indicates an expected call of ImportRecords.
+func (mr *MockServiceMockRecorder) ImportRecords(ctx, userId, records, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportRecords", reflect.TypeOf((*MockService)(nil).ImportRecords), ctx, userId, records, options)
+}
@@ -0,0 +1,147 @@
+package transfer
+
+import (
+	"time"
+	"todo-app/internal/todos"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	// FormatMarkdown : a GitHub task list, only exported by Export
+	FormatMarkdown = "markdown"
+)
+
+const (
+	// ModeCreate : every row creates a new item
+	ModeCreate = "create"
+	// ModeUpsert : rows update the item with the same external id, or create it
+	ModeUpsert = "upsert"
+)
+
+// Record : a todo item as it is exported and imported. ID and CreatedAt are only
+// exported, items are matched by ExternalId.
+type Record struct {
+	ID            uint       `json:"id,omitempty"`
+	ExternalId    string     `json:"external_id,omitempty"`
+	Text          string     `json:"text"`
+	Done          bool       `json:"done"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	DueAt         *time.Time `json:"due_at,omitempty"`
+	Priority      string     `json:"priority,omitempty"`
+	Recurrence    string     `json:"recurrence,omitempty"`
+	Estimate      *int       `json:"estimate,omitempty"`
+	Type          string     `json:"type,omitempty"`
+	TargetPerWeek *int       `json:"target_per_week,omitempty"`
+	Timezone      string     `json:"timezone,omitempty"`
+	Tags          []string   `json:"tags,omitempty"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+}
+
+type ImportOptions struct {
+	Format string
+	Mode   string
+	DryRun bool
+}
+
+type RowError struct {
+	Row        int    `json:"row"`
+	ExternalId string `json:"external_id,omitempty"`
+	Error      string `json:"error"`
+}
+
+// ImportResult : what the import did, or would do for a dry run
+type ImportResult struct {
+	DryRun  bool       `json:"dry_run"`
+	Mode    string     `json:"mode"`
+	Created int        `json:"created"`
+	Updated int        `json:"updated"`
+	Failed  int        `json:"failed"`
+	Errors  []RowError `json:"errors"`
+}
+
+func recordFromItem(item todos.ToDoItem) Record {
+	createdAt := item.CreatedAt
+	record := Record{
+		ID:            item.ID,
+		Text:          item.Text,
+		Done:          item.Done,
+		CompletedAt:   item.CompletedAt,
+		DueAt:         item.DueAt,
+		Priority:      item.Priority,
+		Recurrence:    item.Recurrence,
+		Estimate:      item.Estimate,
+		Type:          item.Type,
+		TargetPerWeek: item.TargetPerWeek,
+		Timezone:      item.Timezone,
+		CreatedAt:     &createdAt,
+	}
+	if item.ExternalId != nil {
+		record.ExternalId = *item.ExternalId
+	}
+	for _, tag := range item.Tags {
+		record.Tags = append(record.Tags, tag.Name)
+	}
+
+	return record
+}
+
+// Item : a new item for the user with the values of the record
+func (r Record) Item(userId uint) todos.ToDoItem {
+	item := todos.ToDoItem{
+		Text:          r.Text,
+		Done:          r.Done,
+		CompletedAt:   r.CompletedAt,
+		UserId:        userId,
+		DueAt:         r.DueAt,
+		Priority:      r.Priority,
+		Recurrence:    r.Recurrence,
+		Estimate:      r.Estimate,
+		Type:          r.Type,
+		TargetPerWeek: r.TargetPerWeek,
+		Timezone:      r.Timezone,
+	}
+	if r.ExternalId != "" {
+		externalId := r.ExternalId
+		item.ExternalId = &externalId
+	}
+	for _, tag := range r.Tags {
+		item.Tags = append(item.Tags, todos.Tag{Name: tag})
+	}
+
+	return item
+}
+
+// UpdateInput : the changes that make an existing item match the record
+func (r Record) UpdateInput() todos.ToDoItemUpdateInput {
+	itemType := r.Type
+	if itemType == "" {
+		itemType = todos.TypeTask
+	}
+	targetPerWeek := 0
+	if r.TargetPerWeek != nil {
+		targetPerWeek = *r.TargetPerWeek
+	}
+	estimate := 0
+	if r.Estimate != nil {
+		estimate = *r.Estimate
+	}
+	tags := r.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return todos.ToDoItemUpdateInput{
+		Text:          &r.Text,
+		Done:          &r.Done,
+		DueAt:         r.DueAt,
+		Priority:      &r.Priority,
+		Recurrence:    &r.Recurrence,
+		Tags:          &tags,
+		Estimate:      &estimate,
+		Type:          &itemType,
+		TargetPerWeek: &targetPerWeek,
+		Timezone:      &r.Timezone,
+	}
+}
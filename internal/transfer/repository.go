@@ -0,0 +1,116 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"todo-app/internal/todos"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// exportBatchSize : the number of items loaded at once while exporting
+const exportBatchSize = 500
+
+type Repository interface {
+	ExportForUser(ctx context.Context, userId uint, fn func(items []todos.ToDoItem) error) error
+	ExportRootsForUser(ctx context.Context, userId uint, fn func(items []todos.ToDoItem) error) error
+	GetChildren(ctx context.Context, userId uint, parentId uint) ([]todos.ToDoItem, error)
+	GetByExternalId(ctx context.Context, userId uint, externalId string) (todos.ToDoItem, bool, error)
+	Restore(ctx context.Context, id uint) error
+}
+
+type repository struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func GetRepository(logger *zap.SugaredLogger, db *gorm.DB) Repository {
+	return &repository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// ExportForUser : calls fn with the user's items in batches, ordered by id
+func (r *repository) ExportForUser(ctx context.Context, userId uint, fn func(items []todos.ToDoItem) error) error {
+	var batch []todos.ToDoItem
+	result := r.db.WithContext(ctx).
+		Where("user_id = ?", userId).
+		Preload("Tags").
+		FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		})
+	if result.Error != nil {
+		r.logger.Errorw("failed to export todo items", "user_id", userId, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+// ExportRootsForUser : calls fn with the user's top-level items in batches, ordered by
+// id. Items whose parent was deleted count as top-level.
+func (r *repository) ExportRootsForUser(ctx context.Context, userId uint, fn func(items []todos.ToDoItem) error) error {
+	var batch []todos.ToDoItem
+	result := r.db.WithContext(ctx).
+		Where("user_id = ?", userId).
+		Where("(parent_id IS NULL OR NOT EXISTS (SELECT 1 FROM to_do_items AS parent WHERE parent.id = to_do_items.parent_id AND parent.deleted_at IS NULL))").
+		Preload("Tags").
+		FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		})
+	if result.Error != nil {
+		r.logger.Errorw("failed to export top-level todo items", "user_id", userId, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+// GetChildren : the sub-tasks of the item, ordered by id
+func (r *repository) GetChildren(ctx context.Context, userId uint, parentId uint) ([]todos.ToDoItem, error) {
+	var children []todos.ToDoItem
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND parent_id = ?", userId, parentId).
+		Preload("Tags").
+		Order("id").
+		Find(&children)
+	if result.Error != nil {
+		r.logger.Errorw("failed to get sub-tasks", "user_id", userId, "parent_id", parentId, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return children, nil
+}
+
+// GetByExternalId : finds the item including deleted ones, since their external id
+// is still taken
+func (r *repository) GetByExternalId(ctx context.Context, userId uint, externalId string) (todos.ToDoItem, bool, error) {
+	var item todos.ToDoItem
+	result := r.db.WithContext(ctx).Unscoped().Where("user_id = ? AND external_id = ?", userId, externalId).First(&item)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return todos.ToDoItem{}, false, nil
+	}
+	if result.Error != nil {
+		r.logger.Errorw("failed to find todo item by external id", "user_id", userId, "error", result.Error)
+
+		return todos.ToDoItem{}, false, result.Error
+	}
+
+	return item, true, nil
+}
+
+func (r *repository) Restore(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Unscoped().Model(&todos.ToDoItem{}).Where("id = ?", id).Update("deleted_at", nil)
+	if result.Error != nil {
+		r.logger.Errorw("failed to restore todo item", "id", id, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
@@ -0,0 +1,298 @@
+package transfer
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"todo-app/internal/todos"
+	"todo-app/pkg/locale"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// maxImportRows : the most rows a single import may contain
+const maxImportRows = 10000
+
+type Service interface {
+	Export(ctx context.Context, userId uint, format string, w io.Writer) error
+	Import(ctx context.Context, userId uint, r io.Reader, options ImportOptions) (ImportResult, error)
+	ImportRecords(ctx context.Context, userId uint, records []Record, options ImportOptions) (ImportResult, error)
+	ImportMarkdown(ctx context.Context, userId uint, r io.Reader, dryRun bool) (ImportResult, error)
+}
+
+type service struct {
+	logger      *zap.SugaredLogger
+	repository  Repository
+	todoService todos.Service
+	validator   *validator.Validate
+}
+
+func GetService(
+	logger *zap.SugaredLogger,
+	repo Repository,
+	todoService todos.Service,
+	validator *validator.Validate,
+) Service {
+	return &service{
+		logger:      logger,
+		repository:  repo,
+		todoService: todoService,
+		validator:   validator,
+	}
+}
+
+// Export : writes all items of the user to w batch by batch, flushing after each batch
+// so that the whole export is never held in memory
+func (s *service) Export(ctx context.Context, userId uint, format string, w io.Writer) error {
+	if format == FormatMarkdown {
+		return s.exportMarkdown(ctx, userId, w)
+	}
+
+	enc, err := newEncoder(format, w)
+	if err != nil {
+		return errors.New(locale.ErrorInvalidTransferFormat)
+	}
+
+	if err := enc.Begin(); err != nil {
+		return err
+	}
+
+	err = s.repository.ExportForUser(ctx, userId, func(items []todos.ToDoItem) error {
+		for _, item := range items {
+			if err := enc.Encode(recordFromItem(item)); err != nil {
+				return err
+			}
+		}
+		flush(w)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return enc.End()
+}
+
+// Import : creates or, in upsert mode, updates an item for every valid row. Invalid
+// rows are reported and skipped, they never stop the import. A dry run only reports.
+func (s *service) Import(ctx context.Context, userId uint, r io.Reader, options ImportOptions) (ImportResult, error) {
+	if options.Mode == "" {
+		options.Mode = ModeCreate
+	}
+	if options.Mode != ModeCreate && options.Mode != ModeUpsert {
+		return ImportResult{}, errors.New(locale.ErrorInvalidImportMode)
+	}
+
+	dec, err := newDecoder(options.Format, r)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	return s.importAll(ctx, userId, dec, options)
+}
+
+// ImportRecords : imports records read by the caller, like Import does for a file.
+// options.Format is ignored.
+func (s *service) ImportRecords(ctx context.Context, userId uint, records []Record, options ImportOptions) (ImportResult, error) {
+	if options.Mode == "" {
+		options.Mode = ModeCreate
+	}
+	if options.Mode != ModeCreate && options.Mode != ModeUpsert {
+		return ImportResult{}, errors.New(locale.ErrorInvalidImportMode)
+	}
+
+	return s.importAll(ctx, userId, &sliceDecoder{records: records}, options)
+}
+
+// exportMarkdown : writes the user's items as a task list. Top-level items are loaded
+// batch by batch like the other formats, the sub-tasks of each item once it is written.
+// A parent is created before its sub-tasks, so parent links never form a cycle.
+func (s *service) exportMarkdown(ctx context.Context, userId uint, w io.Writer) error {
+	writer := bufio.NewWriter(w)
+
+	var write func(item todos.ToDoItem, depth int) error
+	write = func(item todos.ToDoItem, depth int) error {
+		if err := writeMarkdownTask(writer, item, depth); err != nil {
+			return err
+		}
+
+		children, err := s.repository.GetChildren(ctx, userId, item.ID)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			if err := write(child, depth+1); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	err := s.repository.ExportRootsForUser(ctx, userId, func(items []todos.ToDoItem) error {
+		for _, item := range items {
+			if err := write(item, 0); err != nil {
+				return err
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		flush(w)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+// ImportMarkdown : creates an item for every task of the task lists in a Markdown
+// document, nested tasks become sub-tasks. Errors are reported by line; the sub-tasks
+// of a task that could not be created go to the closest created ancestor.
+func (s *service) ImportMarkdown(ctx context.Context, userId uint, r io.Reader, dryRun bool) (ImportResult, error) {
+	tasks, err := parseMarkdown(r)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	if len(tasks) > maxImportRows {
+		return ImportResult{}, fmt.Errorf("import has more than %d rows", maxImportRows)
+	}
+
+	result := ImportResult{DryRun: dryRun, Mode: ModeCreate, Errors: []RowError{}}
+	// ids : the id of every created task, 0 for those that failed
+	ids := make([]uint, len(tasks))
+	for i, task := range tasks {
+		item := task.Record.Item(userId)
+		for parent := task.Parent; parent >= 0; parent = tasks[parent].Parent {
+			if ids[parent] != 0 {
+				parentId := ids[parent]
+				item.ParentId = &parentId
+				break
+			}
+		}
+
+		if err := s.validator.Struct(item); err != nil {
+			result.fail(task.Line, "", err)
+			continue
+		}
+		if dryRun {
+			// any id will do, it only marks the task as created for its sub-tasks
+			ids[i] = uint(i + 1)
+			result.Created++
+			continue
+		}
+		if err := s.todoService.Create(ctx, &item); err != nil {
+			result.fail(task.Line, "", err)
+			continue
+		}
+		ids[i] = item.ID
+		result.Created++
+	}
+
+	return result, nil
+}
+
+func (s *service) importAll(ctx context.Context, userId uint, dec decoder, options ImportOptions) (ImportResult, error) {
+	result := ImportResult{DryRun: options.DryRun, Mode: options.Mode, Errors: []RowError{}}
+	// seen : external ids of earlier rows, so that a dry run reports repeated ids the
+	// same way a real import handles them
+	seen := map[string]bool{}
+	for row := 1; ; row++ {
+		record, err := dec.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if row > maxImportRows {
+			return ImportResult{}, fmt.Errorf("import has more than %d rows", maxImportRows)
+		}
+
+		var recordErr rowError
+		if errors.As(err, &recordErr) {
+			result.fail(row, "", err)
+			continue
+		}
+		if err != nil {
+			return ImportResult{}, err
+		}
+
+		created, err := s.importRecord(ctx, userId, record, options, seen)
+		if err != nil {
+			result.fail(row, record.ExternalId, err)
+			continue
+		}
+		if record.ExternalId != "" {
+			seen[record.ExternalId] = true
+		}
+
+		if created {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+
+	return result, nil
+}
+
+func (s *service) importRecord(ctx context.Context, userId uint, record Record, options ImportOptions, seen map[string]bool) (bool, error) {
+	item := record.Item(userId)
+	if err := s.validator.Struct(item); err != nil {
+		return false, err
+	}
+
+	if options.Mode == ModeCreate {
+		if seen[record.ExternalId] {
+			return false, errors.New(locale.ErrorDuplicateExternalId)
+		}
+		if record.ExternalId != "" {
+			_, found, err := s.repository.GetByExternalId(ctx, userId, record.ExternalId)
+			if err != nil {
+				return false, err
+			}
+			if found {
+				return false, errors.New(locale.ErrorDuplicateExternalId)
+			}
+		}
+		if options.DryRun {
+			return true, nil
+		}
+
+		return true, s.todoService.Create(ctx, &item)
+	}
+
+	if record.ExternalId == "" {
+		return false, errors.New(locale.ErrorMissingExternalId)
+	}
+
+	existing, found, err := s.repository.GetByExternalId(ctx, userId, record.ExternalId)
+	if err != nil {
+		return false, err
+	}
+	if options.DryRun {
+		return !found && !seen[record.ExternalId], nil
+	}
+	if !found {
+		return true, s.todoService.Create(ctx, &item)
+	}
+
+	if existing.DeletedAt.Valid {
+		if err := s.repository.Restore(ctx, existing.ID); err != nil {
+			return false, err
+		}
+	}
+	_, err = s.todoService.UpdateById(ctx, existing.ID, record.UpdateInput())
+
+	return false, err
+}
+
+func (r *ImportResult) fail(row int, externalId string, err error) {
+	r.Failed++
+	r.Errors = append(r.Errors, RowError{Row: row, ExternalId: externalId, Error: err.Error()})
+}
@@ -0,0 +1,276 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/todos"
+	"todo-app/pkg/locale"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestService_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	mockTodoService := todos.NewMockService(ctrl)
+	v := validator.New()
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockRepo, mockTodoService, v)
+	ctx := context.Background()
+
+	created := time.Date(2025, time.March, 1, 9, 0, 0, 0, time.UTC)
+	externalId := "ext-1"
+	batches := [][]todos.ToDoItem{
+		{{Model: gorm.Model{ID: 1, CreatedAt: created}, Text: "Buy milk", ExternalId: &externalId, Tags: []todos.Tag{{Name: "home"}, {Name: "shop"}}}},
+		{{Model: gorm.Model{ID: 2, CreatedAt: created}, Text: "Call \"Bob\", later", Done: true, Priority: todos.PriorityHigh}},
+	}
+	expectBatches := func() {
+		mockRepo.
+			EXPECT().
+			ExportForUser(ctx, uint(3), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uint, fn func(items []todos.ToDoItem) error) error {
+				for _, batch := range batches {
+					if err := fn(batch); err != nil {
+						return err
+					}
+				}
+				return nil
+			}).
+			Times(1)
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		expectBatches()
+
+		var out bytes.Buffer
+		err := service.Export(ctx, 3, FormatCSV, &out)
+		assert.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		assert.Len(t, lines, 3)
+		assert.Equal(t, strings.Join(csvColumns, ","), lines[0])
+		assert.Equal(t, `1,ext-1,Buy milk,false,,,,,,,,,"home,shop",2025-03-01T09:00:00Z`, lines[1])
+		assert.Equal(t, `2,,"Call ""Bob"", later",true,,,high,,,,,,,2025-03-01T09:00:00Z`, lines[2])
+
+		ctrl.Finish()
+	})
+
+	t.Run("json across batches", func(t *testing.T) {
+		expectBatches()
+
+		var out bytes.Buffer
+		err := service.Export(ctx, 3, FormatJSON, &out)
+		assert.NoError(t, err)
+
+		var records []Record
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &records))
+		assert.Len(t, records, 2)
+		assert.Equal(t, []string{"home", "shop"}, records[0].Tags)
+
+		ctrl.Finish()
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		err := service.Export(ctx, 3, "xml", &bytes.Buffer{})
+		assert.Error(t, err)
+		assert.Equal(t, locale.ErrorInvalidTransferFormat, err.Error())
+
+		ctrl.Finish()
+	})
+}
+
+func TestService_Import(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	mockTodoService := todos.NewMockService(ctrl)
+	v := validator.New()
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockRepo, mockTodoService, v)
+	ctx := context.Background()
+
+	t.Run("csv rows are validated on their own", func(t *testing.T) {
+		body := "text,priority,done,tags\n" +
+			"Buy milk,low,false,\"home, shop\"\n" +
+			",high,false,\n" +
+			"Call Bob,urgent,false,\n" +
+			"Water plants,,maybe,\n"
+
+		mockTodoService.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, item *todos.ToDoItem) error {
+				assert.Equal(t, "Buy milk", item.Text)
+				assert.Equal(t, uint(3), item.UserId)
+				assert.Equal(t, []todos.Tag{{Name: "home"}, {Name: "shop"}}, item.Tags)
+				return nil
+			}).
+			Times(1)
+
+		result, err := service.Import(ctx, 3, strings.NewReader(body), ImportOptions{Format: FormatCSV})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Created)
+		assert.Equal(t, 3, result.Failed)
+		assert.Equal(t, []int{2, 3, 4}, []int{result.Errors[0].Row, result.Errors[1].Row, result.Errors[2].Row})
+
+		ctrl.Finish()
+	})
+
+	t.Run("upsert updates by external id", func(t *testing.T) {
+		body := `{"external_id":"a","text":"Buy oat milk","done":true}` + "\n" +
+			`{"external_id":"b","text":"Call Bob"}` + "\n" +
+			`{"text":"No id"}` + "\n"
+
+		existing := todos.ToDoItem{Model: gorm.Model{ID: 7}, UserId: 3, Text: "Buy milk"}
+		mockRepo.EXPECT().GetByExternalId(ctx, uint(3), "a").Return(existing, true, nil).Times(1)
+		mockRepo.EXPECT().GetByExternalId(ctx, uint(3), "b").Return(todos.ToDoItem{}, false, nil).Times(1)
+		mockTodoService.
+			EXPECT().
+			UpdateById(ctx, uint(7), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uint, input todos.ToDoItemUpdateInput) (todos.ToDoItem, error) {
+				assert.Equal(t, "Buy oat milk", *input.Text)
+				assert.True(t, *input.Done)
+				return existing, nil
+			}).
+			Times(1)
+		mockTodoService.EXPECT().Create(ctx, gomock.Any()).Return(nil).Times(1)
+
+		result, err := service.Import(ctx, 3, strings.NewReader(body), ImportOptions{Format: FormatNDJSON, Mode: ModeUpsert})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Updated)
+		assert.Equal(t, 1, result.Created)
+		assert.Equal(t, []RowError{{Row: 3, Error: locale.ErrorMissingExternalId}}, result.Errors)
+
+		ctrl.Finish()
+	})
+
+	t.Run("dry run writes nothing", func(t *testing.T) {
+		body := `[{"external_id":"a","text":"Buy milk"},{"external_id":"a","text":"Buy milk again"},{"text":"Due","due_at":"tomorrow"}]`
+
+		mockRepo.EXPECT().GetByExternalId(ctx, uint(3), "a").Return(todos.ToDoItem{}, false, nil).Times(1)
+
+		result, err := service.Import(ctx, 3, strings.NewReader(body), ImportOptions{Format: FormatJSON, DryRun: true})
+		assert.NoError(t, err)
+		assert.True(t, result.DryRun)
+		assert.Equal(t, 1, result.Created)
+		assert.Equal(t, 2, result.Failed)
+		assert.Equal(t, locale.ErrorDuplicateExternalId, result.Errors[0].Error)
+		assert.Equal(t, 3, result.Errors[1].Row)
+
+		ctrl.Finish()
+	})
+
+	t.Run("broken json", func(t *testing.T) {
+		_, err := service.Import(ctx, 3, strings.NewReader(`{"text":"not an array"}`), ImportOptions{Format: FormatJSON})
+		assert.Error(t, err)
+
+		ctrl.Finish()
+	})
+}
+
+func TestService_ExportMarkdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	service := GetService(zap.NewNop().Sugar(), mockRepo, todos.NewMockService(ctrl), validator.New())
+	ctx := context.Background()
+
+	parentId, childId, missingId := uint(1), uint(3), uint(99)
+	roots := []todos.ToDoItem{
+		{Model: gorm.Model{ID: 1}, Text: "Plan trip", Tags: []todos.Tag{{Name: "travel"}, {Name: "red category"}}},
+		{Model: gorm.Model{ID: 2}, Text: "Orphan", ParentId: &missingId},
+	}
+	mockRepo.
+		EXPECT().
+		ExportRootsForUser(ctx, uint(3), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uint, fn func(items []todos.ToDoItem) error) error {
+			return fn(roots)
+		}).
+		Times(1)
+	mockRepo.
+		EXPECT().
+		GetChildren(ctx, uint(3), uint(1)).
+		Return([]todos.ToDoItem{{Model: gorm.Model{ID: 3}, Text: "Book flights", Done: true, ParentId: &parentId}}, nil).
+		Times(1)
+	mockRepo.
+		EXPECT().
+		GetChildren(ctx, uint(3), uint(3)).
+		Return([]todos.ToDoItem{{Model: gorm.Model{ID: 4}, Text: "Window seat\nplease", ParentId: &childId}}, nil).
+		Times(1)
+	mockRepo.EXPECT().GetChildren(ctx, uint(3), uint(4)).Return(nil, nil).Times(1)
+	mockRepo.EXPECT().GetChildren(ctx, uint(3), uint(2)).Return(nil, nil).Times(1)
+
+	var out bytes.Buffer
+	err := service.Export(ctx, 3, FormatMarkdown, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, "- [ ] Plan trip #travel\n  - [x] Book flights\n    - [ ] Window seat please\n- [ ] Orphan\n", out.String())
+
+	ctrl.Finish()
+}
+
+func TestService_ImportMarkdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	mockTodoService := todos.NewMockService(ctrl)
+	service := GetService(zap.NewNop().Sugar(), mockRepo, mockTodoService, validator.New())
+	ctx := context.Background()
+
+	document := "# Trip\n\n" +
+		"- [ ] Plan trip #travel #summer\n" +
+		"  - [x] Book flights\n" +
+		"\t- [ ]\n" +
+		"      - [ ] Window seat\n" +
+		"  * [X] Hotel\n" +
+		"Some notes\n" +
+		"1. [ ] Pack\n" +
+		"- not a task\n"
+
+	t.Run("nested tasks become sub-tasks", func(t *testing.T) {
+		var created []todos.ToDoItem
+		mockTodoService.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, item *todos.ToDoItem) error {
+				item.ID = uint(10 + len(created))
+				created = append(created, *item)
+				return nil
+			}).
+			Times(5)
+
+		result, err := service.ImportMarkdown(ctx, 3, strings.NewReader(document), false)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, result.Created)
+		assert.Equal(t, 1, result.Failed)
+		assert.Equal(t, 5, result.Errors[0].Row)
+
+		assert.Equal(t, "Plan trip", created[0].Text)
+		assert.Equal(t, []todos.Tag{{Name: "travel"}, {Name: "summer"}}, created[0].Tags)
+		assert.Nil(t, created[0].ParentId)
+		assert.Equal(t, uint(10), *created[1].ParentId)
+		assert.True(t, created[1].Done)
+		// the empty task failed, its sub-task goes to the closest created ancestor
+		assert.Equal(t, "Window seat", created[2].Text)
+		assert.Equal(t, uint(11), *created[2].ParentId)
+		assert.Equal(t, "Hotel", created[3].Text)
+		assert.Equal(t, uint(10), *created[3].ParentId)
+		assert.Equal(t, "Pack", created[4].Text)
+		assert.Nil(t, created[4].ParentId)
+		assert.Equal(t, uint(3), created[4].UserId)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		result, err := service.ImportMarkdown(ctx, 3, strings.NewReader(document), true)
+		assert.NoError(t, err)
+		assert.True(t, result.DryRun)
+		assert.Equal(t, 5, result.Created)
+		assert.Equal(t, 1, result.Failed)
+	})
+
+	ctrl.Finish()
+}
@@ -0,0 +1,39 @@
+package users
+
+import "context"
+
+const (
+	EventCreated       = "created"
+	EventUpdated       = "updated"
+	EventEmailVerified = "email_verified"
+	// EventPasswordChanged : the access tokens issued before are not valid anymore
+	EventPasswordChanged = "password_changed"
+)
+
+// Event : a change to a user, User holds the state after the change
+type Event struct {
+	Type string
+	User User
+}
+
+// Listener : called synchronously after a user was written, listeners that do slow
+// work should hand it off to a goroutine
+type Listener func(ctx context.Context, event Event)
+
+func (s *service) Subscribe(listener Listener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.listeners = append(s.listeners, listener)
+}
+
+func (s *service) publish(ctx context.Context, eventType string, user User) {
+	s.mu.RLock()
+	listeners := s.listeners
+	s.mu.RUnlock()
+
+	event := Event{Type: eventType, User: user}
+	for _, listener := range listeners {
+		listener(ctx, event)
+	}
+}
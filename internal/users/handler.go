@@ -3,12 +3,14 @@ package users
 import (
 	"fmt"
 	"net/http"
+	"todo-app/pkg/authz"
 	e "todo-app/pkg/errors"
 	"todo-app/pkg/handlers"
 	"todo-app/pkg/locale"
 
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type endpointHandler struct {
@@ -46,6 +48,16 @@ func (h *endpointHandler) AddEndpoints() {
 			Path:    "/user/verify-email",
 			Handler: h.verifyEmail,
 		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/user/me/password",
+			Handler: h.changePassword,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/user/me/email",
+			Handler: h.changeEmail,
+		},
 	}
 
 	for _, endpoint := range endpoints {
@@ -154,7 +166,7 @@ func getVerificationHTML(title, message string, success bool) string {
 }
 
 // @Summary Update an existing user
-// @Description This endpoint updates an existing user based on the provided ID and returns the updated user details
+// @Description This endpoint updates the name of the logged in user and returns the updated user details. The email and password are changed with their own endpoints.
 // @Tags users
 // @ID update-user
 // @Security BearerAuth
@@ -165,6 +177,7 @@ func getVerificationHTML(title, message string, success bool) string {
 // @Success 200 {object} User
 // @Failure 400 {object} errors.ResponseError "Invalid user data or ID"
 // @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
 // @Failure 500 {object} errors.ResponseError "Internal server error"
 // @Router /user/{id} [put]
 func (h *endpointHandler) update(ctx echo.Context) error {
@@ -184,6 +197,12 @@ func (h *endpointHandler) update(ctx echo.Context) error {
 
 		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidID})
 	}
+	userId, _ := ctx.Get("user_id").(uint)
+	if err := authz.Authorize(Policy, userId, authz.ActionUpdate, User{Model: gorm.Model{ID: id}}); err != nil {
+		h.logger.Warn("user tried to update another user", "user_id", userId, "id", id)
+
+		return authz.Deny(ctx)
+	}
 	user.ID = id
 
 	user, err = h.service.Update(ctx.Request().Context(), &user)
@@ -197,3 +216,79 @@ func (h *endpointHandler) update(ctx echo.Context) error {
 	user.Password = ""
 	return ctx.JSON(http.StatusOK, user)
 }
+
+// @Summary Change password
+// @Description This endpoint changes the password of the logged in user, the current password is required. Every session is logged out, including the current one, and personal access tokens stop working.
+// @Tags users
+// @ID change-password
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body ChangePasswordRequest true "Current and new password"
+// @Success 204 "Password changed"
+// @Failure 400 {object} errors.ResponseError "Invalid body"
+// @Failure 401 {object} errors.ResponseError "Wrong current password"
+// @Failure 500 {object} errors.ResponseError "Internal server error"
+// @Router /user/me/password [post]
+func (h *endpointHandler) changePassword(ctx echo.Context) error {
+	var req ChangePasswordRequest
+	if err := ctx.Bind(&req); err != nil {
+		h.logger.Warn("could not bind body to change password request", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody})
+	}
+
+	userId, _ := ctx.Get("user_id").(uint)
+	err := h.service.ChangePassword(ctx.Request().Context(), userId, req)
+	if err != nil {
+		return h.credentialsError(ctx, err)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// @Summary Change email
+// @Description This endpoint starts changing the email of the logged in user, the current password is required. A verification link is sent to the new address, which replaces the current one once verified. The current address is notified of the change.
+// @Tags users
+// @ID change-email
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body ChangeEmailRequest true "New email and current password"
+// @Success 202 {string} string "Verification email sent to the new address"
+// @Failure 400 {object} errors.ResponseError "Invalid body or email taken"
+// @Failure 401 {object} errors.ResponseError "Wrong current password"
+// @Failure 500 {object} errors.ResponseError "Internal server error"
+// @Router /user/me/email [post]
+func (h *endpointHandler) changeEmail(ctx echo.Context) error {
+	var req ChangeEmailRequest
+	if err := ctx.Bind(&req); err != nil {
+		h.logger.Warn("could not bind body to change email request", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody})
+	}
+
+	userId, _ := ctx.Get("user_id").(uint)
+	err := h.service.ChangeEmail(ctx.Request().Context(), userId, req)
+	if err != nil {
+		return h.credentialsError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusAccepted, map[string]string{"message": "Verification email sent to the new address"})
+}
+
+// credentialsError : the response for errors of the password and email changes
+func (h *endpointHandler) credentialsError(ctx echo.Context, err error) error {
+	h.logger.Warn("could not change user credentials", "error", err.Error())
+
+	switch err.Error() {
+	case locale.ErrorInvalidCredentials, locale.ErrorUserNotFound:
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: locale.ErrorInvalidCredentials})
+	case locale.ErrorEmailTaken, locale.ErrorNotFoundUpdates:
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: err.Error()})
+	case locale.ErrorInternalServer:
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+}
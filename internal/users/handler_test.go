@@ -201,6 +201,7 @@ func TestHandler_Update(t *testing.T) {
 		ctx.SetPath("/user/:id")
 		ctx.SetParamNames("id")
 		ctx.SetParamValues("1")
+		ctx.Set("user_id", uint(1))
 
 		mockService.
 			EXPECT().
@@ -288,6 +289,7 @@ func TestHandler_Update(t *testing.T) {
 		ctx.SetPath("/user/:id")
 		ctx.SetParamNames("id")
 		ctx.SetParamValues("1")
+		ctx.Set("user_id", uint(1))
 
 		mockService.
 			EXPECT().
@@ -493,3 +495,91 @@ func TestHandler_VerifyEmail(t *testing.T) {
 		}
 	})
 }
+
+func TestHandler_ChangePassword(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	newContext := func() (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/user/me/password", strings.NewReader(`{"current_password":"old password","new_password":"new password"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", uint(1))
+
+		return ctx, rec
+	}
+	request := ChangePasswordRequest{CurrentPassword: "old password", NewPassword: "new password"}
+
+	t.Run("success change password", func(t *testing.T) {
+		ctx, rec := newContext()
+
+		mockService.EXPECT().ChangePassword(ctx.Request().Context(), uint(1), request).Return(nil).Times(1)
+
+		if assert.NoError(t, h.changePassword(ctx)) {
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+		}
+	})
+
+	t.Run("wrong current password", func(t *testing.T) {
+		ctx, rec := newContext()
+
+		mockService.EXPECT().ChangePassword(ctx.Request().Context(), uint(1), request).Return(errors.New(locale.ErrorInvalidCredentials)).Times(1)
+
+		if assert.NoError(t, h.changePassword(ctx)) {
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.Contains(t, rec.Body.String(), locale.ErrorInvalidCredentials)
+		}
+	})
+}
+
+func TestHandler_CrossUserAccess(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	logger := zap.NewNop().Sugar()
+	h := &endpointHandler{logger: logger, service: mockService, e: e}
+
+	tests := []struct {
+		name   string
+		userId uint
+		id     string
+	}{
+		{"another user", 1, "2"},
+		{"lower id", 5, "1"},
+		{"not logged in", 0, "1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			userData := `{"firstName":"Jane","lastName":"Smith"}`
+
+			req := httptest.NewRequest(http.MethodPut, "/user/"+test.id, strings.NewReader(userData))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(req, rec)
+			ctx.SetPath("/user/:id")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(test.id)
+			ctx.Set("user_id", test.userId)
+
+			// the service is never reached
+			if assert.NoError(t, h.update(ctx)) {
+				assert.Equal(t, http.StatusNotFound, rec.Code)
+
+				var responseError localErr.ResponseError
+				err := json.Unmarshal(rec.Body.Bytes(), &responseError)
+				assert.NoError(t, err)
+				assert.Equal(t, locale.ErrorNotFoundRecord, responseError.Message)
+			}
+		})
+	}
+}
@@ -99,6 +99,36 @@ func (mr *MockRepositoryMockRecorder) GetById(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockRepository)(nil).GetById), ctx, id)
 }
 
+// GetByPasswordResetToken mocks base method.
+func (m *MockRepository) GetByPasswordResetToken(ctx context.Context, tokenHash string) (User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByPasswordResetToken", ctx, tokenHash)
+	ret0, _ := ret[0].(User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByPasswordResetToken indicates an expected call of GetByPasswordResetToken.
+func (mr *MockRepositoryMockRecorder) GetByPasswordResetToken(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByPasswordResetToken", reflect.TypeOf((*MockRepository)(nil).GetByPasswordResetToken), ctx, tokenHash)
+}
+
+// ResetPassword mocks base method.
+func (m *MockRepository) ResetPassword(ctx context.Context, id uint, tokenHash, password string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, id, tokenHash, password)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockRepositoryMockRecorder) ResetPassword(ctx, id, tokenHash, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockRepository)(nil).ResetPassword), ctx, id, tokenHash, password)
+}
+
 // Update mocks base method.
 func (m *MockRepository) Update(ctx context.Context, id uint, updates map[string]any) error {
 	m.ctrl.T.Helper()
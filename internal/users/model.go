@@ -17,6 +17,25 @@ type User struct {
 	IsEmailVerified         bool   `gorm:"default:false"`
 	EmailVerificationToken  string `gorm:"type:varchar(255);index"`
 	EmailVerificationExpiry *time.Time
+	// PendingEmail : the address the user is changing to, the current one stays in use
+	// until it is verified
+	PendingEmail string `gorm:"type:varchar(255)"`
+	// PasswordResetTokenHash : SHA-256 hash of the pending password reset token
+	PasswordResetTokenHash string     `gorm:"type:char(64);index" json:"-"`
+	PasswordResetExpiry    *time.Time `json:"-"`
+	// TokensInvalidBefore : access tokens issued before are rejected, it is moved on
+	// password changes and when the user logs out everywhere
+	TokensInvalidBefore *time.Time `json:"-"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail        string `json:"new_email" validate:"required,email"`
+	CurrentPassword string `json:"current_password" validate:"required"`
 }
 
 // BeforeSave : hook before a user is saved
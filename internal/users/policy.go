@@ -0,0 +1,8 @@
+package users
+
+import "todo-app/pkg/authz"
+
+// Policy : users only read and change their own account
+func Policy(userId uint, _ authz.Action, user User) bool {
+	return authz.Owns(userId, user.ID)
+}
@@ -2,6 +2,7 @@ package users
 
 import (
 	"context"
+	"time"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -11,9 +12,11 @@ type Repository interface {
 	GetById(ctx context.Context, id uint) (User, error)
 	GetByEmail(ctx context.Context, email string) (User, error)
 	GetByEmailVerificationToken(ctx context.Context, token string) (User, error)
+	GetByPasswordResetToken(ctx context.Context, tokenHash string) (User, error)
 	Create(ctx context.Context, user *User) error
 	Update(ctx context.Context, id uint, updates map[string]interface{}) error
 	VerifyEmail(ctx context.Context, id uint) error
+	ResetPassword(ctx context.Context, id uint, tokenHash string, password string) (bool, error)
 }
 
 type repository struct {
@@ -64,6 +67,18 @@ func (r *repository) GetByEmailVerificationToken(ctx context.Context, token stri
 	return user, nil
 }
 
+func (r *repository) GetByPasswordResetToken(ctx context.Context, tokenHash string) (User, error) {
+	var user User
+	result := r.db.WithContext(ctx).Where("password_reset_token_hash = ?", tokenHash).First(&user)
+	if result.Error != nil {
+		r.logger.Warnw("failed to find user by password reset token", "error", result.Error)
+
+		return User{}, result.Error
+	}
+
+	return user, nil
+}
+
 func (r *repository) Create(ctx context.Context, user *User) error {
 	result := r.db.WithContext(ctx).Create(user)
 	if result.Error != nil {
@@ -101,3 +116,26 @@ func (r *repository) VerifyEmail(ctx context.Context, id uint) error {
 
 	return nil
 }
+
+// ResetPassword : sets the password and clears the reset token, false when the token was
+// already used. The access tokens issued until now are rejected.
+func (r *repository) ResetPassword(ctx context.Context, id uint, tokenHash string, password string) (bool, error) {
+	updates := map[string]interface{}{
+		"password":                  password,
+		"password_reset_token_hash": "",
+		"password_reset_expiry":     nil,
+		"tokens_invalid_before":     time.Now(),
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&User{}).
+		Where("id = ? AND password_reset_token_hash = ?", id, tokenHash).
+		Updates(updates)
+	if result.Error != nil {
+		r.logger.Errorw("failed to reset user password", "id", id, "error", result.Error)
+
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
@@ -3,6 +3,9 @@ package users
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"time"
 	"todo-app/pkg/email"
 	"todo-app/pkg/locale"
@@ -10,12 +13,16 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type Service interface {
 	Create(ctx context.Context, user *User) error
 	Update(ctx context.Context, user *User) (User, error)
 	VerifyEmail(ctx context.Context, token string) error
+	ChangePassword(ctx context.Context, id uint, req ChangePasswordRequest) error
+	ChangeEmail(ctx context.Context, id uint, req ChangeEmailRequest) error
+	Subscribe(listener Listener)
 }
 
 type service struct {
@@ -23,6 +30,8 @@ type service struct {
 	repository   Repository
 	validator    *validator.Validate
 	emailService email.Service
+	mu           sync.RWMutex
+	listeners    []Listener
 }
 
 func GetService(logger *zap.SugaredLogger, repo Repository, validator *validator.Validate, emailService email.Service) Service {
@@ -46,6 +55,7 @@ func (s *service) Create(ctx context.Context, user *User) error {
 	user.EmailVerificationToken = verificationToken
 	user.EmailVerificationExpiry = &expiryTime
 	user.IsEmailVerified = false
+	user.PendingEmail = ""
 
 	err := s.repository.Create(ctx, user)
 	if err != nil {
@@ -59,6 +69,8 @@ func (s *service) Create(ctx context.Context, user *User) error {
 		// Don't return error here - user is created but email failed to send
 	}
 
+	s.publish(ctx, EventCreated, *user)
+
 	return nil
 }
 
@@ -73,6 +85,10 @@ func (s *service) VerifyEmail(ctx context.Context, token string) error {
 		return errors.New("verification token has expired")
 	}
 
+	if user.PendingEmail != "" {
+		return s.confirmEmailChange(ctx, user)
+	}
+
 	// Check if email is already verified
 	if user.IsEmailVerified {
 		return errors.New("email is already verified")
@@ -84,6 +100,11 @@ func (s *service) VerifyEmail(ctx context.Context, token string) error {
 		return err
 	}
 
+	user.IsEmailVerified = true
+	user.EmailVerificationToken = ""
+	user.EmailVerificationExpiry = nil
+	s.publish(ctx, EventEmailVerified, user)
+
 	return nil
 }
 
@@ -93,18 +114,9 @@ func (s *service) Update(ctx context.Context, user *User) (User, error) {
 		return User{}, err
 	}
 
+	// the email is changed with ChangeEmail, which asks for the password
 	updates := map[string]interface{}{}
 
-	if actualUser.Email != user.Email {
-		updates["email"] = user.Email
-
-		verificationToken := uuid.New().String()
-		expiryTime := time.Now().Add(24 * time.Hour)
-
-		updates["email_verification_token"] = verificationToken
-		updates["email_verification_expiry"] = &expiryTime
-		updates["is_email_verified"] = false
-	}
 	if actualUser.FirstName != user.FirstName {
 		updates["first_name"] = user.FirstName
 	}
@@ -121,17 +133,128 @@ func (s *service) Update(ctx context.Context, user *User) (User, error) {
 		return User{}, err
 	}
 
-	if val, ok := updates["email_verification_token"]; ok {
-		err = s.emailService.SendVerificationEmail(user.Email, user.FirstName, val.(string))
-		if err != nil {
-			s.logger.Errorw("failed to send verification email", "error", err, "email", user.Email)
-		}
-	}
-
 	updatedUser, err := s.repository.GetById(ctx, user.ID)
 	if err != nil {
 		return User{}, errors.New(locale.ErrorNotFoundRecord)
 	}
 
+	s.publish(ctx, EventUpdated, updatedUser)
+
 	return updatedUser, nil
 }
+
+// ChangePassword : sets a new password after checking the current one, the access
+// tokens issued until now are rejected
+func (s *service) ChangePassword(ctx context.Context, id uint, req ChangePasswordRequest) error {
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+
+	user, err := s.repository.GetById(ctx, id)
+	if err != nil {
+		return errors.New(locale.ErrorUserNotFound)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		s.logger.Warnw("wrong current password on password change", "user_id", id)
+		return errors.New(locale.ErrorInvalidCredentials)
+	}
+
+	now := time.Now()
+	err = s.repository.Update(ctx, id, map[string]interface{}{"password": req.NewPassword, "tokens_invalid_before": now})
+	if err != nil {
+		return errors.New(locale.ErrorInternalServer)
+	}
+
+	s.logger.Infow("password changed", "user_id", id)
+
+	user.TokensInvalidBefore = &now
+	s.publish(ctx, EventPasswordChanged, user)
+
+	return nil
+}
+
+// ChangeEmail : starts changing the email after checking the password. The new address
+// gets a verification link and replaces the current one once it is verified, the current
+// address is told about the change.
+func (s *service) ChangeEmail(ctx context.Context, id uint, req ChangeEmailRequest) error {
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+
+	user, err := s.repository.GetById(ctx, id)
+	if err != nil {
+		return errors.New(locale.ErrorUserNotFound)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		s.logger.Warnw("wrong current password on email change", "user_id", id)
+		return errors.New(locale.ErrorInvalidCredentials)
+	}
+
+	newEmail := strings.ToLower(req.NewEmail)
+	if newEmail == user.Email {
+		return errors.New(locale.ErrorNotFoundUpdates)
+	}
+	if _, err := s.repository.GetByEmail(ctx, newEmail); err == nil {
+		return errors.New(locale.ErrorEmailTaken)
+	}
+
+	verificationToken := uuid.New().String()
+	expiryTime := time.Now().Add(24 * time.Hour)
+	updates := map[string]interface{}{
+		"pending_email":             newEmail,
+		"email_verification_token":  verificationToken,
+		"email_verification_expiry": &expiryTime,
+	}
+	if err := s.repository.Update(ctx, id, updates); err != nil {
+		return errors.New(locale.ErrorInternalServer)
+	}
+
+	err = s.emailService.SendVerificationEmail(newEmail, user.FirstName, verificationToken)
+	if err != nil {
+		s.logger.Errorw("failed to send verification email", "error", err, "email", newEmail)
+	}
+
+	body := fmt.Sprintf(
+		"Hi %s,\n\nthe email address of your Todo App account is being changed to %s. "+
+			"This address stays in use until the new one is verified.\n\n"+
+			"If you did not ask for this, reset your password right away.",
+		user.FirstName,
+		newEmail,
+	)
+	if err := s.emailService.Send(user.Email, "Your email address is being changed", body); err != nil {
+		s.logger.Errorw("failed to notify of email change", "error", err, "user_id", id)
+	}
+
+	return nil
+}
+
+// confirmEmailChange : replaces the email with the verified pending one
+func (s *service) confirmEmailChange(ctx context.Context, user User) error {
+	if _, err := s.repository.GetByEmail(ctx, user.PendingEmail); err == nil {
+		return errors.New(locale.ErrorEmailTaken)
+	}
+
+	updates := map[string]interface{}{
+		"email":                     user.PendingEmail,
+		"pending_email":             "",
+		"is_email_verified":         true,
+		"email_verification_token":  nil,
+		"email_verification_expiry": nil,
+	}
+	if err := s.repository.Update(ctx, user.ID, updates); err != nil {
+		return err
+	}
+
+	s.logger.Infow("email changed", "user_id", user.ID)
+
+	user.Email = user.PendingEmail
+	user.PendingEmail = ""
+	user.IsEmailVerified = true
+	user.EmailVerificationToken = ""
+	user.EmailVerificationExpiry = nil
+	s.publish(ctx, EventUpdated, user)
+
+	return nil
+}
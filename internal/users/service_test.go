@@ -87,15 +87,10 @@ func TestService_Update(t *testing.T) {
 			Return(user, nil).
 			Times(1)
 
+		// the email is left as it is
 		mockUsersRepo.
 			EXPECT().
-			Update(ctx, user.ID, gomock.Any()).
-			Return(nil).
-			Times(1)
-
-		mockEmailService.
-			EXPECT().
-			SendVerificationEmail(updatedUser.Email, updatedUser.FirstName, gomock.Any()).
+			Update(ctx, user.ID, map[string]interface{}{"first_name": "new_test"}).
 			Return(nil).
 			Times(1)
 
@@ -184,9 +179,18 @@ func TestService_VerifyEmail(t *testing.T) {
 			Return(nil).
 			Times(1)
 
+		var events []Event
+		service.Subscribe(func(_ context.Context, event Event) {
+			events = append(events, event)
+		})
+
 		err := service.VerifyEmail(ctx, token)
 
 		assert.NoError(t, err)
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, EventEmailVerified, events[0].Type)
+			assert.True(t, events[0].User.IsEmailVerified)
+		}
 		ctrl.Finish()
 	})
 
@@ -225,3 +229,140 @@ func TestService_VerifyEmail(t *testing.T) {
 		ctrl.Finish()
 	})
 }
+
+func TestService_ChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockUsersRepo := NewMockRepository(ctrl)
+	v := validator.New()
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockUsersRepo, v, email.NewMockService(ctrl))
+	ctx := context.Background()
+
+	pw, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	user := User{Model: gorm.Model{ID: 1}, Email: "test@test.com", Password: string(pw)}
+
+	t.Run("change successfully", func(t *testing.T) {
+		mockUsersRepo.EXPECT().GetById(ctx, user.ID).Return(user, nil).Times(1)
+		mockUsersRepo.
+			EXPECT().
+			Update(ctx, user.ID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uint, updates map[string]interface{}) error {
+				assert.Equal(t, "new password", updates["password"])
+				assert.WithinDuration(t, time.Now(), updates["tokens_invalid_before"].(time.Time), time.Second)
+				return nil
+			}).
+			Times(1)
+
+		var events []Event
+		service.Subscribe(func(_ context.Context, event Event) { events = append(events, event) })
+
+		err := service.ChangePassword(ctx, user.ID, ChangePasswordRequest{CurrentPassword: "password", NewPassword: "new password"})
+		assert.NoError(t, err)
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, EventPasswordChanged, events[0].Type)
+			assert.NotNil(t, events[0].User.TokensInvalidBefore)
+		}
+		ctrl.Finish()
+	})
+
+	t.Run("wrong current password", func(t *testing.T) {
+		mockUsersRepo.EXPECT().GetById(ctx, user.ID).Return(user, nil).Times(1)
+
+		err := service.ChangePassword(ctx, user.ID, ChangePasswordRequest{CurrentPassword: "wrong", NewPassword: "new password"})
+		assert.Equal(t, locale.ErrorInvalidCredentials, err.Error())
+		ctrl.Finish()
+	})
+}
+
+func TestService_ChangeEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockUsersRepo := NewMockRepository(ctrl)
+	mockEmailService := email.NewMockService(ctrl)
+	v := validator.New()
+	logger := zap.NewNop().Sugar()
+	service := GetService(logger, mockUsersRepo, v, mockEmailService)
+	ctx := context.Background()
+
+	pw, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	user := User{Model: gorm.Model{ID: 1}, Email: "old@test.com", FirstName: "test", Password: string(pw), IsEmailVerified: true}
+
+	t.Run("keeps the old email until the new one is verified", func(t *testing.T) {
+		mockUsersRepo.EXPECT().GetById(ctx, user.ID).Return(user, nil).Times(1)
+		mockUsersRepo.EXPECT().GetByEmail(ctx, "new@test.com").Return(User{}, gorm.ErrRecordNotFound).Times(1)
+
+		var token string
+		mockUsersRepo.
+			EXPECT().
+			Update(ctx, user.ID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uint, updates map[string]interface{}) error {
+				assert.Equal(t, "new@test.com", updates["pending_email"])
+				assert.NotContains(t, updates, "email")
+				token = updates["email_verification_token"].(string)
+				return nil
+			}).
+			Times(1)
+		mockEmailService.
+			EXPECT().
+			SendVerificationEmail("new@test.com", user.FirstName, gomock.Any()).
+			DoAndReturn(func(_ string, _ string, verificationToken string) error {
+				assert.Equal(t, token, verificationToken)
+				return nil
+			}).
+			Times(1)
+		mockEmailService.EXPECT().Send("old@test.com", gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		err := service.ChangeEmail(ctx, user.ID, ChangeEmailRequest{NewEmail: "New@test.com", CurrentPassword: "password"})
+		assert.NoError(t, err)
+		ctrl.Finish()
+	})
+
+	t.Run("email of another user", func(t *testing.T) {
+		mockUsersRepo.EXPECT().GetById(ctx, user.ID).Return(user, nil).Times(1)
+		mockUsersRepo.EXPECT().GetByEmail(ctx, "taken@test.com").Return(User{Model: gorm.Model{ID: 2}}, nil).Times(1)
+
+		err := service.ChangeEmail(ctx, user.ID, ChangeEmailRequest{NewEmail: "taken@test.com", CurrentPassword: "password"})
+		assert.Equal(t, locale.ErrorEmailTaken, err.Error())
+		ctrl.Finish()
+	})
+
+	t.Run("wrong current password", func(t *testing.T) {
+		mockUsersRepo.EXPECT().GetById(ctx, user.ID).Return(user, nil).Times(1)
+
+		err := service.ChangeEmail(ctx, user.ID, ChangeEmailRequest{NewEmail: "new@test.com", CurrentPassword: "wrong"})
+		assert.Equal(t, locale.ErrorInvalidCredentials, err.Error())
+		ctrl.Finish()
+	})
+
+	t.Run("verifying the new email replaces the old one", func(t *testing.T) {
+		expTime := time.Now().Add(time.Hour)
+		pending := user
+		pending.PendingEmail = "new@test.com"
+		pending.EmailVerificationToken = "verification_token"
+		pending.EmailVerificationExpiry = &expTime
+
+		mockUsersRepo.EXPECT().GetByEmailVerificationToken(ctx, "verification_token").Return(pending, nil).Times(1)
+		mockUsersRepo.EXPECT().GetByEmail(ctx, "new@test.com").Return(User{}, gorm.ErrRecordNotFound).Times(1)
+		mockUsersRepo.
+			EXPECT().
+			Update(ctx, user.ID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uint, updates map[string]interface{}) error {
+				assert.Equal(t, "new@test.com", updates["email"])
+				assert.Equal(t, "", updates["pending_email"])
+				return nil
+			}).
+			Times(1)
+
+		var events []Event
+		service.Subscribe(func(_ context.Context, event Event) {
+			events = append(events, event)
+		})
+
+		err := service.VerifyEmail(ctx, "verification_token")
+		assert.NoError(t, err)
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, EventUpdated, events[0].Type)
+			assert.Equal(t, "new@test.com", events[0].User.Email)
+		}
+		ctrl.Finish()
+	})
+}
@@ -0,0 +1,332 @@
+package webhooks
+
+import (
+	"net/http"
+	"strconv"
+	"todo-app/internal/auth"
+	"todo-app/pkg/authz"
+	e "todo-app/pkg/errors"
+	"todo-app/pkg/handlers"
+	"todo-app/pkg/locale"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type endpointHandler struct {
+	logger  *zap.SugaredLogger
+	service Service
+	e       *echo.Echo
+}
+
+func GetEndpointHandler(
+	logger *zap.SugaredLogger,
+	service Service,
+	e *echo.Echo,
+) handlers.EndpointHandler {
+	return &endpointHandler{
+		logger:  logger,
+		service: service,
+		e:       e,
+	}
+}
+
+func (h *endpointHandler) AddEndpoints() {
+	var endpoints = []handlers.Endpoint{
+		{
+			Method:  http.MethodPost,
+			Path:    "/webhooks",
+			Handler: h.create,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/webhooks",
+			Handler: h.getAll,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/webhooks/:id",
+			Handler: h.getById,
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/webhooks/:id",
+			Handler: h.update,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/webhooks/:id",
+			Handler: h.delete,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/webhooks/:id/deliveries",
+			Handler: h.getDeliveries,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/webhooks/:id/deliveries/:delivery_id/redeliver",
+			Handler: h.redeliver,
+		},
+	}
+
+	for _, endpoint := range endpoints {
+		handlers.Method(h.e, endpoint.Method, endpoint.Path, endpoint.Handler)
+	}
+}
+
+// @Summary Create webhook
+// @Description This endpoint registers a URL that the chosen events of the current user are posted to.
+// @Description The payloads are signed with the secret, which is generated when none is given and only returned here.
+// @Tags webhooks
+// @ID createWebhook
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param webhook body WebhookInput true "Webhook"
+// @Success 201 {object} WebhookResponse
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Router /webhooks [post]
+func (h *endpointHandler) create(ctx echo.Context) error {
+	h.logger.Infow("creating webhook...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	var input WebhookInput
+	err := ctx.Bind(&input)
+	if err != nil {
+		h.logger.Warn("could not bind body to webhook struct", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+	}
+
+	response, err := h.service.Create(ctx.Request().Context(), userId, input)
+	if err != nil {
+		h.logger.Warn("could not create webhook", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusCreated, response)
+}
+
+// @Summary Get webhooks
+// @Description This endpoint returns the webhooks of the current user
+// @Tags webhooks
+// @ID getWebhooks
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} Webhook
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /webhooks [get]
+func (h *endpointHandler) getAll(ctx echo.Context) error {
+	h.logger.Infow("getting webhooks...")
+
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	webhooks, err := h.service.GetAllForUser(ctx.Request().Context(), userId)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorInternalServer, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, webhooks)
+}
+
+// @Summary Get webhook
+// @Description This endpoint returns a webhook of the current user
+// @Tags webhooks
+// @ID getWebhook
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} Webhook
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /webhooks/{id} [get]
+func (h *endpointHandler) getById(ctx echo.Context) error {
+	h.logger.Infow("getting webhook...")
+
+	webhook, ok, err := h.getOwnWebhook(ctx, authz.ActionRead)
+	if !ok {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, webhook)
+}
+
+// @Summary Update webhook
+// @Description This endpoint replaces the URL and events of a webhook. The secret is only changed when one is given.
+// @Tags webhooks
+// @ID updateWebhook
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param webhook body WebhookInput true "Webhook"
+// @Success 200 {object} Webhook
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /webhooks/{id} [put]
+func (h *endpointHandler) update(ctx echo.Context) error {
+	h.logger.Infow("updating webhook...")
+
+	webhook, ok, err := h.getOwnWebhook(ctx, authz.ActionUpdate)
+	if !ok {
+		return err
+	}
+
+	var input WebhookInput
+	err = ctx.Bind(&input)
+	if err != nil {
+		h.logger.Warn("could not bind body to webhook struct", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+	}
+
+	webhook, err = h.service.Update(ctx.Request().Context(), webhook, input)
+	if err != nil {
+		h.logger.Warn("could not update webhook", "error", err.Error())
+
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidBody, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, webhook)
+}
+
+// @Summary Delete webhook
+// @Description This endpoint deletes a webhook, its pending deliveries are not sent
+// @Tags webhooks
+// @ID deleteWebhook
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 204
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /webhooks/{id} [delete]
+func (h *endpointHandler) delete(ctx echo.Context) error {
+	h.logger.Infow("deleting webhook...")
+
+	webhook, ok, err := h.getOwnWebhook(ctx, authz.ActionDelete)
+	if !ok {
+		return err
+	}
+
+	err = h.service.Delete(ctx.Request().Context(), webhook.ID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorCouldNotDelete, Details: err.Error()})
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// @Summary Get webhook deliveries
+// @Description This endpoint returns the latest 100 deliveries of a webhook, newest first, with the outcome of their last attempt
+// @Tags webhooks
+// @ID getWebhookDeliveries
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {array} Delivery
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Failure 500 {object} errors.ResponseError "Internal Server Error"
+// @Router /webhooks/{id}/deliveries [get]
+func (h *endpointHandler) getDeliveries(ctx echo.Context) error {
+	h.logger.Infow("getting webhook deliveries...")
+
+	webhook, ok, err := h.getOwnWebhook(ctx, authz.ActionRead)
+	if !ok {
+		return err
+	}
+
+	deliveries, err := h.service.GetDeliveries(ctx.Request().Context(), webhook.ID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorInternalServer, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, deliveries)
+}
+
+// @Summary Redeliver webhook delivery
+// @Description This endpoint sends the payload of a delivery again, as a new delivery with the same event id
+// @Tags webhooks
+// @ID redeliverWebhook
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param delivery_id path int true "Delivery ID"
+// @Success 202 {object} Delivery
+// @Failure 400 {object} errors.ResponseError "Bad Request"
+// @Failure 401 {object} errors.ResponseError "Unauthorized"
+// @Failure 404 {object} errors.ResponseError "Not Found"
+// @Router /webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (h *endpointHandler) redeliver(ctx echo.Context) error {
+	h.logger.Infow("redelivering webhook delivery...")
+
+	webhook, ok, err := h.getOwnWebhook(ctx, authz.ActionUpdate)
+	if !ok {
+		return err
+	}
+
+	deliveryId, err := strconv.ParseUint(ctx.Param("delivery_id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidID, Details: err.Error()})
+	}
+
+	delivery, err := h.service.Redeliver(ctx.Request().Context(), webhook, uint(deliveryId))
+	if err != nil {
+		if err.Error() == locale.ErrorNotFoundRecord {
+			return ctx.JSON(http.StatusNotFound, e.ResponseError{Message: locale.ErrorNotFoundRecord})
+		}
+
+		return ctx.JSON(http.StatusInternalServerError, e.ResponseError{Message: locale.ErrorInternalServer, Details: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusAccepted, delivery)
+}
+
+// getOwnWebhook : the webhook of the id in the url if it belongs to the current user,
+// otherwise the error response is written and ok is false
+func (h *endpointHandler) getOwnWebhook(ctx echo.Context, action authz.Action) (Webhook, bool, error) {
+	userId := auth.GetUserIdFromContext(ctx)
+	if userId == 0 {
+		return Webhook{}, false, ctx.JSON(http.StatusUnauthorized, e.ResponseError{Message: "Unauthorized"})
+	}
+
+	id, err := handlers.GetUrlId(ctx, h.logger)
+	if err != nil {
+		h.logger.Warn("could not get id from url", "error", err.Error())
+
+		return Webhook{}, false, ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorInvalidID})
+	}
+
+	webhook, err := h.service.GetById(ctx.Request().Context(), id)
+	if err != nil {
+		if authz.Missing(err) {
+			return Webhook{}, false, authz.Deny(ctx)
+		}
+		h.logger.Warn("could not get webhook", "error", err.Error())
+
+		return Webhook{}, false, ctx.JSON(http.StatusBadRequest, e.ResponseError{Message: locale.ErrorNotFoundRecord})
+	}
+	if err := authz.Authorize(WebhookPolicy, userId, action, webhook); err != nil {
+		h.logger.Info("user tried to access webhook of other user")
+
+		return Webhook{}, false, authz.Deny(ctx)
+	}
+
+	return webhook, true, nil
+}
@@ -0,0 +1,237 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"todo-app/pkg/locale"
+
+	localErr "todo-app/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestHandler_Create(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	h := &endpointHandler{logger: zap.NewNop().Sugar(), service: mockService, e: e}
+
+	newContext := func(body string, userId uint) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("user_id", userId)
+
+		return ctx, rec
+	}
+
+	t.Run("returns the secret once", func(t *testing.T) {
+		ctx, rec := newContext(`{"url": "https://example.com/hook", "events": ["todo.created"]}`, 1)
+
+		input := WebhookInput{URL: "https://example.com/hook", Events: []string{EventTodoCreated}}
+		mockService.
+			EXPECT().
+			Create(ctx.Request().Context(), uint(1), input).
+			Return(WebhookResponse{Webhook: Webhook{Model: gorm.Model{ID: 3}, Secret: "s3cr3t"}, Secret: "s3cr3t"}, nil).
+			Times(1)
+
+		if assert.NoError(t, h.create(ctx)) {
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Equal(t, 1, strings.Count(rec.Body.String(), "s3cr3t"))
+		}
+	})
+
+	t.Run("invalid webhook", func(t *testing.T) {
+		ctx, rec := newContext(`{"url": "example.com"}`, 1)
+
+		mockService.EXPECT().Create(ctx.Request().Context(), uint(1), gomock.Any()).Return(WebhookResponse{}, errors.New("Key: 'WebhookInput.URL' Error")).Times(1)
+
+		if assert.NoError(t, h.create(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var response localErr.ResponseError
+			err := json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, locale.ErrorInvalidBody, response.Message)
+		}
+	})
+
+	ctrl.Finish()
+}
+
+func TestHandler_GetDeliveries(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	h := &endpointHandler{logger: zap.NewNop().Sugar(), service: mockService, e: e}
+
+	newContext := func(id string, userId uint) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetPath("/webhooks/:id/deliveries")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(id)
+		ctx.Set("user_id", userId)
+
+		return ctx, rec
+	}
+
+	t.Run("returns the deliveries", func(t *testing.T) {
+		ctx, rec := newContext("3", 1)
+
+		mockService.EXPECT().GetById(ctx.Request().Context(), uint(3)).Return(Webhook{Model: gorm.Model{ID: 3}, UserId: 1}, nil).Times(1)
+		mockService.
+			EXPECT().
+			GetDeliveries(ctx.Request().Context(), uint(3)).
+			Return([]Delivery{{Model: gorm.Model{ID: 9}, Status: DeliveryFailed, LastStatusCode: 500}}, nil).
+			Times(1)
+
+		if assert.NoError(t, h.getDeliveries(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var response []Delivery
+			err := json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, DeliveryFailed, response[0].Status)
+		}
+	})
+
+	t.Run("webhook of another user", func(t *testing.T) {
+		ctx, rec := newContext("3", 2)
+
+		mockService.EXPECT().GetById(ctx.Request().Context(), uint(3)).Return(Webhook{Model: gorm.Model{ID: 3}, UserId: 1}, nil).Times(1)
+
+		if assert.NoError(t, h.getDeliveries(ctx)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+		}
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		ctx, rec := newContext("abc", 1)
+
+		if assert.NoError(t, h.getDeliveries(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	ctrl.Finish()
+}
+
+func TestHandler_Redeliver(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	h := &endpointHandler{logger: zap.NewNop().Sugar(), service: mockService, e: e}
+	webhook := Webhook{Model: gorm.Model{ID: 3}, UserId: 1}
+
+	newContext := func(deliveryId string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetPath("/webhooks/:id/deliveries/:delivery_id/redeliver")
+		ctx.SetParamNames("id", "delivery_id")
+		ctx.SetParamValues("3", deliveryId)
+		ctx.Set("user_id", uint(1))
+
+		return ctx, rec
+	}
+
+	t.Run("queues the delivery", func(t *testing.T) {
+		ctx, rec := newContext("9")
+
+		mockService.EXPECT().GetById(ctx.Request().Context(), uint(3)).Return(webhook, nil).Times(1)
+		mockService.EXPECT().Redeliver(ctx.Request().Context(), webhook, uint(9)).Return(Delivery{Model: gorm.Model{ID: 10}, Status: DeliveryPending}, nil).Times(1)
+
+		if assert.NoError(t, h.redeliver(ctx)) {
+			assert.Equal(t, http.StatusAccepted, rec.Code)
+		}
+	})
+
+	t.Run("unknown delivery", func(t *testing.T) {
+		ctx, rec := newContext("8")
+
+		mockService.EXPECT().GetById(ctx.Request().Context(), uint(3)).Return(webhook, nil).Times(1)
+		mockService.EXPECT().Redeliver(ctx.Request().Context(), webhook, uint(8)).Return(Delivery{}, errors.New(locale.ErrorNotFoundRecord)).Times(1)
+
+		if assert.NoError(t, h.redeliver(ctx)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+		}
+	})
+
+	ctrl.Finish()
+}
+
+func TestHandler_CrossUserAccess(t *testing.T) {
+	os.Setenv("APP_ENV", "test")
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+	e := echo.New()
+	h := &endpointHandler{logger: zap.NewNop().Sugar(), service: mockService, e: e}
+
+	// webhook 3 belongs to user 2, user 1 tries to reach it
+	webhook := Webhook{Model: gorm.Model{ID: 3}, UserId: 2}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		id      string
+		handler echo.HandlerFunc
+	}{
+		{"read webhook", http.MethodGet, "/webhooks/:id", "3", h.getById},
+		{"update webhook", http.MethodPut, "/webhooks/:id", "3", h.update},
+		{"delete webhook", http.MethodDelete, "/webhooks/:id", "3", h.delete},
+		{"read deliveries", http.MethodGet, "/webhooks/:id/deliveries", "3", h.getDeliveries},
+		{"redeliver", http.MethodPost, "/webhooks/:id/deliveries/:delivery_id/redeliver", "3", h.redeliver},
+		// ids that do not exist answer the same
+		{"read nonexistent webhook", http.MethodGet, "/webhooks/:id", "404", h.getById},
+		{"redeliver of nonexistent webhook", http.MethodPost, "/webhooks/:id/deliveries/:delivery_id/redeliver", "404", h.redeliver},
+	}
+	var denied string
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, "/", strings.NewReader(`{}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(req, rec)
+			ctx.Set("user_id", uint(1))
+			ctx.SetPath(test.path)
+			ctx.SetParamNames("id", "delivery_id")
+			ctx.SetParamValues(test.id, "9")
+
+			// only the lookup happens, nothing is changed
+			mockService.EXPECT().GetById(gomock.Any(), webhook.ID).Return(webhook, nil).AnyTimes()
+			mockService.EXPECT().GetById(gomock.Any(), uint(404)).Return(Webhook{}, gorm.ErrRecordNotFound).AnyTimes()
+
+			if assert.NoError(t, test.handler(ctx)) {
+				assert.Equal(t, http.StatusNotFound, rec.Code)
+
+				var responseError localErr.ResponseError
+				err := json.Unmarshal(rec.Body.Bytes(), &responseError)
+				assert.NoError(t, err)
+				assert.Equal(t, locale.ErrorNotFoundRecord, responseError.Message)
+
+				if denied == "" {
+					denied = rec.Body.String()
+				}
+				assert.Equal(t, denied, rec.Body.String())
+			}
+		})
+	}
+
+	ctrl.Finish()
+}
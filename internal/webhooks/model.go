@@ -0,0 +1,81 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Event types a webhook can subscribe to
+const (
+	EventTodoCreated       = "todo.created"
+	EventTodoUpdated       = "todo.updated"
+	EventTodoDeleted       = "todo.deleted"
+	EventUserUpdated       = "user.updated"
+	EventUserEmailVerified = "user.email_verified"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook : a URL the events of the user are posted to. The secret signs the
+// payloads, it is stored as is since the signature needs it.
+type Webhook struct {
+	gorm.Model
+	UserId uint     `gorm:"not null;index" json:"-"`
+	URL    string   `gorm:"type:varchar(2048);not null" json:"url"`
+	Events []string `gorm:"type:json;serializer:json" json:"events"`
+	Secret string   `gorm:"type:varchar(128);not null" json:"-"`
+	Active bool     `gorm:"default:true" json:"active"`
+}
+
+type WebhookInput struct {
+	URL    string   `json:"url" validate:"required,http_url,max=2048"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=todo.created todo.updated todo.deleted user.updated user.email_verified"`
+	// Secret : generated when empty
+	Secret string `json:"secret" validate:"omitempty,min=16,max=128"`
+	Active *bool  `json:"active"`
+}
+
+// WebhookResponse : the secret is only shown once, when the webhook is created
+type WebhookResponse struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+// Delivery : an event sent, or to be sent, to a webhook. Failed attempts are retried
+// at NextAttemptAt until the delivery succeeds or runs out of attempts.
+type Delivery struct {
+	gorm.Model
+	WebhookId      uint            `gorm:"not null;index" json:"webhook_id"`
+	EventId        string          `gorm:"type:varchar(36);not null;index" json:"event_id"`
+	Event          string          `gorm:"type:varchar(32);not null" json:"event"`
+	Payload        json.RawMessage `gorm:"type:json;serializer:json" json:"payload"`
+	Status         string          `gorm:"type:varchar(16);not null;index" json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `gorm:"index" json:"next_attempt_at"`
+	LastStatusCode int             `json:"last_status_code"`
+	LastError      string          `gorm:"type:varchar(1024)" json:"last_error"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+}
+
+// Payload : the JSON body posted to webhooks
+type Payload struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// UserData : the user in user events, without the password and tokens
+type UserData struct {
+	ID              uint   `json:"id"`
+	FirstName       string `json:"first_name"`
+	LastName        string `json:"last_name"`
+	Email           string `json:"email"`
+	IsEmailVerified bool   `json:"is_email_verified"`
+}
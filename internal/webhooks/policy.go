@@ -0,0 +1,8 @@
+package webhooks
+
+import "todo-app/pkg/authz"
+
+// WebhookPolicy : webhooks and their deliveries are only read and changed by their owner
+func WebhookPolicy(userId uint, _ authz.Action, webhook Webhook) bool {
+	return authz.Owns(userId, webhook.UserId)
+}
@@ -0,0 +1,167 @@
+package webhooks
+
+import (
+	"context"
+	"time"
+	"todo-app/pkg/database"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(ctx context.Context, webhook *Webhook) error
+	Save(ctx context.Context, webhook *Webhook) error
+	Delete(ctx context.Context, id uint) error
+	GetById(ctx context.Context, id uint) (Webhook, error)
+	GetAllForUser(ctx context.Context, userId uint) ([]Webhook, error)
+	GetActiveForUser(ctx context.Context, userId uint) ([]Webhook, error)
+	CreateDeliveries(ctx context.Context, deliveries []Delivery) error
+	SaveDelivery(ctx context.Context, delivery *Delivery) error
+	GetDeliveryById(ctx context.Context, id uint) (Delivery, error)
+	GetDeliveries(ctx context.Context, webhookId uint, limit int) ([]Delivery, error)
+	GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]Delivery, error)
+}
+
+type repository struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func GetRepository(logger *zap.SugaredLogger, db *gorm.DB) Repository {
+	return &repository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (r *repository) Create(ctx context.Context, webhook *Webhook) error {
+	result := r.db.WithContext(ctx).Create(webhook)
+	if result.Error != nil {
+		r.logger.Errorw("failed to create webhook", "user_id", webhook.UserId, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) Save(ctx context.Context, webhook *Webhook) error {
+	result := r.db.WithContext(ctx).Save(webhook)
+	if result.Error != nil {
+		r.logger.Errorw("failed to save webhook", "id", webhook.ID, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&Webhook{}, id)
+	if result.Error != nil {
+		r.logger.Errorw("failed to delete webhook", "id", id, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) GetById(ctx context.Context, id uint) (Webhook, error) {
+	var webhook Webhook
+	result := r.db.WithContext(ctx).First(&webhook, id)
+	if result.Error != nil {
+		return Webhook{}, result.Error
+	}
+
+	return webhook, nil
+}
+
+func (r *repository) GetAllForUser(ctx context.Context, userId uint) ([]Webhook, error) {
+	var webhooks []Webhook
+	result := r.db.WithContext(ctx).Where("user_id = ?", userId).Order("id").Find(&webhooks)
+	if result.Error != nil {
+		r.logger.Errorw("failed to get webhooks", "user_id", userId, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return webhooks, nil
+}
+
+func (r *repository) GetActiveForUser(ctx context.Context, userId uint) ([]Webhook, error) {
+	var webhooks []Webhook
+	result := r.db.WithContext(ctx).Where("user_id = ? AND active = ?", userId, true).Find(&webhooks)
+	if result.Error != nil {
+		r.logger.Errorw("failed to get active webhooks", "user_id", userId, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return webhooks, nil
+}
+
+// CreateDeliveries : joins the transaction of the write that caused the event, if
+// there is one
+func (r *repository) CreateDeliveries(ctx context.Context, deliveries []Delivery) error {
+	result := database.Conn(ctx, r.db).Create(&deliveries)
+	if result.Error != nil {
+		r.logger.Errorw("failed to create webhook deliveries", "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) SaveDelivery(ctx context.Context, delivery *Delivery) error {
+	result := r.db.WithContext(ctx).Save(delivery)
+	if result.Error != nil {
+		r.logger.Errorw("failed to save webhook delivery", "id", delivery.ID, "error", result.Error)
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *repository) GetDeliveryById(ctx context.Context, id uint) (Delivery, error) {
+	var delivery Delivery
+	result := r.db.WithContext(ctx).First(&delivery, id)
+	if result.Error != nil {
+		return Delivery{}, result.Error
+	}
+
+	return delivery, nil
+}
+
+// GetDeliveries : the latest deliveries of the webhook, newest first
+func (r *repository) GetDeliveries(ctx context.Context, webhookId uint, limit int) ([]Delivery, error) {
+	var deliveries []Delivery
+	result := r.db.WithContext(ctx).Where("webhook_id = ?", webhookId).Order("id DESC").Limit(limit).Find(&deliveries)
+	if result.Error != nil {
+		r.logger.Errorw("failed to get webhook deliveries", "webhook_id", webhookId, "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return deliveries, nil
+}
+
+// GetDueDeliveries : pending deliveries whose next attempt is due, oldest first
+func (r *repository) GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]Delivery, error) {
+	var deliveries []Delivery
+	result := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", DeliveryPending, now).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&deliveries)
+	if result.Error != nil {
+		r.logger.Errorw("failed to get due webhook deliveries", "error", result.Error)
+
+		return nil, result.Error
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,237 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+	"todo-app/internal/todos"
+	"todo-app/internal/users"
+	"todo-app/pkg/locale"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// deliveryLogSize : the most deliveries returned for a webhook
+	deliveryLogSize = 100
+	// requestTimeout : how long a receiver has to answer
+	requestTimeout = 10 * time.Second
+	// pollInterval : how often the worker looks for due retries
+	pollInterval = 10 * time.Second
+)
+
+type Service interface {
+	Create(ctx context.Context, userId uint, input WebhookInput) (WebhookResponse, error)
+	Update(ctx context.Context, webhook Webhook, input WebhookInput) (Webhook, error)
+	Delete(ctx context.Context, id uint) error
+	GetById(ctx context.Context, id uint) (Webhook, error)
+	GetAllForUser(ctx context.Context, userId uint) ([]Webhook, error)
+	GetDeliveries(ctx context.Context, webhookId uint) ([]Delivery, error)
+	Redeliver(ctx context.Context, webhook Webhook, deliveryId uint) (Delivery, error)
+	HandleTodoEvent(ctx context.Context, event todos.Event)
+	HandleUserEvent(ctx context.Context, event users.Event)
+	Run(ctx context.Context)
+}
+
+type service struct {
+	logger     *zap.SugaredLogger
+	repository Repository
+	validator  *validator.Validate
+	client     *http.Client
+	now        func() time.Time
+	// wake : tells the worker that new deliveries are due
+	wake         chan struct{}
+	pollInterval time.Duration
+}
+
+func GetService(logger *zap.SugaredLogger, repo Repository, validator *validator.Validate) Service {
+	return &service{
+		logger:       logger,
+		repository:   repo,
+		validator:    validator,
+		client:       newClient(),
+		now:          time.Now,
+		wake:         make(chan struct{}, 1),
+		pollInterval: pollInterval,
+	}
+}
+
+// newClient : redirects are not followed, receivers have to answer at the URL they
+// registered
+func newClient() *http.Client {
+	return &http.Client{
+		Timeout: requestTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func (s *service) Create(ctx context.Context, userId uint, input WebhookInput) (WebhookResponse, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return WebhookResponse{}, err
+	}
+
+	secret := input.Secret
+	if secret == "" {
+		var err error
+		if secret, err = generateSecret(); err != nil {
+			return WebhookResponse{}, err
+		}
+	}
+	webhook := Webhook{
+		UserId: userId,
+		URL:    input.URL,
+		Events: input.Events,
+		Secret: secret,
+		Active: input.Active == nil || *input.Active,
+	}
+	if err := s.repository.Create(ctx, &webhook); err != nil {
+		return WebhookResponse{}, err
+	}
+
+	return WebhookResponse{Webhook: webhook, Secret: secret}, nil
+}
+
+// Update : replaces the URL and events, the secret is only changed when one is given
+func (s *service) Update(ctx context.Context, webhook Webhook, input WebhookInput) (Webhook, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return Webhook{}, err
+	}
+
+	webhook.URL = input.URL
+	webhook.Events = input.Events
+	if input.Secret != "" {
+		webhook.Secret = input.Secret
+	}
+	if input.Active != nil {
+		webhook.Active = *input.Active
+	}
+	if err := s.repository.Save(ctx, &webhook); err != nil {
+		return Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+func (s *service) Delete(ctx context.Context, id uint) error {
+	return s.repository.Delete(ctx, id)
+}
+
+func (s *service) GetById(ctx context.Context, id uint) (Webhook, error) {
+	return s.repository.GetById(ctx, id)
+}
+
+func (s *service) GetAllForUser(ctx context.Context, userId uint) ([]Webhook, error) {
+	return s.repository.GetAllForUser(ctx, userId)
+}
+
+func (s *service) GetDeliveries(ctx context.Context, webhookId uint) ([]Delivery, error) {
+	return s.repository.GetDeliveries(ctx, webhookId, deliveryLogSize)
+}
+
+// Redeliver : sends the payload of a delivery again, as a new delivery with the same
+// event id
+func (s *service) Redeliver(ctx context.Context, webhook Webhook, deliveryId uint) (Delivery, error) {
+	delivery, err := s.repository.GetDeliveryById(ctx, deliveryId)
+	if err != nil || delivery.WebhookId != webhook.ID {
+		return Delivery{}, errors.New(locale.ErrorNotFoundRecord)
+	}
+
+	now := s.now()
+	again := []Delivery{{
+		WebhookId:     webhook.ID,
+		EventId:       delivery.EventId,
+		Event:         delivery.Event,
+		Payload:       delivery.Payload,
+		Status:        DeliveryPending,
+		NextAttemptAt: &now,
+	}}
+	if err := s.repository.CreateDeliveries(ctx, again); err != nil {
+		return Delivery{}, err
+	}
+	s.notify()
+
+	return again[0], nil
+}
+
+func (s *service) HandleTodoEvent(ctx context.Context, event todos.Event) {
+	s.enqueue(ctx, event.UserId, "todo."+event.Type, event.Item)
+}
+
+func (s *service) HandleUserEvent(ctx context.Context, event users.Event) {
+	user := event.User
+	data := UserData{
+		ID:              user.ID,
+		FirstName:       user.FirstName,
+		LastName:        user.LastName,
+		Email:           user.Email,
+		IsEmailVerified: user.IsEmailVerified,
+	}
+	s.enqueue(ctx, user.ID, "user."+event.Type, data)
+}
+
+// enqueue : creates a delivery of the event for every active webhook of the user that
+// subscribed to it. They are sent by the worker, not while the caller waits.
+func (s *service) enqueue(ctx context.Context, userId uint, event string, data any) {
+	webhooks, err := s.repository.GetActiveForUser(ctx, userId)
+	if err != nil {
+		return
+	}
+	webhooks = slices.DeleteFunc(webhooks, func(webhook Webhook) bool {
+		return !slices.Contains(webhook.Events, event)
+	})
+	if len(webhooks) == 0 {
+		return
+	}
+
+	// all deliveries of an event share its id, receivers use it to drop duplicates
+	eventId := uuid.New().String()
+	now := s.now()
+	payload, err := json.Marshal(Payload{ID: eventId, Type: event, CreatedAt: now.UTC(), Data: data})
+	if err != nil {
+		s.logger.Errorw("could not encode webhook payload", "event", event, "error", err)
+
+		return
+	}
+
+	deliveries := make([]Delivery, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		deliveries = append(deliveries, Delivery{
+			WebhookId:     webhook.ID,
+			EventId:       eventId,
+			Event:         event,
+			Payload:       payload,
+			Status:        DeliveryPending,
+			NextAttemptAt: &now,
+		})
+	}
+	if err := s.repository.CreateDeliveries(ctx, deliveries); err != nil {
+		return
+	}
+	s.notify()
+}
+
+func (s *service) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+		// the worker is already woken up
+	}
+}
+
+func generateSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,282 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo-app/internal/todos"
+	"todo-app/internal/users"
+	"todo-app/pkg/locale"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var testNow = time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+func newTestService(repo Repository) *service {
+	s := GetService(zap.NewNop().Sugar(), repo, validator.New()).(*service)
+	s.now = func() time.Time { return testNow }
+
+	return s
+}
+
+func TestService_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	s := newTestService(mockRepo)
+	ctx := context.Background()
+
+	t.Run("generates a secret", func(t *testing.T) {
+		mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil).Times(1)
+
+		response, err := s.Create(ctx, 1, WebhookInput{URL: "https://example.com/hook", Events: []string{EventTodoCreated}})
+		assert.NoError(t, err)
+		assert.Len(t, response.Secret, 64)
+		assert.Equal(t, response.Secret, response.Webhook.Secret)
+		assert.True(t, response.Active)
+		assert.Equal(t, uint(1), response.UserId)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := s.Create(ctx, 1, WebhookInput{URL: "ftp://example.com", Events: []string{EventTodoCreated}})
+		assert.Error(t, err)
+
+		_, err = s.Create(ctx, 1, WebhookInput{URL: "https://example.com/hook", Events: []string{"todo.moved"}})
+		assert.Error(t, err)
+
+		_, err = s.Create(ctx, 1, WebhookInput{URL: "https://example.com/hook", Events: []string{EventTodoCreated}, Secret: "short"})
+		assert.Error(t, err)
+	})
+
+	ctrl.Finish()
+}
+
+func TestService_HandleTodoEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	s := newTestService(mockRepo)
+	ctx := context.Background()
+
+	mockRepo.
+		EXPECT().
+		GetActiveForUser(ctx, uint(1)).
+		Return([]Webhook{
+			{Model: gorm.Model{ID: 1}, Events: []string{EventTodoCreated, EventTodoUpdated}},
+			{Model: gorm.Model{ID: 2}, Events: []string{EventTodoDeleted}},
+			{Model: gorm.Model{ID: 3}, Events: []string{EventTodoUpdated}},
+		}, nil).
+		Times(1)
+	mockRepo.
+		EXPECT().
+		CreateDeliveries(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, deliveries []Delivery) error {
+			assert.Len(t, deliveries, 2)
+			assert.Equal(t, uint(1), deliveries[0].WebhookId)
+			assert.Equal(t, uint(3), deliveries[1].WebhookId)
+			assert.Equal(t, deliveries[0].EventId, deliveries[1].EventId)
+			assert.Equal(t, DeliveryPending, deliveries[0].Status)
+			assert.Equal(t, testNow, *deliveries[0].NextAttemptAt)
+
+			var payload struct {
+				ID   string
+				Type string
+				Data todos.ToDoItem
+			}
+			assert.NoError(t, json.Unmarshal(deliveries[0].Payload, &payload))
+			assert.Equal(t, deliveries[0].EventId, payload.ID)
+			assert.Equal(t, EventTodoUpdated, payload.Type)
+			assert.Equal(t, "Report", payload.Data.Text)
+			return nil
+		}).
+		Times(1)
+
+	s.HandleTodoEvent(ctx, todos.Event{Type: todos.EventUpdated, UserId: 1, Item: todos.ToDoItem{Model: gorm.Model{ID: 7}, Text: "Report"}})
+	assert.Len(t, s.wake, 1)
+
+	t.Run("no webhook subscribed", func(t *testing.T) {
+		mockRepo.EXPECT().GetActiveForUser(ctx, uint(2)).Return([]Webhook{{Events: []string{EventTodoDeleted}}}, nil).Times(1)
+
+		s.HandleTodoEvent(ctx, todos.Event{Type: todos.EventCreated, UserId: 2})
+	})
+
+	ctrl.Finish()
+}
+
+func TestService_HandleUserEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	s := newTestService(mockRepo)
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetActiveForUser(ctx, uint(4)).Return([]Webhook{{Model: gorm.Model{ID: 1}, Events: []string{EventUserEmailVerified}}}, nil).Times(1)
+	mockRepo.
+		EXPECT().
+		CreateDeliveries(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, deliveries []Delivery) error {
+			assert.Equal(t, EventUserEmailVerified, deliveries[0].Event)
+			assert.NotContains(t, string(deliveries[0].Payload), "secret-hash")
+			assert.Contains(t, string(deliveries[0].Payload), `"is_email_verified":true`)
+			return nil
+		}).
+		Times(1)
+
+	user := users.User{Model: gorm.Model{ID: 4}, Email: "ann@example.com", Password: "secret-hash", IsEmailVerified: true}
+	s.HandleUserEvent(ctx, users.Event{Type: users.EventEmailVerified, User: user})
+
+	ctrl.Finish()
+}
+
+func TestService_DeliverDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	s := newTestService(mockRepo)
+	ctx := context.Background()
+
+	payload := json.RawMessage(`{"id":"e1","type":"todo.created"}`)
+	var received *http.Request
+	var body []byte
+	status := http.StatusOK
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	webhook := Webhook{Model: gorm.Model{ID: 1}, URL: receiver.URL, Secret: "0123456789abcdef", Active: true}
+	mockRepo.EXPECT().GetById(ctx, uint(1)).Return(webhook, nil).AnyTimes()
+
+	t.Run("signed delivery", func(t *testing.T) {
+		delivery := Delivery{Model: gorm.Model{ID: 5}, WebhookId: 1, EventId: "e1", Event: EventTodoCreated, Payload: payload, Status: DeliveryPending}
+		mockRepo.EXPECT().GetDueDeliveries(ctx, testNow, batchSize).Return([]Delivery{delivery}, nil).Times(1)
+		mockRepo.
+			EXPECT().
+			SaveDelivery(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, saved *Delivery) error {
+				assert.Equal(t, DeliverySucceeded, saved.Status)
+				assert.Equal(t, 1, saved.Attempts)
+				assert.Equal(t, http.StatusOK, saved.LastStatusCode)
+				assert.Nil(t, saved.NextAttemptAt)
+				assert.Equal(t, testNow, *saved.DeliveredAt)
+				return nil
+			}).
+			Times(1)
+
+		assert.Equal(t, 1, s.deliverDue(ctx))
+		assert.Equal(t, string(payload), string(body))
+		assert.Equal(t, EventTodoCreated, received.Header.Get(HeaderEvent))
+		assert.Equal(t, "e1", received.Header.Get(HeaderEventId))
+		assert.Equal(t, "5", received.Header.Get(HeaderDelivery))
+		timestamp := received.Header.Get(HeaderTimestamp)
+		assert.Equal(t, "1740819600", timestamp)
+		assert.Equal(t, "sha256="+Sign("0123456789abcdef", timestamp, body), received.Header.Get(HeaderSignature))
+	})
+
+	t.Run("failed attempts are retried later", func(t *testing.T) {
+		status = http.StatusServiceUnavailable
+		delivery := Delivery{Model: gorm.Model{ID: 6}, WebhookId: 1, Payload: payload, Status: DeliveryPending, Attempts: 2}
+		mockRepo.EXPECT().GetDueDeliveries(ctx, testNow, batchSize).Return([]Delivery{delivery}, nil).Times(1)
+		mockRepo.
+			EXPECT().
+			SaveDelivery(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, saved *Delivery) error {
+				assert.Equal(t, DeliveryPending, saved.Status)
+				assert.Equal(t, 3, saved.Attempts)
+				assert.Equal(t, http.StatusServiceUnavailable, saved.LastStatusCode)
+				assert.Equal(t, "receiver answered 503 Service Unavailable", saved.LastError)
+				assert.Equal(t, testNow.Add(2*time.Minute), *saved.NextAttemptAt)
+				return nil
+			}).
+			Times(1)
+
+		s.deliverDue(ctx)
+	})
+
+	t.Run("the last attempt fails the delivery", func(t *testing.T) {
+		delivery := Delivery{Model: gorm.Model{ID: 7}, WebhookId: 1, Payload: payload, Status: DeliveryPending, Attempts: maxAttempts - 1}
+		mockRepo.EXPECT().GetDueDeliveries(ctx, testNow, batchSize).Return([]Delivery{delivery}, nil).Times(1)
+		mockRepo.
+			EXPECT().
+			SaveDelivery(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, saved *Delivery) error {
+				assert.Equal(t, DeliveryFailed, saved.Status)
+				assert.Nil(t, saved.NextAttemptAt)
+				return nil
+			}).
+			Times(1)
+
+		s.deliverDue(ctx)
+	})
+
+	t.Run("deleted webhooks fail their deliveries", func(t *testing.T) {
+		delivery := Delivery{Model: gorm.Model{ID: 8}, WebhookId: 2, Payload: payload, Status: DeliveryPending}
+		mockRepo.EXPECT().GetDueDeliveries(ctx, testNow, batchSize).Return([]Delivery{delivery}, nil).Times(1)
+		mockRepo.EXPECT().GetById(ctx, uint(2)).Return(Webhook{}, gorm.ErrRecordNotFound).Times(1)
+		mockRepo.
+			EXPECT().
+			SaveDelivery(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, saved *Delivery) error {
+				assert.Equal(t, DeliveryFailed, saved.Status)
+				assert.Equal(t, 0, saved.Attempts)
+				return nil
+			}).
+			Times(1)
+
+		s.deliverDue(ctx)
+	})
+
+	ctrl.Finish()
+}
+
+func TestService_Redeliver(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	s := newTestService(mockRepo)
+	ctx := context.Background()
+	webhook := Webhook{Model: gorm.Model{ID: 1}}
+
+	mockRepo.
+		EXPECT().
+		GetDeliveryById(ctx, uint(5)).
+		Return(Delivery{Model: gorm.Model{ID: 5}, WebhookId: 1, EventId: "e1", Event: EventTodoCreated, Status: DeliveryFailed, Attempts: maxAttempts}, nil).
+		Times(1)
+	mockRepo.
+		EXPECT().
+		CreateDeliveries(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, deliveries []Delivery) error {
+			deliveries[0].ID = 9
+			return nil
+		}).
+		Times(1)
+
+	delivery, err := s.Redeliver(ctx, webhook, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(9), delivery.ID)
+	assert.Equal(t, "e1", delivery.EventId)
+	assert.Equal(t, DeliveryPending, delivery.Status)
+	assert.Equal(t, 0, delivery.Attempts)
+
+	t.Run("delivery of another webhook", func(t *testing.T) {
+		mockRepo.EXPECT().GetDeliveryById(ctx, uint(6)).Return(Delivery{WebhookId: 2}, nil).Times(1)
+
+		_, err := s.Redeliver(ctx, webhook, 6)
+		assert.EqualError(t, err, locale.ErrorNotFoundRecord)
+	})
+
+	ctrl.Finish()
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, retryDelay(1))
+	assert.Equal(t, time.Minute, retryDelay(2))
+	assert.Equal(t, 4*time.Minute, retryDelay(4))
+	assert.Equal(t, maxRetryDelay, retryDelay(20))
+}
@@ -0,0 +1,176 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxAttempts : a delivery fails for good after this many attempts
+	maxAttempts = 10
+	// firstRetryDelay : the wait before the first retry, it doubles with every attempt
+	firstRetryDelay = 30 * time.Second
+	// maxRetryDelay : the longest wait between two attempts
+	maxRetryDelay = 6 * time.Hour
+	// batchSize : the most deliveries sent in one round of the worker
+	batchSize = 50
+	// maxErrorLength : longer errors of receivers are cut off in the delivery log
+	maxErrorLength = 1024
+)
+
+// Signature headers, receivers check them with Sign
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderEventId   = "X-Webhook-Id"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Sign : the hex HMAC-SHA256 of the timestamp and payload joined by a dot. Signing the
+// timestamp lets receivers reject old requests that are replayed.
+func Sign(secret string, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Run : sends due deliveries until the context is done. It wakes up when events are
+// enqueued and every poll interval for retries.
+func (s *service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.deliverDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliverDue : sends the deliveries that are due, in batches, and returns how many
+// were attempted
+func (s *service) deliverDue(ctx context.Context) int {
+	attempted := 0
+	for ctx.Err() == nil {
+		deliveries, err := s.repository.GetDueDeliveries(ctx, s.now(), batchSize)
+		if err != nil {
+			return attempted
+		}
+
+		for i := range deliveries {
+			if err := s.deliver(ctx, &deliveries[i]); err != nil {
+				// saving failed, trying again right away would send it twice
+				return attempted
+			}
+			attempted++
+		}
+		if len(deliveries) < batchSize {
+			return attempted
+		}
+	}
+
+	return attempted
+}
+
+// deliver : makes one attempt and saves its outcome
+func (s *service) deliver(ctx context.Context, delivery *Delivery) error {
+	webhook, err := s.repository.GetById(ctx, delivery.WebhookId)
+	if err != nil || !webhook.Active {
+		delivery.Status = DeliveryFailed
+		delivery.NextAttemptAt = nil
+		delivery.LastError = "webhook was deleted or disabled"
+
+		return s.repository.SaveDelivery(ctx, delivery)
+	}
+
+	delivery.Attempts++
+	statusCode, err := s.post(ctx, webhook, *delivery)
+	delivery.LastStatusCode = statusCode
+	now := s.now()
+	switch {
+	case err == nil:
+		delivery.Status = DeliverySucceeded
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= maxAttempts:
+		delivery.Status = DeliveryFailed
+		delivery.NextAttemptAt = nil
+		delivery.LastError = truncate(err.Error())
+	default:
+		next := now.Add(retryDelay(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+		delivery.LastError = truncate(err.Error())
+	}
+	if err != nil {
+		s.logger.Infow("webhook delivery failed", "delivery_id", delivery.ID, "attempts", delivery.Attempts, "error", err)
+	}
+
+	return s.repository.SaveDelivery(ctx, delivery)
+}
+
+// post : sends the payload, any status other than 2xx is an error
+func (s *service) post(ctx context.Context, webhook Webhook, delivery Delivery) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "todo-app-webhooks")
+	request.Header.Set(HeaderEvent, delivery.Event)
+	request.Header.Set(HeaderEventId, delivery.EventId)
+	request.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	request.Header.Set(HeaderTimestamp, timestamp)
+	request.Header.Set(HeaderSignature, "sha256="+Sign(webhook.Secret, timestamp, delivery.Payload))
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	// read a little of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("receiver answered %s", response.Status)
+	}
+
+	return response.StatusCode, nil
+}
+
+// retryDelay : the wait after the given number of failed attempts
+func retryDelay(attempts int) time.Duration {
+	delay := firstRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxRetryDelay)
+}
+
+func truncate(message string) string {
+	if len(message) <= maxErrorLength {
+		return message
+	}
+
+	return message[:maxErrorLength]
+}
@@ -0,0 +1,57 @@
+package authz
+
+import (
+	"errors"
+	"net/http"
+	e "todo-app/pkg/errors"
+	"todo-app/pkg/locale"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// Action : what a user wants to do with a resource
+type Action string
+
+const (
+	ActionRead   Action = "read"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Policy : whether the user may perform the action on the resource. Each resource
+// package defines the policies of its own resources.
+type Policy[T any] func(userId uint, action Action, resource T) bool
+
+// ErrDenied : the error of a denied action. It reads like a missing record, so that the
+// response does not tell whether records of other users exist.
+var ErrDenied = errors.New(locale.ErrorNotFoundRecord)
+
+// StatusDenied : the status of a denied action, the one of a missing record. The user is
+// authenticated, logging in again would not help.
+const StatusDenied = http.StatusNotFound
+
+// Authorize : ErrDenied when the policy denies the action
+func Authorize[T any](policy Policy[T], userId uint, action Action, resource T) error {
+	if !policy(userId, action, resource) {
+		return ErrDenied
+	}
+
+	return nil
+}
+
+// Missing : whether the lookup of a resource failed because it does not exist. Missing
+// resources are answered with Deny as well, denials would give away which ids exist.
+func Missing(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound) || err.Error() == locale.ErrorNotFoundRecord
+}
+
+// Deny : writes the response of a denied action
+func Deny(ctx echo.Context) error {
+	return ctx.JSON(StatusDenied, e.ResponseError{Message: ErrDenied.Error()})
+}
+
+// Owns : whether the user is the owner of a resource
+func Owns(userId uint, ownerId uint) bool {
+	return userId == ownerId
+}
@@ -0,0 +1,88 @@
+package authz
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"todo-app/pkg/locale"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type note struct {
+	ownerId uint
+	shared  bool
+}
+
+// notePolicy : the owner may do anything, others may read shared notes
+func notePolicy(userId uint, action Action, resource note) bool {
+	if Owns(userId, resource.ownerId) {
+		return true
+	}
+
+	return action == ActionRead && resource.shared
+}
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name     string
+		userId   uint
+		action   Action
+		resource note
+		err      error
+	}{
+		{"owner reads", 1, ActionRead, note{ownerId: 1}, nil},
+		{"owner updates", 1, ActionUpdate, note{ownerId: 1}, nil},
+		{"owner deletes", 1, ActionDelete, note{ownerId: 1}, nil},
+		{"other user reads", 2, ActionRead, note{ownerId: 1}, ErrDenied},
+		{"other user reads shared", 2, ActionRead, note{ownerId: 1, shared: true}, nil},
+		{"other user updates shared", 2, ActionUpdate, note{ownerId: 1, shared: true}, ErrDenied},
+		{"other user deletes", 2, ActionDelete, note{ownerId: 1}, ErrDenied},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.err, Authorize(notePolicy, test.userId, test.action, test.resource))
+		})
+	}
+
+	// denials read like missing records
+	assert.Equal(t, locale.ErrorNotFoundRecord, ErrDenied.Error())
+}
+
+func TestOwns(t *testing.T) {
+	tests := []struct {
+		name    string
+		userId  uint
+		ownerId uint
+		owns    bool
+	}{
+		{"owner", 1, 1, true},
+		{"other user", 2, 1, false},
+		{"no owner", 1, 0, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.owns, Owns(test.userId, test.ownerId))
+		})
+	}
+}
+
+func TestMissing(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		missing bool
+	}{
+		{"record not found", gorm.ErrRecordNotFound, true},
+		{"wrapped record not found", fmt.Errorf("item 4: %w", gorm.ErrRecordNotFound), true},
+		{"not found of a service", errors.New(locale.ErrorNotFoundRecord), true},
+		{"denied", ErrDenied, true},
+		{"other error", gorm.ErrInvalidDB, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.missing, Missing(test.err))
+		})
+	}
+}
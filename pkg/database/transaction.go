@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// txState : the open transaction and what runs once it is committed
+type txState struct {
+	db          *gorm.DB
+	mu          sync.Mutex
+	afterCommit []func(ctx context.Context)
+}
+
+type Transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	AfterCommit(ctx context.Context, fn func(ctx context.Context))
+}
+
+type transactor struct {
+	db *gorm.DB
+}
+
+func GetTransactor(db *gorm.DB) Transactor {
+	return &transactor{
+		db: db,
+	}
+}
+
+// WithTransaction : runs fn inside a database transaction that is carried by the
+// context passed to fn. Nested calls join the outer transaction.
+func (t *transactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok {
+		return fn(ctx)
+	}
+
+	state := &txState{}
+	err := t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		state.db = tx
+		return fn(context.WithValue(ctx, txKey{}, state))
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range state.afterCommit {
+		hook(ctx)
+	}
+
+	return nil
+}
+
+// AfterCommit : runs fn once the transaction in ctx is committed, never when it is
+// rolled back. Without a transaction fn runs right away. fn gets a context that no
+// longer carries the transaction.
+func (t *transactor) AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		fn(ctx)
+		return
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	state.afterCommit = append(state.afterCommit, fn)
+}
+
+// Conn : returns the transaction stored in ctx, or db bound to ctx when there is none
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.db.WithContext(ctx)
+	}
+
+	return db.WithContext(ctx)
+}
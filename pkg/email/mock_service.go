@@ -39,6 +39,34 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// Send mocks base method.
+func (m *MockService) Send(to, subject, body string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", to, subject, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockServiceMockRecorder) Send(to, subject, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockService)(nil).Send), to, subject, body)
+}
+
+// SendPasswordResetEmail mocks base method.
+func (m *MockService) SendPasswordResetEmail(to, firstName, resetToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendPasswordResetEmail", to, firstName, resetToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendPasswordResetEmail indicates an expected call of SendPasswordResetEmail.
+func (mr *MockServiceMockRecorder) SendPasswordResetEmail(to, firstName, resetToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPasswordResetEmail", reflect.TypeOf((*MockService)(nil).SendPasswordResetEmail), to, firstName, resetToken)
+}
+
 // SendVerificationEmail mocks base method.
 func (m *MockService) SendVerificationEmail(to, firstName, verificationToken string) error {
 	m.ctrl.T.Helper()
@@ -11,6 +11,8 @@ import (
 
 type Service interface {
 	SendVerificationEmail(to, firstName, verificationToken string) error
+	SendPasswordResetEmail(to, firstName, resetToken string) error
+	Send(to, subject, body string) error
 }
 
 type service struct {
@@ -65,3 +67,53 @@ func (s *service) SendVerificationEmail(to, firstName, verificationToken string)
 	s.logger.Infow("verification email sent successfully", "to", to)
 	return nil
 }
+
+func (s *service) SendPasswordResetEmail(to, firstName, resetToken string) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", "noreply@todoapp.com")
+	m.SetHeader("To", to)
+	m.SetHeader("Subject", "Reset Your Password")
+
+	resetURL := fmt.Sprintf("%s/reset-password?token=%s", s.appURL, resetToken)
+
+	body := fmt.Sprintf(`
+<html>
+	<body>
+		<h2>Hi %s,</h2>
+		<p>Someone asked to reset the password of your Todo App account.</p>
+		<p><a href="%s">Reset Password</a></p>
+		<p>This link will expire in 1 hour. If you did not ask for it, you can ignore this email.</p>
+	</body>
+</html>
+	`, firstName, resetURL)
+
+	m.SetBody("text/html", body)
+
+	d := gomail.NewDialer(s.smtpHost, s.smtpPort, s.smtpUser, s.smtpPass)
+
+	if err := d.DialAndSend(m); err != nil {
+		s.logger.Errorw("failed to send password reset email", "error", err, "to", to)
+		return err
+	}
+
+	s.logger.Infow("password reset email sent successfully", "to", to)
+	return nil
+}
+
+// Send : sends a plain text email
+func (s *service) Send(to, subject, body string) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", "noreply@todoapp.com")
+	m.SetHeader("To", to)
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/plain", body)
+
+	d := gomail.NewDialer(s.smtpHost, s.smtpPort, s.smtpUser, s.smtpPass)
+
+	if err := d.DialAndSend(m); err != nil {
+		s.logger.Errorw("failed to send email", "error", err, "to", to)
+		return err
+	}
+
+	return nil
+}
@@ -26,6 +26,9 @@ func Method(e *echo.Echo, method string, path string, handler echo.HandlerFunc)
 		e.PUT(path, handler)
 	case "DELETE":
 		e.DELETE(path, handler)
+	case "OPTIONS", "PROPFIND", "REPORT":
+		// WebDAV methods, used by the CalDAV endpoints
+		e.Add(method, path, handler)
 	default:
 		panic("unsupported method: " + method)
 	}
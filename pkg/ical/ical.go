@@ -0,0 +1,411 @@
+// Package ical reads and writes the subset of iCalendar (RFC 5545) needed to exchange
+// todos: VCALENDAR objects holding VTODO components.
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	StatusNeedsAction = "NEEDS-ACTION"
+	StatusInProcess   = "IN-PROCESS"
+	StatusCompleted   = "COMPLETED"
+	StatusCancelled   = "CANCELLED"
+)
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405"
+	// maxLineOctets : content lines longer than this are folded
+	maxLineOctets = 75
+	// maxInputLine : the longest unfolded content line accepted while decoding
+	maxInputLine = 1 << 20
+)
+
+type Calendar struct {
+	ProdID string
+	// Name : shown by calendar clients as the calendar's title (X-WR-CALNAME)
+	Name  string
+	Todos []Todo
+}
+
+type Todo struct {
+	UID         string
+	Summary     string
+	Description string
+	Status      string
+	// Priority : 1 is the highest and 9 the lowest priority, 0 is undefined
+	Priority int
+	Due      *time.Time
+	// DueAllDay : Due is a date without a time of day
+	DueAllDay    bool
+	Completed    *time.Time
+	Created      *time.Time
+	LastModified *time.Time
+	// Stamp : when the component was written (DTSTAMP), now when zero
+	Stamp      time.Time
+	RRule      string
+	Categories []string
+}
+
+// Encode : writes cal as an iCalendar object with CRLF line endings and folded lines
+func Encode(w io.Writer, cal Calendar) error {
+	enc := &encoder{w: bufio.NewWriter(w)}
+
+	enc.line("BEGIN", "VCALENDAR")
+	enc.line("VERSION", "2.0")
+	enc.line("PRODID", cal.ProdID)
+	enc.line("CALSCALE", "GREGORIAN")
+	if cal.Name != "" {
+		enc.line("X-WR-CALNAME", escapeText(cal.Name))
+	}
+
+	for _, todo := range cal.Todos {
+		stamp := todo.Stamp
+		if stamp.IsZero() {
+			stamp = time.Now()
+		}
+
+		enc.line("BEGIN", "VTODO")
+		enc.line("UID", escapeText(todo.UID))
+		enc.line("DTSTAMP", formatDateTime(stamp))
+		enc.line("SUMMARY", escapeText(todo.Summary))
+		if todo.Description != "" {
+			enc.line("DESCRIPTION", escapeText(todo.Description))
+		}
+		if todo.Status != "" {
+			enc.line("STATUS", todo.Status)
+		}
+		if todo.Priority > 0 {
+			enc.line("PRIORITY", strconv.Itoa(todo.Priority))
+		}
+		if todo.Due != nil {
+			if todo.DueAllDay {
+				enc.line("DUE;VALUE=DATE", todo.Due.Format(dateLayout))
+			} else {
+				enc.line("DUE", formatDateTime(*todo.Due))
+			}
+		}
+		if todo.Completed != nil {
+			enc.line("COMPLETED", formatDateTime(*todo.Completed))
+		}
+		if todo.Created != nil {
+			enc.line("CREATED", formatDateTime(*todo.Created))
+		}
+		if todo.LastModified != nil {
+			enc.line("LAST-MODIFIED", formatDateTime(*todo.LastModified))
+		}
+		if todo.RRule != "" {
+			enc.line("RRULE", todo.RRule)
+		}
+		if len(todo.Categories) > 0 {
+			categories := make([]string, len(todo.Categories))
+			for i, category := range todo.Categories {
+				categories[i] = escapeText(category)
+			}
+			enc.line("CATEGORIES", strings.Join(categories, ","))
+		}
+		enc.line("END", "VTODO")
+	}
+
+	enc.line("END", "VCALENDAR")
+	if enc.err != nil {
+		return enc.err
+	}
+
+	return enc.w.Flush()
+}
+
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+// line : writes a content line, folding it after 75 octets without splitting a
+// UTF-8 sequence
+func (e *encoder) line(name string, value string) {
+	if e.err != nil {
+		return
+	}
+
+	content := name + ":" + value
+	limit := maxLineOctets
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		if _, e.err = e.w.WriteString(content[:cut] + "\r\n "); e.err != nil {
+			return
+		}
+		content = content[cut:]
+		// continuation lines start with a space, which counts towards the limit
+		limit = maxLineOctets - 1
+	}
+
+	_, e.err = e.w.WriteString(content + "\r\n")
+}
+
+func formatDateTime(t time.Time) string {
+	return t.UTC().Format(dateTimeLayout) + "Z"
+}
+
+func escapeText(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+	return replacer.Replace(value)
+}
+
+func unescapeText(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i == len(value)-1 {
+			b.WriteByte(value[i])
+			continue
+		}
+
+		i++
+		switch value[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+
+	return b.String()
+}
+
+// splitText : splits a list of text values on the commas that are not escaped
+func splitText(value string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case ',':
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, value[start:])
+}
+
+// property : a content line split into its name, parameters and value
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Decode : reads the VTODO components of an iCalendar object. Other components,
+// like events or alarms inside todos, are skipped.
+func Decode(r io.Reader) (Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return Calendar{}, err
+	}
+
+	var cal Calendar
+	var todo *Todo
+	inCalendar := false
+	// skipped : nesting depth inside components that are not read
+	skipped := 0
+	for number, line := range lines {
+		prop, err := parseProperty(line)
+		if err != nil {
+			return Calendar{}, fmt.Errorf("line %d: %w", number+1, err)
+		}
+
+		switch {
+		case prop.name == "BEGIN" && skipped > 0:
+			skipped++
+		case prop.name == "END" && skipped > 0:
+			skipped--
+		case skipped > 0:
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VCALENDAR"):
+			inCalendar = true
+		case !inCalendar:
+			return Calendar{}, errors.New("not an iCalendar object")
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VTODO") && todo == nil:
+			todo = &Todo{}
+		case prop.name == "BEGIN":
+			skipped++
+		case prop.name == "END" && strings.EqualFold(prop.value, "VTODO") && todo != nil:
+			cal.Todos = append(cal.Todos, *todo)
+			todo = nil
+		case prop.name == "END" && strings.EqualFold(prop.value, "VCALENDAR"):
+			if todo != nil {
+				return Calendar{}, errors.New("unterminated VTODO")
+			}
+
+			return cal, nil
+		case todo != nil:
+			if err := todo.set(prop); err != nil {
+				return Calendar{}, fmt.Errorf("line %d: %s: %w", number+1, prop.name, err)
+			}
+		case prop.name == "PRODID":
+			cal.ProdID = prop.value
+		case prop.name == "X-WR-CALNAME":
+			cal.Name = unescapeText(prop.value)
+		}
+	}
+
+	if !inCalendar {
+		return Calendar{}, errors.New("not an iCalendar object")
+	}
+
+	return Calendar{}, errors.New("unterminated VCALENDAR")
+}
+
+func (t *Todo) set(prop property) error {
+	var err error
+	switch prop.name {
+	case "UID":
+		t.UID = unescapeText(prop.value)
+	case "SUMMARY":
+		t.Summary = unescapeText(prop.value)
+	case "DESCRIPTION":
+		t.Description = unescapeText(prop.value)
+	case "STATUS":
+		t.Status = strings.ToUpper(prop.value)
+	case "PRIORITY":
+		t.Priority, err = strconv.Atoi(prop.value)
+	case "DUE":
+		var due time.Time
+		due, t.DueAllDay, err = parseTime(prop)
+		t.Due = &due
+	case "COMPLETED":
+		t.Completed, err = parseTimePtr(prop)
+	case "CREATED":
+		t.Created, err = parseTimePtr(prop)
+	case "LAST-MODIFIED":
+		t.LastModified, err = parseTimePtr(prop)
+	case "DTSTAMP":
+		t.Stamp, _, err = parseTime(prop)
+	case "RRULE":
+		t.RRule = prop.value
+	case "CATEGORIES":
+		for _, category := range splitText(prop.value) {
+			if category = strings.TrimSpace(unescapeText(category)); category != "" {
+				t.Categories = append(t.Categories, category)
+			}
+		}
+	}
+
+	return err
+}
+
+// unfold : the logical content lines of r, a line starting with a space or a tab
+// continues the previous one
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxInputLine)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+func parseProperty(line string) (property, error) {
+	prop := property{params: map[string]string{}}
+
+	// the name ends at the first ';' or ':', parameter values may contain quoted ':'
+	end := strings.IndexAny(line, ";:")
+	if end <= 0 {
+		return property{}, errors.New("invalid content line")
+	}
+	prop.name = strings.ToUpper(line[:end])
+
+	rest := line[end:]
+	for strings.HasPrefix(rest, ";") {
+		rest = rest[1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return property{}, errors.New("invalid parameter")
+		}
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			closing := strings.IndexByte(rest[1:], '"')
+			if closing < 0 {
+				return property{}, errors.New("unterminated quoted parameter")
+			}
+			value = rest[1 : closing+1]
+			rest = rest[closing+2:]
+		} else {
+			stop := strings.IndexAny(rest, ";:")
+			if stop < 0 {
+				return property{}, errors.New("invalid parameter")
+			}
+			value = rest[:stop]
+			rest = rest[stop:]
+		}
+		prop.params[name] = value
+	}
+
+	if !strings.HasPrefix(rest, ":") {
+		return property{}, errors.New("missing value")
+	}
+	prop.value = rest[1:]
+
+	return prop, nil
+}
+
+// parseTime : reads a DATE or DATE-TIME value. UTC times end in Z, times with a TZID
+// are converted from that zone and floating times are taken as UTC.
+func parseTime(prop property) (time.Time, bool, error) {
+	value := prop.value
+	if prop.params["VALUE"] == "DATE" || len(value) == len(dateLayout) {
+		t, err := time.Parse(dateLayout, value)
+
+		return t, true, err
+	}
+
+	loc := time.UTC
+	if strings.HasSuffix(value, "Z") {
+		value = strings.TrimSuffix(value, "Z")
+	} else if tzid := prop.params["TZID"]; tzid != "" {
+		if zone, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+			loc = zone
+		}
+	}
+
+	t, err := time.ParseInLocation(dateTimeLayout, value, loc)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	return t.UTC(), false, nil
+}
+
+func parseTimePtr(prop property) (*time.Time, error) {
+	t, _, err := parseTime(prop)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
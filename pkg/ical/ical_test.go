@@ -0,0 +1,118 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestEncodeDecode_RoundTrip(t *testing.T) {
+	stamp := time.Date(2025, time.March, 1, 8, 0, 0, 0, time.UTC)
+	cal := Calendar{
+		ProdID: "-//todo-app//todos//EN",
+		Name:   "Todos, mine",
+		Todos: []Todo{
+			{
+				UID:          "todo-1@todo-app",
+				Summary:      "Pay rent; then call landlord, \\ maybe",
+				Description:  "first line\nsecond line",
+				Status:       StatusNeedsAction,
+				Priority:     1,
+				Due:          timePtr(time.Date(2025, time.March, 3, 17, 30, 0, 0, time.UTC)),
+				Created:      timePtr(stamp),
+				LastModified: timePtr(stamp),
+				Stamp:        stamp,
+				RRule:        "FREQ=MONTHLY;BYMONTHDAY=1",
+				Categories:   []string{"finance", "home,office"},
+			},
+			{
+				UID:       "todo-2@todo-app",
+				Summary:   strings.Repeat("Überweisung prüfen ", 8),
+				Status:    StatusCompleted,
+				Due:       timePtr(time.Date(2025, time.March, 4, 0, 0, 0, 0, time.UTC)),
+				DueAllDay: true,
+				Completed: timePtr(stamp),
+				Stamp:     stamp,
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, Encode(&out, cal))
+
+	for _, line := range strings.Split(strings.TrimSuffix(out.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineOctets, line)
+	}
+	assert.Contains(t, out.String(), "DUE;VALUE=DATE:20250304\r\n")
+	assert.Contains(t, out.String(), "SUMMARY:Pay rent\\; then call landlord\\, \\\\ maybe\r\n")
+
+	decoded, err := Decode(&out)
+	assert.NoError(t, err)
+	assert.Equal(t, cal, decoded)
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("todos from another client", func(t *testing.T) {
+		input := "BEGIN:VCALENDAR\r\n" +
+			"VERSION:2.0\r\n" +
+			"PRODID:-//Other//Client//EN\r\n" +
+			"BEGIN:VTIMEZONE\r\n" +
+			"TZID:Europe/Berlin\r\n" +
+			"BEGIN:STANDARD\r\n" +
+			"DTSTART:19701025T030000\r\n" +
+			"END:STANDARD\r\n" +
+			"END:VTIMEZONE\r\n" +
+			"BEGIN:VEVENT\r\n" +
+			"UID:event-1\r\n" +
+			"SUMMARY:Not a todo\r\n" +
+			"END:VEVENT\r\n" +
+			"BEGIN:VTODO\r\n" +
+			"UID:abc-123\r\n" +
+			"SUMMARY:Renew pass\r\n" +
+			" port\r\n" +
+			"DUE;TZID=\"Europe/Berlin\":20250710T090000\r\n" +
+			"PRIORITY:5\r\n" +
+			"CATEGORIES:travel\r\n" +
+			"CATEGORIES:admin\r\n" +
+			"BEGIN:VALARM\r\n" +
+			"ACTION:DISPLAY\r\n" +
+			"DESCRIPTION:Reminder\r\n" +
+			"END:VALARM\r\n" +
+			"END:VTODO\r\n" +
+			"END:VCALENDAR\r\n"
+
+		cal, err := Decode(strings.NewReader(input))
+		assert.NoError(t, err)
+		assert.Len(t, cal.Todos, 1)
+
+		todo := cal.Todos[0]
+		assert.Equal(t, "abc-123", todo.UID)
+		assert.Equal(t, "Renew passport", todo.Summary)
+		assert.Equal(t, "", todo.Description)
+		assert.Equal(t, time.Date(2025, time.July, 10, 7, 0, 0, 0, time.UTC), *todo.Due)
+		assert.Equal(t, 5, todo.Priority)
+		assert.Equal(t, []string{"travel", "admin"}, todo.Categories)
+	})
+
+	t.Run("not a calendar", func(t *testing.T) {
+		_, err := Decode(strings.NewReader("BEGIN:VTODO\nEND:VTODO\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("unterminated calendar", func(t *testing.T) {
+		_, err := Decode(strings.NewReader("BEGIN:VCALENDAR\nBEGIN:VTODO\nUID:1\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		_, err := Decode(strings.NewReader("BEGIN:VCALENDAR\nBEGIN:VTODO\nDUE:tomorrow\nEND:VTODO\nEND:VCALENDAR\n"))
+		assert.Error(t, err)
+	})
+}
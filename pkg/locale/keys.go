@@ -11,6 +11,40 @@ const (
 	ErrorCouldNotDelete        = "error.could.not.delete"
 	ErrorCouldNotReadUser      = "error.could.not.read.user"
 	ErrorInvalidUser           = "error.invalid.user"
+	ErrorInvalidTimezone       = "error.invalid.timezone"
+	ErrorInvalidParent         = "error.invalid.parent"
+	ErrorCouldNotReadTemplate  = "error.could.not.read.template"
+	ErrorInvalidTemplate       = "error.invalid.template"
+	ErrorMissingTemplateValues = "error.missing.template.values"
+	ErrorInvalidEstimate       = "error.invalid.estimate"
+	ErrorNoRunningTimer        = "error.no.running.timer"
+	ErrorInvalidTimeEntry      = "error.invalid.time.entry"
+	ErrorCouldNotReadTimeEntry = "error.could.not.read.time.entry"
+	ErrorInvalidReportQuery    = "error.invalid.report.query"
+	ErrorInvalidStatsQuery     = "error.invalid.stats.query"
+	ErrorCouldNotReadStats     = "error.could.not.read.stats"
+	ErrorCouldNotReadHabit     = "error.could.not.read.habit"
+	ErrorNotAHabit             = "error.not.a.habit"
+	ErrorInvalidCheckIn        = "error.invalid.check.in"
+	ErrorInvalidHeatmapQuery   = "error.invalid.heatmap.query"
+	ErrorInvalidTransferFormat = "error.invalid.transfer.format"
+	ErrorInvalidImport         = "error.invalid.import"
+	ErrorInvalidImportMode     = "error.invalid.import.mode"
+	ErrorMissingExternalId     = "error.missing.external.id"
+	ErrorDuplicateExternalId   = "error.duplicate.external.id"
+	ErrorInvalidFeedToken      = "error.invalid.feed.token"
+	ErrorCouldNotSaveFeedToken = "error.could.not.save.feed.token"
+	ErrorInvalidICal           = "error.invalid.ical"
+	ErrorNotATask              = "error.not.a.task"
+	ErrorPreconditionFailed    = "error.precondition.failed"
+	ErrorInvalidSyncToken      = "error.invalid.sync.token"
+	ErrorInvalidDAVRequest     = "error.invalid.dav.request"
+	ErrorInvalidAppPassword    = "error.invalid.app.password"
+	ErrorInvalidImportSource   = "error.invalid.import.source"
+	ErrorCouldNotReadImport    = "error.could.not.read.import"
+	ErrorImportTooLarge        = "error.import.too.large"
+	ErrorInvalidMessage        = "error.invalid.message"
+	ErrorInvalidCollection     = "error.invalid.collection"
 
 	ErrorInvalidCredentials  = "error.invalid.credentials"
 	ErrorInternalServer      = "error.internal.server"
@@ -19,4 +53,12 @@ const (
 	ErrorUserNotFound        = "error.user.not.found"
 	ErrorMissingRefreshToken = "error.missing.refresh.token"
 	ErrorEmailUnverified     = "error.email.unverified"
+	ErrorInvalidResetToken   = "error.invalid.reset.token"
+	ErrorEmailTaken          = "error.email.taken"
+	ErrorMFAAlreadyEnabled   = "error.mfa.already.enabled"
+	ErrorMFANotEnrolled      = "error.mfa.not.enrolled"
+	ErrorInvalidMFACode      = "error.invalid.mfa.code"
+	ErrorTooManyMFAAttempts  = "error.too.many.mfa.attempts"
+	ErrorInvalidPasskey      = "error.invalid.passkey"
+	ErrorInsufficientScope   = "error.insufficient.scope"
 )
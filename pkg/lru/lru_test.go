@@ -0,0 +1,58 @@
+package lru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := New[string, int](2)
+	cache.Add("a", 1, time.Minute)
+	cache.Add("b", 2, time.Minute)
+
+	// a is used, b becomes the least recently used
+	value, ok := cache.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	cache.Add("c", 3, time.Minute)
+	assert.Equal(t, 2, cache.Len())
+
+	_, ok = cache.Get("b")
+	assert.False(t, ok)
+	_, ok = cache.Get("a")
+	assert.True(t, ok)
+	_, ok = cache.Get("c")
+	assert.True(t, ok)
+}
+
+func TestCache_Expires(t *testing.T) {
+	now := time.Now()
+	cache := New[string, int](2)
+	cache.now = func() time.Time { return now }
+
+	cache.Add("a", 1, time.Minute)
+	cache.Add("b", 2, 2*time.Minute)
+
+	now = now.Add(time.Minute)
+	_, ok := cache.Get("a")
+	assert.False(t, ok)
+	value, ok := cache.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+	assert.Equal(t, 1, cache.Len())
+
+	// adding again replaces the value and the expiry
+	cache.Add("b", 3, time.Minute)
+	now = now.Add(59 * time.Second)
+	value, ok = cache.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, 3, value)
+
+	cache.Remove("b")
+	_, ok = cache.Get("b")
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.Len())
+}